@@ -0,0 +1,56 @@
+// Command mock-oidc runs a local OpenID Connect provider for trying single sign-on without a
+// corporate identity provider. Point the server at it with:
+//
+//	OIDC_ISSUER=http://localhost:9400 OIDC_CLIENT_ID=gcx-cms
+//	OIDC_REDIRECT_URL=http://localhost:3000/auth/sso/callback
+//	OIDC_ROLE_MAPPING=gcx-admins=admin,gcx-editors=blogger
+//
+// Users are given with -user email[:group,group...] and are signed in without a password.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+
+	"gcx-cms/internal/shared/oidc/mock"
+)
+
+type userFlags []string
+
+func (u *userFlags) String() string     { return strings.Join(*u, " ") }
+func (u *userFlags) Set(v string) error { *u = append(*u, v); return nil }
+
+func main() {
+	addr := flag.String("addr", "localhost:9400", "address to listen on")
+	issuer := flag.String("issuer", "", "issuer URL, default http://<addr>")
+	clientID := flag.String("client-id", "gcx-cms", "client id to accept")
+	var users userFlags
+	flag.Var(&users, "user", "user as email[:group,group...], repeatable")
+	flag.Parse()
+
+	if *issuer == "" {
+		*issuer = "http://" + *addr
+	}
+	if len(users) == 0 {
+		users = userFlags{"admin@gcx.com:gcx-admins", "editor@gcx.com:gcx-editors"}
+	}
+
+	provider, err := mock.New(*issuer, *clientID)
+	if err != nil {
+		log.Fatal("Failed to create mock provider:", err)
+	}
+	for _, u := range users {
+		email, groups, _ := strings.Cut(u, ":")
+		user := mock.User{Email: email, EmailVerified: true}
+		if groups != "" {
+			user.Groups = strings.Split(groups, ",")
+		}
+		provider.AddUser(user)
+		log.Printf("User %s in groups %v", user.Email, user.Groups)
+	}
+
+	log.Printf("Mock OpenID Connect provider at %s for client %s", *issuer, *clientID)
+	log.Fatal(http.ListenAndServe(*addr, provider.Handler()))
+}
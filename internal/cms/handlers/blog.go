@@ -6,6 +6,7 @@ import (
 	"gcx-cms/internal/cms/models"
 	"gcx-cms/internal/shared/config"
 	shared_models "gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/rbac"
 	"log"
 	"net/http"
 	"strconv"
@@ -22,7 +23,7 @@ func GetPublicPosts(c *gin.Context) {
 	var posts []models.BlogPost
 
 	// Get only published posts, ordered by publish date
-	if err := config.DB.Preload("Author").
+	if err := config.DB.WithContext(c).Preload("Author").
 		Where("status = ? AND published_at IS NOT NULL", models.StatusPublished).
 		Order("published_at DESC").
 		Find(&posts).Error; err != nil {
@@ -84,10 +85,10 @@ func GetAllPosts(c *gin.Context) {
 	currentUser := user.(*shared_models.User)
 
 	var posts []models.BlogPost
-	query := config.DB.Preload("Author").Order("created_at DESC")
+	query := config.DB.WithContext(c).Preload("Author").Order("created_at DESC")
 
-	// Non-admin users can only see their own posts
-	if currentUser.Role != shared_models.RoleAdmin {
+	// Users who cannot manage every post only see their own
+	if !rbac.Can(currentUser, shared_models.PermBlogManageAll) {
 		query = query.Where("author_id = ?", currentUser.ID)
 	}
 
@@ -151,7 +152,7 @@ func CreatePost(c *gin.Context) {
 
 	// Check if slug already exists
 	var existingPost models.BlogPost
-	if err := config.DB.Where("slug = ?", slug).First(&existingPost).Error; err == nil {
+	if err := config.DB.WithContext(c).Where("slug = ?", slug).First(&existingPost).Error; err == nil {
 		// Make slug unique by appending timestamp
 		slug = slug + "-" + fmt.Sprintf("%d", time.Now().Unix())
 	}
@@ -193,13 +194,13 @@ func CreatePost(c *gin.Context) {
 		post.Publish()
 	}
 
-	if err := config.DB.Create(&post).Error; err != nil {
+	if err := config.DB.WithContext(c).Create(&post).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create post"})
 		return
 	}
 
 	// Load author for response
-	config.DB.Preload("Author").First(&post, post.ID)
+	config.DB.WithContext(c).Preload("Author").First(&post, post.ID)
 
 	c.JSON(http.StatusCreated, post)
 }
@@ -224,7 +225,7 @@ func UpdatePost(c *gin.Context) {
 
 	// Find existing post
 	var post models.BlogPost
-	if err := config.DB.First(&post, postID).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&post, postID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
 		} else {
@@ -278,7 +279,7 @@ func UpdatePost(c *gin.Context) {
 	if req.Slug != "" && req.Slug != post.Slug {
 		// Check if new slug is unique
 		var existingPost models.BlogPost
-		if err := config.DB.Where("slug = ? AND id != ?", req.Slug, post.ID).First(&existingPost).Error; err == nil {
+		if err := config.DB.WithContext(c).Where("slug = ? AND id != ?", req.Slug, post.ID).First(&existingPost).Error; err == nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Slug already exists"})
 			return
 		}
@@ -320,13 +321,13 @@ func UpdatePost(c *gin.Context) {
 	}
 
 	// Save changes
-	if err := config.DB.Save(&post).Error; err != nil {
+	if err := config.DB.WithContext(c).Save(&post).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update post"})
 		return
 	}
 
 	// Reload with relationships
-	config.DB.Preload("Author").First(&post, post.ID)
+	config.DB.WithContext(c).Preload("Author").First(&post, post.ID)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Post updated successfully", "post": post})
 }
@@ -351,7 +352,7 @@ func DeletePost(c *gin.Context) {
 
 	// Find existing post
 	var post models.BlogPost
-	if err := config.DB.First(&post, postID).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&post, postID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
 		} else {
@@ -367,7 +368,7 @@ func DeletePost(c *gin.Context) {
 	}
 
 	// Delete the post
-	if err := config.DB.Delete(&post).Error; err != nil {
+	if err := config.DB.WithContext(c).Delete(&post).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete post"})
 		return
 	}
@@ -14,7 +14,7 @@ import (
 func GetBoardMembers(c *gin.Context) {
 	var boardMembers []models.BoardMember
 	
-	if err := config.DB.Order("order_index ASC, created_at ASC").Find(&boardMembers).Error; err != nil {
+	if err := config.DB.WithContext(c).Order("order_index ASC, created_at ASC").Find(&boardMembers).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Failed to fetch board members",
@@ -52,7 +52,7 @@ func CreateBoardMember(c *gin.Context) {
 	// Get the next order index if not provided
 	if input.OrderIndex == 0 {
 		var maxOrder int
-		config.DB.Model(&models.BoardMember{}).Select("COALESCE(MAX(order_index), 0)").Scan(&maxOrder)
+		config.DB.WithContext(c).Model(&models.BoardMember{}).Select("COALESCE(MAX(order_index), 0)").Scan(&maxOrder)
 		input.OrderIndex = maxOrder + 1
 	}
 
@@ -69,7 +69,7 @@ func CreateBoardMember(c *gin.Context) {
 		UpdatedAt:   time.Now(),
 	}
 
-	if err := config.DB.Create(&boardMember).Error; err != nil {
+	if err := config.DB.WithContext(c).Create(&boardMember).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Failed to create board member",
@@ -89,7 +89,7 @@ func GetBoardMember(c *gin.Context) {
 	id := c.Param("id")
 	
 	var boardMember models.BoardMember
-	if err := config.DB.First(&boardMember, "id = ?", id).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&boardMember, "id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"success": false,
@@ -115,7 +115,7 @@ func UpdateBoardMember(c *gin.Context) {
 	id := c.Param("id")
 	
 	var boardMember models.BoardMember
-	if err := config.DB.First(&boardMember, "id = ?", id).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&boardMember, "id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"success": false,
@@ -179,7 +179,7 @@ func UpdateBoardMember(c *gin.Context) {
 		updates["order_index"] = input.OrderIndex
 	}
 
-	if err := config.DB.Model(&boardMember).Updates(updates).Error; err != nil {
+	if err := config.DB.WithContext(c).Model(&boardMember).Updates(updates).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Failed to update board member",
@@ -188,7 +188,7 @@ func UpdateBoardMember(c *gin.Context) {
 	}
 
 	// Fetch updated record
-	config.DB.First(&boardMember, "id = ?", id)
+	config.DB.WithContext(c).First(&boardMember, "id = ?", id)
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -202,7 +202,7 @@ func DeleteBoardMember(c *gin.Context) {
 	id := c.Param("id")
 	
 	var boardMember models.BoardMember
-	if err := config.DB.First(&boardMember, "id = ?", id).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&boardMember, "id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"success": false,
@@ -217,7 +217,7 @@ func DeleteBoardMember(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Delete(&boardMember).Error; err != nil {
+	if err := config.DB.WithContext(c).Delete(&boardMember).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Failed to delete board member",
@@ -250,7 +250,7 @@ func ReorderBoardMembers(c *gin.Context) {
 
 	// Update order for each member
 	for _, member := range input.Members {
-		if err := config.DB.Model(&models.BoardMember{}).
+		if err := config.DB.WithContext(c).Model(&models.BoardMember{}).
 			Where("id = ?", member.ID).
 			Update("order_index", member.Order).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -15,7 +15,7 @@ import (
 
 // GetCareers retrieves all careers with pagination and search
 func GetCareers(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	// Pagination parameters
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -73,7 +73,7 @@ func GetCareers(c *gin.Context) {
 
 // GetCareer retrieves a single career by ID
 func GetCareer(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var career models.Career
@@ -93,7 +93,7 @@ func GetCareer(c *gin.Context) {
 
 // CreateCareer creates a new career
 func CreateCareer(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	var career models.Career
 	if err := c.ShouldBindJSON(&career); err != nil {
@@ -145,7 +145,7 @@ func CreateCareer(c *gin.Context) {
 
 // UpdateCareer updates an existing career
 func UpdateCareer(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var career models.Career
@@ -255,7 +255,7 @@ func parseDateLike(value string) (time.Time, error) {
 
 // DeleteCareer deletes a career
 func DeleteCareer(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	if err := db.Delete(&models.Career{}, id).Error; err != nil {
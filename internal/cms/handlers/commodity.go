@@ -5,6 +5,8 @@ import (
 	"strconv"
 
 	"gcx-cms/internal/cms/models"
+	marketdata_models "gcx-cms/internal/marketdata/models"
+	marketdata_services "gcx-cms/internal/marketdata/services"
 	"gcx-cms/internal/shared/database"
 	"gcx-cms/internal/services"
 
@@ -13,7 +15,7 @@ import (
 
 // GetCommodities retrieves all commodities with pagination and search
 func GetCommodities(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	// Pagination parameters
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -71,7 +73,7 @@ func GetCommodities(c *gin.Context) {
 
 // GetCommodity retrieves a single commodity by ID
 func GetCommodity(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var commodity models.Commodity
@@ -91,7 +93,7 @@ func GetCommodity(c *gin.Context) {
 
 // CreateCommodity creates a new commodity
 func CreateCommodity(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	var commodity models.Commodity
 	if err := c.ShouldBindJSON(&commodity); err != nil {
@@ -118,7 +120,7 @@ func CreateCommodity(c *gin.Context) {
 
 // UpdateCommodity updates an existing commodity
 func UpdateCommodity(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var commodity models.Commodity
@@ -130,6 +132,8 @@ func UpdateCommodity(c *gin.Context) {
 		return
 	}
 
+	previousStatus := commodity.MarketStatus
+
 	if err := c.ShouldBindJSON(&commodity); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
@@ -146,6 +150,16 @@ func UpdateCommodity(c *gin.Context) {
 		return
 	}
 
+	// Suspending a commodity halts its market for webhook subscribers
+	if commodity.MarketStatus == "Suspended" && previousStatus != "Suspended" {
+		marketdata_services.GetWebhookService().Publish(marketdata_models.WebhookEventMarketHalted, gin.H{
+			"commodity_id":    commodity.ID,
+			"commodity":       commodity.Code,
+			"market_status":   commodity.MarketStatus,
+			"previous_status": previousStatus,
+		})
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    commodity,
@@ -154,7 +168,7 @@ func UpdateCommodity(c *gin.Context) {
 
 // DeleteCommodity deletes a commodity
 func DeleteCommodity(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	if err := db.Delete(&models.Commodity{}, id).Error; err != nil {
@@ -174,7 +188,7 @@ func DeleteCommodity(c *gin.Context) {
 // GetContractFilePresignedURL generates a presigned URL for a contract file
 func GetContractFilePresignedURL(c *gin.Context) {
 	commodityID := c.Param("commodityId")
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	// Get the commodity
 	var commodity models.Commodity
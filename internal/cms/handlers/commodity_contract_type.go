@@ -2,18 +2,30 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"gcx-cms/internal/cms/models"
+	"gcx-cms/internal/services"
+	"gcx-cms/internal/shared/cache"
 	"gcx-cms/internal/shared/database"
+	shared_models "gcx-cms/internal/shared/models"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-// GetAllCommodityContractTypes retrieves all contract types for a commodity
+// GetAllCommodityContractTypes retrieves all contract types for a commodity with the
+// specifications in force. Pass ?as_of=YYYY-MM-DD to see those that applied on a past date;
+// contract types with no specification in effect then keep their current one.
 func GetAllCommodityContractTypes(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	commodityID := c.Param("commodityId")
 
+	asOf, ok := contractSpecAsOf(c)
+	if !ok {
+		return
+	}
+
 	var contractTypes []models.CommodityContractType
 	if err := db.Where("commodity_id = ? AND is_active = ?", commodityID, true).
 		Order("sort_order ASC").
@@ -25,17 +37,51 @@ func GetAllCommodityContractTypes(c *gin.Context) {
 		return
 	}
 
+	if err := services.NewContractSpecService().OverlayEffective(contractTypes, asOf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to resolve contract specifications",
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    contractTypes,
+		"as_of":   asOf,
 	})
 }
 
-// GetCommodityContractType retrieves a single contract type by ID
+// contractSpecAsOf reads the ?as_of=YYYY-MM-DD date to resolve specifications at, the end of
+// that day, or now without one. It responds 400 to a malformed date.
+func contractSpecAsOf(c *gin.Context) (time.Time, bool) {
+	raw := c.Query("as_of")
+	if raw == "" {
+		return time.Now(), true
+	}
+	parsed, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid as_of format. Use YYYY-MM-DD",
+		})
+		return time.Time{}, false
+	}
+	// Include the whole day
+	return parsed.Add(24*time.Hour - time.Nanosecond), true
+}
+
+// GetCommodityContractType retrieves a single contract type by ID with the specification in force.
+// Pass ?as_of=YYYY-MM-DD to see the specification that applied on a past date.
 func GetCommodityContractType(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
+	asOf, ok := contractSpecAsOf(c)
+	if !ok {
+		return
+	}
+
 	var contractType models.CommodityContractType
 	if err := db.Preload("Commodity").First(&contractType, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
@@ -45,15 +91,28 @@ func GetCommodityContractType(c *gin.Context) {
 		return
 	}
 
+	version, err := services.NewContractSpecService().EffectiveAt(contractType.ID, asOf)
+	if err == nil {
+		version.ApplyTo(&contractType)
+	} else if c.Query("as_of") != "" {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "No specification was in effect on that date",
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    contractType,
+		"success":      true,
+		"data":         contractType,
+		"spec_version": version,
+		"as_of":        asOf,
 	})
 }
 
 // CreateCommodityContractType creates a new contract type
 func CreateCommodityContractType(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	var contractType models.CommodityContractType
 	if err := c.ShouldBindJSON(&contractType); err != nil {
@@ -72,15 +131,26 @@ func CreateCommodityContractType(c *gin.Context) {
 		return
 	}
 
+	// The initial specification becomes version 1
+	if err := services.NewContractSpecService().WithContext(c).EnsureBaseline(&contractType); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to record contract specification",
+		})
+		return
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"data":    contractType,
 	})
 }
 
-// UpdateCommodityContractType updates an existing contract type
+// UpdateCommodityContractType updates an existing contract type.
+// Descriptive fields are saved in place; specification changes are proposed as a new
+// version that only takes effect once approved.
 func UpdateCommodityContractType(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var contractType models.CommodityContractType
@@ -92,7 +162,15 @@ func UpdateCommodityContractType(c *gin.Context) {
 		return
 	}
 
-	if err := c.ShouldBindJSON(&contractType); err != nil {
+	current := models.SpecFromContractType(&contractType)
+
+	req := struct {
+		models.CommodityContractType
+		EffectiveFrom *time.Time `json:"effective_from"`
+		ChangeNotes   string     `json:"change_notes"`
+	}{CommodityContractType: contractType}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"success": false,
 			"error":   "Invalid contract type data",
@@ -100,7 +178,40 @@ func UpdateCommodityContractType(c *gin.Context) {
 		return
 	}
 
-	if err := db.Save(&contractType).Error; err != nil {
+	contractType = req.CommodityContractType
+	proposed := models.SpecFromContractType(&contractType)
+
+	// Keep the stored specification until a new version is approved
+	current.ApplyTo(&contractType)
+	if !proposed.SpecEquals(&current) && req.EffectiveFrom == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "effective_from is required to change the specification",
+		})
+		return
+	}
+
+	// The descriptive fields and any proposed version are saved together, or not at all
+	var pending *models.ContractSpecVersion
+	var proposeErr error
+	err := cache.Transaction(db, func(tx *gorm.DB) error {
+		if err := tx.Save(&contractType).Error; err != nil {
+			return err
+		}
+		if proposed.SpecEquals(&current) {
+			return nil
+		}
+		pending, proposeErr = services.NewContractSpecService().WithTx(tx).Propose(&contractType, proposed, *req.EffectiveFrom, req.ChangeNotes, currentUserID(c))
+		return proposeErr
+	})
+	if proposeErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Failed to propose specification change: " + proposeErr.Error(),
+		})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Failed to update contract type",
@@ -109,14 +220,15 @@ func UpdateCommodityContractType(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    contractType,
+		"success":         true,
+		"data":            contractType,
+		"pending_version": pending,
 	})
 }
 
 // DeleteCommodityContractType deletes a contract type
 func DeleteCommodityContractType(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var contractType models.CommodityContractType
@@ -144,7 +256,7 @@ func DeleteCommodityContractType(c *gin.Context) {
 
 // GetCommoditiesWithContractTypes retrieves all commodities with their contract types
 func GetCommoditiesWithContractTypes(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	var commodities []models.Commodity
 	if err := db.Preload("ContractTypes", "is_active = ?", true).
@@ -157,6 +269,24 @@ func GetCommoditiesWithContractTypes(c *gin.Context) {
 		return
 	}
 
+	// Resolve specifications for every contract type in one pass
+	var all []models.CommodityContractType
+	for _, commodity := range commodities {
+		all = append(all, commodity.ContractTypes...)
+	}
+	if err := services.NewContractSpecService().OverlayEffective(all, time.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to resolve contract specifications",
+		})
+		return
+	}
+	for i := range commodities {
+		n := len(commodities[i].ContractTypes)
+		copy(commodities[i].ContractTypes, all[:n])
+		all = all[n:]
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    commodities,
@@ -165,7 +295,7 @@ func GetCommoditiesWithContractTypes(c *gin.Context) {
 
 // UpdateContractTypeSortOrder updates the sort order of contract types
 func UpdateContractTypeSortOrder(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	type SortOrderUpdate struct {
 		ID        uint `json:"id" binding:"required"`
@@ -199,3 +329,13 @@ func UpdateContractTypeSortOrder(c *gin.Context) {
 		"message": "Sort order updated successfully",
 	})
 }
+
+// currentUserID returns the authenticated user's ID, or nil outside authenticated routes
+func currentUserID(c *gin.Context) *uint {
+	if user, exists := c.Get("user"); exists {
+		if u, ok := user.(*shared_models.User); ok {
+			return &u.ID
+		}
+	}
+	return nil
+}
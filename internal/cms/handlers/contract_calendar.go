@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"gcx-cms/internal/cms/models"
+	"gcx-cms/internal/services"
+	"gcx-cms/internal/shared/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCommodityContracts lists the listed contract series for a commodity
+// GET /api/commodities/:id/contracts?status=active|expired|all&contract_type_id=
+func GetCommodityContracts(c *gin.Context) {
+	db := database.GetDB().WithContext(c)
+
+	var commodity models.Commodity
+	if err := db.First(&commodity, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Commodity not found",
+		})
+		return
+	}
+
+	status := c.DefaultQuery("status", "active")
+	if status != "active" && status != "expired" && status != "all" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Status must be active, expired or all",
+		})
+		return
+	}
+
+	var contractTypeID *uint
+	if raw := c.Query("contract_type_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Invalid contract type ID",
+			})
+			return
+		}
+		ctID := uint(id)
+		contractTypeID = &ctID
+	}
+
+	series, err := services.NewContractCalendarService().ListSeries(commodity.ID, contractTypeID, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch contract series",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"commodity": commodity.Code,
+		"status":    status,
+		"data":      series,
+		"count":     len(series),
+	})
+}
+
+// GetContractCalendarRules returns the calendar rules configured for a commodity
+func GetContractCalendarRules(c *gin.Context) {
+	db := database.GetDB().WithContext(c)
+
+	var rules []models.ContractCalendarRule
+	if err := db.Where("commodity_id = ?", c.Param("id")).Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch calendar rules",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rules,
+	})
+}
+
+// SaveContractCalendarRule creates or replaces a commodity's calendar rule and generates its series.
+// When delivery_months is omitted it is derived from the commodity or contract type text.
+func SaveContractCalendarRule(c *gin.Context) {
+	db := database.GetDB().WithContext(c)
+	calendar := services.NewContractCalendarService().WithContext(c)
+
+	var commodity models.Commodity
+	if err := db.First(&commodity, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Commodity not found",
+		})
+		return
+	}
+
+	var req struct {
+		ContractTypeID        *uint  `json:"contract_type_id"`
+		DeliveryMonths        string `json:"delivery_months"`
+		MonthsAhead           *int   `json:"months_ahead"`
+		FirstNoticeDaysBefore *int   `json:"first_notice_days_before"`
+		LastTradingDaysBefore *int   `json:"last_trading_days_before"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid calendar rule data",
+		})
+		return
+	}
+
+	var contractType *models.CommodityContractType
+	if req.ContractTypeID != nil {
+		var ct models.CommodityContractType
+		if err := db.Where("id = ? AND commodity_id = ?", *req.ContractTypeID, commodity.ID).First(&ct).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Contract type does not belong to this commodity",
+			})
+			return
+		}
+		contractType = &ct
+	}
+
+	rule, err := calendar.DefaultRule(&commodity, contractType)
+	if err != nil && req.DeliveryMonths == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if rule == nil {
+		rule = &models.ContractCalendarRule{
+			CommodityID:           commodity.ID,
+			ContractTypeID:        req.ContractTypeID,
+			MonthsAhead:           12,
+			FirstNoticeDaysBefore: 2,
+			LastTradingDaysBefore: 5,
+		}
+	}
+	if req.DeliveryMonths != "" {
+		rule.DeliveryMonths = req.DeliveryMonths
+	}
+	if req.MonthsAhead != nil {
+		rule.MonthsAhead = *req.MonthsAhead
+	}
+	if req.FirstNoticeDaysBefore != nil {
+		rule.FirstNoticeDaysBefore = *req.FirstNoticeDaysBefore
+	}
+	if req.LastTradingDaysBefore != nil {
+		rule.LastTradingDaysBefore = *req.LastTradingDaysBefore
+	}
+	rule.IsActive = true
+
+	if err := calendar.SaveRule(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	generated, err := calendar.Generate(rule)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Calendar rule saved but series generation failed: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"data":      rule,
+		"generated": generated,
+	})
+}
+
+// GenerateCommodityContracts lists any new series due under a commodity's rules,
+// creating a default rule from its delivery months if none exists yet
+func GenerateCommodityContracts(c *gin.Context) {
+	db := database.GetDB().WithContext(c)
+	calendar := services.NewContractCalendarService().WithContext(c)
+
+	var commodity models.Commodity
+	if err := db.First(&commodity, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Commodity not found",
+		})
+		return
+	}
+
+	var rules []models.ContractCalendarRule
+	db.Where("commodity_id = ? AND is_active = ?", commodity.ID, true).Find(&rules)
+
+	if len(rules) == 0 {
+		rule, err := calendar.DefaultRule(&commodity, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		if err := calendar.SaveRule(rule); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to save calendar rule",
+			})
+			return
+		}
+		rules = append(rules, *rule)
+	}
+
+	total := 0
+	for i := range rules {
+		generated, err := calendar.Generate(&rules[i])
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to generate contract series: " + err.Error(),
+			})
+			return
+		}
+		total += generated
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"message":   "Contract series generated",
+		"generated": total,
+	})
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"gcx-cms/internal/cms/models"
+	"gcx-cms/internal/services"
+	"gcx-cms/internal/shared/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetContractSpecVersions lists approved specification versions for a contract type (public)
+func GetContractSpecVersions(c *gin.Context) {
+	listContractSpecVersions(c, false)
+}
+
+// GetAllContractSpecVersions lists every specification version including pending and rejected ones (protected)
+func GetAllContractSpecVersions(c *gin.Context) {
+	listContractSpecVersions(c, true)
+}
+
+func listContractSpecVersions(c *gin.Context, includeUnapproved bool) {
+	db := database.GetDB().WithContext(c)
+	id := c.Param("id")
+
+	var contractType models.CommodityContractType
+	if err := db.First(&contractType, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Contract type not found",
+		})
+		return
+	}
+
+	query := db.Where("contract_type_id = ?", contractType.ID)
+	if !includeUnapproved {
+		query = query.Where("status = ?", models.ContractSpecStatusApproved)
+	} else if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var versions []models.ContractSpecVersion
+	if err := query.Order("version DESC").Find(&versions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to load specification versions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    versions,
+	})
+}
+
+// ProposeContractSpecVersion submits a new specification version for approval
+func ProposeContractSpecVersion(c *gin.Context) {
+	db := database.GetDB().WithContext(c)
+	id := c.Param("id")
+
+	var contractType models.CommodityContractType
+	if err := db.First(&contractType, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "Contract type not found",
+		})
+		return
+	}
+
+	// Unspecified fields carry over from the current specification
+	req := struct {
+		models.ContractSpecVersion
+		EffectiveFrom *time.Time `json:"effective_from"`
+	}{ContractSpecVersion: models.SpecFromContractType(&contractType)}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid specification data",
+		})
+		return
+	}
+
+	if req.EffectiveFrom == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "effective_from is required",
+		})
+		return
+	}
+
+	version, err := services.NewContractSpecService().WithContext(c).Propose(&contractType, req.ContractSpecVersion, *req.EffectiveFrom, req.ChangeNotes, currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Specification version submitted for approval",
+		"data":    version,
+	})
+}
+
+// ApproveContractSpecVersion approves a pending specification version (admin only)
+func ApproveContractSpecVersion(c *gin.Context) {
+	versionID, err := strconv.ParseUint(c.Param("versionId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid version ID",
+		})
+		return
+	}
+
+	reviewer := currentUserID(c)
+	if reviewer == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not found in context",
+		})
+		return
+	}
+
+	version, err := services.NewContractSpecService().WithContext(c).Approve(uint(versionID), *reviewer)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Specification version approved",
+		"data":    version,
+	})
+}
+
+// RejectContractSpecVersion rejects a pending specification version (admin only)
+func RejectContractSpecVersion(c *gin.Context) {
+	versionID, err := strconv.ParseUint(c.Param("versionId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Invalid version ID",
+		})
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Rejection reason is required",
+		})
+		return
+	}
+
+	reviewer := currentUserID(c)
+	if reviewer == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "User not found in context",
+		})
+		return
+	}
+
+	version, err := services.NewContractSpecService().WithContext(c).Reject(uint(versionID), *reviewer, req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Specification version rejected",
+		"data":    version,
+	})
+}
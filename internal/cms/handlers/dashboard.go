@@ -7,6 +7,7 @@ import (
 	"gcx-cms/internal/cms/models"
 	"gcx-cms/internal/shared/config"
 	shared_models "gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/rbac"
 
 	"github.com/gin-gonic/gin"
 )
@@ -48,10 +49,10 @@ func GetDashboardStats(c *gin.Context) {
 	var totalPosts, publishedPosts, draftPosts int64
 
 	// Base query for posts
-	postQuery := config.DB.Model(&models.BlogPost{})
+	postQuery := config.DB.WithContext(c).Model(&models.BlogPost{})
 
-	// Non-admin users can only see their own posts
-	if currentUser.Role != shared_models.RoleAdmin {
+	// Users who cannot manage every post only see their own
+	if !rbac.Can(currentUser, shared_models.PermBlogManageAll) {
 		postQuery = postQuery.Where("author_id = ?", currentUser.ID)
 	}
 
@@ -71,10 +72,10 @@ func GetDashboardStats(c *gin.Context) {
 	var totalPages, publishedPages, draftPages int64
 
 	// Base query for pages
-	pageQuery := config.DB.Model(&models.Page{})
+	pageQuery := config.DB.WithContext(c).Model(&models.Page{})
 
-	// Non-admin users can only see their own pages
-	if currentUser.Role != shared_models.RoleAdmin {
+	// Users who cannot manage pages only see their own
+	if !rbac.Can(currentUser, shared_models.PermPagesManage) {
 		pageQuery = pageQuery.Where("author_id = ?", currentUser.ID)
 	}
 
@@ -114,10 +115,10 @@ func GetDashboardActivity(c *gin.Context) {
 
 	// Get recent blog posts
 	var recentPosts []models.BlogPost
-	postQuery := config.DB.Preload("Author").Order("updated_at DESC").Limit(5)
+	postQuery := config.DB.WithContext(c).Preload("Author").Order("updated_at DESC").Limit(5)
 
-	// Non-admin users can only see their own posts
-	if currentUser.Role != shared_models.RoleAdmin {
+	// Users who cannot manage every post only see their own
+	if !rbac.Can(currentUser, shared_models.PermBlogManageAll) {
 		postQuery = postQuery.Where("author_id = ?", currentUser.ID)
 	}
 
@@ -146,10 +147,10 @@ func GetDashboardActivity(c *gin.Context) {
 
 	// Get recent pages
 	var recentPages []models.Page
-	pageQuery := config.DB.Preload("Author").Order("updated_at DESC").Limit(3)
+	pageQuery := config.DB.WithContext(c).Preload("Author").Order("updated_at DESC").Limit(3)
 
-	// Non-admin users can only see their own pages
-	if currentUser.Role != shared_models.RoleAdmin {
+	// Users who cannot manage pages only see their own
+	if !rbac.Can(currentUser, shared_models.PermPagesManage) {
 		pageQuery = pageQuery.Where("author_id = ?", currentUser.ID)
 	}
 
@@ -16,7 +16,7 @@ import (
 
 // GetEvents retrieves all events (public endpoint)
 func GetEvents(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	// Parse query parameters
 	status := c.Query("status")     // upcoming, completed, cancelled
@@ -75,7 +75,7 @@ func GetEvents(c *gin.Context) {
 
 // GetEvent retrieves a single event by ID (public endpoint)
 func GetEvent(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var event models.Event
@@ -93,7 +93,7 @@ func GetEvent(c *gin.Context) {
 
 // GetEventBySlug retrieves a single event by slug (public endpoint)
 func GetEventBySlug(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	eventSlug := c.Param("slug")
 
 	var event models.Event
@@ -111,7 +111,7 @@ func GetEventBySlug(c *gin.Context) {
 
 // GetUpcomingEvents retrieves all upcoming events (public endpoint)
 func GetUpcomingEvents(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	limit := c.DefaultQuery("limit", "10")
 
 	var events []models.Event
@@ -130,7 +130,7 @@ func GetUpcomingEvents(c *gin.Context) {
 
 // GetPastEvents retrieves all past/completed events (public endpoint)
 func GetPastEvents(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	limit := c.DefaultQuery("limit", "10")
 
 	var events []models.Event
@@ -149,7 +149,7 @@ func GetPastEvents(c *gin.Context) {
 
 // GetAllEvents retrieves all events for CMS (protected endpoint)
 func GetAllEvents(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	var events []models.Event
 	if err := db.Order("date DESC").Find(&events).Error; err != nil {
@@ -162,7 +162,7 @@ func GetAllEvents(c *gin.Context) {
 
 // CreateEvent creates a new event (protected endpoint)
 func CreateEvent(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	var event models.Event
 	if err := c.ShouldBindJSON(&event); err != nil {
@@ -202,7 +202,7 @@ func CreateEvent(c *gin.Context) {
 
 // UpdateEvent updates an existing event (protected endpoint)
 func UpdateEvent(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var event models.Event
@@ -250,7 +250,7 @@ func UpdateEvent(c *gin.Context) {
 
 // DeleteEvent deletes an event (soft delete) (protected endpoint)
 func DeleteEvent(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var event models.Event
@@ -273,7 +273,7 @@ func DeleteEvent(c *gin.Context) {
 
 // GetEventStats retrieves statistics about events (protected endpoint)
 func GetEventStats(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	var totalEvents int64
 	var upcomingEvents int64
@@ -302,7 +302,7 @@ func GetEventStats(c *gin.Context) {
 
 // RegisterForEvent handles event registration (public endpoint)
 func RegisterForEvent(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	eventID := c.Param("id")
 
 	// Check if event exists and registration is open
@@ -357,7 +357,7 @@ func RegisterForEvent(c *gin.Context) {
 
 // GetEventRegistrations retrieves all registrations for an event (protected endpoint)
 func GetEventRegistrations(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	eventID := c.Param("id")
 
 	var registrations []models.EventRegistration
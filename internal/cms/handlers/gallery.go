@@ -16,7 +16,7 @@ import (
 
 // GetGalleries retrieves all active galleries (public endpoint)
 func GetGalleries(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	category := c.Query("category")
 	featured := c.Query("featured")
 
@@ -41,7 +41,7 @@ func GetGalleries(c *gin.Context) {
 
 // GetGallery retrieves a single gallery with photos (public endpoint)
 func GetGallery(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var gallery models.PhotoGallery
@@ -63,7 +63,7 @@ func GetGallery(c *gin.Context) {
 
 // GetGalleryBySlug retrieves a gallery by slug (public endpoint)
 func GetGalleryBySlug(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	gallerySlug := c.Param("slug")
 
 	var gallery models.PhotoGallery
@@ -85,7 +85,7 @@ func GetGalleryBySlug(c *gin.Context) {
 
 // GetAllGalleries retrieves all galleries for CMS (protected endpoint)
 func GetAllGalleries(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	var galleries []models.PhotoGallery
 	if err := db.Order("created_at DESC").Find(&galleries).Error; err != nil {
@@ -98,7 +98,7 @@ func GetAllGalleries(c *gin.Context) {
 
 // CreateGallery creates a new photo gallery (protected endpoint)
 func CreateGallery(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	var gallery models.PhotoGallery
 	if err := c.ShouldBindJSON(&gallery); err != nil {
@@ -137,7 +137,7 @@ func CreateGallery(c *gin.Context) {
 
 // UpdateGallery updates an existing gallery (protected endpoint)
 func UpdateGallery(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var gallery models.PhotoGallery
@@ -169,7 +169,7 @@ func UpdateGallery(c *gin.Context) {
 
 // DeleteGallery deletes a gallery (soft delete) (protected endpoint)
 func DeleteGallery(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var gallery models.PhotoGallery
@@ -192,7 +192,7 @@ func DeleteGallery(c *gin.Context) {
 
 // AddPhotoToGallery adds a photo to a gallery (protected endpoint)
 func AddPhotoToGallery(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	galleryID := c.Param("id")
 
 	var photo models.GalleryPhoto
@@ -224,7 +224,7 @@ func AddPhotoToGallery(c *gin.Context) {
 
 // UpdateGalleryPhoto updates a photo (protected endpoint)
 func UpdateGalleryPhoto(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var photo models.GalleryPhoto
@@ -256,7 +256,7 @@ func UpdateGalleryPhoto(c *gin.Context) {
 
 // DeleteGalleryPhoto deletes a photo (soft delete) (protected endpoint)
 func DeleteGalleryPhoto(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var photo models.GalleryPhoto
@@ -279,7 +279,7 @@ func DeleteGalleryPhoto(c *gin.Context) {
 
 // GetGalleryPhotos retrieves all photos in a gallery (public endpoint)
 func GetGalleryPhotos(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	galleryID := c.Param("id")
 
 	var photos []models.GalleryPhoto
@@ -46,7 +46,7 @@ func (MediaFileRecord) TableName() string {
 
 // GetMedia returns all media files (protected)
 func GetMedia(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	var mediaRecords []MediaFileRecord
 	if err := db.Where("deleted_at IS NULL").Order("created_at DESC").Find(&mediaRecords).Error; err != nil {
@@ -149,7 +149,7 @@ func UploadFile(c *gin.Context) {
 	}
 
 	// Save to database
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	mediaRecord := MediaFileRecord{
 		OriginalName: header.Filename,
 		Filename:     filename,
@@ -430,7 +430,7 @@ func DeleteMedia(c *gin.Context) {
 	}
 
 	// Get database connection
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	// Find the media record in database
 	var mediaRecord MediaFileRecord
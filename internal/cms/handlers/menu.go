@@ -14,7 +14,7 @@ import (
 // GetMenus returns all menus
 func GetMenus(c *gin.Context) {
 	var menus []models.Menu
-	query := config.DB.Preload("Items", func(db *gorm.DB) *gorm.DB {
+	query := config.DB.WithContext(c).Preload("Items", func(db *gorm.DB) *gorm.DB {
 		return db.Order("sort_order ASC")
 	}).Preload("Items.Children", func(db *gorm.DB) *gorm.DB {
 		return db.Order("sort_order ASC")
@@ -53,7 +53,7 @@ func GetMenu(c *gin.Context) {
 	}
 
 	var menu models.Menu
-	if err := config.DB.Preload("Items", func(db *gorm.DB) *gorm.DB {
+	if err := config.DB.WithContext(c).Preload("Items", func(db *gorm.DB) *gorm.DB {
 		return db.Order("sort_order ASC")
 	}).Preload("Items.Children", func(db *gorm.DB) *gorm.DB {
 		return db.Order("sort_order ASC")
@@ -74,7 +74,7 @@ func GetMenuByLocation(c *gin.Context) {
 	location := c.Param("location")
 
 	var menu models.Menu
-	if err := config.DB.Preload("Items", func(db *gorm.DB) *gorm.DB {
+	if err := config.DB.WithContext(c).Preload("Items", func(db *gorm.DB) *gorm.DB {
 		return db.Where("is_active = ?", true).Order("sort_order ASC")
 	}).Preload("Items.Children", func(db *gorm.DB) *gorm.DB {
 		return db.Where("is_active = ?", true).Order("sort_order ASC")
@@ -98,13 +98,13 @@ func CreateMenu(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Create(&menu).Error; err != nil {
+	if err := config.DB.WithContext(c).Create(&menu).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create menu"})
 		return
 	}
 
 	// Reload with relationships
-	config.DB.Preload("Items").First(&menu, menu.ID)
+	config.DB.WithContext(c).Preload("Items").First(&menu, menu.ID)
 
 	c.JSON(http.StatusCreated, gin.H{"menu": menu})
 }
@@ -118,7 +118,7 @@ func UpdateMenu(c *gin.Context) {
 	}
 
 	var menu models.Menu
-	if err := config.DB.First(&menu, id).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&menu, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Menu not found"})
 		} else {
@@ -138,13 +138,13 @@ func UpdateMenu(c *gin.Context) {
 	menu.Location = updateData.Location
 	menu.IsActive = updateData.IsActive
 
-	if err := config.DB.Save(&menu).Error; err != nil {
+	if err := config.DB.WithContext(c).Save(&menu).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update menu"})
 		return
 	}
 
 	// Reload with relationships
-	config.DB.Preload("Items").First(&menu, menu.ID)
+	config.DB.WithContext(c).Preload("Items").First(&menu, menu.ID)
 
 	c.JSON(http.StatusOK, gin.H{"menu": menu})
 }
@@ -158,7 +158,7 @@ func DeleteMenu(c *gin.Context) {
 	}
 
 	var menu models.Menu
-	if err := config.DB.First(&menu, id).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&menu, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Menu not found"})
 		} else {
@@ -167,7 +167,7 @@ func DeleteMenu(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Delete(&menu).Error; err != nil {
+	if err := config.DB.WithContext(c).Delete(&menu).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete menu"})
 		return
 	}
@@ -186,7 +186,7 @@ func GetMenuItems(c *gin.Context) {
 	}
 
 	var items []models.MenuItem
-	if err := config.DB.Where("menu_id = ?", menuID).
+	if err := config.DB.WithContext(c).Where("menu_id = ?", menuID).
 		Preload("Children", func(db *gorm.DB) *gorm.DB {
 			return db.Order("sort_order ASC")
 		}).
@@ -215,13 +215,13 @@ func CreateMenuItem(c *gin.Context) {
 
 	item.MenuID = uint(menuID)
 
-	if err := config.DB.Create(&item).Error; err != nil {
+	if err := config.DB.WithContext(c).Create(&item).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create menu item"})
 		return
 	}
 
 	// Reload with relationships
-	config.DB.Preload("Menu").Preload("Parent").Preload("Children").First(&item, item.ID)
+	config.DB.WithContext(c).Preload("Menu").Preload("Parent").Preload("Children").First(&item, item.ID)
 
 	c.JSON(http.StatusCreated, gin.H{"item": item})
 }
@@ -235,7 +235,7 @@ func UpdateMenuItem(c *gin.Context) {
 	}
 
 	var item models.MenuItem
-	if err := config.DB.First(&item, id).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&item, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Menu item not found"})
 		} else {
@@ -259,13 +259,13 @@ func UpdateMenuItem(c *gin.Context) {
 	item.SortOrder = updateData.SortOrder
 	item.IsActive = updateData.IsActive
 
-	if err := config.DB.Save(&item).Error; err != nil {
+	if err := config.DB.WithContext(c).Save(&item).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update menu item"})
 		return
 	}
 
 	// Reload with relationships
-	config.DB.Preload("Menu").Preload("Parent").Preload("Children").First(&item, item.ID)
+	config.DB.WithContext(c).Preload("Menu").Preload("Parent").Preload("Children").First(&item, item.ID)
 
 	c.JSON(http.StatusOK, gin.H{"item": item})
 }
@@ -279,7 +279,7 @@ func DeleteMenuItem(c *gin.Context) {
 	}
 
 	var item models.MenuItem
-	if err := config.DB.First(&item, id).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&item, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Menu item not found"})
 		} else {
@@ -288,7 +288,7 @@ func DeleteMenuItem(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Delete(&item).Error; err != nil {
+	if err := config.DB.WithContext(c).Delete(&item).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete menu item"})
 		return
 	}
@@ -15,7 +15,7 @@ import (
 
 // GetNewsItems returns all active news items for the ticker
 func GetNewsItems(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	var newsItems []models.NewsItem
 
@@ -73,7 +73,7 @@ func GetNewsItems(c *gin.Context) {
 
 // GetBreakingNews returns only breaking news items
 func GetBreakingNews(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	var newsItems []models.NewsItem
 
@@ -98,7 +98,7 @@ func GetBreakingNews(c *gin.Context) {
 
 // GetNewsItem returns a single news item by ID
 func GetNewsItem(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -126,7 +126,7 @@ func GetNewsItem(c *gin.Context) {
 
 // CreateNewsItem creates a new news item (CMS only)
 func CreateNewsItem(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	var newsItem models.NewsItem
 	if err := c.ShouldBindJSON(&newsItem); err != nil {
@@ -156,7 +156,7 @@ func CreateNewsItem(c *gin.Context) {
 
 // UpdateNewsItem updates an existing news item (CMS only)
 func UpdateNewsItem(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -194,7 +194,7 @@ func UpdateNewsItem(c *gin.Context) {
 
 // DeleteNewsItem deletes a news item (CMS only)
 func DeleteNewsItem(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -227,7 +227,7 @@ func DeleteNewsItem(c *gin.Context) {
 
 // GetAllNewsItems returns all news items for CMS management
 func GetAllNewsItems(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	var newsItems []models.NewsItem
 
@@ -299,7 +299,7 @@ func GetAllNewsItems(c *gin.Context) {
 
 // GetNewsCategories returns all news categories
 func GetNewsCategories(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	var categories []models.NewsCategory
 
@@ -320,7 +320,7 @@ func GetNewsCategories(c *gin.Context) {
 
 // CreateNewsCategory creates a new news category (CMS only)
 func CreateNewsCategory(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	var category models.NewsCategory
 	if err := c.ShouldBindJSON(&category); err != nil {
@@ -342,7 +342,7 @@ func CreateNewsCategory(c *gin.Context) {
 
 // UpdateNewsCategory updates an existing news category (CMS only)
 func UpdateNewsCategory(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -380,7 +380,7 @@ func UpdateNewsCategory(c *gin.Context) {
 
 // DeleteNewsCategory deletes a news category (CMS only)
 func DeleteNewsCategory(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -413,7 +413,7 @@ func DeleteNewsCategory(c *gin.Context) {
 
 // PublishNewsItem publishes a news item (CMS only)
 func PublishNewsItem(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -449,7 +449,7 @@ func PublishNewsItem(c *gin.Context) {
 
 // ArchiveNewsItem archives a news item (CMS only)
 func ArchiveNewsItem(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -485,7 +485,7 @@ func ArchiveNewsItem(c *gin.Context) {
 
 // SetBreakingNews sets a news item as breaking news (CMS only)
 func SetBreakingNews(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -16,7 +16,7 @@ import (
 // GetPages returns all pages with optional filtering
 func GetPages(c *gin.Context) {
 	var pages []models.Page
-	query := config.DB.Preload("Author").Preload("Parent").Preload("Children")
+	query := config.DB.WithContext(c).Preload("Author").Preload("Parent").Preload("Children")
 
 	// Filter by status
 	if status := c.Query("status"); status != "" {
@@ -64,7 +64,7 @@ func GetPage(c *gin.Context) {
 	}
 
 	var page models.Page
-	if err := config.DB.Preload("Author").Preload("Parent").Preload("Children").First(&page, id).Error; err != nil {
+	if err := config.DB.WithContext(c).Preload("Author").Preload("Parent").Preload("Children").First(&page, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
 		} else {
@@ -81,7 +81,7 @@ func GetPageBySlug(c *gin.Context) {
 	slug := c.Param("slug")
 
 	var page models.Page
-	if err := config.DB.Preload("Author").Preload("Parent").Preload("Children").
+	if err := config.DB.WithContext(c).Preload("Author").Preload("Parent").Preload("Children").
 		Where("slug = ? AND status = ?", slug, models.PageStatusPublished).
 		First(&page).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -119,13 +119,13 @@ func CreatePage(c *gin.Context) {
 	// Ensure slug is unique
 	page.Slug = ensureUniqueSlug(page.Slug, 0)
 
-	if err := config.DB.Create(&page).Error; err != nil {
+	if err := config.DB.WithContext(c).Create(&page).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create page"})
 		return
 	}
 
 	// Reload with relationships
-	config.DB.Preload("Author").Preload("Parent").Preload("Children").First(&page, page.ID)
+	config.DB.WithContext(c).Preload("Author").Preload("Parent").Preload("Children").First(&page, page.ID)
 
 	c.JSON(http.StatusCreated, gin.H{"page": page})
 }
@@ -139,7 +139,7 @@ func UpdatePage(c *gin.Context) {
 	}
 
 	var page models.Page
-	if err := config.DB.First(&page, id).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&page, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
 		} else {
@@ -179,13 +179,13 @@ func UpdatePage(c *gin.Context) {
 		page.Unpublish()
 	}
 
-	if err := config.DB.Save(&page).Error; err != nil {
+	if err := config.DB.WithContext(c).Save(&page).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update page"})
 		return
 	}
 
 	// Reload with relationships
-	config.DB.Preload("Author").Preload("Parent").Preload("Children").First(&page, page.ID)
+	config.DB.WithContext(c).Preload("Author").Preload("Parent").Preload("Children").First(&page, page.ID)
 
 	c.JSON(http.StatusOK, gin.H{"page": page})
 }
@@ -199,7 +199,7 @@ func DeletePage(c *gin.Context) {
 	}
 
 	var page models.Page
-	if err := config.DB.First(&page, id).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&page, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Page not found"})
 		} else {
@@ -208,7 +208,7 @@ func DeletePage(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Delete(&page).Error; err != nil {
+	if err := config.DB.WithContext(c).Delete(&page).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete page"})
 		return
 	}
@@ -13,7 +13,7 @@ import (
 
 // CreateRTIRequest creates a new RTI request (public endpoint)
 func CreateRTIRequest(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	var request models.RTIRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -43,7 +43,7 @@ func CreateRTIRequest(c *gin.Context) {
 
 // GetAllRTIRequests retrieves all RTI requests for CMS (protected endpoint)
 func GetAllRTIRequests(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	// Parse query parameters
 	status := c.Query("status")
@@ -96,7 +96,7 @@ func GetAllRTIRequests(c *gin.Context) {
 
 // GetRTIRequest retrieves a single RTI request (protected endpoint)
 func GetRTIRequest(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var request models.RTIRequest
@@ -114,7 +114,7 @@ func GetRTIRequest(c *gin.Context) {
 
 // UpdateRTIRequest updates an RTI request (protected endpoint)
 func UpdateRTIRequest(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var request models.RTIRequest
@@ -146,7 +146,7 @@ func UpdateRTIRequest(c *gin.Context) {
 
 // RespondToRTIRequest adds a response to an RTI request (protected endpoint)
 func RespondToRTIRequest(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var request models.RTIRequest
@@ -187,7 +187,7 @@ func RespondToRTIRequest(c *gin.Context) {
 
 // UpdateRTIStatus updates the status of an RTI request (protected endpoint)
 func UpdateRTIStatus(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var request models.RTIRequest
@@ -234,7 +234,7 @@ func UpdateRTIStatus(c *gin.Context) {
 
 // DeleteRTIRequest deletes an RTI request (soft delete) (protected endpoint)
 func DeleteRTIRequest(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	id := c.Param("id")
 
 	var request models.RTIRequest
@@ -257,7 +257,7 @@ func DeleteRTIRequest(c *gin.Context) {
 
 // GetRTIStats retrieves statistics about RTI requests (protected endpoint)
 func GetRTIStats(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	var totalRequests int64
 	var pendingRequests int64
@@ -15,7 +15,7 @@ import (
 // GetSettings returns all settings with optional filtering
 func GetSettings(c *gin.Context) {
 	var settings []models.Setting
-	query := config.DB
+	query := config.DB.WithContext(c)
 
 	// Filter by group
 	if group := c.Query("group"); group != "" {
@@ -47,7 +47,7 @@ func GetSetting(c *gin.Context) {
 	key := c.Param("key")
 
 	var setting models.Setting
-	if err := config.DB.Where("`key` = ?", key).First(&setting).Error; err != nil {
+	if err := config.DB.WithContext(c).Where("`key` = ?", key).First(&setting).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Setting not found"})
 		} else {
@@ -64,7 +64,7 @@ func GetSettingsByGroup(c *gin.Context) {
 	group := c.Param("group")
 
 	var settings []models.Setting
-	if err := config.DB.Where("`group` = ?", group).
+	if err := config.DB.WithContext(c).Where("`group` = ?", group).
 		Order("sort_order ASC, `key` ASC").
 		Find(&settings).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch settings"})
@@ -84,12 +84,12 @@ func CreateSetting(c *gin.Context) {
 
 	// Check if setting with this key already exists
 	var existingSetting models.Setting
-	if err := config.DB.Where("`key` = ?", setting.Key).First(&existingSetting).Error; err == nil {
+	if err := config.DB.WithContext(c).Where("`key` = ?", setting.Key).First(&existingSetting).Error; err == nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "Setting with this key already exists"})
 		return
 	}
 
-	if err := config.DB.Create(&setting).Error; err != nil {
+	if err := config.DB.WithContext(c).Create(&setting).Error; err != nil {
 		log.Printf("Failed to create setting: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to create setting",
@@ -106,7 +106,7 @@ func UpdateSetting(c *gin.Context) {
 	key := c.Param("key")
 
 	var setting models.Setting
-	if err := config.DB.Where("`key` = ?", key).First(&setting).Error; err != nil {
+	if err := config.DB.WithContext(c).Where("`key` = ?", key).First(&setting).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Setting not found"})
 		} else {
@@ -130,7 +130,7 @@ func UpdateSetting(c *gin.Context) {
 	setting.IsPublic = updateData.IsPublic
 	setting.SortOrder = updateData.SortOrder
 
-	if err := config.DB.Save(&setting).Error; err != nil {
+	if err := config.DB.WithContext(c).Save(&setting).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update setting"})
 		return
 	}
@@ -147,7 +147,7 @@ func UpdateSettingsBatch(c *gin.Context) {
 	}
 
 	// Start a transaction
-	tx := config.DB.Begin()
+	tx := config.DB.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
@@ -185,7 +185,7 @@ func DeleteSetting(c *gin.Context) {
 	key := c.Param("key")
 
 	var setting models.Setting
-	if err := config.DB.Where("`key` = ?", key).First(&setting).Error; err != nil {
+	if err := config.DB.WithContext(c).Where("`key` = ?", key).First(&setting).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Setting not found"})
 		} else {
@@ -194,7 +194,7 @@ func DeleteSetting(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Delete(&setting).Error; err != nil {
+	if err := config.DB.WithContext(c).Delete(&setting).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete setting"})
 		return
 	}
@@ -208,7 +208,7 @@ func GetPublicSettings(c *gin.Context) {
 
 	// First, check if the table exists by trying to count records
 	var count int64
-	if err := config.DB.Model(&models.Setting{}).Count(&count).Error; err != nil {
+	if err := config.DB.WithContext(c).Model(&models.Setting{}).Count(&count).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Database table error",
 			"details": err.Error(),
@@ -224,7 +224,7 @@ func GetPublicSettings(c *gin.Context) {
 	}
 
 	// Fetch public settings - try a simpler query first
-	if err := config.DB.Where("is_public = ?", true).Find(&settings).Error; err != nil {
+	if err := config.DB.WithContext(c).Where("is_public = ?", true).Find(&settings).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to fetch public settings",
 			"details": err.Error(),
@@ -262,7 +262,7 @@ func GetSettingsByGroupPublic(c *gin.Context) {
 	}
 
 	var settings []models.Setting
-	if err := config.DB.Where("`group` = ? AND is_public = ?", group, true).Find(&settings).Error; err != nil {
+	if err := config.DB.WithContext(c).Where("`group` = ? AND is_public = ?", group, true).Find(&settings).Error; err != nil {
 		log.Printf("Error fetching public settings for group %s: %v", group, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch settings"})
 		return
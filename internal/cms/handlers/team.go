@@ -22,7 +22,7 @@ func GetTeamMembers(c *gin.Context) {
 	teamType := c.Query("type")
 
 	// Let custom sorting handle all ordering
-	query := config.DB
+	query := config.DB.WithContext(c)
 
 	if teamType != "" {
 		query = query.Where("type = ?", teamType)
@@ -164,7 +164,7 @@ func GetTeamMember(c *gin.Context) {
 	}
 
 	var teamMember models.TeamMember
-	if err := config.DB.First(&teamMember, uint(id)).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&teamMember, uint(id)).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"success": false,
@@ -207,7 +207,7 @@ func CreateTeamMember(c *gin.Context) {
 	// If no order index provided, set it to the next available index for this type
 	if input.OrderIndex == 0 {
 		var maxOrder int
-		config.DB.Model(&models.TeamMember{}).Where("type = ?", input.Type).Select("COALESCE(MAX(order_index), 0)").Scan(&maxOrder)
+		config.DB.WithContext(c).Model(&models.TeamMember{}).Where("type = ?", input.Type).Select("COALESCE(MAX(order_index), 0)").Scan(&maxOrder)
 		input.OrderIndex = maxOrder + 1
 	}
 
@@ -220,7 +220,7 @@ func CreateTeamMember(c *gin.Context) {
 		OrderIndex:  input.OrderIndex,
 	}
 
-	if err := config.DB.Create(&teamMember).Error; err != nil {
+	if err := config.DB.WithContext(c).Create(&teamMember).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Failed to create team member",
@@ -264,7 +264,7 @@ func UpdateTeamMember(c *gin.Context) {
 	}
 
 	var teamMember models.TeamMember
-	if err := config.DB.First(&teamMember, uint(id)).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&teamMember, uint(id)).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"success": false,
@@ -300,7 +300,7 @@ func UpdateTeamMember(c *gin.Context) {
 		updates["order_index"] = input.OrderIndex
 	}
 
-	if err := config.DB.Model(&teamMember).Updates(updates).Error; err != nil {
+	if err := config.DB.WithContext(c).Model(&teamMember).Updates(updates).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Failed to update team member",
@@ -309,7 +309,7 @@ func UpdateTeamMember(c *gin.Context) {
 	}
 
 	// Fetch updated team member
-	config.DB.First(&teamMember, uint(id))
+	config.DB.WithContext(c).First(&teamMember, uint(id))
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -330,7 +330,7 @@ func DeleteTeamMember(c *gin.Context) {
 	}
 
 	var teamMember models.TeamMember
-	if err := config.DB.First(&teamMember, uint(id)).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&teamMember, uint(id)).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"success": false,
@@ -345,7 +345,7 @@ func DeleteTeamMember(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Delete(&teamMember).Error; err != nil {
+	if err := config.DB.WithContext(c).Delete(&teamMember).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
 			"error":   "Failed to delete team member",
@@ -378,7 +378,7 @@ func ReorderTeamMembers(c *gin.Context) {
 	}
 
 	// Update order indices in a transaction
-	tx := config.DB.Begin()
+	tx := config.DB.WithContext(c).Begin()
 	defer func() {
 		if r := recover(); r != nil {
 			tx.Rollback()
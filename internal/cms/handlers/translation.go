@@ -13,7 +13,7 @@ import (
 // GetTranslations returns all translations
 func GetTranslations(c *gin.Context) {
 	var translations []models.Translation
-	if err := config.DB.Find(&translations).Error; err != nil {
+	if err := config.DB.WithContext(c).Find(&translations).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch translations"})
 		return
 	}
@@ -25,7 +25,7 @@ func GetTranslations(c *gin.Context) {
 func GetTranslation(c *gin.Context) {
 	id := c.Param("id")
 	var translation models.Translation
-	if err := config.DB.First(&translation, id).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&translation, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Translation not found"})
 		} else {
@@ -45,7 +45,7 @@ func CreateTranslation(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Create(&translation).Error; err != nil {
+	if err := config.DB.WithContext(c).Create(&translation).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create translation"})
 		return
 	}
@@ -57,7 +57,7 @@ func CreateTranslation(c *gin.Context) {
 func UpdateTranslation(c *gin.Context) {
 	id := c.Param("id")
 	var translation models.Translation
-	if err := config.DB.First(&translation, id).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&translation, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Translation not found"})
 		} else {
@@ -79,7 +79,7 @@ func UpdateTranslation(c *gin.Context) {
 	translation.FieldName = updateData.FieldName
 	translation.Content = updateData.Content
 
-	if err := config.DB.Save(&translation).Error; err != nil {
+	if err := config.DB.WithContext(c).Save(&translation).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update translation"})
 		return
 	}
@@ -91,7 +91,7 @@ func UpdateTranslation(c *gin.Context) {
 func DeleteTranslation(c *gin.Context) {
 	id := c.Param("id")
 	var translation models.Translation
-	if err := config.DB.First(&translation, id).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&translation, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Translation not found"})
 		} else {
@@ -100,7 +100,7 @@ func DeleteTranslation(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Delete(&translation).Error; err != nil {
+	if err := config.DB.WithContext(c).Delete(&translation).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete translation"})
 		return
 	}
@@ -1,11 +1,28 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	_ "time/tzdata" // Time zones validate without system zoneinfo
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/session"
 )
 
+// maxPreferencesSize bounds the preferences JSON a user can store
+const maxPreferencesSize = 16 * 1024
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ()\-.]{5,24}$`)
+
 // GetProfile returns the current user's profile (protected)
 func GetProfile(c *gin.Context) {
 	user, exists := c.Get("user")
@@ -18,12 +35,215 @@ func GetProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
-// UpdateProfile updates the current user's profile (protected)
+// UpdateProfile updates the current user's profile (protected). Only the fields sent are
+// changed; an empty string clears an optional field.
 func UpdateProfile(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Update profile endpoint - to be implemented"})
+	user, ok := c.MustGet("user").(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var input struct {
+		Name        *string         `json:"name" binding:"omitempty,max=100"`
+		Avatar      *string         `json:"avatar" binding:"omitempty,max=500"`
+		Bio         *string         `json:"bio" binding:"omitempty,max=1000"`
+		Company     *string         `json:"company" binding:"omitempty,max=200"`
+		Phone       *string         `json:"phone"`
+		Country     *string         `json:"country"`
+		TimeZone    *string         `json:"time_zone"`
+		Preferences json.RawMessage `json:"preferences"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	invalid := func(message string) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   message,
+		})
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			invalid("Name cannot be empty")
+			return
+		}
+		updates["name"] = name
+	}
+	if input.Avatar != nil {
+		avatar := strings.TrimSpace(*input.Avatar)
+		// An uploaded image or an external http(s) URL
+		if avatar != "" && !strings.HasPrefix(avatar, "/uploads/") &&
+			!strings.HasPrefix(avatar, "https://") && !strings.HasPrefix(avatar, "http://") {
+			invalid("Avatar must be an uploaded file path or an http(s) URL")
+			return
+		}
+		updates["avatar"] = optional(avatar)
+	}
+	if input.Bio != nil {
+		updates["bio"] = optional(strings.TrimSpace(*input.Bio))
+	}
+	if input.Company != nil {
+		updates["company"] = optional(strings.TrimSpace(*input.Company))
+	}
+	if input.Phone != nil {
+		phone := strings.TrimSpace(*input.Phone)
+		if phone != "" && !phonePattern.MatchString(phone) {
+			invalid("Phone must be a phone number of digits, optionally starting with +")
+			return
+		}
+		updates["phone"] = optional(phone)
+	}
+	if input.Country != nil {
+		country := strings.ToUpper(strings.TrimSpace(*input.Country))
+		if country != "" && !isCountryCode(country) {
+			invalid("Country must be an ISO 3166-1 alpha-2 code, e.g. GH")
+			return
+		}
+		updates["country"] = optional(country)
+	}
+	if input.TimeZone != nil {
+		tz := strings.TrimSpace(*input.TimeZone)
+		if tz != "" {
+			if _, err := time.LoadLocation(tz); err != nil || tz == "Local" {
+				invalid("Time zone must be an IANA time zone, e.g. Africa/Accra")
+				return
+			}
+		}
+		updates["time_zone"] = optional(tz)
+	}
+	if input.Preferences != nil {
+		if len(input.Preferences) > maxPreferencesSize {
+			invalid("Preferences are too large")
+			return
+		}
+		var prefs map[string]interface{}
+		if string(input.Preferences) != "null" {
+			if err := json.Unmarshal(input.Preferences, &prefs); err != nil {
+				invalid("Preferences must be a JSON object")
+				return
+			}
+		}
+		updates["preferences"] = ""
+		if prefs != nil {
+			compact, _ := json.Marshal(prefs)
+			updates["preferences"] = string(compact)
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := config.DB.WithContext(c).Model(user).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to update profile",
+			})
+			return
+		}
+	}
+
+	var updated models.User
+	if err := config.DB.WithContext(c).First(&updated, user.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to fetch profile",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Profile updated successfully",
+		"data":    updated,
+	})
 }
 
-// ChangePassword changes the current user's password (protected)
+// ChangePassword changes the current user's password (protected). The current password is
+// required, and every other session is signed out.
 func ChangePassword(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"message": "Change password endpoint - to be implemented"})
+	user, ok := c.MustGet("user").(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var input struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required,min=8,max=72"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	if !user.CheckPassword(input.CurrentPassword) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Current password is incorrect",
+		})
+		return
+	}
+	if input.NewPassword == input.CurrentPassword {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "New password must be different from the current password",
+		})
+		return
+	}
+
+	user.Password = input.NewPassword
+	if err := user.HashPassword(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to change password",
+		})
+		return
+	}
+	if err := config.DB.WithContext(c).Model(user).Update("password", user.Password).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Failed to change password",
+		})
+		return
+	}
+
+	audit.RecordAuth(c, models.AuthEventPasswordChange, user, "", "")
+
+	revoked, err := session.NewService().RevokeAll(user.ID, c.GetString("session_id"), models.SessionRevokedPasswordChange)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "Password changed but other sessions could not be signed out",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":          true,
+		"message":          "Password changed successfully",
+		"revoked_sessions": revoked,
+	})
+}
+
+// optional maps an empty string to NULL
+func optional(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// isCountryCode checks a code against the ISO 3166-1 alpha-2 list used by the validator
+func isCountryCode(code string) bool {
+	return binding.Validator.Engine().(*validator.Validate).Var(code, "iso3166_1_alpha2") == nil
 }
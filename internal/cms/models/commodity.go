@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"sort"
+	"strings"
+	"time"
+)
 
 // Commodity represents a commodity in the system
 type Commodity struct {
@@ -34,3 +38,54 @@ type Commodity struct {
 	// Relationships
 	ContractTypes []CommodityContractType `json:"contract_types" gorm:"foreignKey:CommodityID"`
 }
+
+// HarvestMonths reads the descriptive harvest season, e.g. "June-September" or
+// "November-January, March", into sorted months. Ranges may wrap the year end;
+// words that are not month names are ignored.
+func (c *Commodity) HarvestMonths() []time.Month {
+	replacer := strings.NewReplacer(";", ",", "&", ",", " and ", ",", "/", ",", " to ", "-", "–", "-")
+	seen := make(map[time.Month]bool)
+
+	for _, part := range strings.Split(replacer.Replace(c.HarvestSeason), ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		start, ok := parseMonthName(bounds[0])
+		if !ok {
+			continue
+		}
+		end := start
+		if len(bounds) == 2 {
+			if m, ok := parseMonthName(bounds[1]); ok {
+				end = m
+			}
+		}
+		for m := start; ; m = m%12 + 1 {
+			seen[m] = true
+			if m == end {
+				break
+			}
+		}
+	}
+
+	months := make([]time.Month, 0, len(seen))
+	for m := range seen {
+		months = append(months, m)
+	}
+	sort.Slice(months, func(i, j int) bool { return months[i] < months[j] })
+	return months
+}
+
+// parseMonthName matches the first word of text that starts with a month abbreviation
+func parseMonthName(text string) (time.Month, bool) {
+	for _, word := range strings.Fields(text) {
+		word = strings.ToUpper(strings.Trim(word, ".()"))
+		if len(word) < 3 {
+			continue
+		}
+		for i, code := range monthCodes {
+			if strings.HasPrefix(word, code) {
+				return time.Month(i + 1), true
+			}
+		}
+	}
+	return 0, false
+}
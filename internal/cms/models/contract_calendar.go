@@ -0,0 +1,164 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ContractCalendarRule configures how listed contract series are generated for a commodity
+// (or a single contract type of it) from its delivery months
+type ContractCalendarRule struct {
+	ID             uint   `json:"id" gorm:"primaryKey"`
+	CommodityID    uint   `json:"commodity_id" gorm:"not null;index"`
+	ContractTypeID *uint  `json:"contract_type_id" gorm:"index"`
+	DeliveryMonths string `json:"delivery_months" gorm:"size:100;not null"` // Normalised, e.g. "MAR,MAY,JUL,SEP,DEC"
+	// MonthsAhead is how far ahead series are listed
+	MonthsAhead int `json:"months_ahead" gorm:"default:12"`
+	// FirstNoticeDaysBefore is the number of business days before the delivery month the first notice falls
+	FirstNoticeDaysBefore int `json:"first_notice_days_before" gorm:"default:2"`
+	// LastTradingDaysBefore is the number of business days before the last business day of the delivery month trading stops
+	LastTradingDaysBefore int       `json:"last_trading_days_before" gorm:"default:5"`
+	IsActive              bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+// ContractSeries is a single listed contract, e.g. MAIZE-MAR26
+type ContractSeries struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	Symbol            string    `json:"symbol" gorm:"type:varchar(50);uniqueIndex;not null"`
+	CommodityID       uint      `json:"commodity_id" gorm:"not null;index"`
+	ContractTypeID    *uint     `json:"contract_type_id" gorm:"index"`
+	RuleID            uint      `json:"rule_id" gorm:"index"`
+	DeliveryMonth     time.Time `json:"delivery_month" gorm:"not null;index"` // First day of the delivery month
+	FirstNoticeDate   time.Time `json:"first_notice_date"`
+	LastTradingDate   time.Time `json:"last_trading_date" gorm:"index"`
+	DeliveryStartDate time.Time `json:"delivery_start_date"`
+	DeliveryEndDate   time.Time `json:"delivery_end_date"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+
+	// Status is derived from the last trading date at read time
+	Status string `json:"status" gorm:"-"`
+}
+
+// TableName specifies the table name for ContractCalendarRule
+func (ContractCalendarRule) TableName() string {
+	return "contract_calendar_rules"
+}
+
+// TableName specifies the table name for ContractSeries
+func (ContractSeries) TableName() string {
+	return "contract_series"
+}
+
+// IsExpired checks if trading in the series has ended
+func (s *ContractSeries) IsExpired(now time.Time) bool {
+	return now.After(s.LastTradingDate.Add(24*time.Hour - time.Nanosecond))
+}
+
+// SetStatus fills the derived Status field
+func (s *ContractSeries) SetStatus(now time.Time) {
+	if s.IsExpired(now) {
+		s.Status = "expired"
+	} else {
+		s.Status = "active"
+	}
+}
+
+var monthCodes = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+
+// ParseDeliveryMonths reads a free-text delivery months value such as "March, May & July",
+// "Mar/May/Jul" or "Oct-Mar" into sorted month numbers. Ranges, written with a dash or "to",
+// include both ends and run on past December. Unknown tokens are returned as an error.
+func ParseDeliveryMonths(text string) ([]time.Month, error) {
+	replacer := strings.NewReplacer("/", ",", ";", ",", "&", ",", " and ", ",", "|", ",")
+	dashes := strings.NewReplacer("-", " - ", "\u2013", " - ", "\u2014", " - ")
+	seen := make(map[time.Month]bool)
+	var months []time.Month
+	add := func(m time.Month) {
+		if !seen[m] {
+			seen[m] = true
+			months = append(months, m)
+		}
+	}
+
+	for _, token := range strings.Split(replacer.Replace(text), ",") {
+		words := strings.Fields(dashes.Replace(token))
+		for i := 0; i < len(words); i++ {
+			word := strings.ToUpper(strings.Trim(words[i], "."))
+			if word == "" || word == "AND" {
+				continue
+			}
+			if isMonthRangeSeparator(word) {
+				return nil, fmt.Errorf("delivery month range %q needs a month on each side", strings.TrimSpace(token))
+			}
+			m, err := parseMonthWord(word)
+			if err != nil {
+				return nil, err
+			}
+			if i+1 < len(words) && isMonthRangeSeparator(strings.ToUpper(words[i+1])) {
+				if i+2 >= len(words) {
+					return nil, fmt.Errorf("delivery month range %q needs a month on each side", strings.TrimSpace(token))
+				}
+				last, err := parseMonthWord(strings.ToUpper(strings.Trim(words[i+2], ".")))
+				if err != nil {
+					return nil, err
+				}
+				for ; m != last; m = m%12 + 1 {
+					add(m)
+				}
+				i += 2
+			}
+			add(m)
+		}
+	}
+
+	if len(months) == 0 {
+		return nil, fmt.Errorf("no delivery months found")
+	}
+	sort.Slice(months, func(i, j int) bool { return months[i] < months[j] })
+	return months, nil
+}
+
+// parseMonthWord reads an upper-case month name or its abbreviation
+func parseMonthWord(word string) (time.Month, error) {
+	if len(word) >= 3 {
+		for i, code := range monthCodes {
+			if strings.HasPrefix(word, code) {
+				return time.Month(i + 1), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unrecognised delivery month %q", word)
+}
+
+func isMonthRangeSeparator(word string) bool {
+	return word == "-" || word == "TO"
+}
+
+// FormatDeliveryMonths renders months in the normalised "MAR,MAY" form
+func FormatDeliveryMonths(months []time.Month) string {
+	codes := make([]string, len(months))
+	for i, m := range months {
+		codes[i] = monthCodes[m-1]
+	}
+	return strings.Join(codes, ",")
+}
+
+// SeriesSymbol builds a series symbol such as MAIZE-MAR26 or RICE-PADDY-MAR26
+func SeriesSymbol(commodityCode, contractTypeCode string, deliveryMonth time.Time) string {
+	parts := []string{strings.ToUpper(commodityCode)}
+	if contractTypeCode != "" {
+		parts = append(parts, strings.ToUpper(contractTypeCode))
+	}
+	parts = append(parts, fmt.Sprintf("%s%02d", monthCodes[deliveryMonth.Month()-1], deliveryMonth.Year()%100))
+	return strings.Join(parts, "-")
+}
+
+// SeriesPrefix is how the symbols of every series listed for a contract type begin, e.g. MAIZE-WHITE-
+func SeriesPrefix(commodityCode, contractTypeCode string) string {
+	return strings.ToUpper(commodityCode) + "-" + strings.ToUpper(contractTypeCode) + "-"
+}
@@ -0,0 +1,107 @@
+package models
+
+import "time"
+
+type ContractSpecStatus string
+
+const (
+	ContractSpecStatusPending  ContractSpecStatus = "pending_approval"
+	ContractSpecStatusApproved ContractSpecStatus = "approved"
+	ContractSpecStatusRejected ContractSpecStatus = "rejected"
+)
+
+// ContractSpecVersion is a dated snapshot of a contract type's trading rules.
+// Approved versions never change; a new version closes the previous one's effective window.
+type ContractSpecVersion struct {
+	ID                  uint               `json:"id" gorm:"primaryKey"`
+	ContractTypeID      uint               `json:"contract_type_id" gorm:"not null;index;uniqueIndex:idx_contract_spec_version"`
+	Version             int                `json:"version" gorm:"not null;uniqueIndex:idx_contract_spec_version"`
+	Status              ContractSpecStatus `json:"status" gorm:"type:varchar(30);index;default:'pending_approval'"`
+	EffectiveFrom       time.Time          `json:"effective_from" gorm:"not null;index"`
+	EffectiveTo         *time.Time         `json:"effective_to" gorm:"index"`
+	Specifications      string             `json:"specifications" gorm:"type:text"`
+	TradingHours        string             `json:"trading_hours" gorm:"size:255"`
+	ContractSize        string             `json:"contract_size" gorm:"size:100"`
+	PriceUnit           string             `json:"price_unit" gorm:"size:50"`
+	ContractFile        string             `json:"contract_file" gorm:"size:500"`
+	DeliveryMonths      string             `json:"delivery_months" gorm:"size:255"`
+	StorageRequirements string             `json:"storage_requirements" gorm:"type:text"`
+	QualityStandards    string             `json:"quality_standards" gorm:"type:text"`
+	ChangeNotes         string             `json:"change_notes" gorm:"type:text"`
+	ProposedBy          *uint              `json:"proposed_by"`
+	ReviewedBy          *uint              `json:"reviewed_by"`
+	ReviewedAt          *time.Time         `json:"reviewed_at"`
+	RejectionReason     *string            `json:"rejection_reason"`
+	AppliedAt           *time.Time         `json:"applied_at"` // When it was copied onto the contract type
+	CreatedAt           time.Time          `json:"created_at"`
+	UpdatedAt           time.Time          `json:"updated_at"`
+}
+
+// TableName specifies the table name for ContractSpecVersion
+func (ContractSpecVersion) TableName() string {
+	return "contract_spec_versions"
+}
+
+// IsEffectiveAt checks if an approved version applies at the given time
+func (v *ContractSpecVersion) IsEffectiveAt(t time.Time) bool {
+	if v.Status != ContractSpecStatusApproved || t.Before(v.EffectiveFrom) {
+		return false
+	}
+	return v.EffectiveTo == nil || t.Before(*v.EffectiveTo)
+}
+
+// SpecFromContractType copies the versioned specification fields from a contract type
+func SpecFromContractType(ct *CommodityContractType) ContractSpecVersion {
+	return ContractSpecVersion{
+		ContractTypeID:      ct.ID,
+		Specifications:      ct.Specifications,
+		TradingHours:        ct.TradingHours,
+		ContractSize:        ct.ContractSize,
+		PriceUnit:           ct.PriceUnit,
+		ContractFile:        ct.ContractFile,
+		DeliveryMonths:      ct.DeliveryMonths,
+		StorageRequirements: ct.StorageRequirements,
+		QualityStandards:    ct.QualityStandards,
+	}
+}
+
+// BaselineSpec is version 1 of a contract type without versions: its specification as it was
+// created, approved and applied from then
+func BaselineSpec(ct *CommodityContractType) ContractSpecVersion {
+	baseline := SpecFromContractType(ct)
+	baseline.Version = 1
+	baseline.Status = ContractSpecStatusApproved
+	baseline.EffectiveFrom = ct.CreatedAt
+	if baseline.EffectiveFrom.IsZero() {
+		baseline.EffectiveFrom = time.Now()
+	}
+	baseline.ChangeNotes = "Baseline specification"
+	now := time.Now()
+	baseline.ReviewedAt = &now
+	baseline.AppliedAt = &now
+	return baseline
+}
+
+// ApplyTo overwrites a contract type's specification fields with this version's values
+func (v *ContractSpecVersion) ApplyTo(ct *CommodityContractType) {
+	ct.Specifications = v.Specifications
+	ct.TradingHours = v.TradingHours
+	ct.ContractSize = v.ContractSize
+	ct.PriceUnit = v.PriceUnit
+	ct.ContractFile = v.ContractFile
+	ct.DeliveryMonths = v.DeliveryMonths
+	ct.StorageRequirements = v.StorageRequirements
+	ct.QualityStandards = v.QualityStandards
+}
+
+// SpecEquals checks if two versions carry identical specification fields
+func (v *ContractSpecVersion) SpecEquals(other *ContractSpecVersion) bool {
+	return v.Specifications == other.Specifications &&
+		v.TradingHours == other.TradingHours &&
+		v.ContractSize == other.ContractSize &&
+		v.PriceUnit == other.PriceUnit &&
+		v.ContractFile == other.ContractFile &&
+		v.DeliveryMonths == other.DeliveryMonths &&
+		v.StorageRequirements == other.StorageRequirements &&
+		v.QualityStandards == other.QualityStandards
+}
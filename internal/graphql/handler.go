@@ -0,0 +1,67 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"gcx-cms/internal/shared/database"
+	shared_models "gcx-cms/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+	gql "github.com/graph-gophers/graphql-go"
+)
+
+type userKey struct{}
+
+// currentUser returns the authenticated user of a request, or nil for anonymous requests
+func currentUser(ctx context.Context) *shared_models.User {
+	user, _ := ctx.Value(userKey{}).(*shared_models.User)
+	return user
+}
+
+// Handler serves GraphQL queries sent as a JSON POST body or as GET query parameters. It runs
+// after OptionalAuthMiddleware, so resolvers see the user when a token was sent.
+func Handler(schema *gql.Schema) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var params struct {
+			Query         string                 `json:"query"`
+			OperationName string                 `json:"operationName"`
+			Variables     map[string]interface{} `json:"variables"`
+		}
+
+		if c.Request.Method == http.MethodGet {
+			params.Query = c.Query("query")
+			params.OperationName = c.Query("operationName")
+			if raw := c.Query("variables"); raw != "" {
+				if err := json.Unmarshal([]byte(raw), &params.Variables); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{
+						"error":   "Invalid variables",
+						"details": err.Error(),
+					})
+					return
+				}
+			}
+		} else if err := c.ShouldBindJSON(&params); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request data",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		if params.Query == "" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Query is required",
+			})
+			return
+		}
+
+		ctx := withLoaders(c.Request.Context(), database.GetDB().WithContext(c))
+		if user, exists := c.Get("user"); exists {
+			ctx = context.WithValue(ctx, userKey{}, user)
+		}
+
+		c.JSON(http.StatusOK, schema.Exec(ctx, params.Query, params.OperationName, params.Variables))
+	}
+}
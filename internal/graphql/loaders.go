@@ -0,0 +1,211 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	cms_models "gcx-cms/internal/cms/models"
+	md_models "gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/services"
+
+	"gorm.io/gorm"
+)
+
+// batchLoader collects the keys of one level of a query and loads them in a single query. List
+// resolvers prime the keys of every row they return; the first child field to load then fetches
+// all primed keys at once and later loads are served from the cache.
+type batchLoader[K comparable, V any] struct {
+	mu      sync.Mutex
+	fetch   func(keys []K) (map[K]V, error)
+	pending map[K]bool
+	loaded  map[K]bool
+	results map[K]V
+}
+
+func newBatchLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *batchLoader[K, V] {
+	return &batchLoader[K, V]{
+		fetch:   fetch,
+		pending: make(map[K]bool),
+		loaded:  make(map[K]bool),
+		results: make(map[K]V),
+	}
+}
+
+// prime registers keys to fetch with the next batch
+func (l *batchLoader[K, V]) prime(keys ...K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		if !l.loaded[key] {
+			l.pending[key] = true
+		}
+	}
+}
+
+// load returns the value for a key, fetching it along with every pending key if needed
+func (l *batchLoader[K, V]) load(key K) (V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.loaded[key] {
+		l.pending[key] = true
+		keys := make([]K, 0, len(l.pending))
+		for k := range l.pending {
+			keys = append(keys, k)
+		}
+		l.pending = make(map[K]bool)
+
+		values, err := l.fetch(keys)
+		if err != nil {
+			var zero V
+			return zero, err
+		}
+		for _, k := range keys {
+			l.loaded[k] = true
+			if v, ok := values[k]; ok {
+				l.results[k] = v
+			}
+		}
+	}
+	return l.results[key], nil
+}
+
+// loaders holds the batch loaders of one request
+type loaders struct {
+	contractTypes *batchLoader[uint, []cms_models.CommodityContractType]
+	commodities   *batchLoader[uint, *cms_models.Commodity]
+	latestPrices  *batchLoader[string, *md_models.MarketData]
+	pages         *batchLoader[uint, *cms_models.Page]
+	pageChildren  *batchLoader[uint, []cms_models.Page]
+	menuItems     *batchLoader[uint, []cms_models.MenuItem]
+}
+
+type loadersKey struct{}
+
+// withLoaders attaches a fresh set of loaders to a request context
+func withLoaders(ctx context.Context, db *gorm.DB) context.Context {
+	return context.WithValue(ctx, loadersKey{}, newLoaders(db))
+}
+
+// loadersFrom returns the request's loaders
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+func newLoaders(db *gorm.DB) *loaders {
+	return &loaders{
+		// Active contract types by commodity ID, with their effective specifications
+		contractTypes: newBatchLoader(func(ids []uint) (map[uint][]cms_models.CommodityContractType, error) {
+			var contractTypes []cms_models.CommodityContractType
+			if err := db.Where("commodity_id IN ? AND is_active = ?", ids, true).
+				Order("sort_order ASC").
+				Find(&contractTypes).Error; err != nil {
+				return nil, fmt.Errorf("failed to fetch contract types: %v", err)
+			}
+			if err := services.NewContractSpecService().OverlayEffective(contractTypes, time.Now()); err != nil {
+				return nil, err
+			}
+			byCommodity := make(map[uint][]cms_models.CommodityContractType)
+			for _, ct := range contractTypes {
+				byCommodity[ct.CommodityID] = append(byCommodity[ct.CommodityID], ct)
+			}
+			return byCommodity, nil
+		}),
+
+		// Commodities by ID
+		commodities: newBatchLoader(func(ids []uint) (map[uint]*cms_models.Commodity, error) {
+			var commodities []cms_models.Commodity
+			if err := db.Where("id IN ?", ids).Find(&commodities).Error; err != nil {
+				return nil, fmt.Errorf("failed to fetch commodities: %v", err)
+			}
+			byID := make(map[uint]*cms_models.Commodity, len(commodities))
+			for i := range commodities {
+				byID[commodities[i].ID] = &commodities[i]
+			}
+			return byID, nil
+		}),
+
+		// Most recent price by lower-cased market data commodity name
+		latestPrices: newBatchLoader(func(names []string) (map[string]*md_models.MarketData, error) {
+			prices, err := latestPrices(db, names)
+			if err != nil {
+				return nil, err
+			}
+			byName := make(map[string]*md_models.MarketData, len(prices))
+			for i := range prices {
+				byName[strings.ToLower(prices[i].Commodity)] = &prices[i]
+			}
+			return byName, nil
+		}),
+
+		// Published pages by ID
+		pages: newBatchLoader(func(ids []uint) (map[uint]*cms_models.Page, error) {
+			var pages []cms_models.Page
+			if err := publishedPages(db).Where("id IN ?", ids).Find(&pages).Error; err != nil {
+				return nil, fmt.Errorf("failed to fetch pages: %v", err)
+			}
+			byID := make(map[uint]*cms_models.Page, len(pages))
+			for i := range pages {
+				byID[pages[i].ID] = &pages[i]
+			}
+			return byID, nil
+		}),
+
+		// Published child pages by parent ID
+		pageChildren: newBatchLoader(func(ids []uint) (map[uint][]cms_models.Page, error) {
+			var pages []cms_models.Page
+			if err := publishedPages(db).Where("parent_id IN ?", ids).
+				Order("sort_order ASC, title ASC").
+				Find(&pages).Error; err != nil {
+				return nil, fmt.Errorf("failed to fetch child pages: %v", err)
+			}
+			byParent := make(map[uint][]cms_models.Page)
+			for _, page := range pages {
+				byParent[*page.ParentID] = append(byParent[*page.ParentID], page)
+			}
+			return byParent, nil
+		}),
+
+		// Every active item of each menu; the tree is assembled in memory
+		menuItems: newBatchLoader(func(ids []uint) (map[uint][]cms_models.MenuItem, error) {
+			var items []cms_models.MenuItem
+			if err := db.Where("menu_id IN ? AND is_active = ?", ids, true).
+				Order("sort_order ASC").
+				Find(&items).Error; err != nil {
+				return nil, fmt.Errorf("failed to fetch menu items: %v", err)
+			}
+			byMenu := make(map[uint][]cms_models.MenuItem)
+			for _, item := range items {
+				byMenu[item.MenuID] = append(byMenu[item.MenuID], item)
+			}
+			return byMenu, nil
+		}),
+	}
+}
+
+// latestPrices returns the most recent market data row of each commodity, matched
+// case-insensitively, or of every commodity when names is empty
+func latestPrices(db *gorm.DB, names []string) ([]md_models.MarketData, error) {
+	latest := db.Model(&md_models.MarketData{}).Select("MAX(id)").Group("commodity")
+	if len(names) > 0 {
+		lowered := make([]string, len(names))
+		for i, name := range names {
+			lowered[i] = strings.ToLower(name)
+		}
+		latest = latest.Where("LOWER(commodity) IN ?", lowered)
+	}
+
+	var prices []md_models.MarketData
+	if err := db.Where("id IN (?)", latest).Order("commodity ASC").Find(&prices).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch latest prices: %v", err)
+	}
+	return prices, nil
+}
+
+// publishedPages scopes a query to pages the public site may show
+func publishedPages(db *gorm.DB) *gorm.DB {
+	return db.Model(&cms_models.Page{}).Where("status = ?", cms_models.PageStatusPublished)
+}
@@ -0,0 +1,464 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	cms_models "gcx-cms/internal/cms/models"
+	md_models "gcx-cms/internal/marketdata/models"
+	md_services "gcx-cms/internal/marketdata/services"
+	"gcx-cms/internal/services"
+	shared_models "gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/rbac"
+
+	gql "github.com/graph-gophers/graphql-go"
+	"gorm.io/gorm"
+)
+
+// Resolver resolves the root Query fields
+type Resolver struct {
+	db *gorm.DB
+}
+
+// maxPageSize caps the limit argument of every list field
+const maxPageSize = 100
+
+// paginate clamps limit and offset arguments
+func paginate(limit, offset int32) (int, int) {
+	l, o := int(limit), int(offset)
+	if l <= 0 {
+		l = 20
+	}
+	if l > maxPageSize {
+		l = maxPageSize
+	}
+	if o < 0 {
+		o = 0
+	}
+	return l, o
+}
+
+// parseID converts a GraphQL ID to a database ID
+func parseID(id gql.ID) (uint, error) {
+	n, err := strconv.ParseUint(string(id), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid id %q", id)
+	}
+	return uint(n), nil
+}
+
+// Prices returns the latest price of each commodity
+func (r *Resolver) Prices(args struct{ Commodities *[]string }) ([]*marketDataResolver, error) {
+	var names []string
+	if args.Commodities != nil {
+		names = *args.Commodities
+	}
+	prices, err := latestPrices(r.db, names)
+	if err != nil {
+		return nil, err
+	}
+	return newMarketDataResolvers(prices), nil
+}
+
+// PriceHistory returns historical prices of a commodity, matching GET /api/marketdata/history
+func (r *Resolver) PriceHistory(args struct {
+	Commodity string
+	StartDate *string
+	EndDate   *string
+	Series    *string
+	Limit     int32
+	Offset    int32
+}) (*marketDataListResolver, error) {
+	start := time.Now().AddDate(0, 0, -30)
+	end := time.Now()
+	var err error
+	if args.StartDate != nil {
+		if start, err = time.Parse("2006-01-02", *args.StartDate); err != nil {
+			return nil, errors.New("invalid startDate format, use YYYY-MM-DD")
+		}
+	}
+	if args.EndDate != nil {
+		if end, err = time.Parse("2006-01-02", *args.EndDate); err != nil {
+			return nil, errors.New("invalid endDate format, use YYYY-MM-DD")
+		}
+	}
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+
+	query := r.db.Model(&md_models.MarketData{}).
+		Where("commodity = ? AND market_date >= ? AND market_date < ?", args.Commodity, start, end)
+	if args.Series != nil {
+		query = query.Where("contract_series = ?", *args.Series)
+	}
+
+	limit, offset := paginate(args.Limit, args.Offset)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count historical prices: %v", err)
+	}
+	var prices []md_models.MarketData
+	if err := query.Order("market_date ASC, id ASC").Limit(limit).Offset(offset).Find(&prices).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch historical prices: %v", err)
+	}
+
+	return &marketDataListResolver{
+		items:    newMarketDataResolvers(prices),
+		listInfo: listInfo{total: total, limit: limit, offset: offset},
+	}, nil
+}
+
+// RealtimePrices returns today's prices to users with market data access entitled to real-time
+// data, counting against their daily quota like GET /api/marketdata/realtime
+func (r *Resolver) RealtimePrices(ctx context.Context, args struct {
+	Commodity *string
+	Limit     int32
+}) ([]*marketDataResolver, error) {
+	user := currentUser(ctx)
+	if user == nil {
+		return nil, errors.New("authentication required")
+	}
+	if !rbac.Can(user, shared_models.PermMarketDataAccess) {
+		return nil, fmt.Errorf("permission required: %s", shared_models.PermMarketDataAccess)
+	}
+	if !md_services.HasDataAccess(user, md_models.DataTypeRealTime) {
+		return nil, errors.New("real-time data access required")
+	}
+	meter := md_services.GetUsageMeter()
+	quota, reserved, err := meter.Reserve(user.ID, md_models.DataTypeRealTime)
+	if err == nil && !reserved {
+		return nil, fmt.Errorf("daily %s request limit of %d reached", md_models.DataTypeRealTime, quota.Limit)
+	}
+
+	limit, _ := paginate(args.Limit, 0)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	query := r.db.Where("market_date >= ? AND market_date < ?", today, today.AddDate(0, 0, 1))
+	commodity := ""
+	if args.Commodity != nil {
+		query = query.Where("commodity = ?", *args.Commodity)
+		commodity = strings.ToLower(strings.TrimSpace(*args.Commodity))
+	}
+
+	var prices []md_models.MarketData
+	if err := query.Order("created_at DESC").Limit(limit).Find(&prices).Error; err != nil {
+		if reserved {
+			meter.Refund(user.ID, md_models.DataTypeRealTime)
+		}
+		return nil, fmt.Errorf("failed to fetch real-time data: %v", err)
+	}
+	meter.Record(user.ID, md_models.DataTypeRealTime, "GraphQL realtimePrices", commodity)
+	return newMarketDataResolvers(prices), nil
+}
+
+// Commodities lists commodities, matching GET /api/commodities filtering
+func (r *Resolver) Commodities(ctx context.Context, args struct {
+	Search   *string
+	Category *string
+	Status   *string
+	Limit    int32
+	Offset   int32
+}) (*commodityListResolver, error) {
+	query := r.db.Model(&cms_models.Commodity{})
+	if args.Search != nil && *args.Search != "" {
+		term := "%" + *args.Search + "%"
+		query = query.Where("name LIKE ? OR code LIKE ? OR description LIKE ? OR category LIKE ?", term, term, term, term)
+	}
+	if args.Category != nil {
+		query = query.Where("category = ?", *args.Category)
+	}
+	if args.Status != nil {
+		query = query.Where("market_status = ?", *args.Status)
+	}
+
+	limit, offset := paginate(args.Limit, args.Offset)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count commodities: %v", err)
+	}
+	var commodities []cms_models.Commodity
+	if err := query.Order("name ASC").Limit(limit).Offset(offset).Find(&commodities).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch commodities: %v", err)
+	}
+
+	return &commodityListResolver{
+		items:    newCommodityResolvers(ctx, commodities),
+		listInfo: listInfo{total: total, limit: limit, offset: offset},
+	}, nil
+}
+
+// Commodity finds a commodity by ID or code
+func (r *Resolver) Commodity(ctx context.Context, args struct {
+	ID   *gql.ID
+	Code *string
+}) (*commodityResolver, error) {
+	query := r.db
+	switch {
+	case args.ID != nil:
+		id, err := parseID(*args.ID)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("id = ?", id)
+	case args.Code != nil:
+		query = query.Where("code = ?", *args.Code)
+	default:
+		return nil, errors.New("either id or code is required")
+	}
+
+	var commodity cms_models.Commodity
+	if err := query.First(&commodity).Error; err != nil {
+		return nil, notFound(err, "commodity")
+	}
+	return newCommodityResolvers(ctx, []cms_models.Commodity{commodity})[0], nil
+}
+
+// ContractType finds an active contract type by ID
+func (r *Resolver) ContractType(ctx context.Context, args struct{ ID gql.ID }) (*contractTypeResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var contractType cms_models.CommodityContractType
+	if err := r.db.Where("id = ? AND is_active = ?", id, true).First(&contractType).Error; err != nil {
+		return nil, notFound(err, "contract type")
+	}
+	contractTypes := []cms_models.CommodityContractType{contractType}
+	if err := services.NewContractSpecService().OverlayEffective(contractTypes, time.Now()); err != nil {
+		return nil, err
+	}
+	return &contractTypeResolver{ct: contractTypes[0], loaders: loadersFrom(ctx)}, nil
+}
+
+// News lists published, unexpired news, matching GET /api/news
+func (r *Resolver) News(args struct {
+	Source   *string
+	Category *string
+	Breaking *bool
+	Limit    int32
+	Offset   int32
+}) (*newsItemListResolver, error) {
+	query := publishedNews(r.db)
+	if args.Source != nil {
+		query = query.Where("source = ?", *args.Source)
+	}
+	if args.Category != nil {
+		query = query.Where("category = ?", *args.Category)
+	}
+	if args.Breaking != nil && *args.Breaking {
+		query = query.Where("is_breaking = ?", true)
+	}
+
+	limit, offset := paginate(args.Limit, args.Offset)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count news items: %v", err)
+	}
+	var items []cms_models.NewsItem
+	if err := query.Order("COALESCE(published_at, created_at) DESC").Limit(limit).Offset(offset).Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch news items: %v", err)
+	}
+
+	resolvers := make([]*newsItemResolver, len(items))
+	for i := range items {
+		resolvers[i] = &newsItemResolver{n: items[i]}
+	}
+	return &newsItemListResolver{
+		items:    resolvers,
+		listInfo: listInfo{total: total, limit: limit, offset: offset},
+	}, nil
+}
+
+// NewsItem finds a published news item by ID
+func (r *Resolver) NewsItem(args struct{ ID gql.ID }) (*newsItemResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var item cms_models.NewsItem
+	if err := publishedNews(r.db).Where("id = ?", id).First(&item).Error; err != nil {
+		return nil, notFound(err, "news item")
+	}
+	return &newsItemResolver{n: item}, nil
+}
+
+// Events lists active events, matching GET /api/events filtering and order
+func (r *Resolver) Events(args struct {
+	Status   *string
+	Type     *string
+	Category *string
+	Featured *bool
+	Search   *string
+	Year     *int32
+	Limit    int32
+	Offset   int32
+}) (*eventListResolver, error) {
+	query := r.db.Model(&cms_models.Event{}).Where("is_active = ?", true)
+	if args.Status != nil {
+		query = query.Where("status = ?", *args.Status)
+	}
+	if args.Type != nil {
+		query = query.Where("type = ?", *args.Type)
+	}
+	if args.Category != nil {
+		query = query.Where("category = ?", *args.Category)
+	}
+	if args.Featured != nil && *args.Featured {
+		query = query.Where("is_featured = ?", true)
+	}
+	if args.Search != nil && *args.Search != "" {
+		term := "%" + *args.Search + "%"
+		query = query.Where("title LIKE ? OR description LIKE ? OR location LIKE ?", term, term, term)
+	}
+	if args.Year != nil {
+		from := time.Date(int(*args.Year), time.January, 1, 0, 0, 0, 0, time.UTC)
+		query = query.Where("date >= ? AND date < ?", from, from.AddDate(1, 0, 0))
+	}
+
+	limit, offset := paginate(args.Limit, args.Offset)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count events: %v", err)
+	}
+
+	// Upcoming events first, then past events in reverse chronological order
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	var events []cms_models.Event
+	if err := query.Order(gorm.Expr("CASE WHEN date >= ? THEN 0 ELSE 1 END, date DESC", today)).
+		Limit(limit).Offset(offset).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch events: %v", err)
+	}
+
+	resolvers := make([]*eventResolver, len(events))
+	for i := range events {
+		resolvers[i] = &eventResolver{e: events[i]}
+	}
+	return &eventListResolver{
+		items:    resolvers,
+		listInfo: listInfo{total: total, limit: limit, offset: offset},
+	}, nil
+}
+
+// Event finds an active event by slug
+func (r *Resolver) Event(args struct{ Slug string }) (*eventResolver, error) {
+	var event cms_models.Event
+	if err := r.db.Where("slug = ? AND is_active = ?", args.Slug, true).First(&event).Error; err != nil {
+		return nil, notFound(err, "event")
+	}
+	return &eventResolver{e: event}, nil
+}
+
+// Pages lists published pages, optionally under a parent page
+func (r *Resolver) Pages(ctx context.Context, args struct {
+	ParentID *gql.ID
+	Search   *string
+	Limit    int32
+	Offset   int32
+}) (*pageListResolver, error) {
+	query := publishedPages(r.db)
+	if args.ParentID != nil {
+		parentID, err := parseID(*args.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("parent_id = ?", parentID)
+	}
+	if args.Search != nil && *args.Search != "" {
+		term := "%" + *args.Search + "%"
+		query = query.Where("title LIKE ? OR excerpt LIKE ?", term, term)
+	}
+
+	limit, offset := paginate(args.Limit, args.Offset)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count pages: %v", err)
+	}
+	var pages []cms_models.Page
+	if err := query.Order("sort_order ASC, title ASC").Limit(limit).Offset(offset).Find(&pages).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch pages: %v", err)
+	}
+
+	return &pageListResolver{
+		items:    newPageResolvers(ctx, pages),
+		listInfo: listInfo{total: total, limit: limit, offset: offset},
+	}, nil
+}
+
+// Page finds a published page by slug, matching GET /api/pages/slug/:slug
+func (r *Resolver) Page(ctx context.Context, args struct{ Slug string }) (*pageResolver, error) {
+	var page cms_models.Page
+	if err := publishedPages(r.db).Where("slug = ?", args.Slug).First(&page).Error; err != nil {
+		return nil, notFound(err, "page")
+	}
+	return newPageResolvers(ctx, []cms_models.Page{page})[0], nil
+}
+
+// Menus lists active menus, optionally at one location
+func (r *Resolver) Menus(ctx context.Context, args struct{ Location *string }) ([]*menuResolver, error) {
+	query := r.db.Where("is_active = ?", true)
+	if args.Location != nil {
+		query = query.Where("location = ?", *args.Location)
+	}
+
+	var menus []cms_models.Menu
+	if err := query.Order("name ASC").Find(&menus).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch menus: %v", err)
+	}
+	return newMenuResolvers(ctx, menus), nil
+}
+
+// Menu finds the active menu at a location, matching GET /api/menus/location/:location
+func (r *Resolver) Menu(ctx context.Context, args struct{ Location string }) (*menuResolver, error) {
+	var menu cms_models.Menu
+	if err := r.db.Where("location = ? AND is_active = ?", args.Location, true).First(&menu).Error; err != nil {
+		return nil, notFound(err, "menu")
+	}
+	return newMenuResolvers(ctx, []cms_models.Menu{menu})[0], nil
+}
+
+// Settings lists public settings, optionally in one group
+func (r *Resolver) Settings(args struct{ Group *string }) ([]*settingResolver, error) {
+	query := r.db.Where("is_public = ?", true)
+	if args.Group != nil {
+		query = query.Where("`group` = ?", *args.Group)
+	}
+
+	var settings []cms_models.Setting
+	if err := query.Order("sort_order ASC, `key` ASC").Find(&settings).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch settings: %v", err)
+	}
+
+	resolvers := make([]*settingResolver, len(settings))
+	for i := range settings {
+		resolvers[i] = &settingResolver{s: settings[i]}
+	}
+	return resolvers, nil
+}
+
+// Setting finds a public setting by key
+func (r *Resolver) Setting(args struct{ Key string }) (*settingResolver, error) {
+	var setting cms_models.Setting
+	if err := r.db.Where("`key` = ? AND is_public = ?", args.Key, true).First(&setting).Error; err != nil {
+		return nil, notFound(err, "setting")
+	}
+	return &settingResolver{s: setting}, nil
+}
+
+// publishedNews scopes a query to news items the public site may show
+func publishedNews(db *gorm.DB) *gorm.DB {
+	return db.Model(&cms_models.NewsItem{}).
+		Where("status = ? AND published_at IS NOT NULL", cms_models.NewsStatusPublished).
+		Where("(expires_at IS NULL OR expires_at > ?)", time.Now())
+}
+
+// notFound turns a missing record into a null result and passes other errors through
+func notFound(err error, what string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return fmt.Errorf("failed to fetch %s: %v", what, err)
+}
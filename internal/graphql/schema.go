@@ -0,0 +1,243 @@
+package graphql
+
+import (
+	gql "github.com/graph-gophers/graphql-go"
+
+	"gcx-cms/internal/shared/database"
+)
+
+// schemaSDL is the read-only public API. Visibility rules match the REST handlers: only
+// published news and pages, active events, menus and contract types, and public settings are
+// exposed, and real-time prices need the same entitlement as /api/marketdata/realtime.
+const schemaSDL = `
+schema {
+	query: Query
+}
+
+scalar Time
+
+type Query {
+	# Latest price of each commodity, optionally limited to some commodities
+	prices(commodities: [String!]): [MarketData!]!
+	# Historical prices between two YYYY-MM-DD dates (inclusive), defaulting to the last 30 days
+	priceHistory(commodity: String!, startDate: String, endDate: String, series: String, limit: Int = 100, offset: Int = 0): MarketDataList!
+	# Today's prices, requires a real-time data subscription
+	realtimePrices(commodity: String, limit: Int = 100): [MarketData!]!
+
+	commodities(search: String, category: String, status: String, limit: Int = 20, offset: Int = 0): CommodityList!
+	commodity(id: ID, code: String): Commodity
+	contractType(id: ID!): CommodityContractType
+
+	news(source: String, category: String, breaking: Boolean, limit: Int = 20, offset: Int = 0): NewsItemList!
+	newsItem(id: ID!): NewsItem
+
+	events(status: String, type: String, category: String, featured: Boolean, search: String, year: Int, limit: Int = 20, offset: Int = 0): EventList!
+	event(slug: String!): Event
+
+	pages(parentId: ID, search: String, limit: Int = 20, offset: Int = 0): PageList!
+	page(slug: String!): Page
+
+	menus(location: String): [Menu!]!
+	menu(location: String!): Menu
+
+	settings(group: String): [Setting!]!
+	setting(key: String!): Setting
+}
+
+type MarketData {
+	id: ID!
+	commodity: String!
+	contractSeries: String
+	price: Float!
+	currency: String!
+	unit: String!
+	change: Float!
+	changePercent: Float!
+	open: Float
+	high: Float
+	low: Float
+	close: Float
+	volume: Float
+	marketDate: Time!
+	source: String!
+}
+
+type MarketDataList {
+	items: [MarketData!]!
+	totalCount: Int!
+	limit: Int!
+	offset: Int!
+	hasMore: Boolean!
+}
+
+type Commodity {
+	id: ID!
+	name: String!
+	code: String!
+	description: String!
+	fullDescription: String!
+	specifications: String!
+	tradingHours: String!
+	contractSize: String!
+	priceUnit: String!
+	minimumPrice: Float!
+	maximumPrice: Float!
+	currentPrice: Float!
+	priceChange: Float!
+	priceChangePercent: Float!
+	tradingVolume: Float!
+	marketStatus: String!
+	imagePath: String!
+	category: String!
+	originCountry: String!
+	harvestSeason: String!
+	deliveryMonths: String!
+	storageRequirements: String!
+	qualityStandards: String!
+	contractTypes: [CommodityContractType!]!
+	latestPrice: MarketData
+}
+
+type CommodityList {
+	items: [Commodity!]!
+	totalCount: Int!
+	limit: Int!
+	offset: Int!
+	hasMore: Boolean!
+}
+
+type CommodityContractType {
+	id: ID!
+	commodityId: ID!
+	name: String!
+	code: String!
+	description: String!
+	fullDescription: String!
+	specifications: String!
+	tradingHours: String!
+	contractSize: String!
+	priceUnit: String!
+	imagePath: String!
+	deliveryMonths: String!
+	storageRequirements: String!
+	qualityStandards: String!
+	sortOrder: Int!
+	commodity: Commodity
+}
+
+type NewsItem {
+	id: ID!
+	title: String!
+	content: String!
+	source: String!
+	sourceName: String
+	sourceUrl: String
+	category: String
+	priority: Int!
+	isBreaking: Boolean!
+	publishedAt: Time
+	expiresAt: Time
+}
+
+type NewsItemList {
+	items: [NewsItem!]!
+	totalCount: Int!
+	limit: Int!
+	offset: Int!
+	hasMore: Boolean!
+}
+
+type Event {
+	id: ID!
+	title: String!
+	slug: String!
+	date: Time!
+	time: String
+	location: String!
+	venue: String
+	address: String
+	type: String!
+	category: String!
+	status: String!
+	description: String
+	fullDescription: String
+	attendees: Int!
+	image: String
+	registrationOpen: Boolean!
+	registrationDeadline: Time
+	price: String
+	# JSON encoded
+	speakers: String
+	agenda: String
+	requirements: String
+	metaTitle: String
+	metaDescription: String
+	isFeatured: Boolean!
+}
+
+type EventList {
+	items: [Event!]!
+	totalCount: Int!
+	limit: Int!
+	offset: Int!
+	hasMore: Boolean!
+}
+
+type Page {
+	id: ID!
+	title: String!
+	slug: String!
+	content: String!
+	excerpt: String!
+	template: String!
+	featuredImage: String
+	metaTitle: String!
+	metaDescription: String!
+	metaKeywords: String!
+	sortOrder: Int!
+	publishedAt: Time
+	parent: Page
+	children: [Page!]!
+}
+
+type PageList {
+	items: [Page!]!
+	totalCount: Int!
+	limit: Int!
+	offset: Int!
+	hasMore: Boolean!
+}
+
+type Menu {
+	id: ID!
+	name: String!
+	location: String!
+	items: [MenuItem!]!
+}
+
+type MenuItem {
+	id: ID!
+	label: String!
+	url: String!
+	target: String!
+	iconClass: String!
+	sortOrder: Int!
+	children: [MenuItem!]!
+}
+
+type Setting {
+	key: String!
+	value: String!
+	type: String!
+	group: String!
+	label: String!
+}
+`
+
+// NewSchema parses the schema and binds it to the resolvers
+func NewSchema() *gql.Schema {
+	return gql.MustParseSchema(schemaSDL, &Resolver{db: database.GetDB()},
+		gql.MaxDepth(10),
+		gql.MaxParallelism(10),
+	)
+}
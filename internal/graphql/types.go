@@ -0,0 +1,384 @@
+package graphql
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	cms_models "gcx-cms/internal/cms/models"
+	md_models "gcx-cms/internal/marketdata/models"
+
+	gql "github.com/graph-gophers/graphql-go"
+	"gorm.io/datatypes"
+)
+
+func toID(id uint) gql.ID {
+	return gql.ID(strconv.FormatUint(uint64(id), 10))
+}
+
+func toTime(t *time.Time) *gql.Time {
+	if t == nil {
+		return nil
+	}
+	return &gql.Time{Time: *t}
+}
+
+func jsonString(j datatypes.JSON) *string {
+	if len(j) == 0 {
+		return nil
+	}
+	s := string(j)
+	return &s
+}
+
+// listInfo resolves the pagination fields shared by the list types
+type listInfo struct {
+	total  int64
+	limit  int
+	offset int
+}
+
+func (l listInfo) TotalCount() int32 { return int32(l.total) }
+func (l listInfo) Limit() int32      { return int32(l.limit) }
+func (l listInfo) Offset() int32     { return int32(l.offset) }
+func (l listInfo) HasMore() bool     { return int64(l.offset+l.limit) < l.total }
+
+// MarketData
+
+type marketDataResolver struct {
+	p md_models.MarketData
+}
+
+func newMarketDataResolvers(prices []md_models.MarketData) []*marketDataResolver {
+	resolvers := make([]*marketDataResolver, len(prices))
+	for i := range prices {
+		resolvers[i] = &marketDataResolver{p: prices[i]}
+	}
+	return resolvers
+}
+
+func (r *marketDataResolver) ID() gql.ID              { return toID(r.p.ID) }
+func (r *marketDataResolver) Commodity() string       { return r.p.Commodity }
+func (r *marketDataResolver) ContractSeries() *string { return r.p.ContractSeries }
+func (r *marketDataResolver) Price() float64          { return r.p.Price }
+func (r *marketDataResolver) Currency() string        { return r.p.Currency }
+func (r *marketDataResolver) Unit() string            { return r.p.Unit }
+func (r *marketDataResolver) Change() float64         { return r.p.Change }
+func (r *marketDataResolver) ChangePercent() float64  { return r.p.ChangePercent }
+func (r *marketDataResolver) Open() *float64          { return r.p.Open }
+func (r *marketDataResolver) High() *float64          { return r.p.High }
+func (r *marketDataResolver) Low() *float64           { return r.p.Low }
+func (r *marketDataResolver) Close() *float64         { return r.p.Close }
+func (r *marketDataResolver) Volume() *float64        { return r.p.Volume }
+func (r *marketDataResolver) MarketDate() gql.Time    { return gql.Time{Time: r.p.MarketDate} }
+func (r *marketDataResolver) Source() string          { return r.p.Source }
+
+type marketDataListResolver struct {
+	listInfo
+	items []*marketDataResolver
+}
+
+func (r *marketDataListResolver) Items() []*marketDataResolver { return r.items }
+
+// Commodity
+
+type commodityResolver struct {
+	c       cms_models.Commodity
+	loaders *loaders
+}
+
+// newCommodityResolvers wraps commodities and primes their contract types and latest prices
+func newCommodityResolvers(ctx context.Context, commodities []cms_models.Commodity) []*commodityResolver {
+	l := loadersFrom(ctx)
+	resolvers := make([]*commodityResolver, len(commodities))
+	for i, c := range commodities {
+		l.contractTypes.prime(c.ID)
+		l.latestPrices.prime(strings.ToLower(c.Code), strings.ToLower(c.Name))
+		resolvers[i] = &commodityResolver{c: c, loaders: l}
+	}
+	return resolvers
+}
+
+func (r *commodityResolver) ID() gql.ID                  { return toID(r.c.ID) }
+func (r *commodityResolver) Name() string                { return r.c.Name }
+func (r *commodityResolver) Code() string                { return r.c.Code }
+func (r *commodityResolver) Description() string         { return r.c.Description }
+func (r *commodityResolver) FullDescription() string     { return r.c.FullDescription }
+func (r *commodityResolver) Specifications() string      { return r.c.Specifications }
+func (r *commodityResolver) TradingHours() string        { return r.c.TradingHours }
+func (r *commodityResolver) ContractSize() string        { return r.c.ContractSize }
+func (r *commodityResolver) PriceUnit() string           { return r.c.PriceUnit }
+func (r *commodityResolver) MinimumPrice() float64       { return r.c.MinimumPrice }
+func (r *commodityResolver) MaximumPrice() float64       { return r.c.MaximumPrice }
+func (r *commodityResolver) CurrentPrice() float64       { return r.c.CurrentPrice }
+func (r *commodityResolver) PriceChange() float64        { return r.c.PriceChange }
+func (r *commodityResolver) PriceChangePercent() float64 { return r.c.PriceChangePercent }
+func (r *commodityResolver) TradingVolume() float64      { return float64(r.c.TradingVolume) }
+func (r *commodityResolver) MarketStatus() string        { return r.c.MarketStatus }
+func (r *commodityResolver) ImagePath() string           { return r.c.ImagePath }
+func (r *commodityResolver) Category() string            { return r.c.Category }
+func (r *commodityResolver) OriginCountry() string       { return r.c.OriginCountry }
+func (r *commodityResolver) HarvestSeason() string       { return r.c.HarvestSeason }
+func (r *commodityResolver) DeliveryMonths() string      { return r.c.DeliveryMonths }
+func (r *commodityResolver) StorageRequirements() string { return r.c.StorageRequirements }
+func (r *commodityResolver) QualityStandards() string    { return r.c.QualityStandards }
+
+func (r *commodityResolver) ContractTypes() ([]*contractTypeResolver, error) {
+	contractTypes, err := r.loaders.contractTypes.load(r.c.ID)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*contractTypeResolver, len(contractTypes))
+	for i, ct := range contractTypes {
+		r.loaders.commodities.prime(ct.CommodityID)
+		resolvers[i] = &contractTypeResolver{ct: ct, loaders: r.loaders}
+	}
+	return resolvers, nil
+}
+
+// LatestPrice matches market data by commodity code, then by name
+func (r *commodityResolver) LatestPrice() (*marketDataResolver, error) {
+	for _, name := range []string{strings.ToLower(r.c.Code), strings.ToLower(r.c.Name)} {
+		price, err := r.loaders.latestPrices.load(name)
+		if err != nil {
+			return nil, err
+		}
+		if price != nil {
+			return &marketDataResolver{p: *price}, nil
+		}
+	}
+	return nil, nil
+}
+
+type commodityListResolver struct {
+	listInfo
+	items []*commodityResolver
+}
+
+func (r *commodityListResolver) Items() []*commodityResolver { return r.items }
+
+// CommodityContractType
+
+type contractTypeResolver struct {
+	ct      cms_models.CommodityContractType
+	loaders *loaders
+}
+
+func (r *contractTypeResolver) ID() gql.ID                  { return toID(r.ct.ID) }
+func (r *contractTypeResolver) CommodityID() gql.ID         { return toID(r.ct.CommodityID) }
+func (r *contractTypeResolver) Name() string                { return r.ct.Name }
+func (r *contractTypeResolver) Code() string                { return r.ct.Code }
+func (r *contractTypeResolver) Description() string         { return r.ct.Description }
+func (r *contractTypeResolver) FullDescription() string     { return r.ct.FullDescription }
+func (r *contractTypeResolver) Specifications() string      { return r.ct.Specifications }
+func (r *contractTypeResolver) TradingHours() string        { return r.ct.TradingHours }
+func (r *contractTypeResolver) ContractSize() string        { return r.ct.ContractSize }
+func (r *contractTypeResolver) PriceUnit() string           { return r.ct.PriceUnit }
+func (r *contractTypeResolver) ImagePath() string           { return r.ct.ImagePath }
+func (r *contractTypeResolver) DeliveryMonths() string      { return r.ct.DeliveryMonths }
+func (r *contractTypeResolver) StorageRequirements() string { return r.ct.StorageRequirements }
+func (r *contractTypeResolver) QualityStandards() string    { return r.ct.QualityStandards }
+func (r *contractTypeResolver) SortOrder() int32            { return int32(r.ct.SortOrder) }
+
+func (r *contractTypeResolver) Commodity(ctx context.Context) (*commodityResolver, error) {
+	commodity, err := r.loaders.commodities.load(r.ct.CommodityID)
+	if err != nil || commodity == nil {
+		return nil, err
+	}
+	return newCommodityResolvers(ctx, []cms_models.Commodity{*commodity})[0], nil
+}
+
+// NewsItem
+
+type newsItemResolver struct {
+	n cms_models.NewsItem
+}
+
+func (r *newsItemResolver) ID() gql.ID             { return toID(r.n.ID) }
+func (r *newsItemResolver) Title() string          { return r.n.Title }
+func (r *newsItemResolver) Content() string        { return r.n.Content }
+func (r *newsItemResolver) Source() string         { return string(r.n.Source) }
+func (r *newsItemResolver) SourceName() *string    { return r.n.SourceName }
+func (r *newsItemResolver) SourceURL() *string     { return r.n.SourceURL }
+func (r *newsItemResolver) Category() *string      { return r.n.Category }
+func (r *newsItemResolver) Priority() int32        { return int32(r.n.Priority) }
+func (r *newsItemResolver) IsBreaking() bool       { return r.n.IsBreaking }
+func (r *newsItemResolver) PublishedAt() *gql.Time { return toTime(r.n.PublishedAt) }
+func (r *newsItemResolver) ExpiresAt() *gql.Time   { return toTime(r.n.ExpiresAt) }
+
+type newsItemListResolver struct {
+	listInfo
+	items []*newsItemResolver
+}
+
+func (r *newsItemListResolver) Items() []*newsItemResolver { return r.items }
+
+// Event
+
+type eventResolver struct {
+	e cms_models.Event
+}
+
+func (r *eventResolver) ID() gql.ID                      { return toID(r.e.ID) }
+func (r *eventResolver) Title() string                   { return r.e.Title }
+func (r *eventResolver) Slug() string                    { return r.e.Slug }
+func (r *eventResolver) Date() gql.Time                  { return gql.Time{Time: r.e.Date} }
+func (r *eventResolver) Time() *string                   { return r.e.Time }
+func (r *eventResolver) Location() string                { return r.e.Location }
+func (r *eventResolver) Venue() *string                  { return r.e.Venue }
+func (r *eventResolver) Address() *string                { return r.e.Address }
+func (r *eventResolver) Type() string                    { return string(r.e.Type) }
+func (r *eventResolver) Category() string                { return r.e.Category }
+func (r *eventResolver) Status() string                  { return string(r.e.Status) }
+func (r *eventResolver) Description() *string            { return r.e.Description }
+func (r *eventResolver) FullDescription() *string        { return r.e.FullDescription }
+func (r *eventResolver) Attendees() int32                { return int32(r.e.Attendees) }
+func (r *eventResolver) Image() *string                  { return r.e.Image }
+func (r *eventResolver) RegistrationOpen() bool          { return r.e.RegistrationOpen }
+func (r *eventResolver) RegistrationDeadline() *gql.Time { return toTime(r.e.RegistrationDeadline) }
+func (r *eventResolver) Price() *string                  { return r.e.Price }
+func (r *eventResolver) Speakers() *string               { return jsonString(r.e.Speakers) }
+func (r *eventResolver) Agenda() *string                 { return jsonString(r.e.Agenda) }
+func (r *eventResolver) Requirements() *string           { return jsonString(r.e.Requirements) }
+func (r *eventResolver) MetaTitle() *string              { return r.e.MetaTitle }
+func (r *eventResolver) MetaDescription() *string        { return r.e.MetaDescription }
+func (r *eventResolver) IsFeatured() bool                { return r.e.IsFeatured }
+
+type eventListResolver struct {
+	listInfo
+	items []*eventResolver
+}
+
+func (r *eventListResolver) Items() []*eventResolver { return r.items }
+
+// Page
+
+type pageResolver struct {
+	p       cms_models.Page
+	loaders *loaders
+}
+
+// newPageResolvers wraps pages and primes their parents and children
+func newPageResolvers(ctx context.Context, pages []cms_models.Page) []*pageResolver {
+	l := loadersFrom(ctx)
+	resolvers := make([]*pageResolver, len(pages))
+	for i, p := range pages {
+		l.pageChildren.prime(p.ID)
+		if p.ParentID != nil {
+			l.pages.prime(*p.ParentID)
+		}
+		resolvers[i] = &pageResolver{p: p, loaders: l}
+	}
+	return resolvers
+}
+
+func (r *pageResolver) ID() gql.ID              { return toID(r.p.ID) }
+func (r *pageResolver) Title() string           { return r.p.Title }
+func (r *pageResolver) Slug() string            { return r.p.Slug }
+func (r *pageResolver) Content() string         { return r.p.Content }
+func (r *pageResolver) Excerpt() string         { return r.p.Excerpt }
+func (r *pageResolver) Template() string        { return r.p.Template }
+func (r *pageResolver) FeaturedImage() *string  { return r.p.FeaturedImage }
+func (r *pageResolver) MetaTitle() string       { return r.p.MetaTitle }
+func (r *pageResolver) MetaDescription() string { return r.p.MetaDescription }
+func (r *pageResolver) MetaKeywords() string    { return r.p.MetaKeywords }
+func (r *pageResolver) SortOrder() int32        { return int32(r.p.SortOrder) }
+func (r *pageResolver) PublishedAt() *gql.Time  { return toTime(r.p.PublishedAt) }
+
+func (r *pageResolver) Parent(ctx context.Context) (*pageResolver, error) {
+	if r.p.ParentID == nil {
+		return nil, nil
+	}
+	parent, err := r.loaders.pages.load(*r.p.ParentID)
+	if err != nil || parent == nil {
+		return nil, err
+	}
+	return newPageResolvers(ctx, []cms_models.Page{*parent})[0], nil
+}
+
+func (r *pageResolver) Children(ctx context.Context) ([]*pageResolver, error) {
+	children, err := r.loaders.pageChildren.load(r.p.ID)
+	if err != nil {
+		return nil, err
+	}
+	return newPageResolvers(ctx, children), nil
+}
+
+type pageListResolver struct {
+	listInfo
+	items []*pageResolver
+}
+
+func (r *pageListResolver) Items() []*pageResolver { return r.items }
+
+// Menu
+
+type menuResolver struct {
+	m       cms_models.Menu
+	loaders *loaders
+}
+
+// newMenuResolvers wraps menus and primes their items
+func newMenuResolvers(ctx context.Context, menus []cms_models.Menu) []*menuResolver {
+	l := loadersFrom(ctx)
+	resolvers := make([]*menuResolver, len(menus))
+	for i, m := range menus {
+		l.menuItems.prime(m.ID)
+		resolvers[i] = &menuResolver{m: m, loaders: l}
+	}
+	return resolvers
+}
+
+func (r *menuResolver) ID() gql.ID       { return toID(r.m.ID) }
+func (r *menuResolver) Name() string     { return r.m.Name }
+func (r *menuResolver) Location() string { return r.m.Location }
+
+// Items returns the top-level items; children come from the same batch
+func (r *menuResolver) Items() ([]*menuItemResolver, error) {
+	items, err := r.loaders.menuItems.load(r.m.ID)
+	if err != nil {
+		return nil, err
+	}
+	return menuItemsUnder(items, nil), nil
+}
+
+type menuItemResolver struct {
+	item cms_models.MenuItem
+	all  []cms_models.MenuItem // Every active item of the menu
+}
+
+// menuItemsUnder returns the items whose parent is parentID, or the top-level items for nil
+func menuItemsUnder(all []cms_models.MenuItem, parentID *uint) []*menuItemResolver {
+	resolvers := []*menuItemResolver{}
+	for _, item := range all {
+		if (parentID == nil && item.ParentID == nil) ||
+			(parentID != nil && item.ParentID != nil && *item.ParentID == *parentID) {
+			resolvers = append(resolvers, &menuItemResolver{item: item, all: all})
+		}
+	}
+	return resolvers
+}
+
+func (r *menuItemResolver) ID() gql.ID        { return toID(r.item.ID) }
+func (r *menuItemResolver) Label() string     { return r.item.Label }
+func (r *menuItemResolver) URL() string       { return r.item.URL }
+func (r *menuItemResolver) Target() string    { return r.item.Target }
+func (r *menuItemResolver) IconClass() string { return r.item.IconClass }
+func (r *menuItemResolver) SortOrder() int32  { return int32(r.item.SortOrder) }
+
+func (r *menuItemResolver) Children() []*menuItemResolver {
+	return menuItemsUnder(r.all, &r.item.ID)
+}
+
+// Setting
+
+type settingResolver struct {
+	s cms_models.Setting
+}
+
+func (r *settingResolver) Key() string   { return r.s.Key }
+func (r *settingResolver) Value() string { return r.s.Value }
+func (r *settingResolver) Type() string  { return r.s.Type }
+func (r *settingResolver) Group() string { return r.s.Group }
+func (r *settingResolver) Label() string { return r.s.Label }
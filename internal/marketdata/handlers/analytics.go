@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/marketdata/services"
 	"gcx-cms/internal/shared/config"
 
 	"github.com/gin-gonic/gin"
@@ -53,7 +55,7 @@ func GetMarketAnalytics(c *gin.Context) {
 
 	var analytics []models.MarketAnalytics
 
-	query := config.DB.Where("commodity = ? AND date BETWEEN ? AND ?",
+	query := config.DB.WithContext(c).Where("commodity = ? AND date BETWEEN ? AND ?",
 		commodity, start, end)
 
 	if err := query.Order("date ASC").Find(&analytics).Error; err != nil {
@@ -74,6 +76,47 @@ func GetMarketAnalytics(c *gin.Context) {
 	})
 }
 
+// GetSeasonality returns a commodity's average price profile by calendar month or week across
+// past years, with percentile bands and the current year's deviation from the seasonal norm
+// GET /api/marketdata/seasonality/:commodity?period=month|week&years=
+func GetSeasonality(c *gin.Context) {
+	commodity := c.Param("commodity")
+	period := c.DefaultQuery("period", services.SeasonalityByMonth)
+
+	years := 0
+	if raw := c.Query("years"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 50 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "years must be a number between 1 and 50",
+			})
+			return
+		}
+		years = parsed
+	}
+
+	if period != services.SeasonalityByMonth && period != services.SeasonalityByWeek {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "period must be month or week",
+		})
+		return
+	}
+
+	report, err := services.NewSeasonalityService().Analyze(commodity, period, years)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Unable to compute seasonality",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
 // GetPriceAlerts returns user's price alerts
 func GetPriceAlerts(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -84,10 +127,20 @@ func GetPriceAlerts(c *gin.Context) {
 		return
 	}
 
+	watchlist, ok := watchlistScope(c)
+	if !ok {
+		return
+	}
+
 	var alerts []models.PriceAlert
 
-	if err := config.DB.Where("user_id = ?", userID).
-		Order("created_at DESC").
+	query := config.DB.WithContext(c).Where("user_id = ?", userID)
+	if watchlist != nil {
+		// Alerts are set on commodities, so contract types on the watchlist cover their commodity's
+		query = query.Where("commodity IN ?", watchlist.Commodities)
+	}
+
+	if err := query.Order("created_at DESC").
 		Find(&alerts).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to fetch price alerts",
@@ -143,7 +196,7 @@ func CreatePriceAlert(c *gin.Context) {
 		IsActive:    true,
 	}
 
-	if err := config.DB.Create(&alert).Error; err != nil {
+	if err := config.DB.WithContext(c).Create(&alert).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to create price alert",
 			"details": err.Error(),
@@ -192,7 +245,7 @@ func UpdatePriceAlert(c *gin.Context) {
 
 	// Get the alert
 	var alert models.PriceAlert
-	if err := config.DB.Where("id = ? AND user_id = ?", alertID, userID).
+	if err := config.DB.WithContext(c).Where("id = ? AND user_id = ?", alertID, userID).
 		First(&alert).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Price alert not found",
@@ -219,7 +272,7 @@ func UpdatePriceAlert(c *gin.Context) {
 	}
 
 	if len(updates) > 0 {
-		if err := config.DB.Model(&alert).Updates(updates).Error; err != nil {
+		if err := config.DB.WithContext(c).Model(&alert).Updates(updates).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error":   "Failed to update price alert",
 				"details": err.Error(),
@@ -253,7 +306,7 @@ func DeletePriceAlert(c *gin.Context) {
 	}
 
 	// Delete the alert
-	if err := config.DB.Where("id = ? AND user_id = ?", alertID, userID).
+	if err := config.DB.WithContext(c).Where("id = ? AND user_id = ?", alertID, userID).
 		Delete(&models.PriceAlert{}).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to delete price alert",
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/marketdata/services"
+	"gcx-cms/internal/shared/config"
+	shared_models "gcx-cms/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHistoricalPrices exports historical prices for one or more commodities as CSV, XLSX or
+// Parquet. Small exports stream in the response; exports above the async threshold, or any
+// export with async=true, are queued as a job with a download link.
+// GET /api/marketdata/export?format=&commodities=&columns=&start_date=&end_date=&series=&async=
+func ExportHistoricalPrices(c *gin.Context) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+	u, ok := user.(*shared_models.User)
+	if !ok || !services.HasDataAccess(u, models.DataTypeHistorical) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Historical data access required",
+		})
+		return
+	}
+
+	format := c.DefaultQuery("format", models.ExportFormatCSV)
+	if !models.IsValidExportFormat(format) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Format must be one of csv, xlsx, parquet",
+		})
+		return
+	}
+
+	req := services.ExportRequest{
+		Format:      format,
+		Commodities: splitList(c.Query("commodities")),
+		Columns:     splitList(c.Query("columns")),
+		Series:      c.Query("series"),
+		Start:       time.Now().AddDate(-1, 0, 0),
+		End:         time.Now(),
+	}
+	if commodity := c.Query("commodity"); commodity != "" {
+		req.Commodities = append(req.Commodities, commodity)
+	}
+
+	var err error
+	if raw := c.Query("start_date"); raw != "" {
+		if req.Start, err = time.Parse("2006-01-02", raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid start_date format. Use YYYY-MM-DD",
+			})
+			return
+		}
+	}
+	if raw := c.Query("end_date"); raw != "" {
+		if req.End, err = time.Parse("2006-01-02", raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid end_date format. Use YYYY-MM-DD",
+			})
+			return
+		}
+	}
+	req.Start = time.Date(req.Start.Year(), req.Start.Month(), req.Start.Day(), 0, 0, 0, 0, time.UTC)
+	req.End = time.Date(req.End.Year(), req.End.Month(), req.End.Day(), 0, 0, 0, 0, time.UTC)
+	if req.End.Before(req.Start) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "end_date must not be before start_date",
+		})
+		return
+	}
+
+	exportService := services.NewExportService()
+	if _, err := exportService.ResolveColumns(req.Columns); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":             err.Error(),
+			"available_columns": services.ExportColumnNames(),
+		})
+		return
+	}
+
+	count, err := exportService.Count(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to count export rows",
+			"details": err.Error(),
+		})
+		return
+	}
+	if format == models.ExportFormatXLSX && count >= services.XLSXMaxRows {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Export has %d rows, more than an Excel sheet holds. Use csv or parquet, or narrow the selection", count),
+		})
+		return
+	}
+
+	if c.Query("async") == "true" || count > exportService.AsyncThreshold() {
+		job, err := exportService.CreateJob(u.ID, req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to queue export",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{
+			"success":    true,
+			"message":    "Export queued",
+			"data":       job,
+			"row_count":  count,
+			"status_url": fmt.Sprintf("/api/marketdata/exports/%d", job.ID),
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("gcx-market-data-%s-%s.%s", req.Start.Format("20060102"), req.End.Format("20060102"), format)
+	c.Header("Content-Type", services.ExportContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("X-Export-Rows", fmt.Sprint(count))
+	c.Status(http.StatusOK)
+
+	// Headers are already sent, so a failure part way can only be logged and the stream cut short
+	if _, err := exportService.Write(c.Request.Context(), c.Writer, req); err != nil {
+		c.Error(err)
+	}
+}
+
+// GetExportJobs lists the user's export jobs
+// GET /api/marketdata/exports
+func GetExportJobs(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	var jobs []models.ExportJob
+	if err := config.DB.WithContext(c).Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(50).
+		Find(&jobs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch export jobs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    jobs,
+		"count":   len(jobs),
+	})
+}
+
+// GetExportJob returns an export job's status and, once complete, its download link
+// GET /api/marketdata/exports/:id
+func GetExportJob(c *gin.Context) {
+	job, ok := findUserExportJob(c)
+	if !ok {
+		return
+	}
+
+	response := gin.H{
+		"success": true,
+		"data":    job,
+	}
+	if job.Status == models.ExportStatusCompleted {
+		response["download_url"] = fmt.Sprintf("/api/marketdata/exports/%d/download", job.ID)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// DownloadExportJob sends a completed export file
+// GET /api/marketdata/exports/:id/download
+func DownloadExportJob(c *gin.Context) {
+	job, ok := findUserExportJob(c)
+	if !ok {
+		return
+	}
+
+	if job.Status != models.ExportStatusCompleted || job.FilePath == "" {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  "Export is not ready for download",
+			"status": job.Status,
+		})
+		return
+	}
+	if _, err := os.Stat(job.FilePath); err != nil {
+		c.JSON(http.StatusGone, gin.H{
+			"error": "Export file is no longer available",
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("gcx-market-data-%s-%s.%s", job.StartDate.Format("20060102"), job.EndDate.Format("20060102"), job.Format)
+	c.Header("Content-Type", services.ExportContentType(job.Format))
+	c.FileAttachment(job.FilePath, filename)
+}
+
+// findUserExportJob loads the export job in the URL if it belongs to the current user
+func findUserExportJob(c *gin.Context) (*models.ExportJob, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return nil, false
+	}
+
+	var job models.ExportJob
+	if err := config.DB.WithContext(c).Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&job).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Export job not found",
+		})
+		return nil, false
+	}
+	return &job, true
+}
+
+// splitList splits a comma-separated query value, dropping blanks
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/marketdata/services"
+	"gcx-cms/internal/shared/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateForecast runs a week-ahead price forecast for a commodity and stores it
+// POST /api/marketdata/forecasts
+func CreateForecast(c *gin.Context) {
+	var req struct {
+		Commodity string `json:"commodity" binding:"required"`
+		Horizon   int    `json:"horizon"` // Weeks ahead, 1-12
+		Model     string `json:"model"`   // auto, holt_winters, drift or naive
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	if req.Horizon == 0 {
+		req.Horizon = 4
+	}
+	if req.Model == "" {
+		req.Model = models.ForecastModelAuto
+	}
+	if !models.IsValidForecastModel(req.Model) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Model must be one of auto, holt_winters, drift, naive",
+		})
+		return
+	}
+
+	var createdBy *uint
+	if userID, exists := c.Get("user_id"); exists {
+		if id, ok := userID.(uint); ok {
+			createdBy = &id
+		}
+	}
+
+	run, err := services.NewForecastService().Run(c, req.Commodity, req.Model, req.Horizon, createdBy)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Unable to produce forecast",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    run,
+	})
+}
+
+// GetForecasts lists stored forecast runs, newest first
+// GET /api/marketdata/forecasts?commodity=&limit=
+func GetForecasts(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	query := config.DB.WithContext(c).Model(&models.ForecastRun{})
+	if commodity := c.Query("commodity"); commodity != "" {
+		query = query.Where("commodity = ?", commodity)
+	}
+
+	var runs []models.ForecastRun
+	if err := query.Order("created_at DESC").Limit(limit).Find(&runs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch forecasts",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    runs,
+		"count":   len(runs),
+	})
+}
+
+// GetForecast returns a stored run with its points compared against actuals where available
+// GET /api/marketdata/forecasts/:id
+func GetForecast(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid forecast ID",
+		})
+		return
+	}
+
+	forecastService := services.NewForecastService()
+	run, err := forecastService.LoadRun(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Forecast not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":       true,
+		"data":          run,
+		"realised_mape": forecastService.FillActuals(c, run),
+	})
+}
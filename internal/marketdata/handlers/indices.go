@@ -0,0 +1,416 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/marketdata/services"
+	"gcx-cms/internal/shared/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// indexDefinitionRequest is the basket definition accepted when creating an index or a new version
+type indexDefinitionRequest struct {
+	RebalanceDates []string `json:"rebalance_dates"` // YYYY-MM-DD
+	Constituents   []struct {
+		Commodity string  `json:"commodity" binding:"required"`
+		Weight    float64 `json:"weight" binding:"required"`
+	} `json:"constituents" binding:"required"`
+	Notes string `json:"notes"`
+}
+
+// toVersion converts the request into an unsaved definition version
+func (r *indexDefinitionRequest) toVersion(c *gin.Context) *models.IndexDefinitionVersion {
+	version := &models.IndexDefinitionVersion{
+		RebalanceDates: strings.Join(r.RebalanceDates, ","),
+		Notes:          r.Notes,
+	}
+	for _, constituent := range r.Constituents {
+		version.Constituents = append(version.Constituents, models.IndexConstituent{
+			Commodity: constituent.Commodity,
+			Weight:    constituent.Weight,
+		})
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		if id, ok := userID.(uint); ok {
+			version.CreatedBy = &id
+		}
+	}
+	return version
+}
+
+// findIndexByCode loads an index by its code (case-insensitive)
+func findIndexByCode(code string) (*models.CommodityIndex, error) {
+	var index models.CommodityIndex
+	if err := config.DB.Where("code = ?", strings.ToUpper(code)).First(&index).Error; err != nil {
+		return nil, err
+	}
+	return &index, nil
+}
+
+// GetIndices lists active GCX indices with their latest level
+// GET /api/marketdata/indices
+func GetIndices(c *gin.Context) {
+	var indices []models.CommodityIndex
+	if err := config.DB.WithContext(c).Where("is_active = ?", true).Order("code ASC").Find(&indices).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch indices",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	indexService := services.NewIndexService()
+	data := make([]gin.H, 0, len(indices))
+	for _, index := range indices {
+		entry := gin.H{"index": index, "latest": nil}
+		if latest, err := indexService.Latest(index.ID); err == nil {
+			entry["latest"] = latest
+		}
+		data = append(data, entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    data,
+		"count":   len(data),
+	})
+}
+
+// GetIndex returns an index with its current definition and latest level
+// GET /api/marketdata/indices/:code
+func GetIndex(c *gin.Context) {
+	index, err := findIndexByCode(c.Param("code"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Index not found",
+		})
+		return
+	}
+
+	indexService := services.NewIndexService()
+	definition, err := indexService.CurrentVersion(index.ID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to load index definition",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response := gin.H{
+		"success":    true,
+		"index":      index,
+		"definition": definition,
+		"latest":     nil,
+	}
+	if latest, err := indexService.Latest(index.ID); err == nil {
+		response["latest"] = latest
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetIndexHistory returns daily index levels
+// GET /api/marketdata/indices/:code/history?start_date=&end_date=
+func GetIndexHistory(c *gin.Context) {
+	index, err := findIndexByCode(c.Param("code"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Index not found",
+		})
+		return
+	}
+
+	start := time.Now().AddDate(0, 0, -30)
+	end := time.Now()
+	if raw := c.Query("start_date"); raw != "" {
+		if start, err = time.Parse("2006-01-02", raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid start_date format. Use YYYY-MM-DD",
+			})
+			return
+		}
+	}
+	if raw := c.Query("end_date"); raw != "" {
+		if end, err = time.Parse("2006-01-02", raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid end_date format. Use YYYY-MM-DD",
+			})
+			return
+		}
+	}
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+
+	var values []models.IndexValue
+	if err := config.DB.WithContext(c).Where("index_id = ? AND is_intraday = ? AND value_date BETWEEN ? AND ?", index.ID, false, start, end).
+		Order("value_date ASC").
+		Find(&values).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch index history",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":    true,
+		"index":      index.Code,
+		"start_date": start,
+		"end_date":   end,
+		"data":       values,
+		"count":      len(values),
+	})
+}
+
+// GetIndexVersions lists every definition version of an index
+// GET /api/marketdata/indices/:code/versions
+func GetIndexVersions(c *gin.Context) {
+	index, err := findIndexByCode(c.Param("code"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Index not found",
+		})
+		return
+	}
+
+	versions, err := services.NewIndexService().Versions(index.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch index definitions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"index":   index.Code,
+		"data":    versions,
+		"count":   len(versions),
+	})
+}
+
+// AdminCreateIndex defines a new index and its base definition
+// POST /api/admin/marketdata/indices
+func AdminCreateIndex(c *gin.Context) {
+	var req struct {
+		Code        string  `json:"code" binding:"required"`
+		Name        string  `json:"name" binding:"required"`
+		Description string  `json:"description"`
+		Currency    string  `json:"currency"`
+		BaseDate    string  `json:"base_date" binding:"required"` // YYYY-MM-DD
+		BaseValue   float64 `json:"base_value"`
+		indexDefinitionRequest
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	baseDate, err := time.Parse("2006-01-02", req.BaseDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid base_date format. Use YYYY-MM-DD",
+		})
+		return
+	}
+
+	index := &models.CommodityIndex{
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+		Currency:    req.Currency,
+		IsActive:    true,
+	}
+	if index.Currency == "" {
+		index.Currency = "GHS"
+	}
+
+	version := req.toVersion(c)
+	version.BaseDate = baseDate
+	version.BaseValue = req.BaseValue
+	if version.BaseValue == 0 {
+		version.BaseValue = 1000
+	}
+
+	indexService := services.NewIndexService()
+	if err := indexService.CreateIndex(c, index, version); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create index",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// The base level is known by definition; store it so history starts at the base date
+	baseLevel, computeErr := indexService.ComputeDaily(index, baseDate)
+
+	response := gin.H{
+		"success":    true,
+		"message":    "Index created successfully",
+		"data":       index,
+		"definition": version,
+	}
+	if computeErr != nil {
+		response["warning"] = "Base level not stored: " + computeErr.Error()
+	} else {
+		response["base_level"] = baseLevel
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// AdminUpdateIndex updates an index's descriptive fields. Basket changes go through a new version.
+// PUT /api/admin/marketdata/indices/:code
+func AdminUpdateIndex(c *gin.Context) {
+	index, err := findIndexByCode(c.Param("code"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Index not found",
+		})
+		return
+	}
+
+	var req struct {
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+		IsActive    *bool   `json:"is_active"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+
+	if err := config.DB.WithContext(c).Model(index).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update index",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Index updated successfully",
+		"data":    index,
+	})
+}
+
+// AdminCreateIndexVersion adds a new basket definition, chain-linked from its effective date
+// POST /api/admin/marketdata/indices/:code/versions
+func AdminCreateIndexVersion(c *gin.Context) {
+	index, err := findIndexByCode(c.Param("code"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Index not found",
+		})
+		return
+	}
+
+	var req struct {
+		EffectiveFrom string `json:"effective_from" binding:"required"` // YYYY-MM-DD
+		indexDefinitionRequest
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	effectiveFrom, err := time.Parse("2006-01-02", req.EffectiveFrom)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid effective_from format. Use YYYY-MM-DD",
+		})
+		return
+	}
+
+	version := req.toVersion(c)
+	version.EffectiveFrom = effectiveFrom
+	if err := services.NewIndexService().AddVersion(c, index, version); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create index definition",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Index definition version created",
+		"data":    version,
+	})
+}
+
+// AdminComputeIndex recomputes stored daily levels over a date range
+// POST /api/admin/marketdata/indices/:code/compute?from=&to=
+func AdminComputeIndex(c *gin.Context) {
+	index, err := findIndexByCode(c.Param("code"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Index not found",
+		})
+		return
+	}
+
+	to := time.Now()
+	from := to
+	if raw := c.Query("from"); raw != "" {
+		if from, err = time.Parse("2006-01-02", raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid from format. Use YYYY-MM-DD",
+			})
+			return
+		}
+	}
+	if raw := c.Query("to"); raw != "" {
+		if to, err = time.Parse("2006-01-02", raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid to format. Use YYYY-MM-DD",
+			})
+			return
+		}
+	}
+
+	stored, err := services.NewIndexService().Backfill(index, from, to)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "Index computation stopped",
+			"details": err.Error(),
+			"stored":  stored,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Index levels computed",
+		"index":   index.Code,
+		"stored":  stored,
+		"from":    from.Format("2006-01-02"),
+		"to":      to.Format("2006-01-02"),
+	})
+}
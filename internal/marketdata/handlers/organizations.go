@@ -0,0 +1,538 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/marketdata/services"
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/mail"
+	shared_models "gcx-cms/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetOrganization returns the current user's organisation with its members, seats and subscription
+func GetOrganization(c *gin.Context) {
+	member, ok := currentMembership(c)
+	if !ok {
+		return
+	}
+
+	var org models.Organization
+	if err := config.DB.WithContext(c).Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("role ASC, created_at ASC")
+	}).Preload("Members.User").First(&org, member.OrganizationID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch organisation",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	organizationResponse(c, &org, member.Role)
+}
+
+// organizationResponse writes an organisation with its seats and active subscription
+func organizationResponse(c *gin.Context, org *models.Organization, role string) {
+	seats, err := services.NewOrganizationService().Seats(org.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to count seats",
+			"details": err.Error(),
+		})
+		return
+	}
+	var subscription *models.UserSubscription
+	if s, err := services.OrganizationSubscription(org.ID); err == nil {
+		subscription = s
+	}
+
+	response := gin.H{
+		"success":      true,
+		"data":         org,
+		"seats":        seats,
+		"subscription": subscription,
+	}
+	if role != "" {
+		response["role"] = role
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateOrganization creates an organisation with the current user as its admin. Members can
+// be invited once the organisation subscribes to a plan with more than one seat.
+func CreateOrganization(c *gin.Context) {
+	user, ok := organizationUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Name is required",
+		})
+		return
+	}
+
+	org, err := services.NewOrganizationService().Create(c, req.Name, user)
+	if err != nil {
+		organizationError(c, err, "Failed to create organisation")
+		return
+	}
+	audit.Record(c, "organization.created", "organization", org.ID, map[string]interface{}{
+		"name": org.Name,
+		"slug": org.Slug,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Organisation created successfully",
+		"data":    org,
+	})
+}
+
+// UpdateOrganization renames the current user's organisation (organisation admin)
+func UpdateOrganization(c *gin.Context) {
+	member, ok := organizationAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Name is required",
+		})
+		return
+	}
+
+	var org models.Organization
+	if err := config.DB.WithContext(c).First(&org, member.OrganizationID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Organisation not found",
+		})
+		return
+	}
+	previous := org.Name
+	if err := config.DB.WithContext(c).Model(&org).Update("name", name).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update organisation",
+			"details": err.Error(),
+		})
+		return
+	}
+	audit.Record(c, "organization.updated", "organization", org.ID, map[string]interface{}{
+		"name": audit.Change{From: previous, To: name},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Organisation updated successfully",
+		"data":    org,
+	})
+}
+
+// GetOrganizationInvites lists the organisation's pending invites (organisation admin)
+func GetOrganizationInvites(c *gin.Context) {
+	member, ok := organizationAdmin(c)
+	if !ok {
+		return
+	}
+
+	var invites []models.OrganizationInvite
+	if err := config.DB.WithContext(c).Where("organization_id = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?",
+		member.OrganizationID, time.Now()).
+		Order("created_at DESC").
+		Find(&invites).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch invites",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    invites,
+		"count":   len(invites),
+	})
+}
+
+// CreateOrganizationInvite emails someone an invite to join the organisation, taking a seat
+// until it is accepted, revoked or expires (organisation admin)
+func CreateOrganizationInvite(c *gin.Context) {
+	member, ok := organizationAdmin(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Email string `json:"email" binding:"required,email"`
+		Role  string `json:"role"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	invite, raw, err := services.NewOrganizationService().Invite(c, member.OrganizationID, req.Email, req.Role, member.UserID)
+	if err != nil {
+		organizationError(c, err, "Failed to create invite")
+		return
+	}
+	audit.Record(c, "organization.member_invited", "organization", member.OrganizationID, map[string]interface{}{
+		"invite_id": invite.ID,
+		"email":     invite.Email,
+		"role":      invite.Role,
+	})
+
+	var org models.Organization
+	config.DB.WithContext(c).First(&org, member.OrganizationID)
+	mail.SendAsync(mail.Message{
+		To:      invite.Email,
+		Subject: fmt.Sprintf("You have been invited to join %s on GCX", org.Name),
+		Body: fmt.Sprintf("Hello,\n\nYou have been invited to join %s on GCX and share its market data subscription. "+
+			"Sign in or create an account with this email address, then accept the invitation within 7 days:\n\n"+
+			"%s/organization/accept-invite?token=%s\n",
+			org.Name, mail.AppURL(), raw),
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Invite sent successfully",
+		"data":    invite,
+	})
+}
+
+// RevokeOrganizationInvite cancels a pending invite, freeing its seat (organisation admin)
+func RevokeOrganizationInvite(c *gin.Context) {
+	member, ok := organizationAdmin(c)
+	if !ok {
+		return
+	}
+
+	result := config.DB.WithContext(c).Model(&models.OrganizationInvite{}).
+		Where("id = ? AND organization_id = ? AND accepted_at IS NULL AND revoked_at IS NULL", c.Param("id"), member.OrganizationID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to revoke invite",
+			"details": result.Error.Error(),
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Pending invite not found",
+		})
+		return
+	}
+	audit.Record(c, "organization.invite_revoked", "organization", member.OrganizationID, map[string]interface{}{
+		"invite_id": c.Param("id"),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Invite revoked successfully",
+	})
+}
+
+// AcceptOrganizationInvite joins the current user to the organisation that invited their email
+func AcceptOrganizationInvite(c *gin.Context) {
+	user, ok := organizationUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	member, err := services.NewOrganizationService().Accept(c, req.Token, user)
+	if err != nil {
+		organizationError(c, err, "Failed to accept invite")
+		return
+	}
+	audit.Record(c, "organization.member_joined", "organization", member.OrganizationID, map[string]interface{}{
+		"user_id": user.ID,
+		"role":    member.Role,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "You have joined the organisation",
+		"data":    member,
+	})
+}
+
+// UpdateOrganizationMember makes a member an organisation admin or a plain member (organisation admin)
+func UpdateOrganizationMember(c *gin.Context) {
+	admin, ok := organizationAdmin(c)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	var req struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	member, previous, err := services.NewOrganizationService().SetRole(c, admin.OrganizationID, uint(userID), req.Role)
+	if err != nil {
+		organizationError(c, err, "Failed to update member")
+		return
+	}
+	if previous != req.Role {
+		audit.Record(c, "organization.member_role_changed", "organization", admin.OrganizationID, map[string]interface{}{
+			"user_id": member.UserID,
+			"role":    audit.Change{From: previous, To: req.Role},
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Member updated successfully",
+		"data":    member,
+	})
+}
+
+// RemoveOrganizationMember takes a member out of the organisation, freeing their seat
+// (organisation admin)
+func RemoveOrganizationMember(c *gin.Context) {
+	admin, ok := organizationAdmin(c)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID",
+		})
+		return
+	}
+
+	member, err := services.NewOrganizationService().RemoveMember(c, admin.OrganizationID, uint(userID))
+	if err != nil {
+		organizationError(c, err, "Failed to remove member")
+		return
+	}
+	audit.Record(c, "organization.member_removed", "organization", admin.OrganizationID, map[string]interface{}{
+		"user_id": member.UserID,
+		"role":    member.Role,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Member removed successfully",
+	})
+}
+
+// LeaveOrganization takes the current user out of their organisation. The last admin has to
+// hand over to another member first.
+func LeaveOrganization(c *gin.Context) {
+	current, ok := currentMembership(c)
+	if !ok {
+		return
+	}
+
+	member, err := services.NewOrganizationService().RemoveMember(c, current.OrganizationID, current.UserID)
+	if err != nil {
+		organizationError(c, err, "Failed to leave organisation")
+		return
+	}
+	audit.Record(c, "organization.member_left", "organization", member.OrganizationID, map[string]interface{}{
+		"user_id": member.UserID,
+		"role":    member.Role,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "You have left the organisation",
+	})
+}
+
+// AdminGetOrganizations lists organisations with their member counts (admin only)
+func AdminGetOrganizations(c *gin.Context) {
+	type organizationSummary struct {
+		models.Organization
+		MemberCount int64 `json:"member_count"`
+	}
+
+	query := config.DB.WithContext(c).Model(&models.Organization{})
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		query = query.Where("name LIKE ? OR slug LIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	var organizations []organizationSummary
+	if err := query.Select("organizations.*, (SELECT COUNT(*) FROM organization_members WHERE organization_members.organization_id = organizations.id) AS member_count").
+		Order("name ASC").
+		Find(&organizations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch organisations",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    organizations,
+		"count":   len(organizations),
+	})
+}
+
+// AdminGetOrganization returns an organisation with its members, seats and subscription (admin only)
+func AdminGetOrganization(c *gin.Context) {
+	var org models.Organization
+	if err := config.DB.WithContext(c).Preload("Members").Preload("Members.User").First(&org, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Organisation not found",
+		})
+		return
+	}
+
+	organizationResponse(c, &org, "")
+}
+
+// organizationUser returns the authenticated user, responding itself when there is none
+func organizationUser(c *gin.Context) (*shared_models.User, bool) {
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return nil, false
+	}
+	u, ok := user.(*shared_models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return nil, false
+	}
+	return u, true
+}
+
+// currentMembership loads the current user's organisation membership, responding itself when
+// they have none
+func currentMembership(c *gin.Context) (*models.OrganizationMember, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return nil, false
+	}
+
+	member, err := services.NewOrganizationService().Membership(userID.(uint))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "You do not belong to an organisation",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to load organisation",
+				"details": err.Error(),
+			})
+		}
+		return nil, false
+	}
+	return member, true
+}
+
+// organizationAdmin loads the current user's membership and checks they administer the
+// organisation, responding itself otherwise
+func organizationAdmin(c *gin.Context) (*models.OrganizationMember, bool) {
+	member, ok := currentMembership(c)
+	if !ok {
+		return nil, false
+	}
+	if member.Role != models.OrganizationRoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Organisation admin access required",
+		})
+		return nil, false
+	}
+	return member, true
+}
+
+// organizationError responds with the status matching an organisation service error
+func organizationError(c *gin.Context, err error, message string) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrAlreadyInOrganization), errors.Is(err, services.ErrAlreadyMember):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrNoOrganizationSubscription), errors.Is(err, services.ErrNoSeatsAvailable):
+		status = http.StatusPaymentRequired
+	case errors.Is(err, services.ErrOrganizationInviteMismatch):
+		status = http.StatusForbidden
+	case errors.Is(err, services.ErrOrganizationMemberNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidOrganizationInvite), errors.Is(err, services.ErrLastOrganizationAdmin),
+		errors.Is(err, services.ErrInvalidOrganizationRoleName):
+		status = http.StatusBadRequest
+	}
+	if status == http.StatusInternalServerError {
+		c.JSON(status, gin.H{
+			"error":   message,
+			"details": err.Error(),
+		})
+		return
+	}
+	c.JSON(status, gin.H{
+		"error": err.Error(),
+	})
+}
@@ -15,7 +15,7 @@ func GetCurrentPrices(c *gin.Context) {
 	var prices []models.MarketData
 
 	// Get latest price for each commodity
-	if err := config.DB.Raw(`
+	if err := config.DB.WithContext(c).Raw(`
 		SELECT DISTINCT ON (commodity) * 
 		FROM market_data 
 		WHERE market_date >= CURRENT_DATE - INTERVAL '1 day'
@@ -47,10 +47,14 @@ func GetCommodityPrices(c *gin.Context) {
 
 	var prices []models.MarketData
 
+	query := config.DB.WithContext(c).Where("commodity = ? AND market_date >= ?",
+		commodity, time.Now().AddDate(0, 0, -30))
+	if series := c.Query("series"); series != "" {
+		query = query.Where("contract_series = ?", series)
+	}
+
 	// Get prices for the last 30 days
-	if err := config.DB.Where("commodity = ? AND market_date >= ?",
-		commodity, time.Now().AddDate(0, 0, -30)).
-		Order("market_date DESC").
+	if err := query.Order("market_date DESC").
 		Find(&prices).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to fetch commodity prices",
@@ -110,8 +114,11 @@ func GetHistoricalPrices(c *gin.Context) {
 
 	var prices []models.MarketData
 
-	query := config.DB.Where("commodity = ? AND market_date BETWEEN ? AND ?",
+	query := config.DB.WithContext(c).Where("commodity = ? AND market_date BETWEEN ? AND ?",
 		commodity, start, end)
+	if series := c.Query("series"); series != "" {
+		query = query.Where("contract_series = ?", series)
+	}
 
 	if err := query.Order("market_date ASC").Find(&prices).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -154,7 +161,7 @@ func GetPriceSummary(c *gin.Context) {
 		LastUpdated   time.Time `json:"last_updated"`
 	}
 
-	if err := config.DB.Raw(`
+	if err := config.DB.WithContext(c).Raw(`
 		SELECT 
 			commodity,
 			price as current_price,
@@ -1,11 +1,17 @@
 package handlers
 
 import (
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/marketdata/services"
+	cms_services "gcx-cms/internal/services"
 	"gcx-cms/internal/shared/config"
+	shared_models "gcx-cms/internal/shared/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,12 +20,20 @@ import (
 func GetRealTimeData(c *gin.Context) {
 	commodity := c.Query("commodity")
 
+	watchlist, ok := watchlistScope(c)
+	if !ok {
+		return
+	}
+
 	var data []models.MarketData
 
-	query := config.DB.Where("market_date = CURRENT_DATE")
+	query := config.DB.WithContext(c).Where("market_date = CURRENT_DATE")
 	if commodity != "" {
 		query = query.Where("commodity = ?", commodity)
 	}
+	if watchlist != nil {
+		query = watchlist.Where(query)
+	}
 
 	if err := query.Order("created_at DESC").Limit(100).Find(&data).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -38,16 +52,80 @@ func GetRealTimeData(c *gin.Context) {
 	})
 }
 
-// GetDataStream returns a stream of real-time data (placeholder for WebSocket implementation)
+// GetDataStream streams price updates to the client as server-sent events.
+// Filter with ?commodity=maize,soybean or ?watchlist_id=, whose contract types narrow their commodity
+// to its listed series; a heartbeat comment is sent every 25 seconds.
+// Admin replay sessions are only delivered with ?replay=include or ?replay=only.
 func GetDataStream(c *gin.Context) {
-	// This is a placeholder for WebSocket implementation
-	// In a real implementation, this would upgrade the connection to WebSocket
-	// and stream real-time market data updates
+	user, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+	if u, ok := user.(*shared_models.User); !ok || !services.HasDataAccess(u, models.DataTypeRealTime) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Real-time data access required",
+		})
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Data stream endpoint - WebSocket implementation required",
-		"note":    "This endpoint will be implemented with WebSocket for real-time streaming",
+	watchlist, ok := watchlistScope(c)
+	if !ok {
+		return
+	}
+	if watchlist != nil && len(watchlist.Commodities) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Watchlist has no commodities",
+		})
+		return
+	}
+	var requested []string
+	if raw := c.Query("commodity"); raw != "" {
+		requested = strings.Split(raw, ",")
+	}
+	commodities := requested
+	if watchlist != nil {
+		commodities = append(append([]string{}, watchlist.Commodities...), requested...)
+	}
+
+	mode, valid := services.ParseStreamMode(c.Query("replay"))
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "replay must be exclude, include or only",
+		})
+		return
+	}
+
+	messages, unsubscribe := services.GetPriceStream().Subscribe(services.NewStreamFilter(requested, watchlist), mode)
+	defer unsubscribe()
+
+	heartbeat := time.NewTicker(25 * time.Second)
+	defer heartbeat.Stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent("connected", gin.H{"commodities": commodities, "replay": c.DefaultQuery("replay", "exclude"), "timestamp": time.Now()})
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case msg, open := <-messages:
+			if !open {
+				return false
+			}
+			c.SSEvent(msg.Event, msg)
+			return true
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			return true
+		}
 	})
 }
 
@@ -68,7 +146,16 @@ func AdminCreatePrice(c *gin.Context) {
 		price.MarketDate = time.Now()
 	}
 
-	if err := config.DB.Create(&price).Error; err != nil {
+	if price.ContractSeries != nil && *price.ContractSeries != "" {
+		if _, err := cms_services.NewContractCalendarService().FindSeries(*price.ContractSeries); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Unknown contract series: " + *price.ContractSeries,
+			})
+			return
+		}
+	}
+
+	if err := config.DB.WithContext(c).Create(&price).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to create price record",
 			"details": err.Error(),
@@ -76,6 +163,8 @@ func AdminCreatePrice(c *gin.Context) {
 		return
 	}
 
+	services.PublishPriceEvent(models.WebhookEventPriceCreated, &price)
+
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"message": "Price record created successfully",
@@ -94,7 +183,7 @@ func AdminUpdatePrice(c *gin.Context) {
 	}
 
 	var price models.MarketData
-	if err := config.DB.First(&price, priceID).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&price, priceID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Price record not found",
 		})
@@ -110,8 +199,17 @@ func AdminUpdatePrice(c *gin.Context) {
 		return
 	}
 
+	if req.ContractSeries != nil && *req.ContractSeries != "" {
+		if _, err := cms_services.NewContractCalendarService().FindSeries(*req.ContractSeries); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Unknown contract series: " + *req.ContractSeries,
+			})
+			return
+		}
+	}
+
 	// Update fields
-	if err := config.DB.Model(&price).Updates(req).Error; err != nil {
+	if err := config.DB.WithContext(c).Model(&price).Updates(req).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to update price record",
 			"details": err.Error(),
@@ -119,6 +217,10 @@ func AdminUpdatePrice(c *gin.Context) {
 		return
 	}
 
+	// Reload so the correction event carries the stored values
+	config.DB.WithContext(c).First(&price, price.ID)
+	services.PublishPriceEvent(models.WebhookEventPriceCorrected, &price)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Price record updated successfully",
@@ -135,7 +237,7 @@ func AdminDeletePrice(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Delete(&models.MarketData{}, priceID).Error; err != nil {
+	if err := config.DB.WithContext(c).Delete(&models.MarketData{}, priceID).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to delete price record",
 			"details": err.Error(),
@@ -161,7 +263,7 @@ func AdminCreatePlan(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Create(&plan).Error; err != nil {
+	if err := config.DB.WithContext(c).Create(&plan).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to create subscription plan",
 			"details": err.Error(),
@@ -187,7 +289,7 @@ func AdminUpdatePlan(c *gin.Context) {
 	}
 
 	var plan models.SubscriptionPlan
-	if err := config.DB.First(&plan, planID).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&plan, planID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Subscription plan not found",
 		})
@@ -203,7 +305,7 @@ func AdminUpdatePlan(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Model(&plan).Updates(req).Error; err != nil {
+	if err := config.DB.WithContext(c).Model(&plan).Updates(req).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to update subscription plan",
 			"details": err.Error(),
@@ -227,7 +329,7 @@ func AdminDeletePlan(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Delete(&models.SubscriptionPlan{}, planID).Error; err != nil {
+	if err := config.DB.WithContext(c).Delete(&models.SubscriptionPlan{}, planID).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to delete subscription plan",
 			"details": err.Error(),
@@ -253,7 +355,7 @@ func AdminCreateCommodity(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Create(&commodity).Error; err != nil {
+	if err := config.DB.WithContext(c).Create(&commodity).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to create commodity",
 			"details": err.Error(),
@@ -279,7 +381,7 @@ func AdminUpdateCommodity(c *gin.Context) {
 	}
 
 	var commodity models.CommodityInfo
-	if err := config.DB.First(&commodity, commodityID).Error; err != nil {
+	if err := config.DB.WithContext(c).First(&commodity, commodityID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Commodity not found",
 		})
@@ -295,7 +397,7 @@ func AdminUpdateCommodity(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Model(&commodity).Updates(req).Error; err != nil {
+	if err := config.DB.WithContext(c).Model(&commodity).Updates(req).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to update commodity",
 			"details": err.Error(),
@@ -319,7 +421,7 @@ func AdminDeleteCommodity(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Delete(&models.CommodityInfo{}, commodityID).Error; err != nil {
+	if err := config.DB.WithContext(c).Delete(&models.CommodityInfo{}, commodityID).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to delete commodity",
 			"details": err.Error(),
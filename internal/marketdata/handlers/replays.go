@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"gcx-cms/internal/marketdata/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminStartReplay replays a past trading day or date range through the price stream.
+// Streaming clients receive it as replay.price events with replay set when they connect with
+// ?replay=include or ?replay=only.
+// POST /api/admin/marketdata/replays
+func AdminStartReplay(c *gin.Context) {
+	var req struct {
+		Date          string   `json:"date"`       // Single trading day, YYYY-MM-DD
+		StartDate     string   `json:"start_date"` // Or a range, YYYY-MM-DD
+		EndDate       string   `json:"end_date"`
+		Commodities   []string `json:"commodities"`
+		Speed         float64  `json:"speed"` // Defaults to 60x
+		MaxGapSeconds int      `json:"max_gap_seconds"`
+		Loop          bool     `json:"loop"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.Date != "" {
+		req.StartDate, req.EndDate = req.Date, req.Date
+	}
+	if req.StartDate == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Either date or start_date is required",
+		})
+		return
+	}
+	if req.EndDate == "" {
+		req.EndDate = req.StartDate
+	}
+
+	from, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid start_date format. Use YYYY-MM-DD",
+		})
+		return
+	}
+	to, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid end_date format. Use YYYY-MM-DD",
+		})
+		return
+	}
+	if req.Speed == 0 {
+		req.Speed = 60
+	}
+
+	var startedBy uint
+	if userID, exists := c.Get("user_id"); exists {
+		startedBy, _ = userID.(uint)
+	}
+
+	session, err := services.GetReplayManager().Start(services.ReplayOptions{
+		From:        from,
+		To:          to,
+		Commodities: req.Commodities,
+		Speed:       req.Speed,
+		MaxGapSecs:  req.MaxGapSeconds,
+		Loop:        req.Loop,
+	}, startedBy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to start replay",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Replay started",
+		"data":    session.Snapshot(),
+	})
+}
+
+// AdminGetReplays lists replay sessions since the server started
+// GET /api/admin/marketdata/replays
+func AdminGetReplays(c *gin.Context) {
+	sessions := services.GetReplayManager().List()
+	data := make([]*services.ReplaySession, len(sessions))
+	for i, s := range sessions {
+		data[i] = s.Snapshot()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    data,
+		"count":   len(data),
+	})
+}
+
+// AdminGetReplay returns a replay session's progress
+// GET /api/admin/marketdata/replays/:id
+func AdminGetReplay(c *gin.Context) {
+	session, ok := services.GetReplayManager().Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Replay session not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    session.Snapshot(),
+	})
+}
+
+// AdminPauseReplay pauses a running replay
+// POST /api/admin/marketdata/replays/:id/pause
+func AdminPauseReplay(c *gin.Context) {
+	replayControl(c, services.GetReplayManager().Pause, "Replay paused")
+}
+
+// AdminResumeReplay resumes a paused replay
+// POST /api/admin/marketdata/replays/:id/resume
+func AdminResumeReplay(c *gin.Context) {
+	replayControl(c, services.GetReplayManager().Resume, "Replay resumed")
+}
+
+// AdminStopReplay stops a replay
+// POST /api/admin/marketdata/replays/:id/stop
+func AdminStopReplay(c *gin.Context) {
+	replayControl(c, services.GetReplayManager().Stop, "Replay stopped")
+}
+
+// replayControl applies a pause/resume/stop action to the session in the URL
+func replayControl(c *gin.Context, action func(string) (*services.ReplaySession, error), message string) {
+	id := c.Param("id")
+	if _, ok := services.GetReplayManager().Get(id); !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Replay session not found",
+		})
+		return
+	}
+
+	session, err := action(id)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Replay state change rejected",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": message,
+		"data":    session.Snapshot(),
+	})
+}
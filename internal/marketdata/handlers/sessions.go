@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/marketdata/services"
+	"gcx-cms/internal/shared/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTradingSession returns the trading session for a date (defaults to today)
+func GetTradingSession(c *gin.Context) {
+	date, err := parseSessionDate(c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid date format. Use YYYY-MM-DD",
+		})
+		return
+	}
+
+	var session models.TradingSession
+	if err := config.DB.WithContext(c).Where("date = ?", date).First(&session).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "No trading session found for date",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    session,
+	})
+}
+
+// AdminUpdateTradingSession opens, closes or halts the trading session for a date
+func AdminUpdateTradingSession(c *gin.Context) {
+	var req struct {
+		Date   string `json:"date"`                      // YYYY-MM-DD, defaults to today
+		Status string `json:"status" binding:"required"` // open, closed, halted, pre_market, post_market
+		Reason string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	switch req.Status {
+	case "open", "closed", "halted", "pre_market", "post_market":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Status must be one of open, closed, halted, pre_market, post_market",
+		})
+		return
+	}
+
+	date, err := parseSessionDate(req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid date format. Use YYYY-MM-DD",
+		})
+		return
+	}
+
+	var session models.TradingSession
+	config.DB.WithContext(c).Where("date = ?", date).FirstOrInit(&session, models.TradingSession{Date: date})
+
+	previous := session.Status
+	now := time.Now()
+	session.Status = req.Status
+	session.IsOpen = req.Status == "open"
+	if req.Status == "open" && session.OpenTime.IsZero() {
+		session.OpenTime = now
+	}
+	if req.Status == "closed" {
+		session.CloseTime = now
+	}
+
+	if err := config.DB.WithContext(c).Save(&session).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update trading session",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if previous != req.Status {
+		event := gin.H{
+			"session":         session,
+			"previous_status": previous,
+			"reason":          req.Reason,
+		}
+		switch req.Status {
+		case "open":
+			services.GetWebhookService().Publish(models.WebhookEventSessionOpened, event)
+		case "halted":
+			services.GetWebhookService().Publish(models.WebhookEventMarketHalted, event)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Trading session updated successfully",
+		"data":    session,
+	})
+}
+
+// parseSessionDate parses a YYYY-MM-DD date, defaulting to today
+func parseSessionDate(value string) (time.Time, error) {
+	if value == "" {
+		now := time.Now()
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()), nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
@@ -1,21 +1,50 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/marketdata/services"
+	"gcx-cms/internal/shared/audit"
 	"gcx-cms/internal/shared/config"
+	shared_models "gcx-cms/internal/shared/models"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// RequireDataAccess lets through users entitled to a data type, by their role or by the plan of
+// their own or their organisation's subscription
+func RequireDataAccess(dataType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, exists := c.Get("user")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "User not authenticated",
+			})
+			c.Abort()
+			return
+		}
+		if u, ok := user.(*shared_models.User); !ok || !services.HasDataAccess(u, dataType) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":     "Your subscription does not include this data",
+				"data_type": dataType,
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 // GetSubscriptionPlans returns all available subscription plans
 func GetSubscriptionPlans(c *gin.Context) {
 	var plans []models.SubscriptionPlan
 
-	if err := config.DB.Where("is_active = ?", true).
+	if err := config.DB.WithContext(c).Where("is_active = ?", true).
 		Order("sort_order ASC, price ASC").
 		Find(&plans).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -32,7 +61,8 @@ func GetSubscriptionPlans(c *gin.Context) {
 	})
 }
 
-// GetUserSubscription returns the current user's subscription
+// GetUserSubscription returns the subscription covering the current user, their own or their
+// organisation's, with its source
 func GetUserSubscription(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -42,11 +72,8 @@ func GetUserSubscription(c *gin.Context) {
 		return
 	}
 
-	var subscription models.UserSubscription
-
-	if err := config.DB.Preload("Plan").
-		Where("user_id = ? AND status = ?", userID, "active").
-		First(&subscription).Error; err != nil {
+	subscription, source, err := services.ActiveSubscription(userID.(uint))
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "No active subscription found",
 		})
@@ -56,10 +83,12 @@ func GetUserSubscription(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data":    subscription,
+		"source":  source,
 	})
 }
 
-// CreateSubscription creates a new subscription for the user
+// CreateSubscription creates a new subscription for the user, or with for_organization for the
+// organisation the user administers, its plan's max_users being the organisation's seats
 func CreateSubscription(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -74,6 +103,7 @@ func CreateSubscription(c *gin.Context) {
 		PaymentMethod    string `json:"payment_method" binding:"required"`
 		PaymentReference string `json:"payment_reference"`
 		AutoRenew        bool   `json:"auto_renew"`
+		ForOrganization  bool   `json:"for_organization"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -86,7 +116,7 @@ func CreateSubscription(c *gin.Context) {
 
 	// Check if plan exists and is active
 	var plan models.SubscriptionPlan
-	if err := config.DB.Where("id = ? AND is_active = ?", req.PlanID, true).
+	if err := config.DB.WithContext(c).Where("id = ? AND is_active = ?", req.PlanID, true).
 		First(&plan).Error; err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Invalid subscription plan",
@@ -94,19 +124,58 @@ func CreateSubscription(c *gin.Context) {
 		return
 	}
 
-	// Check if user already has an active subscription
-	var existingSubscription models.UserSubscription
-	if err := config.DB.Where("user_id = ? AND status = ?", userID, "active").
-		First(&existingSubscription).Error; err == nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "User already has an active subscription",
+	var organizationID *uint
+	member, err := services.NewOrganizationService().Membership(userID.(uint))
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to load organisation",
+			"details": err.Error(),
 		})
 		return
 	}
+	if req.ForOrganization {
+		if member == nil || member.Role != models.OrganizationRoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Only organisation admins can subscribe for the organisation",
+			})
+			return
+		}
+		organizationID = &member.OrganizationID
+
+		// Check if the organisation already has an active subscription
+		if _, err := services.OrganizationSubscription(member.OrganizationID); err == nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Organisation already has an active subscription",
+			})
+			return
+		}
+
+		// The plan must have a seat for every current member
+		usage, err := services.NewOrganizationService().Seats(member.OrganizationID)
+		if err == nil && int64(plan.MaxUsers) < usage.Members {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Plan has fewer seats than the organisation has members",
+			})
+			return
+		}
+	} else {
+		// Check if user already has an active subscription, their own or their organisation's
+		if _, source, err := services.ActiveSubscription(userID.(uint)); err == nil {
+			message := "User already has an active subscription"
+			if source == services.SubscriptionSourceOrganization {
+				message = "Your organisation's subscription already covers you"
+			}
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": message,
+			})
+			return
+		}
+	}
 
 	// Create new subscription
 	subscription := models.UserSubscription{
 		UserID:           userID.(uint),
+		OrganizationID:   organizationID,
 		PlanID:           req.PlanID,
 		Status:           "active",
 		StartDate:        time.Now(),
@@ -120,7 +189,7 @@ func CreateSubscription(c *gin.Context) {
 		NextBillingDate:  &time.Time{},
 	}
 
-	if err := config.DB.Create(&subscription).Error; err != nil {
+	if err := config.DB.WithContext(c).Create(&subscription).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to create subscription",
 			"details": err.Error(),
@@ -128,8 +197,16 @@ func CreateSubscription(c *gin.Context) {
 		return
 	}
 
+	if organizationID != nil {
+		audit.Record(c, "organization.subscribed", "organization", *organizationID, map[string]interface{}{
+			"subscription_id": subscription.ID,
+			"plan_id":         plan.ID,
+			"seats":           plan.MaxUsers,
+		})
+	}
+
 	// Load the plan details for response
-	if err := config.DB.Preload("Plan").First(&subscription, subscription.ID).Error; err != nil {
+	if err := config.DB.WithContext(c).Preload("Plan").First(&subscription, subscription.ID).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to load subscription details",
 			"details": err.Error(),
@@ -176,12 +253,8 @@ func UpdateSubscription(c *gin.Context) {
 	}
 
 	// Get user's subscription
-	var subscription models.UserSubscription
-	if err := config.DB.Where("id = ? AND user_id = ?", subscriptionID, userID).
-		First(&subscription).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Subscription not found",
-		})
+	subscription, ok := managedSubscription(c, userID.(uint), subscriptionID)
+	if !ok {
 		return
 	}
 
@@ -195,7 +268,7 @@ func UpdateSubscription(c *gin.Context) {
 	}
 
 	if len(updates) > 0 {
-		if err := config.DB.Model(&subscription).Updates(updates).Error; err != nil {
+		if err := config.DB.WithContext(c).Model(subscription).Updates(updates).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error":   "Failed to update subscription",
 				"details": err.Error(),
@@ -205,7 +278,7 @@ func UpdateSubscription(c *gin.Context) {
 	}
 
 	// Load updated subscription
-	if err := config.DB.Preload("Plan").First(&subscription, subscription.ID).Error; err != nil {
+	if err := config.DB.WithContext(c).Preload("Plan").First(subscription, subscription.ID).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to load updated subscription",
 			"details": err.Error(),
@@ -239,17 +312,13 @@ func CancelSubscription(c *gin.Context) {
 	}
 
 	// Get user's subscription
-	var subscription models.UserSubscription
-	if err := config.DB.Where("id = ? AND user_id = ?", subscriptionID, userID).
-		First(&subscription).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": "Subscription not found",
-		})
+	subscription, ok := managedSubscription(c, userID.(uint), subscriptionID)
+	if !ok {
 		return
 	}
 
 	// Cancel subscription
-	if err := config.DB.Model(&subscription).Updates(map[string]interface{}{
+	if err := config.DB.WithContext(c).Model(subscription).Updates(map[string]interface{}{
 		"status":     "cancelled",
 		"auto_renew": false,
 	}).Error; err != nil {
@@ -265,3 +334,23 @@ func CancelSubscription(c *gin.Context) {
 		"message": "Subscription cancelled successfully",
 	})
 }
+
+// managedSubscription loads a subscription the user may change: their own, or their
+// organisation's when they are one of its admins. It responds itself when there is none.
+func managedSubscription(c *gin.Context, userID uint, subscriptionID uint64) (*models.UserSubscription, bool) {
+	query := config.DB.WithContext(c).Where("id = ?", subscriptionID)
+	if member, err := services.NewOrganizationService().Membership(userID); err == nil && member.Role == models.OrganizationRoleAdmin {
+		query = query.Where("(user_id = ? AND organization_id IS NULL) OR organization_id = ?", userID, member.OrganizationID)
+	} else {
+		query = query.Where("user_id = ? AND organization_id IS NULL", userID)
+	}
+
+	var subscription models.UserSubscription
+	if err := query.First(&subscription).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Subscription not found",
+		})
+		return nil, false
+	}
+	return &subscription, true
+}
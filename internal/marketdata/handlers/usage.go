@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gcx-cms/internal/marketdata/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MeterUsage counts authenticated requests per endpoint and commodity. With a data type it also
+// enforces the user's daily UserDataAccess quota, rejecting requests over it with 429 and an
+// upgrade suggestion; an empty data type only meters.
+func MeterUsage(dataType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.Next()
+			return
+		}
+		uid, _ := userID.(uint)
+		meter := services.GetUsageMeter()
+
+		reserved := false
+		if dataType != "" {
+			status, ok, err := meter.Reserve(uid, dataType)
+			if err == nil && status.Limit > 0 {
+				c.Header("X-RateLimit-Limit", strconv.Itoa(status.Limit))
+				c.Header("X-RateLimit-Remaining", strconv.Itoa(status.Remaining))
+				c.Header("X-RateLimit-Reset", strconv.FormatInt(status.ResetsAt.Unix(), 10))
+
+				if !ok {
+					c.JSON(http.StatusTooManyRequests, gin.H{
+						"error":              fmt.Sprintf("Daily %s request limit of %d reached", dataType, status.Limit),
+						"usage":              status,
+						"upgrade_suggestion": services.SuggestUpgrade(uid, status),
+					})
+					c.Abort()
+					return
+				}
+			}
+			reserved = ok
+		}
+
+		c.Next()
+
+		// Failed requests are not charged against the quota
+		if c.Writer.Status() >= http.StatusBadRequest {
+			if reserved {
+				meter.Refund(uid, dataType)
+			}
+			return
+		}
+		commodity := c.Param("commodity")
+		if commodity == "" {
+			commodity = c.Query("commodity")
+		}
+		endpoint := c.Request.Method + " " + c.FullPath()
+		meter.Record(uid, dataType, endpoint, strings.ToLower(strings.TrimSpace(commodity)))
+	}
+}
+
+// GetUsage returns the user's market data usage by day, endpoint and commodity, and their
+// daily quotas with an upgrade suggestion for any being hit
+// GET /api/marketdata/usage?days=30
+func GetUsage(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+	uid, _ := userID.(uint)
+
+	days := usageDays(c)
+	meter := services.GetUsageMeter()
+	breakdown, err := meter.Breakdown(uid, time.Now().UTC().AddDate(0, 0, -days+1).Truncate(24*time.Hour))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch usage",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	quotas, err := meter.Quotas(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch quotas",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var suggestion *services.UpgradeSuggestion
+	for _, q := range quotas {
+		if q.Upgrade != nil {
+			suggestion = q.Upgrade
+			break
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":            true,
+		"days":               days,
+		"data":               breakdown,
+		"quotas":             quotas,
+		"upgrade_suggestion": suggestion,
+	})
+}
+
+// AdminGetUsageReport returns market data usage across all users, or one user with ?user_id=
+// GET /api/admin/marketdata/usage?days=30&user_id=
+func AdminGetUsageReport(c *gin.Context) {
+	var userID uint
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid user_id",
+			})
+			return
+		}
+		userID = uint(id)
+	}
+
+	days := usageDays(c)
+	breakdown, err := services.GetUsageMeter().Breakdown(userID, time.Now().UTC().AddDate(0, 0, -days+1).Truncate(24*time.Hour))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to build usage report",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"days":    days,
+		"user_id": userID,
+		"data":    breakdown,
+	})
+}
+
+// usageDays reads the ?days= window, 30 by default and at most a year
+func usageDays(c *gin.Context) int {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days <= 0 {
+		days = 30
+	}
+	if days > 366 {
+		days = 366
+	}
+	return days
+}
@@ -0,0 +1,359 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/marketdata/services"
+	"gcx-cms/internal/shared/config"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// watchlistItemRequest is a single commodity entry in a watchlist create/update request
+type watchlistItemRequest struct {
+	Commodity      string `json:"commodity" binding:"required"`
+	ContractTypeID *uint  `json:"contract_type_id"`
+}
+
+// GetWatchlists returns the current user's watchlists with their items
+func GetWatchlists(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	var watchlists []models.Watchlist
+	if err := config.DB.WithContext(c).Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order ASC, id ASC")
+	}).Where("user_id = ?", userID).
+		Order("is_default DESC, sort_order ASC, id ASC").
+		Find(&watchlists).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch watchlists",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    watchlists,
+		"count":   len(watchlists),
+	})
+}
+
+// CreateWatchlist creates a named watchlist for the current user
+func CreateWatchlist(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	var req struct {
+		Name        string                 `json:"name" binding:"required"`
+		Description string                 `json:"description"`
+		IsDefault   bool                   `json:"is_default"`
+		Items       []watchlistItemRequest `json:"items"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	watchlist := models.Watchlist{
+		UserID:      userID.(uint),
+		Name:        req.Name,
+		Description: req.Description,
+		IsDefault:   req.IsDefault,
+		Items:       buildWatchlistItems(req.Items),
+	}
+	if err := services.NewWatchlistService().ValidateItems(watchlist.Items); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid watchlist items",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	err := config.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if req.IsDefault {
+			if err := tx.Model(&models.Watchlist{}).Where("user_id = ?", userID).
+				Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(&watchlist).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create watchlist",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Watchlist created successfully",
+		"data":    watchlist,
+	})
+}
+
+// UpdateWatchlist renames a watchlist and optionally replaces its items
+func UpdateWatchlist(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	watchlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid watchlist ID",
+		})
+		return
+	}
+
+	var req struct {
+		Name        *string                `json:"name"`
+		Description *string                `json:"description"`
+		IsDefault   *bool                  `json:"is_default"`
+		SortOrder   *int                   `json:"sort_order"`
+		Items       []watchlistItemRequest `json:"items"` // Replaces all items when provided
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	var watchlist models.Watchlist
+	if err := config.DB.WithContext(c).Where("id = ? AND user_id = ?", watchlistID, userID).
+		First(&watchlist).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Watchlist not found",
+		})
+		return
+	}
+
+	items := buildWatchlistItems(req.Items)
+	if err := services.NewWatchlistService().ValidateItems(items); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid watchlist items",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.IsDefault != nil {
+		updates["is_default"] = *req.IsDefault
+	}
+	if req.SortOrder != nil {
+		updates["sort_order"] = *req.SortOrder
+	}
+
+	err = config.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if req.IsDefault != nil && *req.IsDefault {
+			if err := tx.Model(&models.Watchlist{}).Where("user_id = ? AND id <> ?", userID, watchlist.ID).
+				Update("is_default", false).Error; err != nil {
+				return err
+			}
+		}
+		if len(updates) > 0 {
+			if err := tx.Model(&watchlist).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if req.Items != nil {
+			if err := tx.Where("watchlist_id = ?", watchlist.ID).Delete(&models.WatchlistItem{}).Error; err != nil {
+				return err
+			}
+			for i := range items {
+				items[i].WatchlistID = watchlist.ID
+			}
+			if len(items) > 0 {
+				return tx.Create(&items).Error
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to update watchlist",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	updated, _ := services.NewWatchlistService().FindForUser(userID.(uint), watchlist.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Watchlist updated successfully",
+		"data":    updated,
+	})
+}
+
+// DeleteWatchlist deletes a watchlist and its items
+func DeleteWatchlist(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	var watchlist models.Watchlist
+	if err := config.DB.WithContext(c).Where("id = ? AND user_id = ?", c.Param("id"), userID).
+		First(&watchlist).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Watchlist not found",
+		})
+		return
+	}
+
+	err := config.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("watchlist_id = ?", watchlist.ID).Delete(&models.WatchlistItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&watchlist).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete watchlist",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Watchlist deleted successfully",
+	})
+}
+
+// GetWatchlistSummary returns latest price, change, sparkline and triggered alerts for a watchlist in one call
+func GetWatchlistSummary(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	watchlistID, _ := strconv.ParseUint(c.Query("id"), 10, 32)
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+
+	service := services.NewWatchlistService()
+	watchlist, err := service.FindForUser(userID.(uint), uint(watchlistID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Watchlist not found",
+		})
+		return
+	}
+
+	entries, err := service.Summary(watchlist, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to build watchlist summary",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"watchlist": watchlist,
+		"data":      entries,
+		"count":     len(entries),
+		"timestamp": time.Now(),
+	})
+}
+
+// watchlistScope resolves the optional ?watchlist_id= query parameter to what the watchlist
+// covers, or nil without one. It returns ok=false after writing an error response when the
+// watchlist cannot be used.
+func watchlistScope(c *gin.Context) (scope *services.WatchlistScope, ok bool) {
+	raw := c.Query("watchlist_id")
+	if raw == "" {
+		return nil, true
+	}
+
+	watchlistID, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid watchlist ID",
+		})
+		return nil, false
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return nil, false
+	}
+
+	scope, err = services.NewWatchlistService().Scope(userID.(uint), uint(watchlistID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Watchlist not found",
+		})
+		return nil, false
+	}
+
+	return scope, true
+}
+
+// buildWatchlistItems converts request items to models, dropping duplicates
+func buildWatchlistItems(reqItems []watchlistItemRequest) []models.WatchlistItem {
+	seen := make(map[string]bool)
+	items := make([]models.WatchlistItem, 0, len(reqItems))
+	for i, item := range reqItems {
+		key := item.Commodity
+		if item.ContractTypeID != nil {
+			key += ":" + strconv.FormatUint(uint64(*item.ContractTypeID), 10)
+		}
+		if item.Commodity == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		items = append(items, models.WatchlistItem{
+			Commodity:      item.Commodity,
+			ContractTypeID: item.ContractTypeID,
+			SortOrder:      i,
+		})
+	}
+	return items
+}
@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/marketdata/services"
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWebhookSubscriptions returns the current user's webhook subscriptions
+func GetWebhookSubscriptions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	var subscriptions []models.WebhookSubscription
+	if err := config.DB.WithContext(c).Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&subscriptions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch webhook subscriptions",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"data":        subscriptions,
+		"count":       len(subscriptions),
+		"event_types": models.WebhookEventTypes,
+	})
+}
+
+// CreateWebhookSubscription registers a new webhook endpoint for the current user
+func CreateWebhookSubscription(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return
+	}
+
+	var req struct {
+		URL         string   `json:"url" binding:"required"`
+		Description string   `json:"description"`
+		Events      []string `json:"events" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if msg := validateWebhookRequest(c, req.URL, req.Events); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": msg,
+		})
+		return
+	}
+
+	secret, err := token.NewOpaque(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create webhook subscription",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	subscription := models.WebhookSubscription{
+		UserID:      userID.(uint),
+		URL:         req.URL,
+		Description: req.Description,
+		Events:      strings.Join(req.Events, ","),
+		Secret:      secret,
+		IsActive:    true,
+	}
+
+	if err := config.DB.WithContext(c).Create(&subscription).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to create webhook subscription",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// The signing secret is only ever returned at creation time
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Webhook subscription created successfully",
+		"data":    subscription,
+		"secret":  subscription.Secret,
+	})
+}
+
+// UpdateWebhookSubscription updates a webhook subscription's URL, events or active flag
+func UpdateWebhookSubscription(c *gin.Context) {
+	subscription, ok := findUserWebhook(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		URL         *string  `json:"url"`
+		Description *string  `json:"description"`
+		Events      []string `json:"events"`
+		IsActive    *bool    `json:"is_active"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request data",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	updates := make(map[string]interface{})
+	newURL := subscription.URL
+	if req.URL != nil {
+		newURL = *req.URL
+		updates["url"] = *req.URL
+	}
+	events := subscription.EventList()
+	if req.Events != nil {
+		events = req.Events
+		updates["events"] = strings.Join(req.Events, ",")
+	}
+	if msg := validateWebhookRequest(c, newURL, events); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": msg,
+		})
+		return
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+
+	if len(updates) > 0 {
+		if err := config.DB.WithContext(c).Model(subscription).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to update webhook subscription",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Webhook subscription updated successfully",
+		"data":    subscription,
+	})
+}
+
+// DeleteWebhookSubscription removes a webhook subscription
+func DeleteWebhookSubscription(c *gin.Context) {
+	subscription, ok := findUserWebhook(c)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.WithContext(c).Delete(subscription).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to delete webhook subscription",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Webhook subscription deleted successfully",
+	})
+}
+
+// GetWebhookDeliveries returns the delivery log for a subscription
+func GetWebhookDeliveries(c *gin.Context) {
+	subscription, ok := findUserWebhook(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	query := config.DB.WithContext(c).Model(&models.WebhookDelivery{}).Where("subscription_id = ?", subscription.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if eventType := c.Query("event_type"); eventType != "" {
+		query = query.Where("event_type = ?", eventType)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to fetch webhook deliveries",
+			"details": err.Error(),
+		})
+		return
+	}
+	// Deliveries recorded before bodies were cut down to a snippet
+	for i := range deliveries {
+		deliveries[i].ResponseBody = services.WebhookResponseSnippet([]byte(deliveries[i].ResponseBody))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    deliveries,
+		"pagination": gin.H{
+			"page":       page,
+			"limit":      limit,
+			"total":      total,
+			"totalPages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// RedeliverWebhook queues a manual redelivery of a previous event
+func RedeliverWebhook(c *gin.Context) {
+	subscription, ok := findUserWebhook(c)
+	if !ok {
+		return
+	}
+
+	var original models.WebhookDelivery
+	if err := config.DB.WithContext(c).Where("id = ? AND subscription_id = ?", c.Param("deliveryId"), subscription.ID).
+		First(&original).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Webhook delivery not found",
+		})
+		return
+	}
+
+	delivery, err := services.GetWebhookService().Redeliver(&original)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to queue redelivery",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "Redelivery queued",
+		"data":    delivery,
+	})
+}
+
+// findUserWebhook loads the :id subscription owned by the current user, writing an error response if missing
+func findUserWebhook(c *gin.Context) (*models.WebhookSubscription, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User not authenticated",
+		})
+		return nil, false
+	}
+
+	var subscription models.WebhookSubscription
+	if err := config.DB.WithContext(c).Where("id = ? AND user_id = ?", c.Param("id"), userID).
+		First(&subscription).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Webhook subscription not found",
+		})
+		return nil, false
+	}
+
+	return &subscription, true
+}
+
+// validateWebhookRequest returns an error message if the URL or event list is unacceptable
+func validateWebhookRequest(c *gin.Context, rawURL string, events []string) string {
+	if err := services.ValidateWebhookURL(c.Request.Context(), rawURL); err != nil {
+		return err.Error()
+	}
+	if len(events) == 0 {
+		return "At least one event type is required"
+	}
+	for _, e := range events {
+		if !models.IsValidWebhookEvent(e) {
+			return "Unsupported event type: " + e
+		}
+	}
+	return ""
+}
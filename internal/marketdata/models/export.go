@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Export formats
+const (
+	ExportFormatCSV     = "csv"
+	ExportFormatXLSX    = "xlsx"
+	ExportFormatParquet = "parquet"
+)
+
+// Export job states
+const (
+	ExportStatusQueued    = "queued"
+	ExportStatusRunning   = "running"
+	ExportStatusCompleted = "completed"
+	ExportStatusFailed    = "failed"
+	ExportStatusExpired   = "expired"
+)
+
+// ExportJob is a historical data export too large to stream in the request, built in the
+// background and downloaded from a link until it expires
+type ExportJob struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	Format      string     `json:"format" gorm:"size:20;not null"`
+	Commodities string     `json:"commodities" gorm:"type:text"` // Comma-separated, empty for all
+	Columns     string     `json:"columns" gorm:"type:text"`     // Comma-separated, in output order
+	Series      string     `json:"series" gorm:"size:50"`
+	StartDate   time.Time  `json:"start_date"`
+	EndDate     time.Time  `json:"end_date"`
+	Status      string     `json:"status" gorm:"size:20;not null;default:queued;index"`
+	RowCount    int64      `json:"row_count"`
+	FilePath    string     `json:"-" gorm:"size:500"`
+	FileSize    int64      `json:"file_size"`
+	Error       string     `json:"error,omitempty" gorm:"type:text"`
+	StartedAt   *time.Time `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName returns the table name for ExportJob model
+func (ExportJob) TableName() string {
+	return "export_jobs"
+}
+
+// IsValidExportFormat checks if an export format is supported
+func IsValidExportFormat(format string) bool {
+	switch format {
+	case ExportFormatCSV, ExportFormatXLSX, ExportFormatParquet:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Forecast model identifiers
+const (
+	ForecastModelAuto        = "auto"
+	ForecastModelHoltWinters = "holt_winters"
+	ForecastModelDrift       = "drift"
+	ForecastModelNaive       = "naive"
+)
+
+// ForecastRun is a stored week-ahead forecast for a commodity together with the backtest
+// that justified its model choice
+type ForecastRun struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	Commodity    string         `json:"commodity" gorm:"size:100;not null;index"`
+	Model        string         `json:"model" gorm:"size:50;not null"`     // Model used for the forecast
+	Requested    string         `json:"requested" gorm:"size:50;not null"` // Model asked for, may be auto
+	Horizon      int            `json:"horizon"`                           // Weeks ahead
+	SeasonLength int            `json:"season_length"`                     // 0 when too little history for a seasonal fit
+	Params       datatypes.JSON `json:"params" gorm:"type:json"`           // Fitted smoothing parameters
+	Observations int            `json:"observations"`                      // Weekly observations used
+	TrainedFrom  time.Time      `json:"trained_from"`
+	TrainedTo    time.Time      `json:"trained_to"`
+	BacktestMAPE *float64       `json:"backtest_mape"`             // Rolling-origin MAPE of the chosen model
+	Backtest     datatypes.JSON `json:"backtest" gorm:"type:json"` // Per-model rolling-origin results
+	CreatedBy    *uint          `json:"created_by"`
+	CreatedAt    time.Time      `json:"created_at"`
+
+	// Relationships
+	Points []ForecastPoint `json:"points,omitempty" gorm:"foreignKey:RunID"`
+}
+
+// ForecastPoint is one week-ahead forecast with its prediction intervals. Actual is filled
+// in once the target week has traded.
+type ForecastPoint struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	RunID      uint      `json:"run_id" gorm:"not null;index"`
+	Step       int       `json:"step"`        // Weeks ahead, 1-based
+	TargetWeek time.Time `json:"target_week"` // Monday of the forecast week
+	Forecast   float64   `json:"forecast"`
+	Lower80    float64   `json:"lower_80"`
+	Upper80    float64   `json:"upper_80"`
+	Lower95    float64   `json:"lower_95"`
+	Upper95    float64   `json:"upper_95"`
+	Actual     *float64  `json:"actual"`
+	ErrorPct   *float64  `json:"error_pct"` // (forecast - actual) / actual x100
+}
+
+// TableName returns the table name for ForecastRun model
+func (ForecastRun) TableName() string {
+	return "forecast_runs"
+}
+
+// TableName returns the table name for ForecastPoint model
+func (ForecastPoint) TableName() string {
+	return "forecast_points"
+}
+
+// IsValidForecastModel checks if a requested model is supported
+func IsValidForecastModel(model string) bool {
+	switch model {
+	case ForecastModelAuto, ForecastModelHoltWinters, ForecastModelDrift, ForecastModelNaive:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// CommodityIndex represents a GCX index such as the composite grains index
+type CommodityIndex struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Code        string    `json:"code" gorm:"type:varchar(50);uniqueIndex;not null"` // GCX-GRAINS
+	Name        string    `json:"name" gorm:"not null"`
+	Description string    `json:"description" gorm:"type:text"`
+	Currency    string    `json:"currency" gorm:"default:GHS"`
+	IsActive    bool      `json:"is_active" gorm:"default:true"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relationships
+	Versions []IndexDefinitionVersion `json:"versions,omitempty" gorm:"foreignKey:IndexID"`
+}
+
+// IndexDefinitionVersion is an immutable basket definition. A new version takes effect from
+// EffectiveFrom and is chain-linked to the index level on that date.
+type IndexDefinitionVersion struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	IndexID        uint      `json:"index_id" gorm:"not null;index"`
+	Version        int       `json:"version" gorm:"not null"`
+	BaseDate       time.Time `json:"base_date" gorm:"not null"` // Only used by version 1
+	BaseValue      float64   `json:"base_value" gorm:"not null;default:1000"`
+	EffectiveFrom  time.Time `json:"effective_from" gorm:"not null;index"`
+	RebalanceDates string    `json:"rebalance_dates" gorm:"type:text"` // Comma-separated YYYY-MM-DD dates
+	Notes          string    `json:"notes" gorm:"type:text"`
+	CreatedBy      *uint     `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	// Relationships
+	Constituents []IndexConstituent `json:"constituents" gorm:"foreignKey:VersionID"`
+}
+
+// IndexConstituent is a commodity and its target weight within an index version
+type IndexConstituent struct {
+	ID        uint    `json:"id" gorm:"primaryKey"`
+	VersionID uint    `json:"version_id" gorm:"not null;index"`
+	Commodity string  `json:"commodity" gorm:"size:100;not null"` // Matches market_data.commodity
+	Weight    float64 `json:"weight" gorm:"not null"`
+}
+
+// IndexValue is a computed index level. Daily closes are kept for history; the intraday row
+// for a date is overwritten as the streaming feed moves.
+type IndexValue struct {
+	ID         uint           `json:"id" gorm:"primaryKey"`
+	IndexID    uint           `json:"index_id" gorm:"not null;uniqueIndex:idx_index_value_date"`
+	VersionID  uint           `json:"version_id"`
+	ValueDate  time.Time      `json:"value_date" gorm:"not null;uniqueIndex:idx_index_value_date"`
+	IsIntraday bool           `json:"is_intraday" gorm:"not null;default:false;uniqueIndex:idx_index_value_date"`
+	Value      float64        `json:"value" gorm:"not null"`
+	Change     float64        `json:"change"`
+	ChangePct  float64        `json:"change_percent"`
+	Components datatypes.JSON `json:"components" gorm:"type:json"` // Per-constituent prices and contributions
+	ComputedAt time.Time      `json:"computed_at"`
+}
+
+// TableName returns the table name for CommodityIndex model
+func (CommodityIndex) TableName() string {
+	return "commodity_indices"
+}
+
+// TableName returns the table name for IndexDefinitionVersion model
+func (IndexDefinitionVersion) TableName() string {
+	return "index_definition_versions"
+}
+
+// TableName returns the table name for IndexConstituent model
+func (IndexConstituent) TableName() string {
+	return "index_constituents"
+}
+
+// TableName returns the table name for IndexValue model
+func (IndexValue) TableName() string {
+	return "index_values"
+}
+
+// RebalanceDateList parses the rebalance dates in ascending order, ignoring malformed entries
+func (v *IndexDefinitionVersion) RebalanceDateList() []time.Time {
+	var dates []time.Time
+	for _, raw := range strings.Split(v.RebalanceDates, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		if d, err := time.Parse("2006-01-02", raw); err == nil {
+			dates = append(dates, d)
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	return dates
+}
+
+// TotalWeight returns the sum of constituent weights
+func (v *IndexDefinitionVersion) TotalWeight() float64 {
+	total := 0.0
+	for _, c := range v.Constituents {
+		total += c.Weight
+	}
+	return total
+}
@@ -21,6 +21,7 @@ type MarketData struct {
 	Close       *float64       `json:"close"` // Day's closing price
 	MarketDate  time.Time      `json:"market_date"`
 	Source      string         `json:"source"` // GCX, external API, etc.
+	ContractSeries *string     `json:"contract_series" gorm:"type:varchar(50);index"` // Listed series symbol, e.g. MAIZE-MAR26
 	Metadata    datatypes.JSON `json:"metadata" gorm:"type:json"` // Additional data
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
@@ -64,6 +65,7 @@ type PriceAlert struct {
 	Condition  string    `json:"condition"` // above, below
 	IsActive   bool      `json:"is_active" gorm:"default:true"`
 	TriggeredAt *time.Time `json:"triggered_at"`
+	TriggeredSeries *string `json:"triggered_series" gorm:"type:varchar(50)"` // Contract series of the price that triggered it, if any
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 }
@@ -0,0 +1,80 @@
+package models
+
+import (
+	shared_models "gcx-cms/internal/shared/models"
+	"time"
+)
+
+// Roles of a user within an organisation
+const (
+	OrganizationRoleAdmin  = "admin"  // Invites and removes members and manages the subscription
+	OrganizationRoleMember = "member" // Uses the organisation's subscription
+)
+
+// Organization is a company whose members share its subscriptions. Each paid seat of the
+// subscription's plan is one member or pending invite.
+type Organization struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Name        string    `json:"name" gorm:"not null"`
+	Slug        string    `json:"slug" gorm:"type:varchar(191);uniqueIndex;not null"`
+	CreatedByID uint      `json:"created_by_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relationships
+	Members []OrganizationMember `json:"members,omitempty" gorm:"foreignKey:OrganizationID"`
+}
+
+// OrganizationMember places a user in an organisation. A user belongs to at most one.
+type OrganizationMember struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"not null;index"`
+	UserID         uint      `json:"user_id" gorm:"not null;uniqueIndex"`
+	Role           string    `json:"role" gorm:"type:varchar(20);not null;default:member"`
+	InvitedByID    *uint     `json:"invited_by_id"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	// Relationships
+	User shared_models.User `json:"user" gorm:"foreignKey:UserID"`
+}
+
+// OrganizationInvite asks someone to join an organisation. The emailed token is accepted by
+// the invitee signed in with the invited email address.
+type OrganizationInvite struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	OrganizationID uint       `json:"organization_id" gorm:"not null;index"`
+	Email          string     `json:"email" gorm:"type:varchar(191);not null;index"`
+	Role           string     `json:"role" gorm:"type:varchar(20);not null;default:member"`
+	TokenHash      string     `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	InvitedByID    uint       `json:"invited_by_id"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// TableName returns the table name for Organization model
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// TableName returns the table name for OrganizationMember model
+func (OrganizationMember) TableName() string {
+	return "organization_members"
+}
+
+// TableName returns the table name for OrganizationInvite model
+func (OrganizationInvite) TableName() string {
+	return "organization_invites"
+}
+
+// IsValidOrganizationRole checks if a role can be given within an organisation
+func IsValidOrganizationRole(role string) bool {
+	return role == OrganizationRoleAdmin || role == OrganizationRoleMember
+}
+
+// IsPending reports whether the invite can still be accepted
+func (i *OrganizationInvite) IsPending() bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && time.Now().Before(i.ExpiresAt)
+}
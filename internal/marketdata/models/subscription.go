@@ -21,10 +21,13 @@ type SubscriptionPlan struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
-// UserSubscription represents user's active subscription
+// UserSubscription represents user's active subscription. A subscription with an
+// OrganizationID belongs to the organisation and covers its members; UserID is then the
+// organisation admin who bought it.
 type UserSubscription struct {
 	ID               uint       `json:"id" gorm:"primaryKey"`
 	UserID           uint       `json:"user_id" gorm:"not null"`
+	OrganizationID   *uint      `json:"organization_id" gorm:"index"`
 	PlanID           uint       `json:"plan_id" gorm:"not null"`
 	Status           string     `json:"status" gorm:"default:active"` // active, expired, cancelled, suspended
 	StartDate        time.Time  `json:"start_date" gorm:"not null"`
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Data types metered against UserDataAccess quotas
+const (
+	DataTypeRealTime   = "real_time"
+	DataTypeHistorical = "historical"
+	DataTypeAnalytics  = "analytics"
+	DataTypeAlerts     = "alerts"
+)
+
+// UsageRecord counts a user's requests to one endpoint for one commodity on one day
+type UsageRecord struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_usage_record"`
+	Day       time.Time `json:"day" gorm:"type:date;not null;uniqueIndex:idx_usage_record;index"`
+	Endpoint  string    `json:"endpoint" gorm:"type:varchar(191);not null;uniqueIndex:idx_usage_record"` // Method and route, e.g. "GET /api/marketdata/realtime"
+	Commodity string    `json:"commodity" gorm:"type:varchar(100);not null;default:'';uniqueIndex:idx_usage_record"`
+	DataType  string    `json:"data_type" gorm:"size:50"`
+	Count     int64     `json:"count" gorm:"not null;default:0"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for UsageRecord model
+func (UsageRecord) TableName() string {
+	return "usage_records"
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Watchlist represents a user's named list of commodities to follow
+type Watchlist struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"not null;index"`
+	Name        string    `json:"name" gorm:"size:100;not null"`
+	Description string    `json:"description" gorm:"size:255"`
+	IsDefault   bool      `json:"is_default" gorm:"default:false"`
+	SortOrder   int       `json:"sort_order" gorm:"default:0"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relationships
+	Items []WatchlistItem `json:"items" gorm:"foreignKey:WatchlistID;constraint:OnDelete:CASCADE"`
+}
+
+// WatchlistItem represents a commodity (optionally narrowed to a contract type) on a watchlist
+type WatchlistItem struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	WatchlistID    uint      `json:"watchlist_id" gorm:"not null;index"`
+	Commodity      string    `json:"commodity" gorm:"size:100;not null"` // Matches market_data.commodity
+	ContractTypeID *uint     `json:"contract_type_id"`                   // Optional commodity_contract_types.id
+	SortOrder      int       `json:"sort_order" gorm:"default:0"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName returns the table name for Watchlist model
+func (Watchlist) TableName() string {
+	return "watchlists"
+}
+
+// TableName returns the table name for WatchlistItem model
+func (WatchlistItem) TableName() string {
+	return "watchlist_items"
+}
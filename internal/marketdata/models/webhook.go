@@ -0,0 +1,111 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Webhook event types that subscribers can register for
+const (
+	WebhookEventPriceCreated   = "price.created"
+	WebhookEventPriceCorrected = "price.corrected"
+	WebhookEventMarketHalted   = "market.halted"
+	WebhookEventSessionOpened  = "session.opened"
+)
+
+// WebhookEventTypes lists every event type a subscription may register for
+var WebhookEventTypes = []string{
+	WebhookEventPriceCreated,
+	WebhookEventPriceCorrected,
+	WebhookEventMarketHalted,
+	WebhookEventSessionOpened,
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending    = "pending"
+	WebhookDeliveryRetrying   = "retrying"
+	WebhookDeliveryDelivered  = "delivered"
+	WebhookDeliveryDeadLetter = "dead_letter"
+)
+
+// WebhookSubscription represents a partner endpoint registered for push notifications
+type WebhookSubscription struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	URL         string     `json:"url" gorm:"size:500;not null"`
+	Description string     `json:"description" gorm:"size:255"`
+	Events      string     `json:"events" gorm:"type:text;not null"` // Comma-separated event types
+	Secret      string     `json:"-" gorm:"size:128;not null"`       // HMAC signing secret
+	IsActive    bool       `json:"is_active" gorm:"default:true"`
+	LastSuccess *time.Time `json:"last_success"`
+	LastFailure *time.Time `json:"last_failure"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// WebhookDelivery represents a single event delivery to a subscription, including its retry state
+type WebhookDelivery struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	SubscriptionID uint           `json:"subscription_id" gorm:"not null;index"`
+	EventID        string         `json:"event_id" gorm:"type:varchar(64);index;not null"` // Stable across redeliveries
+	EventType      string         `json:"event_type" gorm:"size:50;not null"`
+	Payload        datatypes.JSON `json:"payload" gorm:"type:json"`
+	Status         string         `json:"status" gorm:"size:20;index;default:pending"` // pending, retrying, delivered, dead_letter
+	Attempts       int            `json:"attempts" gorm:"default:0"`
+	NextAttemptAt  *time.Time     `json:"next_attempt_at" gorm:"index"`
+	LastAttemptAt  *time.Time     `json:"last_attempt_at"`
+	ResponseStatus int            `json:"response_status"`
+	ResponseBody   string         `json:"response_body" gorm:"type:text"` // Short printable snippet of the response, never the full body
+	LastError      string         `json:"last_error" gorm:"type:text"`
+	DeliveredAt    *time.Time     `json:"delivered_at"`
+	RedeliveryOf   *uint          `json:"redelivery_of"` // Original delivery when manually redelivered
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// TableName returns the table name for WebhookSubscription model
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// TableName returns the table name for WebhookDelivery model
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// EventList returns the subscribed event types as a slice
+func (ws *WebhookSubscription) EventList() []string {
+	var events []string
+	for _, e := range strings.Split(ws.Events, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// Subscribes checks if the subscription wants the given event type
+func (ws *WebhookSubscription) Subscribes(eventType string) bool {
+	for _, e := range ws.EventList() {
+		if e == eventType || e == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidWebhookEvent checks if an event type is supported
+func IsValidWebhookEvent(eventType string) bool {
+	if eventType == "*" {
+		return true
+	}
+	for _, e := range WebhookEventTypes {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
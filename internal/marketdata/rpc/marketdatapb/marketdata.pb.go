@@ -0,0 +1,1037 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: marketdata/v1/marketdata.proto
+
+package marketdatapb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CandleInterval int32
+
+const (
+	CandleInterval_CANDLE_INTERVAL_UNSPECIFIED CandleInterval = 0 // Treated as DAY
+	CandleInterval_CANDLE_INTERVAL_HOUR        CandleInterval = 1
+	CandleInterval_CANDLE_INTERVAL_DAY         CandleInterval = 2
+	CandleInterval_CANDLE_INTERVAL_WEEK        CandleInterval = 3 // Weeks start on Monday
+	CandleInterval_CANDLE_INTERVAL_MONTH       CandleInterval = 4
+)
+
+// Enum value maps for CandleInterval.
+var (
+	CandleInterval_name = map[int32]string{
+		0: "CANDLE_INTERVAL_UNSPECIFIED",
+		1: "CANDLE_INTERVAL_HOUR",
+		2: "CANDLE_INTERVAL_DAY",
+		3: "CANDLE_INTERVAL_WEEK",
+		4: "CANDLE_INTERVAL_MONTH",
+	}
+	CandleInterval_value = map[string]int32{
+		"CANDLE_INTERVAL_UNSPECIFIED": 0,
+		"CANDLE_INTERVAL_HOUR":        1,
+		"CANDLE_INTERVAL_DAY":         2,
+		"CANDLE_INTERVAL_WEEK":        3,
+		"CANDLE_INTERVAL_MONTH":       4,
+	}
+)
+
+func (x CandleInterval) Enum() *CandleInterval {
+	p := new(CandleInterval)
+	*p = x
+	return p
+}
+
+func (x CandleInterval) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CandleInterval) Descriptor() protoreflect.EnumDescriptor {
+	return file_marketdata_v1_marketdata_proto_enumTypes[0].Descriptor()
+}
+
+func (CandleInterval) Type() protoreflect.EnumType {
+	return &file_marketdata_v1_marketdata_proto_enumTypes[0]
+}
+
+func (x CandleInterval) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CandleInterval.Descriptor instead.
+func (CandleInterval) EnumDescriptor() ([]byte, []int) {
+	return file_marketdata_v1_marketdata_proto_rawDescGZIP(), []int{0}
+}
+
+type ReplayMode int32
+
+const (
+	ReplayMode_REPLAY_MODE_EXCLUDE ReplayMode = 0 // Live prices only
+	ReplayMode_REPLAY_MODE_INCLUDE ReplayMode = 1 // Live prices and admin replay sessions
+	ReplayMode_REPLAY_MODE_ONLY    ReplayMode = 2 // Replay sessions only
+)
+
+// Enum value maps for ReplayMode.
+var (
+	ReplayMode_name = map[int32]string{
+		0: "REPLAY_MODE_EXCLUDE",
+		1: "REPLAY_MODE_INCLUDE",
+		2: "REPLAY_MODE_ONLY",
+	}
+	ReplayMode_value = map[string]int32{
+		"REPLAY_MODE_EXCLUDE": 0,
+		"REPLAY_MODE_INCLUDE": 1,
+		"REPLAY_MODE_ONLY":    2,
+	}
+)
+
+func (x ReplayMode) Enum() *ReplayMode {
+	p := new(ReplayMode)
+	*p = x
+	return p
+}
+
+func (x ReplayMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReplayMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_marketdata_v1_marketdata_proto_enumTypes[1].Descriptor()
+}
+
+func (ReplayMode) Type() protoreflect.EnumType {
+	return &file_marketdata_v1_marketdata_proto_enumTypes[1]
+}
+
+func (x ReplayMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReplayMode.Descriptor instead.
+func (ReplayMode) EnumDescriptor() ([]byte, []int) {
+	return file_marketdata_v1_marketdata_proto_rawDescGZIP(), []int{1}
+}
+
+type Price struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Commodity      string                 `protobuf:"bytes,2,opt,name=commodity,proto3" json:"commodity,omitempty"`
+	ContractSeries *string                `protobuf:"bytes,3,opt,name=contract_series,json=contractSeries,proto3,oneof" json:"contract_series,omitempty"`
+	Price          float64                `protobuf:"fixed64,4,opt,name=price,proto3" json:"price,omitempty"`
+	Currency       string                 `protobuf:"bytes,5,opt,name=currency,proto3" json:"currency,omitempty"`
+	Unit           string                 `protobuf:"bytes,6,opt,name=unit,proto3" json:"unit,omitempty"`
+	Change         float64                `protobuf:"fixed64,7,opt,name=change,proto3" json:"change,omitempty"`
+	ChangePercent  float64                `protobuf:"fixed64,8,opt,name=change_percent,json=changePercent,proto3" json:"change_percent,omitempty"`
+	Open           *float64               `protobuf:"fixed64,9,opt,name=open,proto3,oneof" json:"open,omitempty"`
+	High           *float64               `protobuf:"fixed64,10,opt,name=high,proto3,oneof" json:"high,omitempty"`
+	Low            *float64               `protobuf:"fixed64,11,opt,name=low,proto3,oneof" json:"low,omitempty"`
+	Close          *float64               `protobuf:"fixed64,12,opt,name=close,proto3,oneof" json:"close,omitempty"`
+	Volume         *float64               `protobuf:"fixed64,13,opt,name=volume,proto3,oneof" json:"volume,omitempty"`
+	MarketDate     *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=market_date,json=marketDate,proto3" json:"market_date,omitempty"`
+	Source         string                 `protobuf:"bytes,15,opt,name=source,proto3" json:"source,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Price) Reset() {
+	*x = Price{}
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Price) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Price) ProtoMessage() {}
+
+func (x *Price) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Price.ProtoReflect.Descriptor instead.
+func (*Price) Descriptor() ([]byte, []int) {
+	return file_marketdata_v1_marketdata_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Price) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Price) GetCommodity() string {
+	if x != nil {
+		return x.Commodity
+	}
+	return ""
+}
+
+func (x *Price) GetContractSeries() string {
+	if x != nil && x.ContractSeries != nil {
+		return *x.ContractSeries
+	}
+	return ""
+}
+
+func (x *Price) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Price) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Price) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *Price) GetChange() float64 {
+	if x != nil {
+		return x.Change
+	}
+	return 0
+}
+
+func (x *Price) GetChangePercent() float64 {
+	if x != nil {
+		return x.ChangePercent
+	}
+	return 0
+}
+
+func (x *Price) GetOpen() float64 {
+	if x != nil && x.Open != nil {
+		return *x.Open
+	}
+	return 0
+}
+
+func (x *Price) GetHigh() float64 {
+	if x != nil && x.High != nil {
+		return *x.High
+	}
+	return 0
+}
+
+func (x *Price) GetLow() float64 {
+	if x != nil && x.Low != nil {
+		return *x.Low
+	}
+	return 0
+}
+
+func (x *Price) GetClose() float64 {
+	if x != nil && x.Close != nil {
+		return *x.Close
+	}
+	return 0
+}
+
+func (x *Price) GetVolume() float64 {
+	if x != nil && x.Volume != nil {
+		return *x.Volume
+	}
+	return 0
+}
+
+func (x *Price) GetMarketDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.MarketDate
+	}
+	return nil
+}
+
+func (x *Price) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+type GetCurrentPricesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Restrict to these commodities; empty returns every commodity
+	Commodities   []string `protobuf:"bytes,1,rep,name=commodities,proto3" json:"commodities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCurrentPricesRequest) Reset() {
+	*x = GetCurrentPricesRequest{}
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCurrentPricesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCurrentPricesRequest) ProtoMessage() {}
+
+func (x *GetCurrentPricesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCurrentPricesRequest.ProtoReflect.Descriptor instead.
+func (*GetCurrentPricesRequest) Descriptor() ([]byte, []int) {
+	return file_marketdata_v1_marketdata_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetCurrentPricesRequest) GetCommodities() []string {
+	if x != nil {
+		return x.Commodities
+	}
+	return nil
+}
+
+type GetCurrentPricesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prices        []*Price               `protobuf:"bytes,1,rep,name=prices,proto3" json:"prices,omitempty"`
+	AsOf          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCurrentPricesResponse) Reset() {
+	*x = GetCurrentPricesResponse{}
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCurrentPricesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCurrentPricesResponse) ProtoMessage() {}
+
+func (x *GetCurrentPricesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCurrentPricesResponse.ProtoReflect.Descriptor instead.
+func (*GetCurrentPricesResponse) Descriptor() ([]byte, []int) {
+	return file_marketdata_v1_marketdata_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetCurrentPricesResponse) GetPrices() []*Price {
+	if x != nil {
+		return x.Prices
+	}
+	return nil
+}
+
+func (x *GetCurrentPricesResponse) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
+type GetHistoryRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Commodity string                 `protobuf:"bytes,1,opt,name=commodity,proto3" json:"commodity,omitempty"`
+	// YYYY-MM-DD, defaults to 30 days ago
+	StartDate string `protobuf:"bytes,2,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	// YYYY-MM-DD inclusive, defaults to today
+	EndDate string `protobuf:"bytes,3,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	// Listed contract series, e.g. MAIZE-MAR26
+	Series        string `protobuf:"bytes,4,opt,name=series,proto3" json:"series,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoryRequest) Reset() {
+	*x = GetHistoryRequest{}
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryRequest) ProtoMessage() {}
+
+func (x *GetHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_marketdata_v1_marketdata_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetHistoryRequest) GetCommodity() string {
+	if x != nil {
+		return x.Commodity
+	}
+	return ""
+}
+
+func (x *GetHistoryRequest) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *GetHistoryRequest) GetEndDate() string {
+	if x != nil {
+		return x.EndDate
+	}
+	return ""
+}
+
+func (x *GetHistoryRequest) GetSeries() string {
+	if x != nil {
+		return x.Series
+	}
+	return ""
+}
+
+type GetHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Commodity     string                 `protobuf:"bytes,1,opt,name=commodity,proto3" json:"commodity,omitempty"`
+	Prices        []*Price               `protobuf:"bytes,2,rep,name=prices,proto3" json:"prices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoryResponse) Reset() {
+	*x = GetHistoryResponse{}
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryResponse) ProtoMessage() {}
+
+func (x *GetHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_marketdata_v1_marketdata_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetHistoryResponse) GetCommodity() string {
+	if x != nil {
+		return x.Commodity
+	}
+	return ""
+}
+
+func (x *GetHistoryResponse) GetPrices() []*Price {
+	if x != nil {
+		return x.Prices
+	}
+	return nil
+}
+
+type GetCandlesRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Commodity string                 `protobuf:"bytes,1,opt,name=commodity,proto3" json:"commodity,omitempty"`
+	Interval  CandleInterval         `protobuf:"varint,2,opt,name=interval,proto3,enum=gcx.marketdata.v1.CandleInterval" json:"interval,omitempty"`
+	// YYYY-MM-DD, defaults to 90 days ago
+	StartDate string `protobuf:"bytes,3,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	// YYYY-MM-DD inclusive, defaults to today
+	EndDate       string `protobuf:"bytes,4,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	Series        string `protobuf:"bytes,5,opt,name=series,proto3" json:"series,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCandlesRequest) Reset() {
+	*x = GetCandlesRequest{}
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCandlesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCandlesRequest) ProtoMessage() {}
+
+func (x *GetCandlesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCandlesRequest.ProtoReflect.Descriptor instead.
+func (*GetCandlesRequest) Descriptor() ([]byte, []int) {
+	return file_marketdata_v1_marketdata_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetCandlesRequest) GetCommodity() string {
+	if x != nil {
+		return x.Commodity
+	}
+	return ""
+}
+
+func (x *GetCandlesRequest) GetInterval() CandleInterval {
+	if x != nil {
+		return x.Interval
+	}
+	return CandleInterval_CANDLE_INTERVAL_UNSPECIFIED
+}
+
+func (x *GetCandlesRequest) GetStartDate() string {
+	if x != nil {
+		return x.StartDate
+	}
+	return ""
+}
+
+func (x *GetCandlesRequest) GetEndDate() string {
+	if x != nil {
+		return x.EndDate
+	}
+	return ""
+}
+
+func (x *GetCandlesRequest) GetSeries() string {
+	if x != nil {
+		return x.Series
+	}
+	return ""
+}
+
+type Candle struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Start  *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	Open   float64                `protobuf:"fixed64,2,opt,name=open,proto3" json:"open,omitempty"`
+	High   float64                `protobuf:"fixed64,3,opt,name=high,proto3" json:"high,omitempty"`
+	Low    float64                `protobuf:"fixed64,4,opt,name=low,proto3" json:"low,omitempty"`
+	Close  float64                `protobuf:"fixed64,5,opt,name=close,proto3" json:"close,omitempty"`
+	Volume float64                `protobuf:"fixed64,6,opt,name=volume,proto3" json:"volume,omitempty"`
+	// Number of price records in the candle
+	Count         int32 `protobuf:"varint,7,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Candle) Reset() {
+	*x = Candle{}
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Candle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Candle) ProtoMessage() {}
+
+func (x *Candle) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Candle.ProtoReflect.Descriptor instead.
+func (*Candle) Descriptor() ([]byte, []int) {
+	return file_marketdata_v1_marketdata_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Candle) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *Candle) GetOpen() float64 {
+	if x != nil {
+		return x.Open
+	}
+	return 0
+}
+
+func (x *Candle) GetHigh() float64 {
+	if x != nil {
+		return x.High
+	}
+	return 0
+}
+
+func (x *Candle) GetLow() float64 {
+	if x != nil {
+		return x.Low
+	}
+	return 0
+}
+
+func (x *Candle) GetClose() float64 {
+	if x != nil {
+		return x.Close
+	}
+	return 0
+}
+
+func (x *Candle) GetVolume() float64 {
+	if x != nil {
+		return x.Volume
+	}
+	return 0
+}
+
+func (x *Candle) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type GetCandlesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Commodity     string                 `protobuf:"bytes,1,opt,name=commodity,proto3" json:"commodity,omitempty"`
+	Interval      CandleInterval         `protobuf:"varint,2,opt,name=interval,proto3,enum=gcx.marketdata.v1.CandleInterval" json:"interval,omitempty"`
+	Candles       []*Candle              `protobuf:"bytes,3,rep,name=candles,proto3" json:"candles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCandlesResponse) Reset() {
+	*x = GetCandlesResponse{}
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCandlesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCandlesResponse) ProtoMessage() {}
+
+func (x *GetCandlesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCandlesResponse.ProtoReflect.Descriptor instead.
+func (*GetCandlesResponse) Descriptor() ([]byte, []int) {
+	return file_marketdata_v1_marketdata_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetCandlesResponse) GetCommodity() string {
+	if x != nil {
+		return x.Commodity
+	}
+	return ""
+}
+
+func (x *GetCandlesResponse) GetInterval() CandleInterval {
+	if x != nil {
+		return x.Interval
+	}
+	return CandleInterval_CANDLE_INTERVAL_UNSPECIFIED
+}
+
+func (x *GetCandlesResponse) GetCandles() []*Candle {
+	if x != nil {
+		return x.Candles
+	}
+	return nil
+}
+
+type SubscribePricesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Restrict to these commodities; empty receives every commodity
+	Commodities   []string   `protobuf:"bytes,1,rep,name=commodities,proto3" json:"commodities,omitempty"`
+	Replay        ReplayMode `protobuf:"varint,2,opt,name=replay,proto3,enum=gcx.marketdata.v1.ReplayMode" json:"replay,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribePricesRequest) Reset() {
+	*x = SubscribePricesRequest{}
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribePricesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribePricesRequest) ProtoMessage() {}
+
+func (x *SubscribePricesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribePricesRequest.ProtoReflect.Descriptor instead.
+func (*SubscribePricesRequest) Descriptor() ([]byte, []int) {
+	return file_marketdata_v1_marketdata_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SubscribePricesRequest) GetCommodities() []string {
+	if x != nil {
+		return x.Commodities
+	}
+	return nil
+}
+
+func (x *SubscribePricesRequest) GetReplay() ReplayMode {
+	if x != nil {
+		return x.Replay
+	}
+	return ReplayMode_REPLAY_MODE_EXCLUDE
+}
+
+type PriceEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// price.created, price.corrected, index.updated or replay.price
+	Event         string                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	Price         *Price                 `protobuf:"bytes,2,opt,name=price,proto3" json:"price,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Replay        bool                   `protobuf:"varint,4,opt,name=replay,proto3" json:"replay,omitempty"`
+	ReplaySession string                 `protobuf:"bytes,5,opt,name=replay_session,json=replaySession,proto3" json:"replay_session,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PriceEvent) Reset() {
+	*x = PriceEvent{}
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PriceEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriceEvent) ProtoMessage() {}
+
+func (x *PriceEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_v1_marketdata_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriceEvent.ProtoReflect.Descriptor instead.
+func (*PriceEvent) Descriptor() ([]byte, []int) {
+	return file_marketdata_v1_marketdata_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PriceEvent) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *PriceEvent) GetPrice() *Price {
+	if x != nil {
+		return x.Price
+	}
+	return nil
+}
+
+func (x *PriceEvent) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *PriceEvent) GetReplay() bool {
+	if x != nil {
+		return x.Replay
+	}
+	return false
+}
+
+func (x *PriceEvent) GetReplaySession() string {
+	if x != nil {
+		return x.ReplaySession
+	}
+	return ""
+}
+
+var File_marketdata_v1_marketdata_proto protoreflect.FileDescriptor
+
+const file_marketdata_v1_marketdata_proto_rawDesc = "" +
+	"\n" +
+	"\x1emarketdata/v1/marketdata.proto\x12\x11gcx.marketdata.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x81\x04\n" +
+	"\x05Price\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1c\n" +
+	"\tcommodity\x18\x02 \x01(\tR\tcommodity\x12,\n" +
+	"\x0fcontract_series\x18\x03 \x01(\tH\x00R\x0econtractSeries\x88\x01\x01\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\x01R\x05price\x12\x1a\n" +
+	"\bcurrency\x18\x05 \x01(\tR\bcurrency\x12\x12\n" +
+	"\x04unit\x18\x06 \x01(\tR\x04unit\x12\x16\n" +
+	"\x06change\x18\a \x01(\x01R\x06change\x12%\n" +
+	"\x0echange_percent\x18\b \x01(\x01R\rchangePercent\x12\x17\n" +
+	"\x04open\x18\t \x01(\x01H\x01R\x04open\x88\x01\x01\x12\x17\n" +
+	"\x04high\x18\n" +
+	" \x01(\x01H\x02R\x04high\x88\x01\x01\x12\x15\n" +
+	"\x03low\x18\v \x01(\x01H\x03R\x03low\x88\x01\x01\x12\x19\n" +
+	"\x05close\x18\f \x01(\x01H\x04R\x05close\x88\x01\x01\x12\x1b\n" +
+	"\x06volume\x18\r \x01(\x01H\x05R\x06volume\x88\x01\x01\x12;\n" +
+	"\vmarket_date\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"marketDate\x12\x16\n" +
+	"\x06source\x18\x0f \x01(\tR\x06sourceB\x12\n" +
+	"\x10_contract_seriesB\a\n" +
+	"\x05_openB\a\n" +
+	"\x05_highB\x06\n" +
+	"\x04_lowB\b\n" +
+	"\x06_closeB\t\n" +
+	"\a_volume\";\n" +
+	"\x17GetCurrentPricesRequest\x12 \n" +
+	"\vcommodities\x18\x01 \x03(\tR\vcommodities\"}\n" +
+	"\x18GetCurrentPricesResponse\x120\n" +
+	"\x06prices\x18\x01 \x03(\v2\x18.gcx.marketdata.v1.PriceR\x06prices\x12/\n" +
+	"\x05as_of\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\"\x83\x01\n" +
+	"\x11GetHistoryRequest\x12\x1c\n" +
+	"\tcommodity\x18\x01 \x01(\tR\tcommodity\x12\x1d\n" +
+	"\n" +
+	"start_date\x18\x02 \x01(\tR\tstartDate\x12\x19\n" +
+	"\bend_date\x18\x03 \x01(\tR\aendDate\x12\x16\n" +
+	"\x06series\x18\x04 \x01(\tR\x06series\"d\n" +
+	"\x12GetHistoryResponse\x12\x1c\n" +
+	"\tcommodity\x18\x01 \x01(\tR\tcommodity\x120\n" +
+	"\x06prices\x18\x02 \x03(\v2\x18.gcx.marketdata.v1.PriceR\x06prices\"\xc2\x01\n" +
+	"\x11GetCandlesRequest\x12\x1c\n" +
+	"\tcommodity\x18\x01 \x01(\tR\tcommodity\x12=\n" +
+	"\binterval\x18\x02 \x01(\x0e2!.gcx.marketdata.v1.CandleIntervalR\binterval\x12\x1d\n" +
+	"\n" +
+	"start_date\x18\x03 \x01(\tR\tstartDate\x12\x19\n" +
+	"\bend_date\x18\x04 \x01(\tR\aendDate\x12\x16\n" +
+	"\x06series\x18\x05 \x01(\tR\x06series\"\xb8\x01\n" +
+	"\x06Candle\x120\n" +
+	"\x05start\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x05start\x12\x12\n" +
+	"\x04open\x18\x02 \x01(\x01R\x04open\x12\x12\n" +
+	"\x04high\x18\x03 \x01(\x01R\x04high\x12\x10\n" +
+	"\x03low\x18\x04 \x01(\x01R\x03low\x12\x14\n" +
+	"\x05close\x18\x05 \x01(\x01R\x05close\x12\x16\n" +
+	"\x06volume\x18\x06 \x01(\x01R\x06volume\x12\x14\n" +
+	"\x05count\x18\a \x01(\x05R\x05count\"\xa6\x01\n" +
+	"\x12GetCandlesResponse\x12\x1c\n" +
+	"\tcommodity\x18\x01 \x01(\tR\tcommodity\x12=\n" +
+	"\binterval\x18\x02 \x01(\x0e2!.gcx.marketdata.v1.CandleIntervalR\binterval\x123\n" +
+	"\acandles\x18\x03 \x03(\v2\x19.gcx.marketdata.v1.CandleR\acandles\"q\n" +
+	"\x16SubscribePricesRequest\x12 \n" +
+	"\vcommodities\x18\x01 \x03(\tR\vcommodities\x125\n" +
+	"\x06replay\x18\x02 \x01(\x0e2\x1d.gcx.marketdata.v1.ReplayModeR\x06replay\"\xcb\x01\n" +
+	"\n" +
+	"PriceEvent\x12\x14\n" +
+	"\x05event\x18\x01 \x01(\tR\x05event\x12.\n" +
+	"\x05price\x18\x02 \x01(\v2\x18.gcx.marketdata.v1.PriceR\x05price\x128\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x16\n" +
+	"\x06replay\x18\x04 \x01(\bR\x06replay\x12%\n" +
+	"\x0ereplay_session\x18\x05 \x01(\tR\rreplaySession*\x99\x01\n" +
+	"\x0eCandleInterval\x12\x1f\n" +
+	"\x1bCANDLE_INTERVAL_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14CANDLE_INTERVAL_HOUR\x10\x01\x12\x17\n" +
+	"\x13CANDLE_INTERVAL_DAY\x10\x02\x12\x18\n" +
+	"\x14CANDLE_INTERVAL_WEEK\x10\x03\x12\x19\n" +
+	"\x15CANDLE_INTERVAL_MONTH\x10\x04*T\n" +
+	"\n" +
+	"ReplayMode\x12\x17\n" +
+	"\x13REPLAY_MODE_EXCLUDE\x10\x00\x12\x17\n" +
+	"\x13REPLAY_MODE_INCLUDE\x10\x01\x12\x14\n" +
+	"\x10REPLAY_MODE_ONLY\x10\x022\x95\x03\n" +
+	"\x11MarketDataService\x12k\n" +
+	"\x10GetCurrentPrices\x12*.gcx.marketdata.v1.GetCurrentPricesRequest\x1a+.gcx.marketdata.v1.GetCurrentPricesResponse\x12Y\n" +
+	"\n" +
+	"GetHistory\x12$.gcx.marketdata.v1.GetHistoryRequest\x1a%.gcx.marketdata.v1.GetHistoryResponse\x12Y\n" +
+	"\n" +
+	"GetCandles\x12$.gcx.marketdata.v1.GetCandlesRequest\x1a%.gcx.marketdata.v1.GetCandlesResponse\x12]\n" +
+	"\x0fSubscribePrices\x12).gcx.marketdata.v1.SubscribePricesRequest\x1a\x1d.gcx.marketdata.v1.PriceEvent0\x01B.Z,gcx-cms/internal/marketdata/rpc/marketdatapbb\x06proto3"
+
+var (
+	file_marketdata_v1_marketdata_proto_rawDescOnce sync.Once
+	file_marketdata_v1_marketdata_proto_rawDescData []byte
+)
+
+func file_marketdata_v1_marketdata_proto_rawDescGZIP() []byte {
+	file_marketdata_v1_marketdata_proto_rawDescOnce.Do(func() {
+		file_marketdata_v1_marketdata_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_marketdata_v1_marketdata_proto_rawDesc), len(file_marketdata_v1_marketdata_proto_rawDesc)))
+	})
+	return file_marketdata_v1_marketdata_proto_rawDescData
+}
+
+var file_marketdata_v1_marketdata_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_marketdata_v1_marketdata_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_marketdata_v1_marketdata_proto_goTypes = []any{
+	(CandleInterval)(0),              // 0: gcx.marketdata.v1.CandleInterval
+	(ReplayMode)(0),                  // 1: gcx.marketdata.v1.ReplayMode
+	(*Price)(nil),                    // 2: gcx.marketdata.v1.Price
+	(*GetCurrentPricesRequest)(nil),  // 3: gcx.marketdata.v1.GetCurrentPricesRequest
+	(*GetCurrentPricesResponse)(nil), // 4: gcx.marketdata.v1.GetCurrentPricesResponse
+	(*GetHistoryRequest)(nil),        // 5: gcx.marketdata.v1.GetHistoryRequest
+	(*GetHistoryResponse)(nil),       // 6: gcx.marketdata.v1.GetHistoryResponse
+	(*GetCandlesRequest)(nil),        // 7: gcx.marketdata.v1.GetCandlesRequest
+	(*Candle)(nil),                   // 8: gcx.marketdata.v1.Candle
+	(*GetCandlesResponse)(nil),       // 9: gcx.marketdata.v1.GetCandlesResponse
+	(*SubscribePricesRequest)(nil),   // 10: gcx.marketdata.v1.SubscribePricesRequest
+	(*PriceEvent)(nil),               // 11: gcx.marketdata.v1.PriceEvent
+	(*timestamppb.Timestamp)(nil),    // 12: google.protobuf.Timestamp
+}
+var file_marketdata_v1_marketdata_proto_depIdxs = []int32{
+	12, // 0: gcx.marketdata.v1.Price.market_date:type_name -> google.protobuf.Timestamp
+	2,  // 1: gcx.marketdata.v1.GetCurrentPricesResponse.prices:type_name -> gcx.marketdata.v1.Price
+	12, // 2: gcx.marketdata.v1.GetCurrentPricesResponse.as_of:type_name -> google.protobuf.Timestamp
+	2,  // 3: gcx.marketdata.v1.GetHistoryResponse.prices:type_name -> gcx.marketdata.v1.Price
+	0,  // 4: gcx.marketdata.v1.GetCandlesRequest.interval:type_name -> gcx.marketdata.v1.CandleInterval
+	12, // 5: gcx.marketdata.v1.Candle.start:type_name -> google.protobuf.Timestamp
+	0,  // 6: gcx.marketdata.v1.GetCandlesResponse.interval:type_name -> gcx.marketdata.v1.CandleInterval
+	8,  // 7: gcx.marketdata.v1.GetCandlesResponse.candles:type_name -> gcx.marketdata.v1.Candle
+	1,  // 8: gcx.marketdata.v1.SubscribePricesRequest.replay:type_name -> gcx.marketdata.v1.ReplayMode
+	2,  // 9: gcx.marketdata.v1.PriceEvent.price:type_name -> gcx.marketdata.v1.Price
+	12, // 10: gcx.marketdata.v1.PriceEvent.timestamp:type_name -> google.protobuf.Timestamp
+	3,  // 11: gcx.marketdata.v1.MarketDataService.GetCurrentPrices:input_type -> gcx.marketdata.v1.GetCurrentPricesRequest
+	5,  // 12: gcx.marketdata.v1.MarketDataService.GetHistory:input_type -> gcx.marketdata.v1.GetHistoryRequest
+	7,  // 13: gcx.marketdata.v1.MarketDataService.GetCandles:input_type -> gcx.marketdata.v1.GetCandlesRequest
+	10, // 14: gcx.marketdata.v1.MarketDataService.SubscribePrices:input_type -> gcx.marketdata.v1.SubscribePricesRequest
+	4,  // 15: gcx.marketdata.v1.MarketDataService.GetCurrentPrices:output_type -> gcx.marketdata.v1.GetCurrentPricesResponse
+	6,  // 16: gcx.marketdata.v1.MarketDataService.GetHistory:output_type -> gcx.marketdata.v1.GetHistoryResponse
+	9,  // 17: gcx.marketdata.v1.MarketDataService.GetCandles:output_type -> gcx.marketdata.v1.GetCandlesResponse
+	11, // 18: gcx.marketdata.v1.MarketDataService.SubscribePrices:output_type -> gcx.marketdata.v1.PriceEvent
+	15, // [15:19] is the sub-list for method output_type
+	11, // [11:15] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_marketdata_v1_marketdata_proto_init() }
+func file_marketdata_v1_marketdata_proto_init() {
+	if File_marketdata_v1_marketdata_proto != nil {
+		return
+	}
+	file_marketdata_v1_marketdata_proto_msgTypes[0].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_marketdata_v1_marketdata_proto_rawDesc), len(file_marketdata_v1_marketdata_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_marketdata_v1_marketdata_proto_goTypes,
+		DependencyIndexes: file_marketdata_v1_marketdata_proto_depIdxs,
+		EnumInfos:         file_marketdata_v1_marketdata_proto_enumTypes,
+		MessageInfos:      file_marketdata_v1_marketdata_proto_msgTypes,
+	}.Build()
+	File_marketdata_v1_marketdata_proto = out.File
+	file_marketdata_v1_marketdata_proto_goTypes = nil
+	file_marketdata_v1_marketdata_proto_depIdxs = nil
+}
@@ -0,0 +1,255 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: marketdata/v1/marketdata.proto
+
+package marketdatapb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	MarketDataService_GetCurrentPrices_FullMethodName = "/gcx.marketdata.v1.MarketDataService/GetCurrentPrices"
+	MarketDataService_GetHistory_FullMethodName       = "/gcx.marketdata.v1.MarketDataService/GetHistory"
+	MarketDataService_GetCandles_FullMethodName       = "/gcx.marketdata.v1.MarketDataService/GetCandles"
+	MarketDataService_SubscribePrices_FullMethodName  = "/gcx.marketdata.v1.MarketDataService/SubscribePrices"
+)
+
+// MarketDataServiceClient is the client API for MarketDataService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// MarketDataService is the gRPC interface to GCX market data. Send a JWT from /api/auth/login as
+// "authorization: Bearer <token>" metadata. Prices, history and candles are public like their
+// REST counterparts; SubscribePrices needs real-time data access.
+type MarketDataServiceClient interface {
+	// Latest price of each commodity, as GET /api/marketdata/prices
+	GetCurrentPrices(ctx context.Context, in *GetCurrentPricesRequest, opts ...grpc.CallOption) (*GetCurrentPricesResponse, error)
+	// Prices of one commodity over a date range, as GET /api/marketdata/history
+	GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error)
+	// Prices of one commodity aggregated into OHLC candles
+	GetCandles(ctx context.Context, in *GetCandlesRequest, opts ...grpc.CallOption) (*GetCandlesResponse, error)
+	// Price updates as they are published, as GET /api/marketdata/stream
+	SubscribePrices(ctx context.Context, in *SubscribePricesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PriceEvent], error)
+}
+
+type marketDataServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMarketDataServiceClient(cc grpc.ClientConnInterface) MarketDataServiceClient {
+	return &marketDataServiceClient{cc}
+}
+
+func (c *marketDataServiceClient) GetCurrentPrices(ctx context.Context, in *GetCurrentPricesRequest, opts ...grpc.CallOption) (*GetCurrentPricesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCurrentPricesResponse)
+	err := c.cc.Invoke(ctx, MarketDataService_GetCurrentPrices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketDataServiceClient) GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetHistoryResponse)
+	err := c.cc.Invoke(ctx, MarketDataService_GetHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketDataServiceClient) GetCandles(ctx context.Context, in *GetCandlesRequest, opts ...grpc.CallOption) (*GetCandlesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCandlesResponse)
+	err := c.cc.Invoke(ctx, MarketDataService_GetCandles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketDataServiceClient) SubscribePrices(ctx context.Context, in *SubscribePricesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PriceEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MarketDataService_ServiceDesc.Streams[0], MarketDataService_SubscribePrices_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribePricesRequest, PriceEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MarketDataService_SubscribePricesClient = grpc.ServerStreamingClient[PriceEvent]
+
+// MarketDataServiceServer is the server API for MarketDataService service.
+// All implementations must embed UnimplementedMarketDataServiceServer
+// for forward compatibility.
+//
+// MarketDataService is the gRPC interface to GCX market data. Send a JWT from /api/auth/login as
+// "authorization: Bearer <token>" metadata. Prices, history and candles are public like their
+// REST counterparts; SubscribePrices needs real-time data access.
+type MarketDataServiceServer interface {
+	// Latest price of each commodity, as GET /api/marketdata/prices
+	GetCurrentPrices(context.Context, *GetCurrentPricesRequest) (*GetCurrentPricesResponse, error)
+	// Prices of one commodity over a date range, as GET /api/marketdata/history
+	GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error)
+	// Prices of one commodity aggregated into OHLC candles
+	GetCandles(context.Context, *GetCandlesRequest) (*GetCandlesResponse, error)
+	// Price updates as they are published, as GET /api/marketdata/stream
+	SubscribePrices(*SubscribePricesRequest, grpc.ServerStreamingServer[PriceEvent]) error
+	mustEmbedUnimplementedMarketDataServiceServer()
+}
+
+// UnimplementedMarketDataServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMarketDataServiceServer struct{}
+
+func (UnimplementedMarketDataServiceServer) GetCurrentPrices(context.Context, *GetCurrentPricesRequest) (*GetCurrentPricesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCurrentPrices not implemented")
+}
+func (UnimplementedMarketDataServiceServer) GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHistory not implemented")
+}
+func (UnimplementedMarketDataServiceServer) GetCandles(context.Context, *GetCandlesRequest) (*GetCandlesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCandles not implemented")
+}
+func (UnimplementedMarketDataServiceServer) SubscribePrices(*SubscribePricesRequest, grpc.ServerStreamingServer[PriceEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribePrices not implemented")
+}
+func (UnimplementedMarketDataServiceServer) mustEmbedUnimplementedMarketDataServiceServer() {}
+func (UnimplementedMarketDataServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeMarketDataServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MarketDataServiceServer will
+// result in compilation errors.
+type UnsafeMarketDataServiceServer interface {
+	mustEmbedUnimplementedMarketDataServiceServer()
+}
+
+func RegisterMarketDataServiceServer(s grpc.ServiceRegistrar, srv MarketDataServiceServer) {
+	// If the following call pancis, it indicates UnimplementedMarketDataServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MarketDataService_ServiceDesc, srv)
+}
+
+func _MarketDataService_GetCurrentPrices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCurrentPricesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketDataServiceServer).GetCurrentPrices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MarketDataService_GetCurrentPrices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketDataServiceServer).GetCurrentPrices(ctx, req.(*GetCurrentPricesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MarketDataService_GetHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketDataServiceServer).GetHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MarketDataService_GetHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketDataServiceServer).GetHistory(ctx, req.(*GetHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MarketDataService_GetCandles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCandlesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketDataServiceServer).GetCandles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MarketDataService_GetCandles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketDataServiceServer).GetCandles(ctx, req.(*GetCandlesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MarketDataService_SubscribePrices_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribePricesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MarketDataServiceServer).SubscribePrices(m, &grpc.GenericServerStream[SubscribePricesRequest, PriceEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MarketDataService_SubscribePricesServer = grpc.ServerStreamingServer[PriceEvent]
+
+// MarketDataService_ServiceDesc is the grpc.ServiceDesc for MarketDataService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MarketDataService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gcx.marketdata.v1.MarketDataService",
+	HandlerType: (*MarketDataServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetCurrentPrices",
+			Handler:    _MarketDataService_GetCurrentPrices_Handler,
+		},
+		{
+			MethodName: "GetHistory",
+			Handler:    _MarketDataService_GetHistory_Handler,
+		},
+		{
+			MethodName: "GetCandles",
+			Handler:    _MarketDataService_GetCandles_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribePrices",
+			Handler:       _MarketDataService_SubscribePrices_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "marketdata/v1/marketdata.proto",
+}
@@ -0,0 +1,102 @@
+// Package rpc serves market data over gRPC for institutional clients and internal services.
+// The protobuf definitions live in proto/marketdata/v1.
+package rpc
+
+//go:generate protoc -I ../../../proto --go_out=. --go_opt=module=gcx-cms/internal/marketdata/rpc --go-grpc_out=. --go-grpc_opt=module=gcx-cms/internal/marketdata/rpc marketdata/v1/marketdata.proto
+
+import (
+	"context"
+	"log"
+	"net"
+	"strings"
+
+	"gcx-cms/internal/marketdata/rpc/marketdatapb"
+	"gcx-cms/internal/shared/middleware"
+	shared_models "gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/rbac"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// Serve listens on addr and serves the market data service until the listener fails
+func Serve(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryAuthInterceptor),
+		grpc.ChainStreamInterceptor(streamAuthInterceptor),
+	)
+	marketdatapb.RegisterMarketDataServiceServer(server, &marketDataServer{})
+	reflection.Register(server)
+
+	log.Printf("📡 gRPC market data service listening on %s", addr)
+	return server.Serve(listener)
+}
+
+type userKey struct{}
+
+// currentUser returns the authenticated user of a call, or nil for anonymous calls
+func currentUser(ctx context.Context) *shared_models.User {
+	user, _ := ctx.Value(userKey{}).(*shared_models.User)
+	return user
+}
+
+// authenticate validates the bearer token in the call metadata, as OptionalAuthMiddleware does
+// for HTTP: calls without a token are anonymous, calls with a bad token are rejected
+func authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || values[0] == "" {
+		return ctx, nil
+	}
+
+	tokenString := strings.TrimPrefix(values[0], "Bearer ")
+	if tokenString == values[0] {
+		return nil, status.Error(codes.Unauthenticated, "Bearer token required")
+	}
+
+	user, _, err := middleware.ValidateToken(tokenString)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if !user.IsEmailVerified() {
+		return nil, status.Error(codes.PermissionDenied, "Email address not verified")
+	}
+	if !user.TwoFactorEnabled && rbac.GetService().RequiresTwoFactor(user.Role) {
+		return nil, status.Error(codes.PermissionDenied, "Two-factor authentication setup required")
+	}
+	return context.WithValue(ctx, userKey{}, user), nil
+}
+
+func unaryAuthInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func streamAuthInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticatedStream carries the authenticated context into stream handlers
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,203 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/marketdata/rpc/marketdatapb"
+	"gcx-cms/internal/marketdata/services"
+	shared_models "gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/rbac"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// marketDataServer implements marketdatapb.MarketDataServiceServer on top of the price services
+type marketDataServer struct {
+	marketdatapb.UnimplementedMarketDataServiceServer
+}
+
+// GetCurrentPrices returns the latest price of each commodity
+func (s *marketDataServer) GetCurrentPrices(ctx context.Context, req *marketdatapb.GetCurrentPricesRequest) (*marketdatapb.GetCurrentPricesResponse, error) {
+	prices, err := services.NewPriceService().GetCurrentPrices()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch current prices: %v", err)
+	}
+
+	filter := services.NewStreamFilter(req.GetCommodities(), nil)
+	resp := &marketdatapb.GetCurrentPricesResponse{AsOf: timestamppb.Now()}
+	for i := range prices {
+		if filter.Matches(&prices[i]) {
+			resp.Prices = append(resp.Prices, toPrice(&prices[i]))
+		}
+	}
+	return resp, nil
+}
+
+// GetHistory returns a commodity's prices over a date range
+func (s *marketDataServer) GetHistory(ctx context.Context, req *marketdatapb.GetHistoryRequest) (*marketdatapb.GetHistoryResponse, error) {
+	if req.GetCommodity() == "" {
+		return nil, status.Error(codes.InvalidArgument, "commodity is required")
+	}
+	start, end, err := dateRange(req.GetStartDate(), req.GetEndDate(), 30)
+	if err != nil {
+		return nil, err
+	}
+
+	prices, err := services.NewPriceService().GetHistoricalPrices(req.GetCommodity(), req.GetSeries(), start, end)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to fetch historical prices: %v", err)
+	}
+
+	resp := &marketdatapb.GetHistoryResponse{Commodity: req.GetCommodity()}
+	for i := range prices {
+		resp.Prices = append(resp.Prices, toPrice(&prices[i]))
+	}
+	return resp, nil
+}
+
+// GetCandles returns a commodity's prices aggregated into OHLC candles
+func (s *marketDataServer) GetCandles(ctx context.Context, req *marketdatapb.GetCandlesRequest) (*marketdatapb.GetCandlesResponse, error) {
+	if req.GetCommodity() == "" {
+		return nil, status.Error(codes.InvalidArgument, "commodity is required")
+	}
+	start, end, err := dateRange(req.GetStartDate(), req.GetEndDate(), 90)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := req.GetInterval()
+	if interval == marketdatapb.CandleInterval_CANDLE_INTERVAL_UNSPECIFIED {
+		interval = marketdatapb.CandleInterval_CANDLE_INTERVAL_DAY
+	}
+	candleIntervals := map[marketdatapb.CandleInterval]string{
+		marketdatapb.CandleInterval_CANDLE_INTERVAL_HOUR:  services.CandleHour,
+		marketdatapb.CandleInterval_CANDLE_INTERVAL_DAY:   services.CandleDay,
+		marketdatapb.CandleInterval_CANDLE_INTERVAL_WEEK:  services.CandleWeek,
+		marketdatapb.CandleInterval_CANDLE_INTERVAL_MONTH: services.CandleMonth,
+	}
+	name, ok := candleIntervals[interval]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown candle interval %v", interval)
+	}
+
+	candles, err := services.NewPriceService().GetCandles(req.GetCommodity(), req.GetSeries(), name, start, end)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to build candles: %v", err)
+	}
+
+	resp := &marketdatapb.GetCandlesResponse{Commodity: req.GetCommodity(), Interval: interval}
+	for _, c := range candles {
+		resp.Candles = append(resp.Candles, &marketdatapb.Candle{
+			Start:  timestamppb.New(c.Start),
+			Open:   c.Open,
+			High:   c.High,
+			Low:    c.Low,
+			Close:  c.Close,
+			Volume: c.Volume,
+			Count:  int32(c.Count),
+		})
+	}
+	return resp, nil
+}
+
+// SubscribePrices streams price updates until the client disconnects. Like the SSE stream it
+// needs market data access and real-time data access, and each subscription counts against the
+// daily quota.
+func (s *marketDataServer) SubscribePrices(req *marketdatapb.SubscribePricesRequest, stream marketdatapb.MarketDataService_SubscribePricesServer) error {
+	user := currentUser(stream.Context())
+	if user == nil {
+		return status.Error(codes.Unauthenticated, "User not authenticated")
+	}
+	if !rbac.Can(user, shared_models.PermMarketDataAccess) {
+		return status.Errorf(codes.PermissionDenied, "Permission required: %s", shared_models.PermMarketDataAccess)
+	}
+	if !services.HasDataAccess(user, models.DataTypeRealTime) {
+		return status.Error(codes.PermissionDenied, "Real-time data access required")
+	}
+	meter := services.GetUsageMeter()
+	quota, ok, err := meter.Reserve(user.ID, models.DataTypeRealTime)
+	if err == nil && !ok {
+		return status.Errorf(codes.ResourceExhausted, "Daily %s request limit of %d reached", models.DataTypeRealTime, quota.Limit)
+	}
+	meter.Record(user.ID, models.DataTypeRealTime, "gRPC SubscribePrices", "")
+
+	mode := services.StreamLiveOnly
+	switch req.GetReplay() {
+	case marketdatapb.ReplayMode_REPLAY_MODE_INCLUDE:
+		mode = services.StreamLiveAndReplay
+	case marketdatapb.ReplayMode_REPLAY_MODE_ONLY:
+		mode = services.StreamReplayOnly
+	}
+
+	messages, unsubscribe := services.GetPriceStream().Subscribe(services.NewStreamFilter(req.GetCommodities(), nil), mode)
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case msg, open := <-messages:
+			if !open {
+				return nil
+			}
+			if err := stream.Send(&marketdatapb.PriceEvent{
+				Event:         msg.Event,
+				Price:         toPrice(&msg.Price),
+				Timestamp:     timestamppb.New(msg.Timestamp),
+				Replay:        msg.Replay,
+				ReplaySession: msg.ReplaySession,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// dateRange parses optional YYYY-MM-DD bounds, defaulting to the last defaultDays days. The end
+// date is inclusive.
+func dateRange(startDate, endDate string, defaultDays int) (time.Time, time.Time, error) {
+	start := time.Now().AddDate(0, 0, -defaultDays)
+	end := time.Now()
+	var err error
+	if startDate != "" {
+		if start, err = time.Parse("2006-01-02", startDate); err != nil {
+			return start, end, status.Error(codes.InvalidArgument, "invalid start_date format, use YYYY-MM-DD")
+		}
+	}
+	if endDate != "" {
+		if end, err = time.Parse("2006-01-02", endDate); err != nil {
+			return start, end, status.Error(codes.InvalidArgument, "invalid end_date format, use YYYY-MM-DD")
+		}
+	}
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1).Add(-time.Nanosecond)
+	if end.Before(start) {
+		return start, end, status.Error(codes.InvalidArgument, "end_date must not be before start_date")
+	}
+	return start, end, nil
+}
+
+// toPrice converts a market data record to its protobuf message
+func toPrice(p *models.MarketData) *marketdatapb.Price {
+	return &marketdatapb.Price{
+		Id:             uint64(p.ID),
+		Commodity:      p.Commodity,
+		ContractSeries: p.ContractSeries,
+		Price:          p.Price,
+		Currency:       p.Currency,
+		Unit:           p.Unit,
+		Change:         p.Change,
+		ChangePercent:  p.ChangePercent,
+		Open:           p.Open,
+		High:           p.High,
+		Low:            p.Low,
+		Close:          p.Close,
+		Volume:         p.Volume,
+		MarketDate:     timestamppb.New(p.MarketDate),
+		Source:         p.Source,
+	}
+}
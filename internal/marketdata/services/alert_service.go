@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/config"
+
+	"gorm.io/gorm"
+)
+
+// AlertService handles evaluation of user price alerts
+type AlertService struct{}
+
+// NewAlertService creates a new alert service instance
+func NewAlertService() *AlertService {
+	return &AlertService{}
+}
+
+// Evaluate marks active alerts on the price's commodity as triggered when their condition is met.
+// Triggered alerts are deactivated so they fire once; users re-arm them by setting is_active again.
+// The contract series of the price, if it has one, is recorded with them.
+// The system triggers them, whoever wrote the price.
+func (as *AlertService) Evaluate(price *models.MarketData) {
+	if config.DB == nil {
+		return
+	}
+	audit.System("price alerts", func(ctx context.Context) error {
+		as.evaluate(config.DB.WithContext(ctx), price)
+		return nil
+	})
+}
+
+// evaluate does the work of Evaluate through db
+func (as *AlertService) evaluate(db *gorm.DB, price *models.MarketData) {
+	var alerts []models.PriceAlert
+	if err := db.Where("commodity = ? AND is_active = ?", price.Commodity, true).
+		Find(&alerts).Error; err != nil {
+		log.Printf("Warning: Failed to load price alerts for %s: %v", price.Commodity, err)
+		return
+	}
+
+	now := time.Now()
+	for _, alert := range alerts {
+		if !alertConditionMet(alert, price.Price) {
+			continue
+		}
+		if err := db.Model(&alert).Updates(map[string]interface{}{
+			"triggered_at":     now,
+			"triggered_series": price.ContractSeries,
+			"is_active":        false,
+		}).Error; err != nil {
+			log.Printf("Warning: Failed to mark price alert %d triggered: %v", alert.ID, err)
+		}
+	}
+}
+
+// alertConditionMet checks a price against an alert's target
+func alertConditionMet(alert models.PriceAlert, price float64) bool {
+	switch alert.Condition {
+	case "above":
+		return price >= alert.TargetPrice
+	case "below":
+		return price <= alert.TargetPrice
+	}
+	return false
+}
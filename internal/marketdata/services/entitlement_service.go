@@ -0,0 +1,106 @@
+package services
+
+import (
+	"time"
+
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/shared/config"
+	shared_models "gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/rbac"
+
+	"gorm.io/gorm"
+)
+
+// Subscription sources reported with a user's active subscription
+const (
+	SubscriptionSourcePersonal     = "personal"
+	SubscriptionSourceOrganization = "organization"
+)
+
+// dataTypePermissions are the role permissions that grant a data type without a subscription
+var dataTypePermissions = map[string]string{
+	models.DataTypeRealTime:   shared_models.PermMarketDataRealtime,
+	models.DataTypeHistorical: shared_models.PermMarketDataHistorical,
+	models.DataTypeAnalytics:  shared_models.PermMarketDataAnalytics,
+}
+
+// featureNames are the SubscriptionFeature names that offer a data type, e.g. real_time or
+// real_time_data
+func featureNames(dataType string) []string {
+	return []string{dataType, dataType + "_data"}
+}
+
+// activeSubscriptions selects subscriptions that are active now
+func activeSubscriptions(tx *gorm.DB) *gorm.DB {
+	now := time.Now()
+	return tx.Where("status = ? AND start_date <= ? AND end_date > ?", "active", now, now)
+}
+
+// ActiveSubscription returns the subscription covering a user: their own, else the one of
+// their organisation, with its source. It returns gorm.ErrRecordNotFound if neither exists.
+func ActiveSubscription(userID uint) (*models.UserSubscription, string, error) {
+	var subscription models.UserSubscription
+	err := activeSubscriptions(config.DB.Preload("Plan")).
+		Where("user_id = ? AND organization_id IS NULL", userID).
+		Order("end_date DESC").
+		First(&subscription).Error
+	if err == nil {
+		return &subscription, SubscriptionSourcePersonal, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, "", err
+	}
+
+	member, err := NewOrganizationService().Membership(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	orgSubscription, err := organizationSubscription(config.DB, member.OrganizationID)
+	if err != nil {
+		return nil, "", err
+	}
+	return orgSubscription, SubscriptionSourceOrganization, nil
+}
+
+// OrganizationSubscription returns an organisation's active subscription
+func OrganizationSubscription(orgID uint) (*models.UserSubscription, error) {
+	return organizationSubscription(config.DB, orgID)
+}
+
+func organizationSubscription(tx *gorm.DB, orgID uint) (*models.UserSubscription, error) {
+	var subscription models.UserSubscription
+	if err := activeSubscriptions(tx.Preload("Plan")).
+		Where("organization_id = ?", orgID).
+		Order("end_date DESC").
+		First(&subscription).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// HasDataAccess reports whether a user may use a data type, either through their role or
+// through a plan feature of their own or their organisation's active subscription. Data types
+// without a permission of their own come with basic market data access.
+func HasDataAccess(user *shared_models.User, dataType string) bool {
+	perm, gated := dataTypePermissions[dataType]
+	if !gated || rbac.Can(user, perm) {
+		return true
+	}
+
+	var planIDs []uint
+	query := activeSubscriptions(config.DB.Model(&models.UserSubscription{}))
+	if member, err := NewOrganizationService().Membership(user.ID); err == nil {
+		query = query.Where("(user_id = ? AND organization_id IS NULL) OR organization_id = ?", user.ID, member.OrganizationID)
+	} else {
+		query = query.Where("user_id = ? AND organization_id IS NULL", user.ID)
+	}
+	if err := query.Pluck("plan_id", &planIDs).Error; err != nil || len(planIDs) == 0 {
+		return false
+	}
+
+	var count int64
+	config.DB.Model(&models.SubscriptionFeature{}).
+		Where("plan_id IN ? AND name IN ? AND is_enabled = ?", planIDs, featureNames(dataType), true).
+		Count(&count)
+	return count > 0
+}
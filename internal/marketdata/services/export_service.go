@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/token"
+
+	"gorm.io/gorm"
+)
+
+const (
+	exportKindString = iota
+	exportKindInt
+	exportKindFloat
+	exportKindTime
+)
+
+// ExportColumn is a market_data column available for export
+type ExportColumn struct {
+	Name     string
+	Kind     int
+	Nullable bool
+	value    func(p *models.MarketData) interface{}
+}
+
+// exportColumns lists the exportable columns in their default order
+var exportColumns = []ExportColumn{
+	{Name: "id", Kind: exportKindInt, value: func(p *models.MarketData) interface{} { return p.ID }},
+	{Name: "commodity", value: func(p *models.MarketData) interface{} { return p.Commodity }},
+	{Name: "contract_series", Nullable: true, value: func(p *models.MarketData) interface{} { return optionalString(p.ContractSeries) }},
+	{Name: "market_date", Kind: exportKindTime, value: func(p *models.MarketData) interface{} { return p.MarketDate }},
+	{Name: "price", Kind: exportKindFloat, value: func(p *models.MarketData) interface{} { return p.Price }},
+	{Name: "currency", value: func(p *models.MarketData) interface{} { return p.Currency }},
+	{Name: "unit", value: func(p *models.MarketData) interface{} { return p.Unit }},
+	{Name: "change", Kind: exportKindFloat, value: func(p *models.MarketData) interface{} { return p.Change }},
+	{Name: "change_percent", Kind: exportKindFloat, value: func(p *models.MarketData) interface{} { return p.ChangePercent }},
+	{Name: "open", Kind: exportKindFloat, Nullable: true, value: func(p *models.MarketData) interface{} { return optionalFloat(p.Open) }},
+	{Name: "high", Kind: exportKindFloat, Nullable: true, value: func(p *models.MarketData) interface{} { return optionalFloat(p.High) }},
+	{Name: "low", Kind: exportKindFloat, Nullable: true, value: func(p *models.MarketData) interface{} { return optionalFloat(p.Low) }},
+	{Name: "close", Kind: exportKindFloat, Nullable: true, value: func(p *models.MarketData) interface{} { return optionalFloat(p.Close) }},
+	{Name: "volume", Kind: exportKindFloat, Nullable: true, value: func(p *models.MarketData) interface{} { return optionalFloat(p.Volume) }},
+	{Name: "source", value: func(p *models.MarketData) interface{} { return p.Source }},
+}
+
+// ExportRequest selects the market data to export
+type ExportRequest struct {
+	Format      string
+	Commodities []string
+	Columns     []string
+	Series      string
+	Start       time.Time
+	End         time.Time // Inclusive day
+}
+
+// ExportService streams historical market data exports and runs large ones as background jobs
+type ExportService struct{}
+
+// NewExportService creates a new export service instance
+func NewExportService() *ExportService {
+	return &ExportService{}
+}
+
+// AsyncThreshold returns the row count above which exports run as jobs (EXPORT_ASYNC_ROWS, default 100000)
+func (es *ExportService) AsyncThreshold() int64 {
+	if raw := os.Getenv("EXPORT_ASYNC_ROWS"); raw != "" {
+		if n, err := strconv.ParseInt(raw, 10, 64); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 100000
+}
+
+// ExportColumnNames lists the columns that can be exported
+func ExportColumnNames() []string {
+	names := make([]string, len(exportColumns))
+	for i, col := range exportColumns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// ResolveColumns validates requested column names, returning all columns when none are given
+func (es *ExportService) ResolveColumns(names []string) ([]ExportColumn, error) {
+	if len(names) == 0 {
+		return exportColumns, nil
+	}
+	columns := make([]ExportColumn, 0, len(names))
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		found := false
+		for _, col := range exportColumns {
+			if col.Name == name {
+				columns = append(columns, col)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		seen[name] = true
+	}
+	return columns, nil
+}
+
+// Count returns how many rows an export would contain
+func (es *ExportService) Count(req ExportRequest) (int64, error) {
+	var count int64
+	err := es.query(req).Model(&models.MarketData{}).Count(&count).Error
+	return count, err
+}
+
+// Write streams an export to w, reading rows from the database one at a time
+func (es *ExportService) Write(ctx context.Context, w io.Writer, req ExportRequest) (int64, error) {
+	columns, err := es.ResolveColumns(req.Columns)
+	if err != nil {
+		return 0, err
+	}
+	writer, err := newExportWriter(req.Format, w, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := es.query(req).WithContext(ctx).Model(&models.MarketData{}).
+		Order("market_date ASC, id ASC").
+		Rows()
+	if err != nil {
+		return 0, fmt.Errorf("failed to query market data: %v", err)
+	}
+	defer rows.Close()
+
+	var written int64
+	values := make([]interface{}, len(columns))
+	for rows.Next() {
+		var price models.MarketData
+		if err := config.DB.ScanRows(rows, &price); err != nil {
+			return written, fmt.Errorf("failed to read market data: %v", err)
+		}
+		for i, col := range columns {
+			values[i] = col.value(&price)
+		}
+		if err := writer.WriteRow(values); err != nil {
+			return written, err
+		}
+		written++
+	}
+	if err := rows.Err(); err != nil {
+		return written, err
+	}
+	return written, writer.Close()
+}
+
+// CreateJob queues a background export for a user
+func (es *ExportService) CreateJob(userID uint, req ExportRequest) (*models.ExportJob, error) {
+	job := &models.ExportJob{
+		UserID:      userID,
+		Format:      req.Format,
+		Commodities: strings.Join(req.Commodities, ","),
+		Columns:     strings.Join(req.Columns, ","),
+		Series:      req.Series,
+		StartDate:   req.Start,
+		EndDate:     req.End,
+		Status:      models.ExportStatusQueued,
+	}
+	if err := config.DB.Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to create export job: %v", err)
+	}
+
+	go es.runJob(job.ID, req)
+	return job, nil
+}
+
+// runJob writes a job's export to a randomly named file in the export directory
+func (es *ExportService) runJob(jobID uint, req ExportRequest) {
+	audit.System("export worker", func(ctx context.Context) error {
+		es.writeJob(config.DB.WithContext(ctx), jobID, req)
+		return nil
+	})
+}
+
+// writeJob does the work of runJob through db
+func (es *ExportService) writeJob(db *gorm.DB, jobID uint, req ExportRequest) {
+	started := time.Now()
+	db.Model(&models.ExportJob{}).Where("id = ?", jobID).Updates(map[string]interface{}{
+		"status":     models.ExportStatusRunning,
+		"started_at": started,
+	})
+
+	fail := func(err error) {
+		log.Printf("Warning: Export job %d failed: %v", jobID, err)
+		db.Model(&models.ExportJob{}).Where("id = ?", jobID).Updates(map[string]interface{}{
+			"status": models.ExportStatusFailed,
+			"error":  err.Error(),
+		})
+	}
+
+	dir := es.directory()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		fail(err)
+		return
+	}
+
+	name, err := token.NewOpaque(16)
+	if err != nil {
+		fail(err)
+		return
+	}
+	path := filepath.Join(dir, name+"."+req.Format)
+	tmp := path + ".part"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	count, err := es.Write(context.Background(), file, req)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		fail(err)
+		return
+	}
+
+	var size int64
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+	now := time.Now()
+	db.Model(&models.ExportJob{}).Where("id = ?", jobID).Updates(map[string]interface{}{
+		"status":       models.ExportStatusCompleted,
+		"row_count":    count,
+		"file_path":    path,
+		"file_size":    size,
+		"completed_at": now,
+		"expires_at":   now.Add(es.retention()),
+	})
+}
+
+// StartCleanup fails jobs interrupted by a restart and periodically deletes expired export files
+func (es *ExportService) StartCleanup(interval time.Duration) {
+	if err := config.DB.Model(&models.ExportJob{}).
+		Where("status IN ?", []string{models.ExportStatusQueued, models.ExportStatusRunning}).
+		Updates(map[string]interface{}{
+			"status": models.ExportStatusFailed,
+			"error":  "Interrupted by server restart",
+		}).Error; err != nil {
+		log.Printf("Warning: Failed to reset interrupted export jobs: %v", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			es.expire()
+		}
+	}()
+}
+
+// expire removes files of completed jobs past their expiry, as the system
+func (es *ExportService) expire() {
+	audit.System("export cleanup", func(ctx context.Context) error {
+		es.expireFiles(config.DB.WithContext(ctx))
+		return nil
+	})
+}
+
+// expireFiles does the work of expire through db
+func (es *ExportService) expireFiles(db *gorm.DB) {
+	var jobs []models.ExportJob
+	if err := db.Where("status = ? AND expires_at < ?", models.ExportStatusCompleted, time.Now()).
+		Find(&jobs).Error; err != nil {
+		log.Printf("Warning: Failed to load expired export jobs: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		if job.FilePath != "" {
+			if err := os.Remove(job.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.Printf("Warning: Failed to remove export file %s: %v", job.FilePath, err)
+				continue
+			}
+		}
+		db.Model(&job).Updates(map[string]interface{}{
+			"status":    models.ExportStatusExpired,
+			"file_path": "",
+		})
+	}
+}
+
+// directory is where export files are written (EXPORT_DIR, default ./exports). It must not be
+// served statically: files are only downloaded through their job, by its owner.
+func (es *ExportService) directory() string {
+	if dir := os.Getenv("EXPORT_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(".", "exports")
+}
+
+// retention is how long completed export files are kept (EXPORT_RETENTION_HOURS, default 24)
+func (es *ExportService) retention() time.Duration {
+	if raw := os.Getenv("EXPORT_RETENTION_HOURS"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			return time.Duration(n) * time.Hour
+		}
+	}
+	return 24 * time.Hour
+}
+
+// query selects the market_data rows of an export, matching GetHistoricalPrices filtering
+func (es *ExportService) query(req ExportRequest) *gorm.DB {
+	query := config.DB.Where("market_date >= ? AND market_date < ?", req.Start, req.End.AddDate(0, 0, 1))
+	if len(req.Commodities) > 0 {
+		query = query.Where("commodity IN ?", req.Commodities)
+	}
+	if req.Series != "" {
+		query = query.Where("contract_series = ?", req.Series)
+	}
+	return query
+}
+
+func optionalFloat(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func optionalString(v *string) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
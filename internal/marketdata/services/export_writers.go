@@ -0,0 +1,273 @@
+package services
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"gcx-cms/internal/marketdata/models"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress/snappy"
+)
+
+// exportWriter writes rows of one export format to an output stream
+type exportWriter interface {
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// newExportWriter creates the writer for a format with the given columns
+func newExportWriter(format string, w io.Writer, columns []ExportColumn) (exportWriter, error) {
+	switch format {
+	case models.ExportFormatCSV:
+		return newCSVExportWriter(w, columns)
+	case models.ExportFormatXLSX:
+		return newXLSXExportWriter(w, columns)
+	case models.ExportFormatParquet:
+		return newParquetExportWriter(w, columns), nil
+	}
+	return nil, fmt.Errorf("unsupported export format %q", format)
+}
+
+// ExportContentType returns the MIME type of an export format
+func ExportContentType(format string) string {
+	switch format {
+	case models.ExportFormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case models.ExportFormatParquet:
+		return "application/vnd.apache.parquet"
+	}
+	return "text/csv"
+}
+
+// formatExportValue renders a value as text for CSV and XLSX
+func formatExportValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case uint:
+		return strconv.FormatUint(uint64(v), 10)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
+
+// csvExportWriter streams rows as CSV with a header line
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func newCSVExportWriter(w io.Writer, columns []ExportColumn) (*csvExportWriter, error) {
+	cw := &csvExportWriter{w: csv.NewWriter(w)}
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	return cw, cw.w.Write(header)
+}
+
+func (cw *csvExportWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = formatExportValue(v)
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvExportWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// xlsxExportWriter streams a single-sheet workbook. The sheet XML is written row by row into
+// the zip entry, so memory use does not grow with the export size. Cells use inline strings
+// and plain numbers, which need no shared string table or styles part.
+type xlsxExportWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	rows  int
+}
+
+// XLSXMaxRows is the row limit of an Excel worksheet, including the header
+const XLSXMaxRows = 1048576
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Market Data" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+)
+
+func newXLSXExportWriter(w io.Writer, columns []ExportColumn) (*xlsxExportWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	} {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	xw := &xlsxExportWriter{zip: zw, sheet: bufio.NewWriterSize(sheet, 64*1024)}
+	xw.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]interface{}, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	return xw, xw.WriteRow(header)
+}
+
+func (xw *xlsxExportWriter) WriteRow(values []interface{}) error {
+	if xw.rows >= XLSXMaxRows {
+		return fmt.Errorf("xlsx exports are limited to %d rows", XLSXMaxRows-1)
+	}
+	xw.rows++
+	fmt.Fprintf(xw.sheet, `<row r="%d">`, xw.rows)
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+			xw.sheet.WriteString(`<c/>`)
+		case float64, uint:
+			fmt.Fprintf(xw.sheet, `<c><v>%s</v></c>`, formatExportValue(v))
+		default:
+			xw.sheet.WriteString(`<c t="inlineStr"><is><t>`)
+			if err := xml.EscapeText(xw.sheet, []byte(formatExportValue(v))); err != nil {
+				return err
+			}
+			xw.sheet.WriteString(`</t></is></c>`)
+		}
+	}
+	_, err := xw.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (xw *xlsxExportWriter) Close() error {
+	xw.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+	return xw.zip.Close()
+}
+
+// parquetExportWriter writes a flat, snappy-compressed Parquet file, flushing a row group every
+// parquetRowGroupSize rows so only one row group is held in memory. Parquet orders the columns
+// of a flat schema by name rather than by the requested column order.
+type parquetExportWriter struct {
+	writer  *parquet.Writer
+	columns []ExportColumn
+	leaves  []parquet.LeafColumn
+	buffer  []parquet.Row
+	pending int
+}
+
+const parquetRowGroupSize = 50000
+
+func newParquetExportWriter(w io.Writer, columns []ExportColumn) *parquetExportWriter {
+	group := parquet.Group{}
+	for _, col := range columns {
+		var node parquet.Node
+		switch col.Kind {
+		case exportKindInt:
+			node = parquet.Int(64)
+		case exportKindFloat:
+			node = parquet.Leaf(parquet.DoubleType)
+		case exportKindTime:
+			node = parquet.Timestamp(parquet.Millisecond)
+		default:
+			node = parquet.String()
+		}
+		if col.Nullable {
+			node = parquet.Optional(node)
+		}
+		group[col.Name] = node
+	}
+
+	schema := parquet.NewSchema("market_data", group)
+	pw := &parquetExportWriter{
+		writer:  parquet.NewWriter(w, schema, parquet.Compression(&snappy.Codec{})),
+		columns: columns,
+		leaves:  make([]parquet.LeafColumn, len(columns)),
+	}
+	for i, col := range columns {
+		pw.leaves[i], _ = schema.Lookup(col.Name)
+	}
+	return pw
+}
+
+func (pw *parquetExportWriter) WriteRow(values []interface{}) error {
+	// Group fields are stored in name order, so place each value at its leaf's column index
+	row := make(parquet.Row, len(values))
+	for i, value := range values {
+		leaf := pw.leaves[i]
+		var v parquet.Value
+		switch x := value.(type) {
+		case nil:
+			v = parquet.NullValue().Level(0, 0, leaf.ColumnIndex)
+			row[leaf.ColumnIndex] = v
+			continue
+		case uint:
+			v = parquet.Int64Value(int64(x))
+		case float64:
+			v = parquet.DoubleValue(x)
+		case time.Time:
+			v = parquet.Int64Value(x.UnixMilli())
+		default:
+			v = parquet.ByteArrayValue([]byte(formatExportValue(x)))
+		}
+		row[leaf.ColumnIndex] = v.Level(0, leaf.MaxDefinitionLevel, leaf.ColumnIndex)
+	}
+
+	pw.buffer = append(pw.buffer, row)
+	if len(pw.buffer) >= 1000 {
+		if err := pw.flushBuffer(); err != nil {
+			return err
+		}
+	}
+	if pw.pending >= parquetRowGroupSize {
+		pw.pending = 0
+		return pw.writer.Flush()
+	}
+	return nil
+}
+
+func (pw *parquetExportWriter) flushBuffer() error {
+	n, err := pw.writer.WriteRows(pw.buffer)
+	pw.pending += n
+	pw.buffer = pw.buffer[:0]
+	return err
+}
+
+func (pw *parquetExportWriter) Close() error {
+	if err := pw.flushBuffer(); err != nil {
+		return err
+	}
+	return pw.writer.Close()
+}
@@ -0,0 +1,470 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/shared/config"
+
+	"gorm.io/gorm"
+)
+
+const (
+	forecastSeasonLength = 52 // Weekly data with yearly seasonality
+	forecastMinWeeks     = 12
+	forecastMaxOrigins   = 12
+	z80                  = 1.2816
+	z95                  = 1.9600
+)
+
+// ModelBacktest is the rolling-origin accuracy of one forecasting model
+type ModelBacktest struct {
+	Model     string   `json:"model"`
+	MAPE      *float64 `json:"mape"` // nil when no origin had an actual to compare
+	Origins   int      `json:"origins"`
+	Forecasts int      `json:"forecasts"`
+}
+
+// forecastFit is a fitted model able to project h steps ahead
+type forecastFit struct {
+	model        string
+	seasonLength int
+	params       map[string]float64
+	sigma        float64 // Standard deviation of one-step in-sample errors
+	project      func(h int) float64
+	spread       func(h int) float64 // Multiplier on sigma for the h-step interval
+}
+
+// ForecastService produces week-ahead price forecasts from market_data history
+type ForecastService struct{}
+
+// NewForecastService creates a new forecast service instance
+func NewForecastService() *ForecastService {
+	return &ForecastService{}
+}
+
+// Run fits the requested model (or the best backtested one for auto), forecasts horizon weeks
+// ahead and stores the run with its points
+func (fs *ForecastService) Run(ctx context.Context, commodity, model string, horizon int, createdBy *uint) (*models.ForecastRun, error) {
+	if horizon < 1 || horizon > 12 {
+		return nil, errors.New("horizon must be between 1 and 12 weeks")
+	}
+	if !models.IsValidForecastModel(model) {
+		return nil, fmt.Errorf("unknown model %q", model)
+	}
+
+	weeks, values, err := weeklySeries(commodity)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) < forecastMinWeeks {
+		return nil, fmt.Errorf("at least %d weeks of %s prices are needed, found %d", forecastMinWeeks, commodity, len(values))
+	}
+
+	backtests := make([]ModelBacktest, 0, 3)
+	best := ""
+	bestMAPE := math.Inf(1)
+	for _, candidate := range []string{models.ForecastModelHoltWinters, models.ForecastModelDrift, models.ForecastModelNaive} {
+		bt := backtestModel(candidate, values, horizon)
+		backtests = append(backtests, bt)
+		if bt.MAPE != nil && *bt.MAPE < bestMAPE {
+			best, bestMAPE = candidate, *bt.MAPE
+		}
+	}
+
+	chosen := model
+	if model == models.ForecastModelAuto {
+		chosen = best
+		if chosen == "" {
+			chosen = models.ForecastModelNaive
+		}
+	}
+
+	fit := fitModel(chosen, values)
+	run := &models.ForecastRun{
+		Commodity:    commodity,
+		Model:        chosen,
+		Requested:    model,
+		Horizon:      horizon,
+		SeasonLength: fit.seasonLength,
+		Observations: len(values),
+		TrainedFrom:  weeks[0],
+		TrainedTo:    weeks[len(weeks)-1],
+		CreatedBy:    createdBy,
+	}
+	for _, bt := range backtests {
+		if bt.Model == chosen {
+			run.BacktestMAPE = bt.MAPE
+		}
+	}
+	if data, err := json.Marshal(fit.params); err == nil {
+		run.Params = data
+	}
+	if data, err := json.Marshal(backtests); err == nil {
+		run.Backtest = data
+	}
+
+	last := weeks[len(weeks)-1]
+	for h := 1; h <= horizon; h++ {
+		point := fit.project(h)
+		width := fit.sigma * fit.spread(h)
+		run.Points = append(run.Points, models.ForecastPoint{
+			Step:       h,
+			TargetWeek: last.AddDate(0, 0, 7*h),
+			Forecast:   roundIndex(point),
+			Lower80:    roundIndex(math.Max(0, point-z80*width)),
+			Upper80:    roundIndex(point + z80*width),
+			Lower95:    roundIndex(math.Max(0, point-z95*width)),
+			Upper95:    roundIndex(point + z95*width),
+		})
+	}
+
+	if err := config.DB.WithContext(ctx).Create(run).Error; err != nil {
+		return nil, fmt.Errorf("failed to store forecast run: %v", err)
+	}
+	return run, nil
+}
+
+// FillActuals records actual weekly prices against points whose target week has completed.
+// It returns the realised MAPE over the points with actuals, or nil if none have traded yet.
+func (fs *ForecastService) FillActuals(ctx context.Context, run *models.ForecastRun) *float64 {
+	now := time.Now()
+	var pending []int
+	for i, p := range run.Points {
+		if p.Actual == nil && now.After(p.TargetWeek.AddDate(0, 0, 7)) {
+			pending = append(pending, i)
+		}
+	}
+
+	if len(pending) > 0 {
+		weeks, values, err := weeklyAverages(run.Commodity, run.Points[pending[0]].TargetWeek)
+		if err == nil {
+			actuals := make(map[string]float64, len(weeks))
+			for i, w := range weeks {
+				actuals[w.Format("2006-01-02")] = values[i]
+			}
+			for _, i := range pending {
+				p := &run.Points[i]
+				actual, ok := actuals[p.TargetWeek.Format("2006-01-02")]
+				if !ok || actual == 0 {
+					continue
+				}
+				actual = roundIndex(actual)
+				errPct := roundIndex((p.Forecast - actual) / actual * 100)
+				p.Actual = &actual
+				p.ErrorPct = &errPct
+				config.DB.WithContext(ctx).Model(p).Updates(map[string]interface{}{"actual": actual, "error_pct": errPct})
+			}
+		}
+	}
+
+	total, count := 0.0, 0
+	for _, p := range run.Points {
+		if p.ErrorPct != nil {
+			total += math.Abs(*p.ErrorPct)
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	mape := roundIndex(total / float64(count))
+	return &mape
+}
+
+// LoadRun fetches a forecast run with its points
+func (fs *ForecastService) LoadRun(id uint) (*models.ForecastRun, error) {
+	var run models.ForecastRun
+	err := config.DB.Preload("Points", func(db *gorm.DB) *gorm.DB {
+		return db.Order("step ASC")
+	}).First(&run, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// weeklySeries returns a commodity's complete weekly average price series up to the last
+// completed week, carrying the previous price forward through weeks without trades
+func weeklySeries(commodity string) ([]time.Time, []float64, error) {
+	weeks, values, err := weeklyAverages(commodity, time.Time{})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(weeks) == 0 {
+		return nil, nil, fmt.Errorf("no %s price history", commodity)
+	}
+
+	// Drop the week in progress so the last observation is a full week
+	current := weekStart(time.Now())
+	if weeks[len(weeks)-1].Equal(current) {
+		weeks, values = weeks[:len(weeks)-1], values[:len(values)-1]
+	}
+	if len(weeks) == 0 {
+		return nil, nil, fmt.Errorf("no completed weeks of %s prices", commodity)
+	}
+
+	var filledWeeks []time.Time
+	var filled []float64
+	for i := range weeks {
+		if i > 0 {
+			for gap := filledWeeks[len(filledWeeks)-1].AddDate(0, 0, 7); gap.Before(weeks[i]); gap = gap.AddDate(0, 0, 7) {
+				filledWeeks = append(filledWeeks, gap)
+				filled = append(filled, filled[len(filled)-1])
+			}
+		}
+		filledWeeks = append(filledWeeks, weeks[i])
+		filled = append(filled, values[i])
+	}
+	return filledWeeks, filled, nil
+}
+
+// weeklyAverages averages a commodity's closes by week (Monday start) from the given date
+func weeklyAverages(commodity string, from time.Time) ([]time.Time, []float64, error) {
+	query := config.DB.Where("commodity = ?", commodity)
+	if !from.IsZero() {
+		query = query.Where("market_date >= ?", from)
+	}
+	var prices []models.MarketData
+	if err := query.Order("market_date ASC").Find(&prices).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load price history: %v", err)
+	}
+
+	sums := make(map[time.Time]float64)
+	counts := make(map[time.Time]int)
+	for _, p := range prices {
+		value := p.Price
+		if p.Close != nil && *p.Close > 0 {
+			value = *p.Close
+		}
+		if value <= 0 {
+			continue
+		}
+		w := weekStart(p.MarketDate)
+		sums[w] += value
+		counts[w]++
+	}
+
+	weeks := make([]time.Time, 0, len(sums))
+	for w := range sums {
+		weeks = append(weeks, w)
+	}
+	sort.Slice(weeks, func(i, j int) bool { return weeks[i].Before(weeks[j]) })
+	values := make([]float64, len(weeks))
+	for i, w := range weeks {
+		values[i] = sums[w] / float64(counts[w])
+	}
+	return weeks, values, nil
+}
+
+// weekStart returns the Monday of t's week
+func weekStart(t time.Time) time.Time {
+	day := indexDay(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// backtestModel refits the model at up to forecastMaxOrigins rolling origins ending before the
+// last observation and scores each h-step forecast that has an actual
+func backtestModel(model string, values []float64, horizon int) ModelBacktest {
+	result := ModelBacktest{Model: model}
+	n := len(values)
+	firstOrigin := n - forecastMaxOrigins
+	if firstOrigin < forecastMinWeeks-2 {
+		firstOrigin = forecastMinWeeks - 2
+	}
+
+	totalAPE := 0.0
+	for origin := firstOrigin; origin < n; origin++ {
+		fit := fitModel(model, values[:origin])
+		scored := false
+		for h := 1; h <= horizon && origin+h-1 < n; h++ {
+			actual := values[origin+h-1]
+			if actual == 0 {
+				continue
+			}
+			totalAPE += math.Abs(actual-fit.project(h)) / math.Abs(actual)
+			result.Forecasts++
+			scored = true
+		}
+		if scored {
+			result.Origins++
+		}
+	}
+
+	if result.Forecasts > 0 {
+		mape := roundIndex(totalAPE / float64(result.Forecasts) * 100)
+		result.MAPE = &mape
+	}
+	return result
+}
+
+// fitModel fits one of the supported models to the series
+func fitModel(model string, values []float64) forecastFit {
+	switch model {
+	case models.ForecastModelHoltWinters:
+		return fitHoltWinters(values)
+	case models.ForecastModelDrift:
+		return fitDrift(values)
+	}
+	return fitNaive(values)
+}
+
+// fitNaive forecasts the last observation; the random-walk interval widens with sqrt(h)
+func fitNaive(values []float64) forecastFit {
+	n := len(values)
+	last := values[n-1]
+	sse, count := 0.0, 0
+	for i := 1; i < n; i++ {
+		e := values[i] - values[i-1]
+		sse += e * e
+		count++
+	}
+	return forecastFit{
+		model:   models.ForecastModelNaive,
+		params:  map[string]float64{},
+		sigma:   residualSigma(sse, count),
+		project: func(h int) float64 { return last },
+		spread:  func(h int) float64 { return math.Sqrt(float64(h)) },
+	}
+}
+
+// fitDrift extends the line between the first and last observations
+func fitDrift(values []float64) forecastFit {
+	n := len(values)
+	last := values[n-1]
+	drift := 0.0
+	if n > 1 {
+		drift = (last - values[0]) / float64(n-1)
+	}
+	sse, count := 0.0, 0
+	for i := 1; i < n; i++ {
+		e := values[i] - values[i-1] - drift
+		sse += e * e
+		count++
+	}
+	observations := float64(n)
+	return forecastFit{
+		model:   models.ForecastModelDrift,
+		params:  map[string]float64{"drift": roundIndex(drift)},
+		sigma:   residualSigma(sse, count),
+		project: func(h int) float64 { return last + float64(h)*drift },
+		spread: func(h int) float64 {
+			return math.Sqrt(float64(h) * (1 + float64(h)/math.Max(observations-1, 1)))
+		},
+	}
+}
+
+// fitHoltWinters fits additive Holt-Winters with a yearly season when at least two seasons
+// of history exist, otherwise Holt's linear trend. Smoothing parameters are chosen by grid
+// search on one-step in-sample squared error. Intervals use the sqrt(h) approximation.
+func fitHoltWinters(values []float64) forecastFit {
+	seasonLength := forecastSeasonLength
+	if len(values) < 2*seasonLength {
+		seasonLength = 0
+	}
+
+	grid := []float64{0.1, 0.3, 0.5, 0.7, 0.9}
+	gammas := []float64{0}
+	if seasonLength > 0 {
+		gammas = []float64{0.05, 0.1, 0.3, 0.5}
+	}
+
+	var best *hwState
+	for _, alpha := range grid {
+		for _, beta := range grid {
+			if beta > alpha {
+				continue
+			}
+			for _, gamma := range gammas {
+				state := runHoltWinters(values, seasonLength, alpha, beta, gamma)
+				if best == nil || state.sse < best.sse {
+					best = state
+				}
+			}
+		}
+	}
+
+	params := map[string]float64{"alpha": best.alpha, "beta": best.beta}
+	if seasonLength > 0 {
+		params["gamma"] = best.gamma
+	}
+	final := best
+	n := len(values)
+	return forecastFit{
+		model:        models.ForecastModelHoltWinters,
+		seasonLength: seasonLength,
+		params:       params,
+		sigma:        residualSigma(final.sse, final.count),
+		project: func(h int) float64 {
+			forecast := final.level + float64(h)*final.trend
+			if seasonLength > 0 {
+				forecast += final.season[(n-1+h)%seasonLength]
+			}
+			return forecast
+		},
+		spread: func(h int) float64 { return math.Sqrt(float64(h)) },
+	}
+}
+
+// hwState is the smoothed state after running Holt-Winters over a series
+type hwState struct {
+	alpha, beta, gamma float64
+	level, trend       float64
+	season             []float64
+	sse                float64
+	count              int
+}
+
+func runHoltWinters(values []float64, m int, alpha, beta, gamma float64) *hwState {
+	state := &hwState{alpha: alpha, beta: beta, gamma: gamma}
+	start := 1
+
+	if m > 0 {
+		first := mean(values[:m])
+		second := mean(values[m : 2*m])
+		state.level = first
+		state.trend = (second - first) / float64(m)
+		state.season = make([]float64, m)
+		for i := 0; i < m; i++ {
+			state.season[i] = values[i] - first
+		}
+		start = m
+	} else {
+		state.level = values[0]
+		if len(values) > 1 {
+			state.trend = values[1] - values[0]
+		}
+	}
+
+	for t := start; t < len(values); t++ {
+		seasonal := 0.0
+		if m > 0 {
+			seasonal = state.season[t%m]
+		}
+		e := values[t] - (state.level + state.trend + seasonal)
+		state.sse += e * e
+		state.count++
+
+		prevLevel := state.level
+		state.level = alpha*(values[t]-seasonal) + (1-alpha)*(state.level+state.trend)
+		state.trend = beta*(state.level-prevLevel) + (1-beta)*state.trend
+		if m > 0 {
+			state.season[t%m] = gamma*(values[t]-state.level) + (1-gamma)*seasonal
+		}
+	}
+	return state
+}
+
+func residualSigma(sse float64, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return math.Sqrt(sse / float64(count))
+}
@@ -0,0 +1,484 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/shared/config"
+
+	"gorm.io/gorm"
+)
+
+// IndexUpdatedEvent is the stream event sent when an intraday index level moves
+const IndexUpdatedEvent = "index.updated"
+
+// IndexComponent is one constituent's contribution to a computed index level
+type IndexComponent struct {
+	Commodity    string  `json:"commodity"`
+	Weight       float64 `json:"weight"` // Normalised so weights sum to 1
+	AnchorPrice  float64 `json:"anchor_price"`
+	Price        float64 `json:"price"`
+	Contribution float64 `json:"contribution"` // Index points attributable to the constituent
+}
+
+// IndexService computes and stores GCX index levels.
+//
+// Levels are chain-linked: between anchors the index moves with the weighted price relatives
+// of its constituents, level(t) = level(anchor) * Σ wᵢ·Pᵢ(t)/Pᵢ(anchor). Anchors are the base
+// date, every rebalancing date and the effective date of each new definition version, so weights
+// reset to their targets on those dates without a jump in the published level.
+type IndexService struct{}
+
+// NewIndexService creates a new index service instance
+func NewIndexService() *IndexService {
+	return &IndexService{}
+}
+
+// Versions returns an index's definition versions, oldest first, with constituents loaded
+func (is *IndexService) Versions(indexID uint) ([]models.IndexDefinitionVersion, error) {
+	var versions []models.IndexDefinitionVersion
+	err := config.DB.Preload("Constituents").
+		Where("index_id = ?", indexID).
+		Order("version ASC").
+		Find(&versions).Error
+	return versions, err
+}
+
+// CurrentVersion returns the definition version in force on the given date
+func (is *IndexService) CurrentVersion(indexID uint, date time.Time) (*models.IndexDefinitionVersion, error) {
+	versions, err := is.Versions(indexID)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, errors.New("index has no definition")
+	}
+	version, _ := activeVersion(versions, indexDay(date))
+	return version, nil
+}
+
+// CreateIndex stores a new index with its first definition version
+func (is *IndexService) CreateIndex(ctx context.Context, index *models.CommodityIndex, version *models.IndexDefinitionVersion) error {
+	index.Code = strings.ToUpper(strings.TrimSpace(index.Code))
+	if index.Code == "" || index.Name == "" {
+		return errors.New("code and name are required")
+	}
+	version.BaseDate = indexDay(version.BaseDate)
+	version.EffectiveFrom = version.BaseDate
+	if err := validateIndexVersion(version); err != nil {
+		return err
+	}
+
+	return config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		tx.Model(&models.CommodityIndex{}).Where("code = ?", index.Code).Count(&count)
+		if count > 0 {
+			return fmt.Errorf("index %s already exists", index.Code)
+		}
+		if err := tx.Create(index).Error; err != nil {
+			return fmt.Errorf("failed to create index: %v", err)
+		}
+		version.IndexID = index.ID
+		version.Version = 1
+		if err := tx.Create(version).Error; err != nil {
+			return fmt.Errorf("failed to create index definition: %v", err)
+		}
+		return nil
+	})
+}
+
+// AddVersion stores a new definition version. It must take effect after the current latest
+// version, and stored levels from its effective date onwards are discarded for recomputation.
+func (is *IndexService) AddVersion(ctx context.Context, index *models.CommodityIndex, version *models.IndexDefinitionVersion) error {
+	versions, err := is.Versions(index.ID)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		return errors.New("index has no base definition")
+	}
+	latest := versions[len(versions)-1]
+
+	version.EffectiveFrom = indexDay(version.EffectiveFrom)
+	version.BaseDate = versions[0].BaseDate
+	version.BaseValue = versions[0].BaseValue
+	if !version.EffectiveFrom.After(latest.EffectiveFrom) {
+		return fmt.Errorf("effective_from must be after %s, when version %d took effect",
+			latest.EffectiveFrom.Format("2006-01-02"), latest.Version)
+	}
+	if err := validateIndexVersion(version); err != nil {
+		return err
+	}
+
+	return config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		version.IndexID = index.ID
+		version.Version = latest.Version + 1
+		if err := tx.Create(version).Error; err != nil {
+			return fmt.Errorf("failed to create index definition: %v", err)
+		}
+		return tx.Where("index_id = ? AND value_date > ?", index.ID, version.EffectiveFrom).
+			Delete(&models.IndexValue{}).Error
+	})
+}
+
+// Compute calculates an index level for a date without storing it. Intraday levels use the
+// latest traded price of each constituent; daily levels use the day's close.
+func (is *IndexService) Compute(index *models.CommodityIndex, date time.Time, intraday bool) (*models.IndexValue, error) {
+	versions, err := is.Versions(index.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, errors.New("index has no definition")
+	}
+	return is.compute(index, versions, indexDay(date), intraday, 0)
+}
+
+// ComputeDaily calculates and stores the daily level for a date
+func (is *IndexService) ComputeDaily(index *models.CommodityIndex, date time.Time) (*models.IndexValue, error) {
+	value, err := is.Compute(index, date, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := is.save(value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Backfill computes daily levels for every weekday in a range, returning how many were stored
+func (is *IndexService) Backfill(index *models.CommodityIndex, from, to time.Time) (int, error) {
+	from, to = indexDay(from), indexDay(to)
+	if to.Before(from) {
+		return 0, errors.New("to must not be before from")
+	}
+	if to.Sub(from) > 5*366*24*time.Hour {
+		return 0, errors.New("backfill range cannot exceed five years")
+	}
+
+	stored := 0
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			continue
+		}
+		if _, err := is.ComputeDaily(index, day); err != nil {
+			return stored, fmt.Errorf("%s: %v", day.Format("2006-01-02"), err)
+		}
+		stored++
+	}
+	return stored, nil
+}
+
+// ComputeAll refreshes today's and yesterday's daily levels for every active index
+func (is *IndexService) ComputeAll() {
+	if config.DB == nil {
+		return
+	}
+
+	var indices []models.CommodityIndex
+	if err := config.DB.Where("is_active = ?", true).Find(&indices).Error; err != nil {
+		log.Printf("Warning: Failed to load indices: %v", err)
+		return
+	}
+
+	today := indexDay(time.Now())
+	for i := range indices {
+		for _, day := range []time.Time{today.AddDate(0, 0, -1), today} {
+			if _, err := is.ComputeDaily(&indices[i], day); err != nil {
+				log.Printf("Warning: Failed to compute %s for %s: %v", indices[i].Code, day.Format("2006-01-02"), err)
+			}
+		}
+	}
+}
+
+// StartDaily periodically recomputes daily index levels from market_data closes
+func (is *IndexService) StartDaily(interval time.Duration) {
+	go func() {
+		is.ComputeAll()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			is.ComputeAll()
+		}
+	}()
+}
+
+// ComputeIntraday recalculates the intraday level of every active index containing the
+// commodity and pushes it to streaming clients. It does nothing when nobody is streaming.
+func (is *IndexService) ComputeIntraday(commodity string) {
+	if config.DB == nil || GetPriceStream().SubscriberCount() == 0 {
+		return
+	}
+
+	var indices []models.CommodityIndex
+	if err := config.DB.Where("is_active = ?", true).Find(&indices).Error; err != nil {
+		log.Printf("Warning: Failed to load indices: %v", err)
+		return
+	}
+
+	today := indexDay(time.Now())
+	for i := range indices {
+		version, err := is.CurrentVersion(indices[i].ID, today)
+		if err != nil || !versionContains(version, commodity) {
+			continue
+		}
+
+		value, err := is.Compute(&indices[i], today, true)
+		if err != nil {
+			log.Printf("Warning: Failed to compute intraday %s: %v", indices[i].Code, err)
+			continue
+		}
+		if err := is.save(value); err != nil {
+			log.Printf("Warning: Failed to store intraday %s: %v", indices[i].Code, err)
+			continue
+		}
+
+		GetPriceStream().Broadcast(StreamMessage{
+			Event: IndexUpdatedEvent,
+			Price: models.MarketData{
+				Commodity:     indices[i].Code,
+				Price:         value.Value,
+				Currency:      indices[i].Currency,
+				Unit:          "points",
+				Change:        value.Change,
+				ChangePercent: value.ChangePct,
+				MarketDate:    value.ComputedAt,
+				Source:        "GCX Index",
+			},
+			Timestamp: value.ComputedAt,
+		})
+	}
+}
+
+// Latest returns the most recent level for an index, preferring today's intraday level when it
+// is newer than the last daily close
+func (is *IndexService) Latest(indexID uint) (*models.IndexValue, error) {
+	var daily models.IndexValue
+	dailyErr := config.DB.Where("index_id = ? AND is_intraday = ?", indexID, false).
+		Order("value_date DESC").
+		First(&daily).Error
+
+	var intraday models.IndexValue
+	intradayErr := config.DB.Where("index_id = ? AND is_intraday = ? AND value_date = ?", indexID, true, indexDay(time.Now())).
+		First(&intraday).Error
+
+	if intradayErr == nil && (dailyErr != nil || intraday.ComputedAt.After(daily.ComputedAt)) {
+		return &intraday, nil
+	}
+	if dailyErr != nil {
+		return nil, dailyErr
+	}
+	return &daily, nil
+}
+
+// compute evaluates the chain-linked level at day, recursing to earlier anchors as needed
+func (is *IndexService) compute(index *models.CommodityIndex, versions []models.IndexDefinitionVersion, day time.Time, intraday bool, depth int) (*models.IndexValue, error) {
+	if depth > 500 {
+		return nil, errors.New("too many chained anchors")
+	}
+	base := versions[0]
+	if day.Before(base.BaseDate) {
+		return nil, fmt.Errorf("date is before the index base date %s", base.BaseDate.Format("2006-01-02"))
+	}
+
+	version, anchor := activeVersion(versions, day)
+
+	anchorLevel := base.BaseValue
+	if day.Equal(base.BaseDate) {
+		anchor = base.BaseDate
+	} else if !anchor.Equal(base.BaseDate) {
+		var stored models.IndexValue
+		err := config.DB.Where("index_id = ? AND value_date = ? AND is_intraday = ?", index.ID, anchor, false).
+			First(&stored).Error
+		if err == nil {
+			anchorLevel = stored.Value
+		} else {
+			prior, err := is.compute(index, versions, anchor, false, depth+1)
+			if err != nil {
+				return nil, fmt.Errorf("anchor %s: %v", anchor.Format("2006-01-02"), err)
+			}
+			if err := is.save(prior); err != nil {
+				return nil, err
+			}
+			anchorLevel = prior.Value
+		}
+	}
+
+	totalWeight := version.TotalWeight()
+	components := make([]IndexComponent, 0, len(version.Constituents))
+	level := 0.0
+	for _, constituent := range version.Constituents {
+		anchorPrice, err := priceAsOf(constituent.Commodity, anchor, false)
+		if err != nil {
+			return nil, err
+		}
+		price, err := priceAsOf(constituent.Commodity, day, intraday)
+		if err != nil {
+			return nil, err
+		}
+
+		weight := constituent.Weight / totalWeight
+		contribution := anchorLevel * weight * price / anchorPrice
+		level += contribution
+		components = append(components, IndexComponent{
+			Commodity:    constituent.Commodity,
+			Weight:       weight,
+			AnchorPrice:  anchorPrice,
+			Price:        price,
+			Contribution: roundIndex(contribution),
+		})
+	}
+
+	value := &models.IndexValue{
+		IndexID:    index.ID,
+		VersionID:  version.ID,
+		ValueDate:  day,
+		IsIntraday: intraday,
+		Value:      roundIndex(level),
+		ComputedAt: time.Now(),
+	}
+
+	var previous models.IndexValue
+	if err := config.DB.Where("index_id = ? AND is_intraday = ? AND value_date < ?", index.ID, false, day).
+		Order("value_date DESC").
+		First(&previous).Error; err == nil && previous.Value != 0 {
+		value.Change = roundIndex(value.Value - previous.Value)
+		value.ChangePct = roundIndex(value.Change / previous.Value * 100)
+	}
+
+	if data, err := json.Marshal(components); err == nil {
+		value.Components = data
+	}
+	return value, nil
+}
+
+// save upserts a computed level on its (index, date, intraday) key
+func (is *IndexService) save(value *models.IndexValue) error {
+	var existing models.IndexValue
+	err := config.DB.Where("index_id = ? AND value_date = ? AND is_intraday = ?",
+		value.IndexID, value.ValueDate, value.IsIntraday).
+		First(&existing).Error
+	if err == nil {
+		value.ID = existing.ID
+		return config.DB.Save(value).Error
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return config.DB.Create(value).Error
+}
+
+// activeVersion picks the version in force on day and the anchor its price relatives are measured
+// from. A version applies from the day after it takes effect, so the effective date itself is the
+// last level under the old basket and the first anchor of the new one.
+func activeVersion(versions []models.IndexDefinitionVersion, day time.Time) (*models.IndexDefinitionVersion, time.Time) {
+	version := &versions[0]
+	for i := 1; i < len(versions); i++ {
+		if versions[i].EffectiveFrom.Before(day) {
+			version = &versions[i]
+		}
+	}
+
+	anchor := version.EffectiveFrom
+	if version.Version == versions[0].Version {
+		anchor = versions[0].BaseDate
+	}
+	for _, rebalance := range version.RebalanceDateList() {
+		if rebalance.After(anchor) && rebalance.Before(day) {
+			anchor = rebalance
+		}
+	}
+	return version, anchor
+}
+
+// priceAsOf returns a commodity's last close (or last traded price intraday) on or before day
+func priceAsOf(commodity string, day time.Time, intraday bool) (float64, error) {
+	var price models.MarketData
+	err := config.DB.Where("commodity = ? AND market_date < ?", commodity, day.AddDate(0, 0, 1)).
+		Order("market_date DESC, id DESC").
+		First(&price).Error
+	if err != nil {
+		return 0, fmt.Errorf("no %s price on or before %s", commodity, day.Format("2006-01-02"))
+	}
+	if !intraday && price.Close != nil && *price.Close > 0 {
+		return *price.Close, nil
+	}
+	if price.Price <= 0 {
+		return 0, fmt.Errorf("invalid %s price on %s", commodity, price.MarketDate.Format("2006-01-02"))
+	}
+	return price.Price, nil
+}
+
+// validateIndexVersion checks a definition's constituents, weights and rebalancing dates
+func validateIndexVersion(version *models.IndexDefinitionVersion) error {
+	if version.BaseDate.IsZero() {
+		return errors.New("base_date is required")
+	}
+	if version.BaseValue <= 0 {
+		return errors.New("base_value must be positive")
+	}
+	if len(version.Constituents) == 0 {
+		return errors.New("at least one constituent is required")
+	}
+
+	seen := make(map[string]bool)
+	for i := range version.Constituents {
+		c := &version.Constituents[i]
+		c.Commodity = strings.TrimSpace(c.Commodity)
+		if c.Commodity == "" {
+			return errors.New("constituent commodity is required")
+		}
+		if seen[c.Commodity] {
+			return fmt.Errorf("commodity %s is listed twice", c.Commodity)
+		}
+		seen[c.Commodity] = true
+		if c.Weight <= 0 {
+			return fmt.Errorf("weight for %s must be positive", c.Commodity)
+		}
+	}
+
+	var dates []string
+	for _, raw := range strings.Split(version.RebalanceDates, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		d, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return fmt.Errorf("invalid rebalance date %q. Use YYYY-MM-DD", raw)
+		}
+		if !d.After(version.EffectiveFrom) {
+			return fmt.Errorf("rebalance date %s must be after %s", raw, version.EffectiveFrom.Format("2006-01-02"))
+		}
+		dates = append(dates, raw)
+	}
+	sort.Strings(dates)
+	version.RebalanceDates = strings.Join(dates, ",")
+	return nil
+}
+
+func versionContains(version *models.IndexDefinitionVersion, commodity string) bool {
+	for _, c := range version.Constituents {
+		if c.Commodity == commodity {
+			return true
+		}
+	}
+	return false
+}
+
+// indexDay truncates a time to its calendar date
+func indexDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func roundIndex(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/shared/config"
+	shared_models "gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/token"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrganizationInviteLifetime is how long an invite to join an organisation can be accepted
+const OrganizationInviteLifetime = 7 * 24 * time.Hour
+
+var (
+	ErrAlreadyInOrganization       = errors.New("user already belongs to an organisation")
+	ErrAlreadyMember               = errors.New("user is already a member of the organisation")
+	ErrNoOrganizationSubscription  = errors.New("organisation has no active subscription")
+	ErrNoSeatsAvailable            = errors.New("all seats of the organisation's plan are taken")
+	ErrInvalidOrganizationInvite   = errors.New("invalid or expired invite")
+	ErrOrganizationInviteMismatch  = errors.New("invite was sent to a different email address")
+	ErrLastOrganizationAdmin       = errors.New("organisation must keep at least one admin")
+	ErrOrganizationMemberNotFound  = errors.New("member not found")
+	ErrInvalidOrganizationRoleName = errors.New("role must be admin or member")
+)
+
+var slugInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// SeatUsage is how many of an organisation's paid seats are taken. Pending invites hold a seat
+// until they are accepted, revoked or expire.
+type SeatUsage struct {
+	Limit          int   `json:"limit"` // Zero without an active subscription
+	Members        int64 `json:"members"`
+	PendingInvites int64 `json:"pending_invites"`
+	Available      int   `json:"available"`
+}
+
+// OrganizationService handles organisation membership, invites and seats
+type OrganizationService struct{}
+
+// NewOrganizationService creates a new organisation service instance
+func NewOrganizationService() *OrganizationService {
+	return &OrganizationService{}
+}
+
+// Membership returns the user's membership, or gorm.ErrRecordNotFound if they have no organisation
+func (os *OrganizationService) Membership(userID uint) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	if err := config.DB.Where("user_id = ?", userID).First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// Create sets up an organisation with the user as its first admin
+func (os *OrganizationService) Create(ctx context.Context, name string, user *shared_models.User) (*models.Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	org := models.Organization{Name: name, CreatedByID: user.ID}
+	err := config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		tx.Model(&models.OrganizationMember{}).Where("user_id = ?", user.ID).Count(&count)
+		if count > 0 {
+			return ErrAlreadyInOrganization
+		}
+
+		org.Slug = uniqueSlug(tx, name)
+		if err := tx.Create(&org).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OrganizationMember{
+			OrganizationID: org.ID,
+			UserID:         user.ID,
+			Role:           models.OrganizationRoleAdmin,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+// uniqueSlug derives a URL-safe slug from a name, numbering it if it is taken
+func uniqueSlug(tx *gorm.DB, name string) string {
+	base := strings.Trim(slugInvalid.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if base == "" {
+		base = "organization"
+	}
+	slug := base
+	for i := 2; ; i++ {
+		var count int64
+		tx.Model(&models.Organization{}).Where("slug = ?", slug).Count(&count)
+		if count == 0 {
+			return slug
+		}
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// Seats counts the seats an organisation's active subscription pays for and how many are taken
+func (os *OrganizationService) Seats(orgID uint) (SeatUsage, error) {
+	return seats(config.DB, orgID)
+}
+
+func seats(tx *gorm.DB, orgID uint) (SeatUsage, error) {
+	var usage SeatUsage
+	if subscription, err := organizationSubscription(tx, orgID); err == nil {
+		usage.Limit = subscription.Plan.MaxUsers
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return usage, err
+	}
+
+	if err := tx.Model(&models.OrganizationMember{}).
+		Where("organization_id = ?", orgID).
+		Count(&usage.Members).Error; err != nil {
+		return usage, err
+	}
+	if err := tx.Model(&models.OrganizationInvite{}).
+		Where("organization_id = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", orgID, time.Now()).
+		Count(&usage.PendingInvites).Error; err != nil {
+		return usage, err
+	}
+	usage.Available = max(usage.Limit-int(usage.Members+usage.PendingInvites), 0)
+	return usage, nil
+}
+
+// Invite asks someone to join the organisation, replacing any pending invite for the same
+// email. It needs a free seat and returns the raw token to email to the invitee.
+func (os *OrganizationService) Invite(ctx context.Context, orgID uint, email, role string, invitedBy uint) (*models.OrganizationInvite, string, error) {
+	email = strings.TrimSpace(email)
+	if role == "" {
+		role = models.OrganizationRoleMember
+	}
+	if !models.IsValidOrganizationRole(role) {
+		return nil, "", ErrInvalidOrganizationRoleName
+	}
+
+	raw, err := token.NewOpaque(32)
+	if err != nil {
+		return nil, "", err
+	}
+	invite := models.OrganizationInvite{
+		OrganizationID: orgID,
+		Email:          email,
+		Role:           role,
+		TokenHash:      token.Hash(raw),
+		InvitedByID:    invitedBy,
+		ExpiresAt:      time.Now().Add(OrganizationInviteLifetime),
+	}
+
+	err = config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockOrganization(tx, orgID); err != nil {
+			return err
+		}
+
+		var members int64
+		tx.Model(&models.OrganizationMember{}).
+			Joins("JOIN users ON users.id = organization_members.user_id").
+			Where("organization_members.organization_id = ? AND LOWER(users.email) = LOWER(?)", orgID, email).
+			Count(&members)
+		if members > 0 {
+			return ErrAlreadyMember
+		}
+
+		// A new invite for the same email takes over the seat of the old one
+		if err := tx.Model(&models.OrganizationInvite{}).
+			Where("organization_id = ? AND LOWER(email) = LOWER(?) AND accepted_at IS NULL AND revoked_at IS NULL", orgID, email).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		usage, err := seats(tx, orgID)
+		if err != nil {
+			return err
+		}
+		if usage.Limit == 0 {
+			return ErrNoOrganizationSubscription
+		}
+		if usage.Available == 0 {
+			return ErrNoSeatsAvailable
+		}
+		return tx.Create(&invite).Error
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return &invite, raw, nil
+}
+
+// Accept joins the user to the organisation of an invite sent to their email address
+func (os *OrganizationService) Accept(ctx context.Context, rawToken string, user *shared_models.User) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	err := config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var invite models.OrganizationInvite
+		if err := tx.Where("token_hash = ?", token.Hash(rawToken)).First(&invite).Error; err != nil || !invite.IsPending() {
+			return ErrInvalidOrganizationInvite
+		}
+		if !strings.EqualFold(invite.Email, user.Email) {
+			return ErrOrganizationInviteMismatch
+		}
+		if err := lockOrganization(tx, invite.OrganizationID); err != nil {
+			return err
+		}
+
+		var count int64
+		tx.Model(&models.OrganizationMember{}).Where("user_id = ?", user.ID).Count(&count)
+		if count > 0 {
+			return ErrAlreadyInOrganization
+		}
+
+		// The invite held a seat, but the plan may have shrunk or lapsed since
+		usage, err := seats(tx, invite.OrganizationID)
+		if err != nil {
+			return err
+		}
+		if usage.Limit == 0 {
+			return ErrNoOrganizationSubscription
+		}
+		if usage.Members >= int64(usage.Limit) {
+			return ErrNoSeatsAvailable
+		}
+
+		result := tx.Model(&models.OrganizationInvite{}).
+			Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", invite.ID).
+			Update("accepted_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidOrganizationInvite
+		}
+
+		member = models.OrganizationMember{
+			OrganizationID: invite.OrganizationID,
+			UserID:         user.ID,
+			Role:           invite.Role,
+			InvitedByID:    &invite.InvitedByID,
+		}
+		return tx.Create(&member).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// SetRole makes a member an admin or a plain member. The last admin cannot be demoted.
+func (os *OrganizationService) SetRole(ctx context.Context, orgID, userID uint, role string) (*models.OrganizationMember, string, error) {
+	if !models.IsValidOrganizationRole(role) {
+		return nil, "", ErrInvalidOrganizationRoleName
+	}
+
+	var member models.OrganizationMember
+	var previous string
+	err := config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockOrganization(tx, orgID); err != nil {
+			return err
+		}
+		if err := tx.Where("organization_id = ? AND user_id = ?", orgID, userID).First(&member).Error; err != nil {
+			return ErrOrganizationMemberNotFound
+		}
+		previous = member.Role
+		if previous == role {
+			return nil
+		}
+		if previous == models.OrganizationRoleAdmin {
+			if err := keepAnAdmin(tx, orgID); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&member).Update("role", role).Error
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return &member, previous, nil
+}
+
+// RemoveMember takes a user out of the organisation, freeing their seat. The last admin
+// cannot be removed.
+func (os *OrganizationService) RemoveMember(ctx context.Context, orgID, userID uint) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	err := config.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockOrganization(tx, orgID); err != nil {
+			return err
+		}
+		if err := tx.Where("organization_id = ? AND user_id = ?", orgID, userID).First(&member).Error; err != nil {
+			return ErrOrganizationMemberNotFound
+		}
+		if member.Role == models.OrganizationRoleAdmin {
+			if err := keepAnAdmin(tx, orgID); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&member).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// lockOrganization locks the organisation's row for the rest of the transaction, so that changes
+// to its members and invites are made one at a time and each sees the seats and admins the
+// last one left
+func lockOrganization(tx *gorm.DB, orgID uint) error {
+	var org models.Organization
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&org, orgID).Error
+}
+
+// keepAnAdmin fails unless the organisation has an admin besides the one about to go
+func keepAnAdmin(tx *gorm.DB, orgID uint) error {
+	var admins int64
+	if err := tx.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND role = ?", orgID, models.OrganizationRoleAdmin).
+		Count(&admins).Error; err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return ErrLastOrganizationAdmin
+	}
+	return nil
+}
@@ -49,20 +49,101 @@ func (ps *PriceService) GetCommodityPrices(commodity string, days int) ([]models
 	return prices, nil
 }
 
-// GetHistoricalPrices returns historical prices with date range
-func (ps *PriceService) GetHistoricalPrices(commodity string, startDate, endDate time.Time) ([]models.MarketData, error) {
+// GetHistoricalPrices returns historical prices with date range, optionally for one contract series
+func (ps *PriceService) GetHistoricalPrices(commodity, series string, startDate, endDate time.Time) ([]models.MarketData, error) {
 	var prices []models.MarketData
 
 	query := config.DB.Where("commodity = ? AND market_date BETWEEN ? AND ?",
 		commodity, startDate, endDate)
+	if series != "" {
+		query = query.Where("contract_series = ?", series)
+	}
 
-	if err := query.Order("market_date ASC").Find(&prices).Error; err != nil {
+	if err := query.Order("market_date ASC, id ASC").Find(&prices).Error; err != nil {
 		return nil, err
 	}
 
 	return prices, nil
 }
 
+// Candle intervals
+const (
+	CandleHour  = "hour"
+	CandleDay   = "day"
+	CandleWeek  = "week"
+	CandleMonth = "month"
+)
+
+// Candle is the open, high, low and close of a commodity's prices over one interval
+type Candle struct {
+	Start  time.Time `json:"start"`
+	Open   float64   `json:"open"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Close  float64   `json:"close"`
+	Volume float64   `json:"volume"`
+	Count  int       `json:"count"`
+}
+
+// GetCandles aggregates historical prices into OHLC candles. A record's own open, high, low and
+// close are used when set, otherwise its price stands for all four.
+func (ps *PriceService) GetCandles(commodity, series, interval string, startDate, endDate time.Time) ([]Candle, error) {
+	prices, err := ps.GetHistoricalPrices(commodity, series, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var candles []Candle
+	for _, p := range prices {
+		start := candleStart(p.MarketDate.UTC(), interval)
+		open, high, low, closing := p.Price, p.Price, p.Price, p.Price
+		if p.Open != nil {
+			open = *p.Open
+		}
+		if p.High != nil {
+			high = *p.High
+		}
+		if p.Low != nil {
+			low = *p.Low
+		}
+		if p.Close != nil {
+			closing = *p.Close
+		}
+
+		if n := len(candles); n == 0 || !candles[n-1].Start.Equal(start) {
+			candles = append(candles, Candle{Start: start, Open: open, High: high, Low: low})
+		}
+		c := &candles[len(candles)-1]
+		if high > c.High {
+			c.High = high
+		}
+		if low < c.Low {
+			c.Low = low
+		}
+		c.Close = closing
+		if p.Volume != nil {
+			c.Volume += *p.Volume
+		}
+		c.Count++
+	}
+
+	return candles, nil
+}
+
+// candleStart truncates a time to the start of its candle interval; weeks start on Monday
+func candleStart(t time.Time, interval string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case CandleHour:
+		return t.Truncate(time.Hour)
+	case CandleWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case CandleMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
 // GetPriceSummary returns price summary for a commodity
 func (ps *PriceService) GetPriceSummary(commodity string) (*models.MarketData, error) {
 	var summary models.MarketData
@@ -80,9 +161,17 @@ func (ps *PriceService) GetPriceSummary(commodity string) (*models.MarketData, e
 func (ps *PriceService) UpdatePrice(price *models.MarketData) error {
 	if price.ID == 0 {
 		// Create new price record
-		return config.DB.Create(price).Error
+		if err := config.DB.Create(price).Error; err != nil {
+			return err
+		}
+		PublishPriceEvent(models.WebhookEventPriceCreated, price)
+		return nil
 	}
 
 	// Update existing price record
-	return config.DB.Save(price).Error
+	if err := config.DB.Save(price).Error; err != nil {
+		return err
+	}
+	PublishPriceEvent(models.WebhookEventPriceCorrected, price)
+	return nil
 }
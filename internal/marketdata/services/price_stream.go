@@ -0,0 +1,160 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"gcx-cms/internal/marketdata/models"
+)
+
+// StreamMessage is a single update pushed to streaming clients
+type StreamMessage struct {
+	Event     string            `json:"event"`
+	Price     models.MarketData `json:"price"`
+	Timestamp time.Time         `json:"timestamp"`
+	// Replay marks historical data re-played by an admin replay session rather than live prices
+	Replay        bool   `json:"replay"`
+	ReplaySession string `json:"replay_session,omitempty"`
+}
+
+// StreamMode selects whether a subscriber receives live prices, replayed prices or both
+type StreamMode int
+
+const (
+	StreamLiveOnly StreamMode = iota
+	StreamLiveAndReplay
+	StreamReplayOnly
+)
+
+// ParseStreamMode reads the ?replay= query value: exclude (default), include or only
+func ParseStreamMode(value string) (StreamMode, bool) {
+	switch value {
+	case "", "exclude":
+		return StreamLiveOnly, true
+	case "include":
+		return StreamLiveAndReplay, true
+	case "only":
+		return StreamReplayOnly, true
+	}
+	return StreamLiveOnly, false
+}
+
+// accepts checks if a subscriber in this mode should receive the message
+func (m StreamMode) accepts(msg StreamMessage) bool {
+	switch m {
+	case StreamLiveOnly:
+		return !msg.Replay
+	case StreamReplayOnly:
+		return msg.Replay
+	}
+	return true
+}
+
+// streamSubscriber is a connected client's filter and mode
+type streamSubscriber struct {
+	filter StreamFilter
+	mode   StreamMode
+}
+
+// StreamFilter restricts a subscription to a set of commodities and the prices a watchlist
+// covers; an empty filter receives everything
+type StreamFilter struct {
+	commodities map[string]bool
+	watchlist   *WatchlistScope
+}
+
+// Matches checks if a price passes the filter
+func (f StreamFilter) Matches(price *models.MarketData) bool {
+	if len(f.commodities) == 0 && f.watchlist == nil {
+		return true
+	}
+	return f.commodities[price.Commodity] ||
+		(f.watchlist != nil && f.watchlist.Matches(price.Commodity, price.ContractSeries))
+}
+
+// NewStreamFilter builds a filter from a list of commodities and, unless it is nil, a watchlist
+func NewStreamFilter(commodities []string, watchlist *WatchlistScope) StreamFilter {
+	filter := StreamFilter{commodities: make(map[string]bool), watchlist: watchlist}
+	for _, c := range commodities {
+		if c != "" {
+			filter.commodities[c] = true
+		}
+	}
+	return filter
+}
+
+// PriceStream fans out price updates to connected streaming clients
+type PriceStream struct {
+	mu          sync.RWMutex
+	subscribers map[chan StreamMessage]streamSubscriber
+}
+
+var priceStream = &PriceStream{
+	subscribers: make(map[chan StreamMessage]streamSubscriber),
+}
+
+// GetPriceStream returns the shared price stream hub
+func GetPriceStream() *PriceStream {
+	return priceStream
+}
+
+// Subscribe registers a client and returns its message channel and an unsubscribe function
+func (ps *PriceStream) Subscribe(filter StreamFilter, mode StreamMode) (<-chan StreamMessage, func()) {
+	ch := make(chan StreamMessage, 64)
+
+	ps.mu.Lock()
+	ps.subscribers[ch] = streamSubscriber{filter: filter, mode: mode}
+	ps.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			ps.mu.Lock()
+			delete(ps.subscribers, ch)
+			ps.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Broadcast sends a message to every matching subscriber. Slow clients miss updates rather than block writers.
+func (ps *PriceStream) Broadcast(msg StreamMessage) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	for ch, sub := range ps.subscribers {
+		if !sub.mode.accepts(msg) || !sub.filter.Matches(&msg.Price) {
+			continue
+		}
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+}
+
+// SubscriberCount returns the number of connected clients receiving live prices
+func (ps *PriceStream) SubscriberCount() int {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	count := 0
+	for _, sub := range ps.subscribers {
+		if sub.mode != StreamReplayOnly {
+			count++
+		}
+	}
+	return count
+}
+
+// PublishPriceEvent notifies streaming clients, webhook subscribers and price alerts of a price write,
+// and moves the intraday level of any index the commodity belongs to
+func PublishPriceEvent(eventType string, price *models.MarketData) {
+	GetPriceStream().Broadcast(StreamMessage{
+		Event:     eventType,
+		Price:     *price,
+		Timestamp: time.Now(),
+	})
+	GetWebhookService().Publish(eventType, price)
+	NewAlertService().Evaluate(price)
+	go NewIndexService().ComputeIntraday(price.Commodity)
+}
@@ -0,0 +1,342 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/token"
+
+	"gorm.io/gorm"
+)
+
+// Replay session states
+const (
+	ReplayRunning   = "running"
+	ReplayPaused    = "paused"
+	ReplayStopped   = "stopped"
+	ReplayCompleted = "completed"
+	ReplayFailed    = "failed"
+)
+
+// ReplayPriceEvent is the stream event used for replayed prices
+const ReplayPriceEvent = "replay.price"
+
+const replayBatchSize = 500
+
+// ReplayOptions describes what a replay session plays back and how fast
+type ReplayOptions struct {
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"` // Inclusive day
+	Commodities []string  `json:"commodities"`
+	Speed       float64   `json:"speed"`           // Market time elapses Speed times faster than wall time
+	MaxGapSecs  int       `json:"max_gap_seconds"` // Longest real pause between two rows, e.g. across nights
+	Loop        bool      `json:"loop"`            // Restart from the beginning when finished (GCX TV loops)
+}
+
+func (o ReplayOptions) maxGap() time.Duration {
+	return time.Duration(o.MaxGapSecs) * time.Second
+}
+
+// ReplaySession is a running or finished replay
+type ReplaySession struct {
+	ID         string        `json:"id"`
+	Options    ReplayOptions `json:"options"`
+	Status     string        `json:"status"`
+	Position   *time.Time    `json:"position"` // market_date of the last row sent
+	Sent       int           `json:"sent"`
+	Loops      int           `json:"loops"`
+	StartedBy  uint          `json:"started_by"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+	Error      string        `json:"error,omitempty"`
+
+	mu      sync.Mutex
+	resume  chan struct{}
+	stop    chan struct{}
+	stopped bool
+}
+
+// ReplayManager runs replay sessions that push historical market_data rows through the price
+// stream, marked as replay so they are never mistaken for live prices
+type ReplayManager struct {
+	mu       sync.RWMutex
+	sessions map[string]*ReplaySession
+}
+
+var replayManager = &ReplayManager{
+	sessions: make(map[string]*ReplaySession),
+}
+
+// GetReplayManager returns the shared replay manager
+func GetReplayManager() *ReplayManager {
+	return replayManager
+}
+
+// Start validates the options and begins a replay session
+func (rm *ReplayManager) Start(opts ReplayOptions, startedBy uint) (*ReplaySession, error) {
+	opts.From = indexDay(opts.From)
+	opts.To = indexDay(opts.To)
+	if opts.From.IsZero() || opts.To.Before(opts.From) {
+		return nil, errors.New("end date must not be before start date")
+	}
+	if opts.Speed <= 0 || opts.Speed > 100000 {
+		return nil, errors.New("speed must be between 0 and 100000")
+	}
+	if opts.MaxGapSecs <= 0 {
+		opts.MaxGapSecs = 10
+	}
+
+	var total int64
+	if err := rm.query(opts).Model(&models.MarketData{}).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count market data: %v", err)
+	}
+	if total == 0 {
+		return nil, errors.New("no market data in the selected range")
+	}
+
+	id, err := token.NewOpaque(8)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create replay session: %v", err)
+	}
+	session := &ReplaySession{
+		ID:        id,
+		Options:   opts,
+		Status:    ReplayRunning,
+		StartedBy: startedBy,
+		StartedAt: time.Now(),
+		resume:    make(chan struct{}),
+		stop:      make(chan struct{}),
+	}
+
+	rm.mu.Lock()
+	rm.sessions[session.ID] = session
+	rm.mu.Unlock()
+
+	go rm.run(session)
+	return session, nil
+}
+
+// Get returns a session by ID
+func (rm *ReplayManager) Get(id string) (*ReplaySession, bool) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	session, ok := rm.sessions[id]
+	return session, ok
+}
+
+// List returns all sessions, newest first
+func (rm *ReplayManager) List() []*ReplaySession {
+	rm.mu.RLock()
+	sessions := make([]*ReplaySession, 0, len(rm.sessions))
+	for _, s := range rm.sessions {
+		sessions = append(sessions, s)
+	}
+	rm.mu.RUnlock()
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].StartedAt.After(sessions[j].StartedAt) })
+	return sessions
+}
+
+// Pause holds a running session at its current position
+func (rm *ReplayManager) Pause(id string) (*ReplaySession, error) {
+	session, ok := rm.Get(id)
+	if !ok {
+		return nil, errors.New("replay session not found")
+	}
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.Status != ReplayRunning {
+		return nil, fmt.Errorf("cannot pause a %s session", session.Status)
+	}
+	session.Status = ReplayPaused
+	return session, nil
+}
+
+// Resume continues a paused session
+func (rm *ReplayManager) Resume(id string) (*ReplaySession, error) {
+	session, ok := rm.Get(id)
+	if !ok {
+		return nil, errors.New("replay session not found")
+	}
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.Status != ReplayPaused {
+		return nil, fmt.Errorf("cannot resume a %s session", session.Status)
+	}
+	session.Status = ReplayRunning
+	close(session.resume)
+	session.resume = make(chan struct{})
+	return session, nil
+}
+
+// Stop ends a running or paused session
+func (rm *ReplayManager) Stop(id string) (*ReplaySession, error) {
+	session, ok := rm.Get(id)
+	if !ok {
+		return nil, errors.New("replay session not found")
+	}
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.Status != ReplayRunning && session.Status != ReplayPaused {
+		return nil, fmt.Errorf("session is already %s", session.Status)
+	}
+	session.finish(ReplayStopped, "")
+	return session, nil
+}
+
+// Snapshot returns a copy of the session safe to serialise while it runs
+func (s *ReplaySession) Snapshot() *ReplaySession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &ReplaySession{
+		ID:         s.ID,
+		Options:    s.Options,
+		Status:     s.Status,
+		Position:   s.Position,
+		Sent:       s.Sent,
+		Loops:      s.Loops,
+		StartedBy:  s.StartedBy,
+		StartedAt:  s.StartedAt,
+		FinishedAt: s.FinishedAt,
+		Error:      s.Error,
+	}
+}
+
+// finish moves the session to a terminal state; the caller holds s.mu
+func (s *ReplaySession) finish(status, errMsg string) {
+	if s.stopped {
+		return
+	}
+	now := time.Now()
+	s.Status = status
+	s.Error = errMsg
+	s.FinishedAt = &now
+	s.stopped = true
+	close(s.stop)
+}
+
+// run plays the session's rows in batches, pacing them by their market_date gaps
+func (rm *ReplayManager) run(session *ReplaySession) {
+	opts := session.Options
+	for {
+		var lastTime time.Time
+		var lastID uint
+
+		for {
+			var rows []models.MarketData
+			query := rm.query(opts)
+			if lastID != 0 {
+				query = query.Where("(market_date > ? OR (market_date = ? AND id > ?))", lastTime, lastTime, lastID)
+			}
+			if err := query.Order("market_date ASC, id ASC").Limit(replayBatchSize).Find(&rows).Error; err != nil {
+				log.Printf("Warning: Replay %s failed to load market data: %v", session.ID, err)
+				session.mu.Lock()
+				session.finish(ReplayFailed, err.Error())
+				session.mu.Unlock()
+				return
+			}
+			if len(rows) == 0 {
+				break
+			}
+
+			for i := range rows {
+				row := rows[i]
+				if lastID != 0 && !session.wait(pacing(row.MarketDate.Sub(lastTime), opts)) {
+					return
+				}
+				lastTime, lastID = row.MarketDate, row.ID
+
+				GetPriceStream().Broadcast(StreamMessage{
+					Event:         ReplayPriceEvent,
+					Price:         row,
+					Timestamp:     time.Now(),
+					Replay:        true,
+					ReplaySession: session.ID,
+				})
+
+				session.mu.Lock()
+				position := row.MarketDate
+				session.Position = &position
+				session.Sent++
+				session.mu.Unlock()
+			}
+		}
+
+		session.mu.Lock()
+		if !opts.Loop || session.stopped {
+			session.finish(ReplayCompleted, "")
+			session.mu.Unlock()
+			return
+		}
+		session.Loops++
+		session.mu.Unlock()
+		if !session.wait(opts.maxGap()) {
+			return
+		}
+	}
+}
+
+// wait sleeps for d while honouring pause and stop. It returns false once the session is stopped.
+func (s *ReplaySession) wait(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	elapsed := false
+
+	for {
+		s.mu.Lock()
+		stopped, paused := s.stopped, s.Status == ReplayPaused
+		resume, stop := s.resume, s.stop
+		s.mu.Unlock()
+
+		if stopped {
+			return false
+		}
+		if paused {
+			select {
+			case <-resume:
+				continue
+			case <-stop:
+				return false
+			}
+		}
+		if elapsed {
+			return true
+		}
+
+		select {
+		case <-timer.C:
+			elapsed = true
+		case <-stop:
+			return false
+		case <-time.After(250 * time.Millisecond):
+			// Re-check for a pause requested mid-wait
+		}
+	}
+}
+
+// pacing converts a market-time gap into the real delay before the next row
+func pacing(gap time.Duration, opts ReplayOptions) time.Duration {
+	if gap <= 0 {
+		return 0
+	}
+	delay := time.Duration(float64(gap) / opts.Speed)
+	if delay > opts.maxGap() {
+		return opts.maxGap()
+	}
+	return delay
+}
+
+// query selects the market_data rows a replay covers
+func (rm *ReplayManager) query(opts ReplayOptions) *gorm.DB {
+	query := config.DB.Where("market_date >= ? AND market_date < ?", opts.From, opts.To.AddDate(0, 0, 1))
+	if len(opts.Commodities) > 0 {
+		query = query.Where("commodity IN ?", opts.Commodities)
+	}
+	return query
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	cms_models "gcx-cms/internal/cms/models"
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/shared/config"
+)
+
+// Seasonality periods
+const (
+	SeasonalityByMonth = "month"
+	SeasonalityByWeek  = "week"
+)
+
+// SeasonalBucket is the price profile of one calendar month or ISO week across past years
+type SeasonalBucket struct {
+	Bucket       int      `json:"bucket"` // Month 1-12 or ISO week 1-53
+	Label        string   `json:"label"`
+	Years        int      `json:"years"` // Past years with prices in this bucket
+	Mean         float64  `json:"mean"`
+	P10          float64  `json:"p10"`
+	P25          float64  `json:"p25"`
+	Median       float64  `json:"median"`
+	P75          float64  `json:"p75"`
+	P90          float64  `json:"p90"`
+	SeasonalIdx  float64  `json:"seasonal_index"` // Average of bucket price / that year's mean price, x100
+	IsHarvest    bool     `json:"is_harvest"`
+	Current      *float64 `json:"current"`       // Current year's average price in the bucket
+	DeviationPct *float64 `json:"deviation_pct"` // Current vs the seasonal median
+	Flag         string   `json:"flag,omitempty"`
+}
+
+// SeasonalityReport is the seasonal profile of a commodity with the current year overlaid
+type SeasonalityReport struct {
+	Commodity     string           `json:"commodity"`
+	Period        string           `json:"period"`
+	YearsUsed     []int            `json:"years_used"`
+	CurrentYear   int              `json:"current_year"`
+	HarvestSeason string           `json:"harvest_season"`
+	HarvestMonths []int            `json:"harvest_months"`
+	Buckets       []SeasonalBucket `json:"buckets"`
+	Latest        *SeasonalBucket  `json:"latest"` // Most recent bucket with a current-year price
+}
+
+// SeasonalityService computes seasonal price profiles from market_data history
+type SeasonalityService struct{}
+
+// NewSeasonalityService creates a new seasonality service instance
+func NewSeasonalityService() *SeasonalityService {
+	return &SeasonalityService{}
+}
+
+// Analyze builds the seasonal profile of a commodity. Past years (up to maxYears, 0 for all)
+// form the norm; the current year is compared against its percentile bands.
+func (ss *SeasonalityService) Analyze(commodity, period string, maxYears int) (*SeasonalityReport, error) {
+	if period != SeasonalityByMonth && period != SeasonalityByWeek {
+		return nil, errors.New("period must be month or week")
+	}
+
+	now := time.Now()
+	query := config.DB.Where("commodity = ?", commodity)
+	if maxYears > 0 {
+		query = query.Where("market_date >= ?", time.Date(now.Year()-maxYears, 1, 1, 0, 0, 0, 0, time.UTC))
+	}
+	var prices []models.MarketData
+	if err := query.Order("market_date ASC, id ASC").Find(&prices).Error; err != nil {
+		return nil, fmt.Errorf("failed to load price history: %v", err)
+	}
+
+	// Average closes per (year, bucket); ISO weeks are keyed by their ISO year
+	type key struct{ year, bucket int }
+	sums := make(map[key]float64)
+	counts := make(map[key]int)
+	for _, p := range prices {
+		value := p.Price
+		if p.Close != nil && *p.Close > 0 {
+			value = *p.Close
+		}
+		if value <= 0 {
+			continue
+		}
+		year, bucket := seasonalBucket(p.MarketDate, period)
+		k := key{year, bucket}
+		sums[k] += value
+		counts[k]++
+	}
+
+	currentYear, _ := seasonalBucket(now, period)
+	byBucket := make(map[int][]float64)
+	relative := make(map[int][]float64)
+	yearTotals := make(map[int]float64)
+	yearCounts := make(map[int]int)
+	current := make(map[int]float64)
+	for k, sum := range sums {
+		avg := sum / float64(counts[k])
+		if k.year == currentYear {
+			current[k.bucket] = avg
+			continue
+		}
+		yearTotals[k.year] += avg
+		yearCounts[k.year]++
+	}
+	if len(yearTotals) == 0 {
+		return nil, fmt.Errorf("no %s price history before %d to build a seasonal norm", commodity, currentYear)
+	}
+	for k, sum := range sums {
+		if k.year == currentYear {
+			continue
+		}
+		avg := sum / float64(counts[k])
+		byBucket[k.bucket] = append(byBucket[k.bucket], avg)
+		relative[k.bucket] = append(relative[k.bucket], avg/(yearTotals[k.year]/float64(yearCounts[k.year]))*100)
+	}
+
+	report := &SeasonalityReport{
+		Commodity:   commodity,
+		Period:      period,
+		CurrentYear: currentYear,
+	}
+	for year := range yearTotals {
+		report.YearsUsed = append(report.YearsUsed, year)
+	}
+	sort.Ints(report.YearsUsed)
+
+	harvest := make(map[time.Month]bool)
+	if info := findCMSCommodity(commodity); info != nil {
+		report.HarvestSeason = info.HarvestSeason
+		for _, m := range info.HarvestMonths() {
+			harvest[m] = true
+			report.HarvestMonths = append(report.HarvestMonths, int(m))
+		}
+	}
+
+	buckets := 12
+	if period == SeasonalityByWeek {
+		buckets = 53
+	}
+	for b := 1; b <= buckets; b++ {
+		values := byBucket[b]
+		if len(values) == 0 {
+			continue
+		}
+		sort.Float64s(values)
+
+		bucket := SeasonalBucket{
+			Bucket:      b,
+			Label:       seasonalLabel(b, period),
+			Years:       len(values),
+			Mean:        roundIndex(mean(values)),
+			P10:         roundIndex(percentile(values, 10)),
+			P25:         roundIndex(percentile(values, 25)),
+			Median:      roundIndex(percentile(values, 50)),
+			P75:         roundIndex(percentile(values, 75)),
+			P90:         roundIndex(percentile(values, 90)),
+			SeasonalIdx: roundIndex(mean(relative[b])),
+			IsHarvest:   harvest[seasonalMonth(b, period, currentYear)],
+		}
+
+		if value, ok := current[b]; ok {
+			value = roundIndex(value)
+			deviation := roundIndex((value - bucket.Median) / bucket.Median * 100)
+			bucket.Current = &value
+			bucket.DeviationPct = &deviation
+			bucket.Flag = seasonalFlag(value, bucket)
+		}
+		report.Buckets = append(report.Buckets, bucket)
+	}
+
+	for i := len(report.Buckets) - 1; i >= 0; i-- {
+		if report.Buckets[i].Current != nil {
+			latest := report.Buckets[i]
+			report.Latest = &latest
+			break
+		}
+	}
+	return report, nil
+}
+
+// findCMSCommodity matches a market_data commodity to its CMS record by code or name
+func findCMSCommodity(commodity string) *cms_models.Commodity {
+	var info cms_models.Commodity
+	name := strings.ToLower(strings.TrimSpace(commodity))
+	if err := config.DB.Where("LOWER(code) = ? OR LOWER(name) = ?", name, name).First(&info).Error; err != nil {
+		return nil
+	}
+	return &info
+}
+
+// seasonalBucket returns the year and bucket (month or ISO week) a date falls in
+func seasonalBucket(t time.Time, period string) (int, int) {
+	if period == SeasonalityByWeek {
+		return t.ISOWeek()
+	}
+	return t.Year(), int(t.Month())
+}
+
+// seasonalMonth maps a bucket to the calendar month it mostly falls in
+func seasonalMonth(bucket int, period string, year int) time.Month {
+	if period == SeasonalityByWeek {
+		// The Thursday of ISO week 1 is always in January; count forward from it
+		jan4 := time.Date(year, 1, 4, 0, 0, 0, 0, time.UTC)
+		thursday := jan4.AddDate(0, 0, int(time.Thursday-jan4.Weekday()))
+		return thursday.AddDate(0, 0, 7*(bucket-1)).Month()
+	}
+	return time.Month(bucket)
+}
+
+func seasonalLabel(bucket int, period string) string {
+	if period == SeasonalityByWeek {
+		return fmt.Sprintf("W%02d", bucket)
+	}
+	return time.Month(bucket).String()
+}
+
+// seasonalFlag places a current-year price within the bucket's percentile bands
+func seasonalFlag(value float64, bucket SeasonalBucket) string {
+	switch {
+	case value > bucket.P90:
+		return "well_above_norm"
+	case value > bucket.P75:
+		return "above_norm"
+	case value < bucket.P10:
+		return "well_below_norm"
+	case value < bucket.P25:
+		return "below_norm"
+	}
+	return "within_norm"
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values))
+}
+
+// percentile interpolates linearly between the closest ranks of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
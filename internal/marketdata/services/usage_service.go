@@ -0,0 +1,480 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/config"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// quotaWarnRatio is the share of a daily quota after which an upgrade is suggested
+const quotaWarnRatio = 0.8
+
+// UsageMeter counts market data requests per user. Counts are buffered in memory and added
+// to usage_records and UserDataAccess.RequestCount periodically, so metering adds no database
+// write to the request path.
+type UsageMeter struct {
+	mu      sync.Mutex
+	pending map[usageKey]int64
+	quotas  map[quotaKey]*quotaState
+}
+
+type usageKey struct {
+	userID    uint
+	day       string
+	endpoint  string
+	commodity string
+	dataType  string
+}
+
+type quotaKey struct {
+	userID   uint
+	dataType string
+}
+
+// quotaState is today's request count against one UserDataAccess row
+type quotaState struct {
+	accessID uint // Zero when the user has no quota for the data type
+	limit    int
+	day      string
+	count    int // Including those not yet flushed
+	unsaved  int // Requests since the last flush
+	saving   int // Requests being flushed now
+	loadedAt time.Time
+}
+
+// QuotaStatus reports a user's use of a daily quota
+type QuotaStatus struct {
+	DataType  string             `json:"data_type"`
+	Used      int                `json:"used"`
+	Limit     int                `json:"limit"` // Zero means unlimited
+	Remaining int                `json:"remaining"`
+	ResetsAt  time.Time          `json:"resets_at"`
+	Upgrade   *UpgradeSuggestion `json:"upgrade_suggestion,omitempty"`
+}
+
+// UpgradeSuggestion points a user near a quota at a plan with more headroom
+type UpgradeSuggestion struct {
+	PlanID   uint    `json:"plan_id"`
+	PlanName string  `json:"plan_name"`
+	Price    float64 `json:"price"`
+	Currency string  `json:"currency"`
+	Reason   string  `json:"reason"`
+}
+
+var (
+	usageMeter     *UsageMeter
+	usageMeterOnce sync.Once
+)
+
+// GetUsageMeter returns the process-wide usage meter
+func GetUsageMeter() *UsageMeter {
+	usageMeterOnce.Do(func() {
+		usageMeter = &UsageMeter{
+			pending: make(map[usageKey]int64),
+			quotas:  make(map[quotaKey]*quotaState),
+		}
+	})
+	return usageMeter
+}
+
+// usageDay is the UTC day usage is counted against
+func usageDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// Check returns the user's quota status for a data type without counting a request
+func (um *UsageMeter) Check(userID uint, dataType string) (QuotaStatus, error) {
+	state, err := um.quota(userID, dataType)
+	if err != nil {
+		return QuotaStatus{}, err
+	}
+
+	um.mu.Lock()
+	defer um.mu.Unlock()
+	return um.status(dataType, state), nil
+}
+
+// Reserve takes one request from the user's daily quota for a data type, unless it is used up.
+// It reports the status with the request counted, and whether it was. Give it back with Refund
+// if the request then fails.
+func (um *UsageMeter) Reserve(userID uint, dataType string) (QuotaStatus, bool, error) {
+	state, err := um.quota(userID, dataType)
+	if err != nil {
+		return QuotaStatus{}, false, err
+	}
+
+	um.mu.Lock()
+	defer um.mu.Unlock()
+	if status := um.status(dataType, state); status.Exceeded() {
+		return status, false, nil
+	}
+	state.count++
+	state.unsaved++
+	return um.status(dataType, state), true, nil
+}
+
+// Refund gives back a request Reserve took, for a request that failed
+func (um *UsageMeter) Refund(userID uint, dataType string) {
+	um.mu.Lock()
+	defer um.mu.Unlock()
+
+	state, ok := um.quotas[quotaKey{userID: userID, dataType: dataType}]
+	if !ok || state.day != usageDay(time.Now()) || state.count == 0 {
+		return
+	}
+	state.count--
+	state.unsaved--
+}
+
+// Record counts one request in the usage reports. Quotas are charged by Reserve.
+func (um *UsageMeter) Record(userID uint, dataType, endpoint, commodity string) {
+	um.mu.Lock()
+	defer um.mu.Unlock()
+
+	now := time.Now()
+	um.pending[usageKey{userID: userID, day: usageDay(now), endpoint: endpoint, commodity: commodity, dataType: dataType}]++
+}
+
+// quota returns the cached quota state, loading it on first use, on a new day or every few
+// minutes so limit changes by admins and requests counted by other instances are picked up.
+// The database is read without um.mu held; the state's fields are read and written with it.
+func (um *UsageMeter) quota(userID uint, dataType string) (*quotaState, error) {
+	key := quotaKey{userID: userID, dataType: dataType}
+	today := usageDay(time.Now())
+
+	um.mu.Lock()
+	state, ok := um.quotas[key]
+	fresh := ok && state.day == today && time.Since(state.loadedAt) < 5*time.Minute
+	um.mu.Unlock()
+	if fresh {
+		return state, nil
+	}
+
+	var access models.UserDataAccess
+	if err := config.DB.Where("user_id = ? AND data_type = ?", userID, dataType).Limit(1).Find(&access).Error; err != nil {
+		return nil, err
+	}
+
+	um.mu.Lock()
+	defer um.mu.Unlock()
+	state, ok = um.quotas[key]
+	if !ok || state.day != today {
+		state = &quotaState{day: today}
+		um.quotas[key] = state
+	}
+	state.accessID = access.ID
+	state.limit = access.MaxRequests
+	// Counts already flushed today, by this instance or others, and those still to be
+	state.count = state.unsaved + state.saving
+	if access.ID != 0 && usageDay(access.LastReset) == today {
+		state.count += access.RequestCount
+	}
+	state.loadedAt = time.Now()
+	return state, nil
+}
+
+// status builds a QuotaStatus from a quota state
+func (um *UsageMeter) status(dataType string, state *quotaState) QuotaStatus {
+	day, _ := time.Parse("2006-01-02", state.day)
+	status := QuotaStatus{
+		DataType: dataType,
+		Used:     state.count,
+		Limit:    state.limit,
+		ResetsAt: day.AddDate(0, 0, 1),
+	}
+	if state.limit > 0 {
+		status.Remaining = state.limit - state.count
+		if status.Remaining < 0 {
+			status.Remaining = 0
+		}
+	}
+	return status
+}
+
+// Exceeded reports whether a quota status has no requests left
+func (qs QuotaStatus) Exceeded() bool {
+	return qs.Limit > 0 && qs.Used >= qs.Limit
+}
+
+// NearLimit reports whether a quota is used past the warning ratio
+func (qs QuotaStatus) NearLimit() bool {
+	return qs.Limit > 0 && float64(qs.Used) >= float64(qs.Limit)*quotaWarnRatio
+}
+
+// Quotas returns the status of every quota the user has, with upgrade suggestions for those
+// being hit
+func (um *UsageMeter) Quotas(userID uint) ([]QuotaStatus, error) {
+	var accesses []models.UserDataAccess
+	if err := config.DB.Where("user_id = ?", userID).Order("data_type ASC").Find(&accesses).Error; err != nil {
+		return nil, err
+	}
+
+	statuses := make([]QuotaStatus, 0, len(accesses))
+	for _, access := range accesses {
+		status, err := um.Check(userID, access.DataType)
+		if err != nil {
+			return nil, err
+		}
+		if status.NearLimit() {
+			status.Upgrade = SuggestUpgrade(userID, status)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// SuggestUpgrade finds the cheapest active plan above the user's current one, which may be their
+// organisation's, that offers the data type with a higher limit than the user has now, or nil
+// if there is none
+func SuggestUpgrade(userID uint, status QuotaStatus) *UpgradeSuggestion {
+	var currentPrice float64
+	if subscription, _, err := ActiveSubscription(userID); err == nil {
+		currentPrice = subscription.Plan.Price
+	}
+
+	var plans []models.SubscriptionPlan
+	if err := config.DB.Where("is_active = ? AND price > ?", true, currentPrice).
+		Order("price ASC").
+		Find(&plans).Error; err != nil || len(plans) == 0 {
+		return nil
+	}
+
+	planIDs := make([]uint, len(plans))
+	for i, plan := range plans {
+		planIDs[i] = plan.ID
+	}
+	var features []models.SubscriptionFeature
+	config.DB.Where("plan_id IN ? AND name IN ? AND is_enabled = ?",
+		planIDs, featureNames(status.DataType), true).
+		Find(&features)
+	featureByPlan := make(map[uint]models.SubscriptionFeature, len(features))
+	for _, feature := range features {
+		featureByPlan[feature.PlanID] = feature
+	}
+
+	for _, plan := range plans {
+		feature, ok := featureByPlan[plan.ID]
+		if !ok || (feature.Limit != nil && *feature.Limit <= status.Limit) {
+			continue
+		}
+		reason := fmt.Sprintf("You have used %d of %d %s requests today", status.Used, status.Limit, status.DataType)
+		if feature.Limit == nil {
+			reason += fmt.Sprintf("; %s has no daily limit", plan.Name)
+		} else {
+			reason += fmt.Sprintf("; %s allows %d", plan.Name, *feature.Limit)
+		}
+		return &UpgradeSuggestion{
+			PlanID:   plan.ID,
+			PlanName: plan.Name,
+			Price:    plan.Price,
+			Currency: plan.Currency,
+			Reason:   reason,
+		}
+	}
+	return nil
+}
+
+// Flush writes buffered usage to the database, as the system. Failed writes are put back to
+// retry on the next flush.
+func (um *UsageMeter) Flush() {
+	audit.System("usage metering", func(ctx context.Context) error {
+		um.flush(config.DB.WithContext(ctx))
+		return nil
+	})
+}
+
+func (um *UsageMeter) flush(db *gorm.DB) {
+	um.mu.Lock()
+	pending := um.pending
+	um.pending = make(map[usageKey]int64)
+	type counter struct {
+		state *quotaState
+		day   string
+		added int
+	}
+	var counters []counter
+	for _, state := range um.quotas {
+		if state.accessID != 0 && state.unsaved != 0 {
+			counters = append(counters, counter{state: state, day: state.day, added: state.unsaved})
+			state.saving += state.unsaved
+			state.unsaved = 0
+		}
+	}
+	um.mu.Unlock()
+
+	failed := make(map[usageKey]int64)
+	for key, count := range pending {
+		day, _ := time.Parse("2006-01-02", key.day)
+		record := models.UsageRecord{
+			UserID:    key.userID,
+			Day:       day,
+			Endpoint:  key.endpoint,
+			Commodity: key.commodity,
+			DataType:  key.dataType,
+			Count:     count,
+		}
+		if err := db.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}, {Name: "day"}, {Name: "endpoint"}, {Name: "commodity"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"count":      gorm.Expr("usage_records.count + ?", count),
+				"updated_at": time.Now(),
+			}),
+		}).Create(&record).Error; err != nil {
+			log.Printf("Warning: Failed to flush usage for user %d: %v", key.userID, err)
+			failed[key] = count
+		}
+	}
+
+	for _, c := range counters {
+		err := addRequestCount(db, c.state.accessID, c.day, c.added)
+		if err != nil {
+			log.Printf("Warning: Failed to update request count of data access %d: %v", c.state.accessID, err)
+		}
+		um.mu.Lock()
+		c.state.saving -= c.added
+		if err != nil {
+			c.state.unsaved += c.added
+		}
+		um.mu.Unlock()
+	}
+
+	if len(failed) > 0 {
+		um.mu.Lock()
+		for key, count := range failed {
+			um.pending[key] += count
+		}
+		um.mu.Unlock()
+	}
+}
+
+// addRequestCount adds requests made on a day to a UserDataAccess row's count, starting the
+// count again if the row was last reset on an earlier day. Both are conditional updates, so
+// instances flushing at the same time each add their own requests; those of a day the row has
+// already moved past are dropped.
+func addRequestCount(db *gorm.DB, accessID uint, day string, added int) error {
+	start, _ := time.Parse("2006-01-02", day)
+	end := start.AddDate(0, 0, 1)
+	for attempt := 0; attempt < 2; attempt++ {
+		result := db.Model(&models.UserDataAccess{}).
+			Where("id = ? AND last_reset >= ? AND last_reset < ?", accessID, start, end).
+			UpdateColumn("request_count", gorm.Expr("request_count + ?", added))
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
+
+		result = db.Model(&models.UserDataAccess{}).
+			Where("id = ? AND (last_reset < ? OR last_reset IS NULL)", accessID, start).
+			UpdateColumns(map[string]interface{}{
+				"request_count": max(added, 0),
+				"last_reset":    start,
+			})
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
+	}
+	return nil
+}
+
+// Start flushes buffered usage on an interval
+func (um *UsageMeter) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			um.Flush()
+		}
+	}()
+}
+
+// UsageBreakdown is request counts grouped by day, endpoint and commodity
+type UsageBreakdown struct {
+	Total       int64            `json:"total"`
+	ByDay       []UsageGroup     `json:"by_day"`
+	ByEndpoint  []UsageGroup     `json:"by_endpoint"`
+	ByCommodity []UsageGroup     `json:"by_commodity"`
+	ByUser      []UsageUserGroup `json:"by_user,omitempty"`
+}
+
+// UsageGroup is the request count of one group
+type UsageGroup struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// UsageUserGroup is one user's request count in an aggregate report
+type UsageUserGroup struct {
+	UserID uint   `json:"user_id"`
+	Email  string `json:"email"`
+	Count  int64  `json:"count"`
+}
+
+// Breakdown summarises flushed and buffered usage since a day, for one user or, with userID
+// zero, for everyone with the top users included
+func (um *UsageMeter) Breakdown(userID uint, since time.Time) (*UsageBreakdown, error) {
+	um.Flush()
+
+	scope := func() *gorm.DB {
+		query := config.DB.Model(&models.UsageRecord{}).Where("day >= ?", since)
+		if userID != 0 {
+			query = query.Where("user_id = ?", userID)
+		}
+		return query
+	}
+
+	breakdown := &UsageBreakdown{}
+	if err := scope().Select("COALESCE(SUM(count), 0)").Scan(&breakdown.Total).Error; err != nil {
+		return nil, err
+	}
+
+	groups := []struct {
+		column string
+		into   *[]UsageGroup
+		order  string
+	}{
+		{"day", &breakdown.ByDay, "day ASC"},
+		{"endpoint", &breakdown.ByEndpoint, "count DESC"},
+		{"commodity", &breakdown.ByCommodity, "count DESC"},
+	}
+	for _, g := range groups {
+		var rows []struct {
+			GroupKey string
+			Count    int64
+		}
+		if err := scope().Select(g.column + " AS group_key, SUM(count) AS count").
+			Group(g.column).
+			Order(g.order).
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		*g.into = make([]UsageGroup, len(rows))
+		for i, row := range rows {
+			key := row.GroupKey
+			if g.column == "day" && len(key) > 10 {
+				key = key[:10]
+			}
+			(*g.into)[i] = UsageGroup{Key: key, Count: row.Count}
+		}
+	}
+
+	if userID == 0 {
+		if err := scope().Select("usage_records.user_id, users.email, SUM(usage_records.count) AS count").
+			Joins("LEFT JOIN users ON users.id = usage_records.user_id").
+			Group("usage_records.user_id, users.email").
+			Order("count DESC").
+			Limit(50).
+			Scan(&breakdown.ByUser).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return breakdown, nil
+}
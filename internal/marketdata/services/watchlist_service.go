@@ -0,0 +1,338 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	cms_models "gcx-cms/internal/cms/models"
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/shared/config"
+
+	"gorm.io/gorm"
+)
+
+// WatchlistEntry is the row of a watchlist summary for a commodity, or one of its contract types
+type WatchlistEntry struct {
+	Commodity       string              `json:"commodity"`
+	ContractTypeID  *uint               `json:"contract_type_id,omitempty"`
+	ContractType    string              `json:"contract_type,omitempty"`
+	LatestPrice     *models.MarketData  `json:"latest_price"`
+	Change          float64             `json:"change"`
+	ChangePercent   float64             `json:"change_percent"`
+	Sparkline       []SparklinePoint    `json:"sparkline"`
+	TriggeredAlerts []models.PriceAlert `json:"triggered_alerts"`
+}
+
+// SparklinePoint is a single daily close used to draw a sparkline
+type SparklinePoint struct {
+	Date  time.Time `json:"date"`
+	Price float64   `json:"price"`
+}
+
+// WatchlistService handles business logic for user watchlists
+type WatchlistService struct{}
+
+// NewWatchlistService creates a new watchlist service instance
+func NewWatchlistService() *WatchlistService {
+	return &WatchlistService{}
+}
+
+// FindForUser loads a watchlist with its items, ensuring it belongs to the user.
+// A zero watchlistID selects the user's default watchlist, falling back to their first one.
+func (ws *WatchlistService) FindForUser(userID uint, watchlistID uint) (*models.Watchlist, error) {
+	var watchlist models.Watchlist
+	query := config.DB.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order ASC, id ASC")
+	}).Where("user_id = ?", userID)
+
+	if watchlistID != 0 {
+		query = query.Where("id = ?", watchlistID)
+	} else {
+		query = query.Order("is_default DESC, sort_order ASC, id ASC")
+	}
+
+	if err := query.First(&watchlist).Error; err != nil {
+		return nil, fmt.Errorf("watchlist not found: %v", err)
+	}
+	return &watchlist, nil
+}
+
+// WatchlistScope is the market data a watchlist covers: every price of the commodities on it
+// alone, and for those narrowed to contract types, the prices of those types' listed series.
+// Price alerts are set on commodities, so a contract type covers its commodity's alerts, though
+// only those a price of its series triggered count as triggered for it.
+type WatchlistScope struct {
+	Commodities []string            // Distinct commodities on the watchlist, in item order
+	whole       map[string]bool     // Commodities on it without a contract type
+	prefixes    map[string][]string // Series symbol prefixes of its contract types, by commodity
+}
+
+// Matches checks if a price of a commodity, from a series or none, is covered
+func (s *WatchlistScope) Matches(commodity string, series *string) bool {
+	if s.whole[commodity] {
+		return true
+	}
+	if series == nil {
+		return false
+	}
+	for _, prefix := range s.prefixes[commodity] {
+		if strings.HasPrefix(*series, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Where narrows a market_data query to the prices covered
+func (s *WatchlistScope) Where(query *gorm.DB) *gorm.DB {
+	var conditions []string
+	var args []interface{}
+	if len(s.whole) > 0 {
+		commodities := make([]string, 0, len(s.whole))
+		for commodity := range s.whole {
+			commodities = append(commodities, commodity)
+		}
+		conditions = append(conditions, "commodity IN ?")
+		args = append(args, commodities)
+	}
+	for commodity, prefixes := range s.prefixes {
+		if s.whole[commodity] {
+			continue
+		}
+		series := make([]string, len(prefixes))
+		args = append(args, commodity)
+		for i, prefix := range prefixes {
+			series[i] = "contract_series LIKE ?"
+			args = append(args, prefix+"%")
+		}
+		conditions = append(conditions, "(commodity = ? AND ("+strings.Join(series, " OR ")+"))")
+	}
+	if len(conditions) == 0 {
+		return query.Where("1 = 0")
+	}
+	return query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
+
+// Scope returns what a user's watchlist covers, for use as a price, stream or alert filter
+func (ws *WatchlistService) Scope(userID uint, watchlistID uint) (*WatchlistScope, error) {
+	watchlist, err := ws.FindForUser(userID, watchlistID)
+	if err != nil {
+		return nil, err
+	}
+	contractTypes, err := watchlistContractTypes(watchlist.Items)
+	if err != nil {
+		return nil, err
+	}
+	return newWatchlistScope(watchlist.Items, contractTypes), nil
+}
+
+// ValidateItems checks that every contract type on the items exists and is one of the item's
+// commodity, matched by its code or name as market data names commodities
+func (ws *WatchlistService) ValidateItems(items []models.WatchlistItem) error {
+	contractTypes, err := watchlistContractTypes(items)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.ContractTypeID == nil {
+			continue
+		}
+		ct, ok := contractTypes[*item.ContractTypeID]
+		if !ok {
+			return fmt.Errorf("contract type %d not found", *item.ContractTypeID)
+		}
+		if !strings.EqualFold(item.Commodity, ct.Commodity.Code) && !strings.EqualFold(item.Commodity, ct.Commodity.Name) {
+			return fmt.Errorf("contract type %d is not a contract type of %s", *item.ContractTypeID, item.Commodity)
+		}
+	}
+	return nil
+}
+
+// watchlistContractTypes loads the contract types on watchlist items, with their commodities,
+// by ID
+func watchlistContractTypes(items []models.WatchlistItem) (map[uint]cms_models.CommodityContractType, error) {
+	var ids []uint
+	for _, item := range items {
+		if item.ContractTypeID != nil {
+			ids = append(ids, *item.ContractTypeID)
+		}
+	}
+	byID := make(map[uint]cms_models.CommodityContractType, len(ids))
+	if len(ids) == 0 {
+		return byID, nil
+	}
+
+	var contractTypes []cms_models.CommodityContractType
+	if err := config.DB.Preload("Commodity").Where("id IN ?", ids).Find(&contractTypes).Error; err != nil {
+		return nil, fmt.Errorf("failed to load contract types: %v", err)
+	}
+	for _, ct := range contractTypes {
+		byID[ct.ID] = ct
+	}
+	return byID, nil
+}
+
+// newWatchlistScope builds the scope of watchlist items. An item whose contract type has since
+// been deleted covers nothing.
+func newWatchlistScope(items []models.WatchlistItem, contractTypes map[uint]cms_models.CommodityContractType) *WatchlistScope {
+	scope := &WatchlistScope{whole: make(map[string]bool), prefixes: make(map[string][]string)}
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if !seen[item.Commodity] {
+			seen[item.Commodity] = true
+			scope.Commodities = append(scope.Commodities, item.Commodity)
+		}
+		if item.ContractTypeID == nil {
+			scope.whole[item.Commodity] = true
+		} else if ct, ok := contractTypes[*item.ContractTypeID]; ok {
+			scope.prefixes[item.Commodity] = append(scope.prefixes[item.Commodity], cms_models.SeriesPrefix(ct.Commodity.Code, ct.Code))
+		}
+	}
+	return scope
+}
+
+// Summary builds latest price, change, sparkline and triggered alerts for every item on a
+// watchlist, a commodity or one of its contract types
+func (ws *WatchlistService) Summary(watchlist *models.Watchlist, sparklineDays int) ([]WatchlistEntry, error) {
+	if sparklineDays <= 0 {
+		sparklineDays = 30
+	}
+
+	entries := make([]WatchlistEntry, 0, len(watchlist.Items))
+	if len(watchlist.Items) == 0 {
+		return entries, nil
+	}
+	contractTypes, err := watchlistContractTypes(watchlist.Items)
+	if err != nil {
+		return nil, err
+	}
+	scope := newWatchlistScope(watchlist.Items, contractTypes)
+
+	// Price history for all items in one query
+	var history []models.MarketData
+	if err := scope.Where(config.DB).Where("market_date >= ?", time.Now().AddDate(0, 0, -sparklineDays)).
+		Order("market_date ASC, created_at ASC").
+		Find(&history).Error; err != nil {
+		return nil, fmt.Errorf("failed to load price history: %v", err)
+	}
+
+	// Triggered alerts for all commodities in one query
+	var alerts []models.PriceAlert
+	if err := config.DB.Where("user_id = ? AND commodity IN ? AND triggered_at IS NOT NULL",
+		watchlist.UserID, scope.Commodities).
+		Order("triggered_at DESC").
+		Find(&alerts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load price alerts: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	var scopes []*WatchlistScope
+	for _, item := range watchlist.Items {
+		entry := WatchlistEntry{
+			Commodity:       item.Commodity,
+			ContractTypeID:  item.ContractTypeID,
+			Sparkline:       []SparklinePoint{},
+			TriggeredAlerts: []models.PriceAlert{},
+		}
+		key := item.Commodity
+		if item.ContractTypeID != nil {
+			key = fmt.Sprintf("%s:%d", item.Commodity, *item.ContractTypeID)
+			entry.ContractType = contractTypes[*item.ContractTypeID].Name
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		itemScope := newWatchlistScope([]models.WatchlistItem{item}, contractTypes)
+
+		// Keep the last record of each day; history is ordered so later rows win
+		byDay := make(map[string]int)
+		for i := range history {
+			p := history[i]
+			if !itemScope.Matches(p.Commodity, p.ContractSeries) {
+				continue
+			}
+			day := p.MarketDate.Format("2006-01-02")
+			point := SparklinePoint{Date: p.MarketDate, Price: p.Price}
+			if idx, ok := byDay[day]; ok {
+				entry.Sparkline[idx] = point
+			} else {
+				byDay[day] = len(entry.Sparkline)
+				entry.Sparkline = append(entry.Sparkline, point)
+			}
+			entry.LatestPrice = &history[i]
+		}
+
+		for _, alert := range alerts {
+			if itemScope.Matches(alert.Commodity, alert.TriggeredSeries) {
+				entry.TriggeredAlerts = append(entry.TriggeredAlerts, alert)
+			}
+		}
+
+		entries = append(entries, entry)
+		scopes = append(scopes, itemScope)
+	}
+
+	if err := latestOutsideWindow(entries, scopes); err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].LatestPrice != nil {
+			entries[i].Change = entries[i].LatestPrice.Change
+			entries[i].ChangePercent = entries[i].LatestPrice.ChangePercent
+		}
+	}
+
+	return entries, nil
+}
+
+// latestOutsideWindow fills in the most recent price of entries with none in the sparkline
+// window, in one query: the rows of the latest market date of each commodity and series they
+// cover, whenever they were written
+func latestOutsideWindow(entries []WatchlistEntry, scopes []*WatchlistScope) error {
+	var missing []*WatchlistScope
+	combined := &WatchlistScope{whole: make(map[string]bool), prefixes: make(map[string][]string)}
+	for i := range entries {
+		if entries[i].LatestPrice != nil {
+			continue
+		}
+		missing = append(missing, scopes[i])
+		for commodity := range scopes[i].whole {
+			combined.whole[commodity] = true
+		}
+		for commodity, prefixes := range scopes[i].prefixes {
+			combined.prefixes[commodity] = append(combined.prefixes[commodity], prefixes...)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	latest := combined.Where(config.DB.Model(&models.MarketData{})).
+		Select("commodity, contract_series, MAX(market_date) AS market_date").
+		Group("commodity, contract_series")
+	var prices []models.MarketData
+	if err := config.DB.Joins(`JOIN (?) latest ON latest.commodity = market_data.commodity
+		AND latest.market_date = market_data.market_date
+		AND (latest.contract_series = market_data.contract_series OR (latest.contract_series IS NULL AND market_data.contract_series IS NULL))`, latest).
+		Order("market_data.market_date ASC, market_data.id ASC").
+		Find(&prices).Error; err != nil {
+		return fmt.Errorf("failed to load latest prices: %v", err)
+	}
+
+	// Prices are ordered so the latest market date wins, and the last row written that day
+	m := 0
+	for i := range entries {
+		if entries[i].LatestPrice != nil {
+			continue
+		}
+		for j := range prices {
+			if missing[m].Matches(prices[j].Commodity, prices[j].ContractSeries) {
+				entries[i].LatestPrice = &prices[j]
+			}
+		}
+		m++
+	}
+	return nil
+}
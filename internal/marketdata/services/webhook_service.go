@@ -0,0 +1,432 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/config"
+	shared_models "gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/token"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	webhookMaxAttempts   = 8
+	webhookBaseBackoff   = 30 * time.Second
+	webhookMaxBackoff    = 6 * time.Hour
+	webhookPollInterval  = 15 * time.Second
+	webhookBatchSize     = 50
+	webhookResponseLimit = 200 // Characters of the response kept for troubleshooting
+)
+
+// ErrWebhookAddressNotAllowed is returned for webhook URLs that lead to loopback, private,
+// link-local or other non-public addresses
+var ErrWebhookAddressNotAllowed = errors.New("webhook URL must lead to a public address")
+
+// blockedWebhookNetworks are reserved ranges not covered by the net.IP predicates
+var blockedWebhookNetworks = func() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range []string{
+		"0.0.0.0/8",     // This network
+		"100.64.0.0/10", // Carrier-grade NAT
+		"192.0.0.0/24",  // IETF protocol assignments
+		"198.18.0.0/15", // Benchmarking
+		"240.0.0.0/4",   // Reserved, and broadcast
+		"64:ff9b::/96",  // NAT64, which reaches IPv4 addresses
+	} {
+		_, network, _ := net.ParseCIDR(cidr)
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+// WebhookEvent is the envelope POSTed to subscriber endpoints
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookService fans out market events to subscriber endpoints with signed, retried deliveries
+type WebhookService struct {
+	client *http.Client
+	wake   chan struct{}
+	once   sync.Once
+}
+
+var webhookService = &WebhookService{
+	client: &http.Client{
+		Timeout: 10 * time.Second,
+		// Addresses are checked where the connection is made, so that a name resolving to a
+		// public address when registered cannot be pointed at an internal one later
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialPublicWebhookAddress,
+			TLSHandshakeTimeout: 5 * time.Second,
+		},
+		// A redirect would be followed through the same dialer, but subscribers have no reason to send one
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	},
+	wake: make(chan struct{}, 1),
+}
+
+// GetWebhookService returns the shared webhook service instance
+func GetWebhookService() *WebhookService {
+	return webhookService
+}
+
+// Start launches the background delivery worker. Safe to call more than once.
+func (ws *WebhookService) Start() {
+	ws.once.Do(func() {
+		go ws.run()
+		log.Println("✅ Webhook delivery worker started")
+	})
+}
+
+// Publish records a delivery for every active subscription registered for the event type.
+// Failures are logged rather than returned so the write that triggered the event is never rolled back.
+func (ws *WebhookService) Publish(eventType string, data interface{}) {
+	if config.DB == nil {
+		return
+	}
+
+	var subscriptions []models.WebhookSubscription
+	if err := config.DB.Where("is_active = ?", true).Find(&subscriptions).Error; err != nil {
+		log.Printf("Warning: Failed to load webhook subscriptions: %v", err)
+		return
+	}
+
+	eventID, err := token.NewOpaque(16)
+	if err != nil {
+		log.Printf("Warning: Failed to create webhook event %s: %v", eventType, err)
+		return
+	}
+	event := WebhookEvent{
+		ID:        eventID,
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Warning: Failed to encode webhook event %s: %v", eventType, err)
+		return
+	}
+
+	now := time.Now()
+	queued := 0
+	entitled := map[uint]bool{}
+	for _, sub := range subscriptions {
+		if !sub.Subscribes(eventType) {
+			continue
+		}
+		if _, checked := entitled[sub.UserID]; !checked {
+			entitled[sub.UserID] = webhookOwnerEntitled(sub.UserID)
+		}
+		if !entitled[sub.UserID] {
+			continue
+		}
+		delivery := models.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        event.ID,
+			EventType:      eventType,
+			Payload:        datatypes.JSON(payload),
+			Status:         models.WebhookDeliveryPending,
+			NextAttemptAt:  &now,
+		}
+		if err := config.DB.Create(&delivery).Error; err != nil {
+			log.Printf("Warning: Failed to queue webhook delivery for subscription %d: %v", sub.ID, err)
+			continue
+		}
+		queued++
+	}
+
+	if queued > 0 {
+		ws.kick()
+	}
+}
+
+// Redeliver queues a fresh delivery of a previous event, keeping the original event ID
+func (ws *WebhookService) Redeliver(original *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	now := time.Now()
+	originalID := original.ID
+	delivery := models.WebhookDelivery{
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         models.WebhookDeliveryPending,
+		NextAttemptAt:  &now,
+		RedeliveryOf:   &originalID,
+	}
+	if err := config.DB.Create(&delivery).Error; err != nil {
+		return nil, fmt.Errorf("failed to queue redelivery: %v", err)
+	}
+	ws.kick()
+	return &delivery, nil
+}
+
+// kick wakes the worker without blocking the caller
+func (ws *WebhookService) kick() {
+	select {
+	case ws.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (ws *WebhookService) run() {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ws.wake:
+		}
+		ws.processDue()
+	}
+}
+
+// processDue attempts every delivery whose next attempt time has passed, as the system
+func (ws *WebhookService) processDue() {
+	audit.System("webhook delivery", func(ctx context.Context) error {
+		ws.deliverDue(config.DB.WithContext(ctx))
+		return nil
+	})
+}
+
+// deliverDue attempts the due deliveries through db
+func (ws *WebhookService) deliverDue(db *gorm.DB) {
+	var deliveries []models.WebhookDelivery
+	if err := db.Where("status IN ? AND next_attempt_at <= ?",
+		[]string{models.WebhookDeliveryPending, models.WebhookDeliveryRetrying}, time.Now()).
+		Order("next_attempt_at ASC").
+		Limit(webhookBatchSize).
+		Find(&deliveries).Error; err != nil {
+		log.Printf("Warning: Failed to load due webhook deliveries: %v", err)
+		return
+	}
+
+	for i := range deliveries {
+		ws.attempt(db, &deliveries[i])
+	}
+}
+
+// attempt performs one HTTP delivery and records the outcome
+func (ws *WebhookService) attempt(db *gorm.DB, delivery *models.WebhookDelivery) {
+	var sub models.WebhookSubscription
+	if err := db.First(&sub, delivery.SubscriptionID).Error; err != nil || !sub.IsActive {
+		db.Model(delivery).Updates(map[string]interface{}{
+			"status":          models.WebhookDeliveryDeadLetter,
+			"last_error":      "subscription removed or inactive",
+			"next_attempt_at": nil,
+		})
+		return
+	}
+
+	// Pushes are real-time data; they stop when the owner's entitlement lapses
+	if !webhookOwnerEntitled(sub.UserID) {
+		db.Model(delivery).Updates(map[string]interface{}{
+			"status":          models.WebhookDeliveryDeadLetter,
+			"last_error":      "owner no longer has real-time data access",
+			"next_attempt_at": nil,
+		})
+		return
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"attempts":        delivery.Attempts + 1,
+		"last_attempt_at": now,
+	}
+
+	statusCode, body, err := ws.send(&sub, delivery, now)
+	updates["response_status"] = statusCode
+	updates["response_body"] = body
+
+	if err == nil && statusCode >= 200 && statusCode < 300 {
+		updates["status"] = models.WebhookDeliveryDelivered
+		updates["delivered_at"] = now
+		updates["next_attempt_at"] = nil
+		updates["last_error"] = ""
+		db.Model(delivery).Updates(updates)
+		db.Model(&sub).Update("last_success", now)
+		return
+	}
+
+	if err != nil {
+		updates["last_error"] = err.Error()
+	} else {
+		updates["last_error"] = fmt.Sprintf("subscriber responded with status %d", statusCode)
+	}
+
+	attempts := delivery.Attempts + 1
+	if attempts >= webhookMaxAttempts {
+		updates["status"] = models.WebhookDeliveryDeadLetter
+		updates["next_attempt_at"] = nil
+		log.Printf("Webhook delivery %d to %s dead-lettered after %d attempts", delivery.ID, sub.URL, attempts)
+	} else {
+		next := now.Add(webhookBackoff(attempts))
+		updates["status"] = models.WebhookDeliveryRetrying
+		updates["next_attempt_at"] = next
+	}
+	db.Model(delivery).Updates(updates)
+	db.Model(&sub).Update("last_failure", now)
+}
+
+// send POSTs the signed payload and returns the response status and a short, sanitised snippet
+// of the body; the body itself is never stored, as it is shown to the subscriber
+func (ws *WebhookService) send(sub *models.WebhookSubscription, delivery *models.WebhookDelivery, now time.Time) (int, string, error) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GCX-Webhooks/1.0")
+	req.Header.Set("X-GCX-Event", delivery.EventType)
+	req.Header.Set("X-GCX-Event-ID", delivery.EventID)
+	req.Header.Set("X-GCX-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-GCX-Timestamp", timestamp)
+	req.Header.Set("X-GCX-Signature", "sha256="+SignWebhookPayload(sub.Secret, timestamp, delivery.Payload))
+
+	resp, err := ws.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit*4))
+	return resp.StatusCode, WebhookResponseSnippet(body), nil
+}
+
+// WebhookResponseSnippet keeps the start of a response body as printable text
+func WebhookResponseSnippet(body []byte) string {
+	text := strings.Map(func(r rune) rune {
+		if r == unicode.ReplacementChar || !unicode.IsPrint(r) {
+			return ' '
+		}
+		return r
+	}, string(body))
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > webhookResponseLimit {
+		text = string(runes[:webhookResponseLimit]) + "…"
+	}
+	return text
+}
+
+// ValidateWebhookURL checks that a webhook URL is an absolute http(s) URL whose host resolves
+// only to public addresses
+func ValidateWebhookURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return errors.New("URL must be an absolute http(s) URL")
+	}
+	if u.User != nil {
+		return errors.New("URL must not contain credentials")
+	}
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(ips) == 0 {
+		return fmt.Errorf("webhook host %s could not be resolved", u.Hostname())
+	}
+	for _, ip := range ips {
+		if !IsPublicWebhookIP(ip.IP) {
+			return ErrWebhookAddressNotAllowed
+		}
+	}
+	return nil
+}
+
+// IsPublicWebhookIP reports whether an address may receive webhooks: not loopback, private,
+// link-local (including cloud metadata at 169.254.169.254), multicast or otherwise reserved
+func IsPublicWebhookIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range blockedWebhookNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// dialPublicWebhookAddress resolves the host and connects to the first public address, refusing
+// if any address is not public
+func dialPublicWebhookAddress(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		if !IsPublicWebhookIP(ip.IP) {
+			return nil, ErrWebhookAddressNotAllowed
+		}
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("no address for %s", host)
+	}
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	return dialer.DialContext(ctx, network, net.JoinHostPort(ips[0].IP.String(), port))
+}
+
+// webhookOwnerEntitled reports whether the owner of a subscription is active and may still
+// receive real-time data
+func webhookOwnerEntitled(userID uint) bool {
+	var user shared_models.User
+	if err := config.DB.First(&user, userID).Error; err != nil || !user.IsActive {
+		return false
+	}
+	return HasDataAccess(&user, models.DataTypeRealTime)
+}
+
+// SignWebhookPayload computes the hex HMAC-SHA256 of "timestamp.payload" with the subscription secret.
+// Subscribers verify by recomputing it and comparing against the X-GCX-Signature header.
+func SignWebhookPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff returns the exponential delay before the given retry attempt
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookBaseBackoff << uint(attempts-1)
+	if delay <= 0 || delay > webhookMaxBackoff {
+		return webhookMaxBackoff
+	}
+	return delay
+}
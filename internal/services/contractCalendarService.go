@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gcx-cms/internal/cms/models"
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/database"
+
+	"gorm.io/gorm"
+)
+
+// ContractCalendarService generates listed contract series from delivery month rules
+type ContractCalendarService struct {
+	db *gorm.DB
+}
+
+// NewContractCalendarService creates a new contract calendar service instance
+func NewContractCalendarService() *ContractCalendarService {
+	return &ContractCalendarService{db: database.GetDB()}
+}
+
+// WithContext returns the service making its database statements for ctx, so that the audit
+// trail records its changes against the request or process ctx is for
+func (s *ContractCalendarService) WithContext(ctx context.Context) *ContractCalendarService {
+	return &ContractCalendarService{db: s.db.WithContext(ctx)}
+}
+
+// DefaultRule builds a rule from the commodity's (or contract type's) free-text delivery months
+func (s *ContractCalendarService) DefaultRule(commodity *models.Commodity, contractType *models.CommodityContractType) (*models.ContractCalendarRule, error) {
+	text := commodity.DeliveryMonths
+	rule := &models.ContractCalendarRule{
+		CommodityID:           commodity.ID,
+		MonthsAhead:           12,
+		FirstNoticeDaysBefore: 2,
+		LastTradingDaysBefore: 5,
+		IsActive:              true,
+	}
+	if contractType != nil {
+		rule.ContractTypeID = &contractType.ID
+		if contractType.DeliveryMonths != "" {
+			text = contractType.DeliveryMonths
+		}
+	}
+
+	months, err := models.ParseDeliveryMonths(text)
+	if err != nil {
+		return nil, fmt.Errorf("cannot derive delivery months from %q: %v", text, err)
+	}
+	rule.DeliveryMonths = models.FormatDeliveryMonths(months)
+	return rule, nil
+}
+
+// SaveRule validates and stores a calendar rule, replacing any existing rule for the same scope
+func (s *ContractCalendarService) SaveRule(rule *models.ContractCalendarRule) error {
+	months, err := models.ParseDeliveryMonths(rule.DeliveryMonths)
+	if err != nil {
+		return err
+	}
+	rule.DeliveryMonths = models.FormatDeliveryMonths(months)
+	if rule.MonthsAhead <= 0 || rule.MonthsAhead > 36 {
+		return errors.New("months_ahead must be between 1 and 36")
+	}
+	if rule.FirstNoticeDaysBefore < 0 || rule.LastTradingDaysBefore < 0 {
+		return errors.New("business day offsets cannot be negative")
+	}
+
+	var existing models.ContractCalendarRule
+	query := s.db.Where("commodity_id = ?", rule.CommodityID)
+	if rule.ContractTypeID != nil {
+		query = query.Where("contract_type_id = ?", *rule.ContractTypeID)
+	} else {
+		query = query.Where("contract_type_id IS NULL")
+	}
+	if err := query.First(&existing).Error; err == nil {
+		rule.ID = existing.ID
+		rule.CreatedAt = existing.CreatedAt
+	}
+
+	return s.db.Save(rule).Error
+}
+
+// Generate lists every series due under a rule up to its horizon. Existing unexpired series
+// are updated to reflect rule changes; expired series are left untouched.
+func (s *ContractCalendarService) Generate(rule *models.ContractCalendarRule) (int, error) {
+	var commodity models.Commodity
+	if err := s.db.First(&commodity, rule.CommodityID).Error; err != nil {
+		return 0, fmt.Errorf("commodity not found: %v", err)
+	}
+
+	contractTypeCode := ""
+	if rule.ContractTypeID != nil {
+		var contractType models.CommodityContractType
+		if err := s.db.First(&contractType, *rule.ContractTypeID).Error; err != nil {
+			return 0, fmt.Errorf("contract type not found: %v", err)
+		}
+		contractTypeCode = contractType.Code
+	}
+
+	months, err := models.ParseDeliveryMonths(rule.DeliveryMonths)
+	if err != nil {
+		return 0, err
+	}
+	listed := make(map[time.Month]bool, len(months))
+	for _, m := range months {
+		listed[m] = true
+	}
+
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	generated := 0
+
+	for i := 0; i <= rule.MonthsAhead; i++ {
+		month := start.AddDate(0, i, 0)
+		if !listed[month.Month()] {
+			continue
+		}
+
+		series := s.buildSeries(rule, month)
+		series.Symbol = models.SeriesSymbol(commodity.Code, contractTypeCode, month)
+		if series.IsExpired(now) {
+			continue
+		}
+
+		var existing models.ContractSeries
+		err := s.db.Where("symbol = ?", series.Symbol).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := s.db.Create(&series).Error; err != nil {
+				return generated, fmt.Errorf("failed to create series %s: %v", series.Symbol, err)
+			}
+			generated++
+			continue
+		}
+		if err != nil {
+			return generated, err
+		}
+
+		series.ID = existing.ID
+		series.CreatedAt = existing.CreatedAt
+		if err := s.db.Save(&series).Error; err != nil {
+			return generated, fmt.Errorf("failed to update series %s: %v", series.Symbol, err)
+		}
+	}
+
+	return generated, nil
+}
+
+// GenerateAll rolls every active rule forward
+func (s *ContractCalendarService) GenerateAll() {
+	var rules []models.ContractCalendarRule
+	if err := s.db.Where("is_active = ?", true).Find(&rules).Error; err != nil {
+		log.Printf("Warning: Failed to load contract calendar rules: %v", err)
+		return
+	}
+	for i := range rules {
+		if _, err := s.Generate(&rules[i]); err != nil {
+			log.Printf("Warning: Failed to generate series for rule %d: %v", rules[i].ID, err)
+		}
+	}
+}
+
+// StartRolling periodically lists new series as the calendar moves forward
+func (s *ContractCalendarService) StartRolling(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			audit.System("contract calendar rolling", func(ctx context.Context) error {
+				s.WithContext(ctx).GenerateAll()
+				return nil
+			})
+		}
+	}()
+}
+
+// ListSeries returns series for a commodity filtered by status (active, expired or all)
+func (s *ContractCalendarService) ListSeries(commodityID uint, contractTypeID *uint, status string) ([]models.ContractSeries, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	query := s.db.Where("commodity_id = ?", commodityID)
+	if contractTypeID != nil {
+		query = query.Where("contract_type_id = ?", *contractTypeID)
+	}
+	switch status {
+	case "active":
+		query = query.Where("last_trading_date >= ?", today)
+	case "expired":
+		query = query.Where("last_trading_date < ?", today)
+	}
+
+	var series []models.ContractSeries
+	if err := query.Order("delivery_month ASC, symbol ASC").Find(&series).Error; err != nil {
+		return nil, err
+	}
+	for i := range series {
+		series[i].SetStatus(now)
+	}
+	return series, nil
+}
+
+// FindSeries looks up a series by symbol
+func (s *ContractCalendarService) FindSeries(symbol string) (*models.ContractSeries, error) {
+	var series models.ContractSeries
+	if err := s.db.Where("symbol = ?", symbol).First(&series).Error; err != nil {
+		return nil, err
+	}
+	series.SetStatus(time.Now())
+	return &series, nil
+}
+
+// buildSeries derives the key dates for a delivery month under a rule
+func (s *ContractCalendarService) buildSeries(rule *models.ContractCalendarRule, month time.Time) models.ContractSeries {
+	monthEnd := month.AddDate(0, 1, -1)
+	deliveryStart := nextBusinessDay(month)
+	deliveryEnd := previousBusinessDay(monthEnd)
+
+	return models.ContractSeries{
+		CommodityID:       rule.CommodityID,
+		ContractTypeID:    rule.ContractTypeID,
+		RuleID:            rule.ID,
+		DeliveryMonth:     month,
+		FirstNoticeDate:   addBusinessDays(month, -rule.FirstNoticeDaysBefore),
+		LastTradingDate:   addBusinessDays(deliveryEnd, -rule.LastTradingDaysBefore),
+		DeliveryStartDate: deliveryStart,
+		DeliveryEndDate:   deliveryEnd,
+	}
+}
+
+func isBusinessDay(t time.Time) bool {
+	return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+}
+
+func nextBusinessDay(t time.Time) time.Time {
+	for !isBusinessDay(t) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+func previousBusinessDay(t time.Time) time.Time {
+	for !isBusinessDay(t) {
+		t = t.AddDate(0, 0, -1)
+	}
+	return t
+}
+
+// addBusinessDays moves n business days from t (negative n moves backwards)
+func addBusinessDays(t time.Time, n int) time.Time {
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for n > 0 {
+		t = t.AddDate(0, 0, step)
+		if isBusinessDay(t) {
+			n--
+		}
+	}
+	return t
+}
@@ -0,0 +1,316 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gcx-cms/internal/cms/models"
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/cache"
+	"gcx-cms/internal/shared/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ContractSpecService manages versioned contract specifications and their approval
+type ContractSpecService struct {
+	db *gorm.DB
+}
+
+// NewContractSpecService creates a new contract specification service instance
+func NewContractSpecService() *ContractSpecService {
+	return &ContractSpecService{db: database.GetDB()}
+}
+
+// WithContext returns the service making its database statements for ctx, so that the audit
+// trail records its changes against the request or process ctx is for
+func (s *ContractSpecService) WithContext(ctx context.Context) *ContractSpecService {
+	return &ContractSpecService{db: s.db.WithContext(ctx)}
+}
+
+// WithTx returns the service making its database statements in tx, so that they commit or roll
+// back together with the caller's own writes
+func (s *ContractSpecService) WithTx(tx *gorm.DB) *ContractSpecService {
+	return &ContractSpecService{db: tx}
+}
+
+// EnsureBaseline records the contract type's current specification as version 1 if it has no
+// versions yet. InitDB does this for existing contract types, so only writes call it.
+func (s *ContractSpecService) EnsureBaseline(ct *models.CommodityContractType) error {
+	return cache.Transaction(s.db, func(tx *gorm.DB) error {
+		if err := lockContractType(tx, ct.ID); err != nil {
+			return err
+		}
+		return ensureBaseline(tx, ct)
+	})
+}
+
+func ensureBaseline(tx *gorm.DB, ct *models.CommodityContractType) error {
+	var count int64
+	if err := tx.Model(&models.ContractSpecVersion{}).
+		Where("contract_type_id = ?", ct.ID).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count spec versions: %v", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	baseline := models.BaselineSpec(ct)
+	if err := tx.Create(&baseline).Error; err != nil {
+		return fmt.Errorf("failed to create baseline spec version: %v", err)
+	}
+	return nil
+}
+
+// lockContractType locks the contract type's row for the rest of the transaction, so that its
+// versions are numbered one at a time
+func lockContractType(tx *gorm.DB, contractTypeID uint) error {
+	var ct models.CommodityContractType
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&ct, contractTypeID).Error; err != nil {
+		return fmt.Errorf("contract type not found")
+	}
+	return nil
+}
+
+// Propose creates a pending specification version for approval. It can only take effect from
+// now on, so that the history of what applied when is never rewritten.
+func (s *ContractSpecService) Propose(ct *models.CommodityContractType, spec models.ContractSpecVersion, effectiveFrom time.Time, notes string, proposedBy *uint) (*models.ContractSpecVersion, error) {
+	if effectiveFrom.Before(time.Now()) {
+		return nil, errors.New("effective_from must not be in the past")
+	}
+
+	err := cache.Transaction(s.db, func(tx *gorm.DB) error {
+		if err := lockContractType(tx, ct.ID); err != nil {
+			return err
+		}
+		if err := ensureBaseline(tx, ct); err != nil {
+			return err
+		}
+
+		latest, err := s.latestApproved(tx, ct.ID)
+		if err != nil {
+			return err
+		}
+		if latest != nil && !effectiveFrom.After(latest.EffectiveFrom) {
+			return fmt.Errorf("effective_from must be after %s, when version %d took effect",
+				latest.EffectiveFrom.Format("2006-01-02"), latest.Version)
+		}
+		if latest != nil && spec.SpecEquals(latest) {
+			return errors.New("specification is unchanged from the current version")
+		}
+
+		var maxVersion int
+		if err := tx.Model(&models.ContractSpecVersion{}).
+			Where("contract_type_id = ?", ct.ID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&maxVersion).Error; err != nil {
+			return fmt.Errorf("failed to number spec version: %v", err)
+		}
+
+		spec.ID = 0
+		spec.ContractTypeID = ct.ID
+		spec.Version = maxVersion + 1
+		spec.Status = models.ContractSpecStatusPending
+		spec.EffectiveFrom = effectiveFrom
+		spec.EffectiveTo = nil
+		spec.ChangeNotes = notes
+		spec.ProposedBy = proposedBy
+		spec.ReviewedBy = nil
+		spec.ReviewedAt = nil
+		spec.RejectionReason = nil
+
+		if err := tx.Create(&spec).Error; err != nil {
+			return fmt.Errorf("failed to create spec version: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// Approve activates a pending version, closing the previous version's effective window. A
+// version whose effective date passed while it waited cannot be approved, as it would change
+// what applied in the past; it has to be proposed again. ActivateDue copies it onto the contract
+// type when it takes effect.
+func (s *ContractSpecService) Approve(versionID uint, reviewerID uint) (*models.ContractSpecVersion, error) {
+	var version models.ContractSpecVersion
+
+	err := cache.Transaction(s.db, func(tx *gorm.DB) error {
+		if err := tx.First(&version, versionID).Error; err != nil {
+			return fmt.Errorf("spec version not found")
+		}
+		if version.Status != models.ContractSpecStatusPending {
+			return fmt.Errorf("only pending versions can be approved")
+		}
+		if version.ProposedBy != nil && *version.ProposedBy == reviewerID {
+			return fmt.Errorf("a version cannot be approved by the user who proposed it")
+		}
+		now := time.Now()
+		if version.EffectiveFrom.Before(now) {
+			return fmt.Errorf("version %d was to take effect on %s, which has passed; propose it again with a new effective date",
+				version.Version, version.EffectiveFrom.Format("2006-01-02 15:04"))
+		}
+
+		latest, err := s.latestApproved(tx, version.ContractTypeID)
+		if err != nil {
+			return err
+		}
+		if latest != nil {
+			if !version.EffectiveFrom.After(latest.EffectiveFrom) {
+				return fmt.Errorf("a later version (%d) was approved first; propose a new effective date", latest.Version)
+			}
+			if err := tx.Model(latest).Update("effective_to", version.EffectiveFrom).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&version).Updates(map[string]interface{}{
+			"status":      models.ContractSpecStatusApproved,
+			"reviewed_by": reviewerID,
+			"reviewed_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// Reject closes a pending version without activating it
+func (s *ContractSpecService) Reject(versionID uint, reviewerID uint, reason string) (*models.ContractSpecVersion, error) {
+	var version models.ContractSpecVersion
+	if err := s.db.First(&version, versionID).Error; err != nil {
+		return nil, fmt.Errorf("spec version not found")
+	}
+	if version.Status != models.ContractSpecStatusPending {
+		return nil, fmt.Errorf("only pending versions can be rejected")
+	}
+
+	now := time.Now()
+	if err := s.db.Model(&version).Updates(map[string]interface{}{
+		"status":           models.ContractSpecStatusRejected,
+		"reviewed_by":      reviewerID,
+		"reviewed_at":      now,
+		"rejection_reason": reason,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to reject spec version: %v", err)
+	}
+	return &version, nil
+}
+
+// EffectiveAt returns the approved version in force for a contract type at the given time
+func (s *ContractSpecService) EffectiveAt(contractTypeID uint, asOf time.Time) (*models.ContractSpecVersion, error) {
+	var version models.ContractSpecVersion
+	err := s.db.Where("contract_type_id = ? AND status = ? AND effective_from <= ? AND (effective_to IS NULL OR effective_to > ?)",
+		contractTypeID, models.ContractSpecStatusApproved, asOf, asOf).
+		Order("effective_from DESC").
+		First(&version).Error
+	if err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// OverlayEffective replaces each contract type's specification fields with the version in force at asOf.
+// Contract types without versions keep their stored values.
+func (s *ContractSpecService) OverlayEffective(contractTypes []models.CommodityContractType, asOf time.Time) error {
+	if len(contractTypes) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(contractTypes))
+	for i, ct := range contractTypes {
+		ids[i] = ct.ID
+	}
+
+	var versions []models.ContractSpecVersion
+	if err := s.db.Where("contract_type_id IN ? AND status = ? AND effective_from <= ? AND (effective_to IS NULL OR effective_to > ?)",
+		ids, models.ContractSpecStatusApproved, asOf, asOf).
+		Find(&versions).Error; err != nil {
+		return fmt.Errorf("failed to load effective spec versions: %v", err)
+	}
+
+	byContractType := make(map[uint]*models.ContractSpecVersion, len(versions))
+	for i := range versions {
+		byContractType[versions[i].ContractTypeID] = &versions[i]
+	}
+	for i := range contractTypes {
+		if v, ok := byContractType[contractTypes[i].ID]; ok {
+			v.ApplyTo(&contractTypes[i])
+		}
+	}
+	return nil
+}
+
+// ActivateDue copies approved versions whose effective date has arrived onto their contract
+// types, oldest first. Every one not applied yet is picked up, however long ago it took effect,
+// so none is missed while the job was not running.
+func (s *ContractSpecService) ActivateDue() {
+	var versions []models.ContractSpecVersion
+	if err := s.db.Where("status = ? AND applied_at IS NULL AND effective_from <= ?",
+		models.ContractSpecStatusApproved, time.Now()).
+		Order("effective_from ASC, id ASC").
+		Find(&versions).Error; err != nil {
+		log.Printf("Warning: Failed to load due spec versions: %v", err)
+		return
+	}
+	for i := range versions {
+		err := cache.Transaction(s.db, func(tx *gorm.DB) error {
+			if err := s.applyVersion(tx, &versions[i]); err != nil {
+				return err
+			}
+			return tx.Model(&versions[i]).Update("applied_at", time.Now()).Error
+		})
+		if err != nil {
+			log.Printf("Warning: Failed to activate spec version %d: %v", versions[i].ID, err)
+		}
+	}
+}
+
+// StartActivation periodically applies future-dated versions once they take effect
+func (s *ContractSpecService) StartActivation(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			audit.System("contract spec activation", func(ctx context.Context) error {
+				s.WithContext(ctx).ActivateDue()
+				return nil
+			})
+		}
+	}()
+}
+
+// applyVersion writes a version's specification onto its contract type row
+func (s *ContractSpecService) applyVersion(tx *gorm.DB, version *models.ContractSpecVersion) error {
+	var ct models.CommodityContractType
+	if err := tx.First(&ct, version.ContractTypeID).Error; err != nil {
+		return fmt.Errorf("contract type not found: %v", err)
+	}
+	version.ApplyTo(&ct)
+	return tx.Model(&ct).Select("specifications", "trading_hours", "contract_size", "price_unit",
+		"contract_file", "delivery_months", "storage_requirements", "quality_standards").
+		Updates(&ct).Error
+}
+
+// latestApproved returns the approved version with the latest effective date, or nil if none exist
+func (s *ContractSpecService) latestApproved(tx *gorm.DB, contractTypeID uint) (*models.ContractSpecVersion, error) {
+	var version models.ContractSpecVersion
+	err := tx.Where("contract_type_id = ? AND status = ?", contractTypeID, models.ContractSpecStatusApproved).
+		Order("effective_from DESC").
+		First(&version).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load current spec version: %v", err)
+	}
+	return &version, nil
+}
@@ -0,0 +1,62 @@
+// Package audit keeps the trail of changes made through the API and the log of authentication
+// events. Handlers record actions with Record; every other create, update and delete made while
+// serving a request is recorded by the database callbacks of TrackChanges.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"gcx-cms/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Record writes an audit entry for a change made by the request's user. Changes is any JSON
+// value describing what changed, typically a map of field to {from, to}. Failures are logged
+// rather than failing a change that has already been made.
+func Record(c *gin.Context, action, targetType string, targetID interface{}, changes interface{}) {
+	entry := models.AuditLog{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   fmt.Sprint(targetID),
+	}
+	if changes != nil {
+		if data, err := json.Marshal(changes); err == nil {
+			entry.Changes = string(data)
+		}
+	}
+
+	// Within a tracked request the entry is written with the request's changes, in order
+	if req := requestFromContext(c); req != nil {
+		req.add(entry, false)
+		return
+	}
+	describe(c, &entry)
+	if err := appendEntries([]models.AuditLog{entry}); err != nil {
+		log.Printf("Warning: Failed to write audit log %s %s/%s: %v", action, targetType, entry.TargetID, err)
+	}
+}
+
+// describe fills in who made a change and from where
+func describe(c *gin.Context, entry *models.AuditLog) {
+	entry.IPAddress = c.ClientIP()
+	entry.UserAgent = c.Request.UserAgent()
+	if len(entry.UserAgent) > 500 {
+		entry.UserAgent = entry.UserAgent[:500]
+	}
+	entry.RequestID = c.GetString("request_id")
+	if user, ok := c.Get("user"); ok {
+		if u, ok := user.(*models.User); ok {
+			entry.ActorID = &u.ID
+			entry.ActorEmail = u.Email
+		}
+	}
+}
+
+// Change describes a field changing from one value to another
+type Change struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
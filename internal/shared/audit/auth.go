@@ -0,0 +1,42 @@
+package audit
+
+import (
+	"log"
+
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecordAuth writes an entry to the authentication log. User is nil when the email matches no
+// account. The request's session is recorded when it belongs to the user. Failures are logged.
+func RecordAuth(c *gin.Context, event string, user *models.User, email, detail string) {
+	entry := models.AuthEvent{
+		Email:     email,
+		Event:     event,
+		Detail:    detail,
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if user != nil {
+		entry.UserID = &user.ID
+		entry.Email = user.Email
+		if c.GetUint("user_id") == user.ID {
+			entry.SessionID = c.GetString("session_id")
+		}
+	}
+	if len(entry.Email) > 191 {
+		entry.Email = entry.Email[:191]
+	}
+	if len(entry.Detail) > 255 {
+		entry.Detail = entry.Detail[:255]
+	}
+	if len(entry.UserAgent) > 500 {
+		entry.UserAgent = entry.UserAgent[:500]
+	}
+
+	if err := config.DB.Create(&entry).Error; err != nil {
+		log.Printf("Warning: Failed to write auth log %s for %s: %v", event, entry.Email, err)
+	}
+}
@@ -0,0 +1,239 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// chainHeadID is the primary key of the one chain head row
+const chainHeadID = 1
+
+// chainMu queues this process's appends, rather than leaving them to wait on the row lock
+var chainMu sync.Mutex
+
+// appendEntries writes entries to the end of the hash chain, in order. The chain head row is
+// locked for the transaction, so that no two appends, from any process, take the same previous
+// hash.
+func appendEntries(entries []models.AuditLog) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	chainMu.Lock()
+	defer chainMu.Unlock()
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		head, err := lockChainHead(tx)
+		if err != nil {
+			return err
+		}
+		prev := head.Hash
+
+		for i := range entries {
+			e := &entries[i]
+			if e.CreatedAt.IsZero() {
+				e.CreatedAt = time.Now()
+			}
+			// Stored times lose precision in some databases; hash what is read back
+			e.CreatedAt = e.CreatedAt.UTC().Truncate(time.Millisecond)
+			e.PrevHash = prev
+			e.Digest = payloadDigest(*e)
+			e.Hash = entryHash(*e)
+			if err := tx.Create(e).Error; err != nil {
+				return err
+			}
+			prev = e.Hash
+		}
+		return tx.Model(head).Updates(map[string]interface{}{
+			"entry_id": entries[len(entries)-1].ID,
+			"hash":     prev,
+		}).Error
+	})
+}
+
+// lockChainHead locks the chain head row for the transaction, creating it from the last
+// chained entry the first time
+func lockChainHead(tx *gorm.DB) (*models.AuditChainHead, error) {
+	var head models.AuditChainHead
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", chainHeadID).Limit(1).Find(&head).Error; err != nil {
+		return nil, err
+	}
+	if head.ID != 0 {
+		return &head, nil
+	}
+
+	var last []models.AuditLog
+	if err := tx.Where("hash <> ''").Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+		return nil, err
+	}
+	head = models.AuditChainHead{ID: chainHeadID}
+	if len(last) > 0 {
+		head.EntryID = last[0].ID
+		head.Hash = last[0].Hash
+	}
+	// Another process may create it first; then wait for its lock
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&head).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", chainHeadID).First(&head).Error; err != nil {
+		return nil, err
+	}
+	return &head, nil
+}
+
+// entryHash is the SHA-256 of an entry's content and the hash of the entry before it. The parts
+// that can hold personal data are covered by their digest.
+func entryHash(e models.AuditLog) string {
+	data, _ := json.Marshal(struct {
+		PrevHash   string `json:"prev_hash"`
+		ActorID    *uint  `json:"actor_id"`
+		Action     string `json:"action"`
+		TargetType string `json:"target_type"`
+		TargetID   string `json:"target_id"`
+		RequestID  string `json:"request_id"`
+		Digest     string `json:"digest"`
+		CreatedAt  string `json:"created_at"`
+	}{
+		PrevHash:   e.PrevHash,
+		ActorID:    e.ActorID,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		RequestID:  e.RequestID,
+		Digest:     e.Digest,
+		CreatedAt:  e.CreatedAt.UTC().Truncate(time.Millisecond).Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// payloadDigest is the SHA-256 of the parts of an entry that can hold personal data
+func payloadDigest(e models.AuditLog) string {
+	data, _ := json.Marshal(struct {
+		ActorEmail string `json:"actor_email"`
+		Changes    string `json:"changes"`
+		IPAddress  string `json:"ip_address"`
+		UserAgent  string `json:"user_agent"`
+	}{
+		ActorEmail: e.ActorEmail,
+		Changes:    e.Changes,
+		IPAddress:  e.IPAddress,
+		UserAgent:  e.UserAgent,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Redact erases the personal data in an entry: the values of its changes and, if actor is set,
+// who made it and from where. The entry stays in the chain, which holds the digest of what was
+// there; Verify no longer compares the redacted parts with it.
+func Redact(tx *gorm.DB, e models.AuditLog, actor bool) error {
+	updates := map[string]interface{}{
+		"changes":     redactChanges(e.Changes),
+		"redacted_at": time.Now(),
+	}
+	if actor {
+		updates["actor_email"] = ""
+		updates["ip_address"] = ""
+		updates["user_agent"] = ""
+	}
+	return tx.Model(&models.AuditLog{}).Where("id = ?", e.ID).Updates(updates).Error
+}
+
+// redactChanges keeps which fields changed but not their values
+func redactChanges(changes string) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(changes), &fields); err != nil {
+		return ""
+	}
+	kept := make(map[string]string, len(fields))
+	for field := range fields {
+		kept[field] = redacted
+	}
+	data, _ := json.Marshal(kept)
+	return string(data)
+}
+
+// Verification is the outcome of checking the hash chain
+type Verification struct {
+	Valid     bool   `json:"valid"`
+	Checked   int    `json:"checked"`   // Chained entries checked
+	Unchained int    `json:"unchained"` // Entries written before chaining, which cannot be checked
+	BrokenAt  *uint  `json:"broken_at,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	Head      string `json:"head"` // Hash of the last entry checked; keep a copy elsewhere to detect the head row being rewound too
+}
+
+// errChainBroken stops the walk over the trail at the first broken link
+var errChainBroken = errors.New("audit chain broken")
+
+// Verify walks the audit trail in order and checks that every entry's hash matches its content
+// and links to the entry before it, and that the chain reaches the head recorded when the last
+// entry was appended. Entries written before chaining are counted but only allowed ahead of the
+// first chained entry.
+func Verify() (Verification, error) {
+	var v Verification
+	prev := ""
+	chained := false
+
+	// Read first, as entries appended during the walk move the head on
+	var head models.AuditChainHead
+	if err := config.DB.Where("id = ?", chainHeadID).Limit(1).Find(&head).Error; err != nil {
+		return v, err
+	}
+	reachedHead := head.EntryID == 0
+
+	var batch []models.AuditLog
+	result := config.DB.Order("id").FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+		for _, e := range batch {
+			var reason string
+			switch {
+			case e.Hash == "" && !chained:
+				v.Unchained++
+				continue
+			case e.Hash == "":
+				reason = "entry is not chained"
+			case e.PrevHash != prev:
+				reason = "entry does not link to the one before it"
+			case entryHash(e) != e.Hash:
+				reason = "entry does not match its hash"
+			case e.RedactedAt == nil && payloadDigest(e) != e.Digest:
+				reason = "entry does not match its digest"
+			case e.ID == head.EntryID && e.Hash != head.Hash:
+				reason = "entry is not the recorded chain head"
+			}
+			if reason != "" {
+				id := e.ID
+				v.BrokenAt = &id
+				v.Reason = reason
+				return errChainBroken
+			}
+			chained = true
+			prev = e.Hash
+			v.Checked++
+			if e.ID == head.EntryID {
+				reachedHead = true
+			}
+		}
+		return nil
+	})
+	if result.Error != nil && !errors.Is(result.Error, errChainBroken) {
+		return v, result.Error
+	}
+	if v.BrokenAt == nil && !reachedHead {
+		v.Reason = "chain ends before its recorded head; entries were removed from the end"
+	}
+
+	v.Head = prev
+	v.Valid = v.BrokenAt == nil && reachedHead
+	return v, nil
+}
@@ -0,0 +1,338 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"gcx-cms/internal/shared/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Actions of the entries written by the database callbacks
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+const (
+	// maxTrackedRows bounds how many rows of a single bulk update or delete are recorded
+	maxTrackedRows = 100
+	// maxValueSize is the longest value kept in full; longer ones are recorded by size and digest
+	maxValueSize = 1024
+	redacted     = "[redacted]"
+	beforeKey    = "audit:before"
+	skipKey      = "audit:skip"
+)
+
+// untracked tables are bookkeeping written on sign-ins and API use, or values computed from
+// other data, rather than changes someone makes; the auth log covers what matters in them
+var untracked = map[string]bool{
+	"audit_logs":            true,
+	"auth_events":           true,
+	"login_throttles":       true,
+	"sessions":              true,
+	"refresh_tokens":        true,
+	"password_reset_tokens": true,
+	"oidc_login_states":     true,
+	"usage_records":         true,
+	"export_jobs":           true,
+	"webhook_deliveries":    true,
+	"index_values":          true,
+}
+
+// sensitiveColumns hold personal data the trail has no use for; like hidden columns they are
+// recorded as changed without their values
+var sensitiveColumns = map[string]map[string]bool{
+	"event_registrations": {"dietary_requirements": true, "special_needs": true},
+	"rti_requests":        {"id_number": true, "address": true},
+}
+
+// ignoredColumns change as a side effect of use. An update touching only these is not recorded.
+var ignoredColumns = map[string]bool{
+	"created_at":           true,
+	"updated_at":           true,
+	"last_login":           true,
+	"last_login_at":        true,
+	"last_used_at":         true,
+	"last_used_step":       true,
+	"verification_sent_at": true,
+	"last_success":         true,
+	"last_failure":         true,
+	"request_count":        true,
+	"last_reset":           true,
+}
+
+// TrackChanges registers GORM callbacks that record every create, update and delete made for a
+// request tracked by Track, or by the system within System, with the values before and after.
+// The request or process is taken from the statement's context. Columns hidden from JSON, such
+// as password hashes, and sensitive personal columns are recorded as changed without their
+// values.
+func TrackChanges(db *gorm.DB) {
+	db.Callback().Create().After("gorm:create").Register("audit:after_create", afterCreate)
+	db.Callback().Update().Before("gorm:update").Register("audit:before_update", loadBefore)
+	db.Callback().Update().After("gorm:update").Register("audit:after_update", afterUpdate)
+	db.Callback().Delete().Before("gorm:delete").Register("audit:before_delete", loadBefore)
+	db.Callback().Delete().After("gorm:delete").Register("audit:after_delete", afterDelete)
+}
+
+// Untracked returns db for writes the callbacks must not record, such as erasing personal data,
+// which would otherwise be kept in the trail as the values before. Record what was done instead.
+func Untracked(db *gorm.DB) *gorm.DB {
+	return db.Set(skipKey, true)
+}
+
+// row is a record as recorded: its primary key and its column values as JSON. Hidden columns
+// are compared like the others but their values never written to the trail.
+type row struct {
+	id     string
+	values map[string]json.RawMessage
+	hidden map[string]bool
+}
+
+// value is how a column of the row appears in the trail
+func (r row) value(column string) interface{} {
+	if r.hidden[column] {
+		return redacted
+	}
+	return r.values[column]
+}
+
+// trackedRequest returns the request a write is made for, or nil if it is not recorded
+func trackedRequest(tx *gorm.DB) *request {
+	stmt := tx.Statement
+	if tx.Error != nil || stmt.Schema == nil || stmt.Schema.PrioritizedPrimaryField == nil || untracked[stmt.Table] {
+		return nil
+	}
+	if skip, ok := tx.Get(skipKey); ok && skip == true {
+		return nil
+	}
+	return currentRequest(tx)
+}
+
+func afterCreate(tx *gorm.DB) {
+	req := trackedRequest(tx)
+	if req == nil || tx.RowsAffected == 0 {
+		return
+	}
+
+	var created []row
+	value := reflect.Indirect(tx.Statement.ReflectValue)
+	switch value.Kind() {
+	case reflect.Struct:
+		created = append(created, snapshot(tx, value))
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len() && i < maxTrackedRows; i++ {
+			if elem := reflect.Indirect(value.Index(i)); elem.Kind() == reflect.Struct {
+				created = append(created, snapshot(tx, elem))
+			}
+		}
+	}
+
+	for _, r := range created {
+		changes := map[string]Change{}
+		for column, v := range r.values {
+			if ignoredColumns[column] || string(v) == "null" {
+				continue
+			}
+			changes[column] = Change{From: nil, To: r.value(column)}
+		}
+		req.add(entry(tx, ActionCreate, r.id, changes), inTransaction(tx))
+	}
+}
+
+// loadBefore keeps the rows an update or delete is about to change
+func loadBefore(tx *gorm.DB) {
+	if trackedRequest(tx) == nil {
+		return
+	}
+	stmt := tx.Statement
+	rows, err := findRows(tx, func(query *gorm.DB) *gorm.DB {
+		conditions := false
+		if where, ok := stmt.Clauses["WHERE"]; ok {
+			if w, ok := where.Expression.(clause.Where); ok && len(w.Exprs) > 0 {
+				query = query.Clauses(clause.Where{Exprs: w.Exprs})
+				conditions = true
+			}
+		}
+		// GORM adds the primary key of the model being written itself, later on
+		_, values := schema.GetIdentityFieldValuesMap(stmt.Context, stmt.ReflectValue, stmt.Schema.PrimaryFields)
+		if column, queryValues := schema.ToQueryValues(stmt.Table, stmt.Schema.PrimaryFieldDBNames, values); len(queryValues) > 0 {
+			query = query.Clauses(clause.Where{Exprs: []clause.Expression{clause.IN{Column: column, Values: queryValues}}})
+			conditions = true
+		}
+		if !conditions {
+			// Refused by GORM without AllowGlobalUpdate; too broad to record row by row if allowed
+			return nil
+		}
+		if stmt.Unscoped {
+			query = query.Unscoped()
+		}
+		return query
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to load %s rows for the audit trail: %v", stmt.Table, err)
+		return
+	}
+	tx.InstanceSet(beforeKey, rows)
+}
+
+func afterUpdate(tx *gorm.DB) {
+	req := trackedRequest(tx)
+	if req == nil || tx.RowsAffected == 0 {
+		return
+	}
+	before := loadedRows(tx)
+	if len(before) == 0 {
+		return
+	}
+
+	ids := make([]interface{}, len(before))
+	for i, r := range before {
+		ids[i] = r.id
+	}
+	after, err := findRows(tx, func(query *gorm.DB) *gorm.DB {
+		return query.Unscoped().Where(clause.IN{
+			Column: clause.Column{Table: tx.Statement.Table, Name: tx.Statement.Schema.PrioritizedPrimaryField.DBName},
+			Values: ids,
+		})
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to load updated %s rows for the audit trail: %v", tx.Statement.Table, err)
+		return
+	}
+	afterByID := make(map[string]row, len(after))
+	for _, r := range after {
+		afterByID[r.id] = r
+	}
+
+	for _, b := range before {
+		a, ok := afterByID[b.id]
+		if !ok {
+			continue
+		}
+		changes := map[string]Change{}
+		for column, from := range b.values {
+			to := a.values[column]
+			if ignoredColumns[column] || string(from) == string(to) {
+				continue
+			}
+			changes[column] = Change{From: b.value(column), To: a.value(column)}
+		}
+		if len(changes) > 0 {
+			req.add(entry(tx, ActionUpdate, b.id, changes), inTransaction(tx))
+		}
+	}
+}
+
+func afterDelete(tx *gorm.DB) {
+	req := trackedRequest(tx)
+	if req == nil || tx.RowsAffected == 0 {
+		return
+	}
+	for _, r := range loadedRows(tx) {
+		changes := map[string]Change{}
+		for column, v := range r.values {
+			if ignoredColumns[column] || string(v) == "null" {
+				continue
+			}
+			changes[column] = Change{From: r.value(column), To: nil}
+		}
+		req.add(entry(tx, ActionDelete, r.id, changes), inTransaction(tx))
+	}
+}
+
+func loadedRows(tx *gorm.DB) []row {
+	if v, ok := tx.InstanceGet(beforeKey); ok {
+		return v.([]row)
+	}
+	return nil
+}
+
+// findRows loads the rows of the statement's table selected by scope, which returns nil to
+// select none
+func findRows(tx *gorm.DB, scope func(*gorm.DB) *gorm.DB) ([]row, error) {
+	stmt := tx.Statement
+	query := scope(tx.Session(&gorm.Session{NewDB: true}).Table(stmt.Table))
+	if query == nil {
+		return nil, nil
+	}
+	found := reflect.New(reflect.SliceOf(stmt.Schema.ModelType))
+	if err := query.Limit(maxTrackedRows).Find(found.Interface()).Error; err != nil {
+		return nil, err
+	}
+
+	rows := make([]row, found.Elem().Len())
+	for i := range rows {
+		rows[i] = snapshot(tx, found.Elem().Index(i))
+	}
+	return rows, nil
+}
+
+// snapshot records the column values of a struct of the statement's model
+func snapshot(tx *gorm.DB, value reflect.Value) row {
+	stmt := tx.Statement
+	r := row{
+		values: make(map[string]json.RawMessage, len(stmt.Schema.DBNames)),
+		hidden: map[string]bool{},
+	}
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		v, _ := field.ValueOf(stmt.Context, value)
+		if field == stmt.Schema.PrioritizedPrimaryField {
+			r.id = fmt.Sprint(v)
+		}
+		r.values[field.DBName] = jsonValue(v)
+		r.hidden[field.DBName] = strings.Split(field.Tag.Get("json"), ",")[0] == "-" || sensitiveColumns[stmt.Table][field.DBName]
+	}
+	return r
+}
+
+// jsonValue encodes a column value, summarising long ones by size and digest
+func jsonValue(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	if len(data) > maxValueSize {
+		sum := sha256.Sum256(data)
+		data, _ = json.Marshal(fmt.Sprintf("[%d bytes, sha256 %s]", len(data), hex.EncodeToString(sum[:])))
+	}
+	return data
+}
+
+// entry builds the audit entry of a recorded write
+func entry(tx *gorm.DB, action, id string, changes map[string]Change) models.AuditLog {
+	e := models.AuditLog{
+		Action:     action,
+		TargetType: tx.Statement.Table,
+		TargetID:   id,
+	}
+	if len(e.TargetID) > 64 {
+		e.TargetID = e.TargetID[:64]
+	}
+	if data, err := json.Marshal(changes); err == nil {
+		e.Changes = string(data)
+	}
+	return e
+}
+
+// inTransaction reports whether a write is part of a transaction the caller commits, rather
+// than one GORM committed around the statement
+func inTransaction(tx *gorm.DB) bool {
+	if _, ok := tx.InstanceGet("gorm:started_transaction"); ok {
+		return false
+	}
+	_, ok := tx.Statement.ConnPool.(gorm.TxCommitter)
+	return ok
+}
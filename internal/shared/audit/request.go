@@ -0,0 +1,140 @@
+package audit
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"gcx-cms/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// requestKey is the gin context key of the request's audit state
+const requestKey = "audit_request"
+
+// SystemActor is the actor email of changes made by the system, such as background jobs
+const SystemActor = "system"
+
+// contextKey is the context.Context key of the audit state of the work a context is for
+type contextKey struct{}
+
+// request collects the audit entries of a request being served, or of work the system does,
+// until its outcome is known
+type request struct {
+	c       *gin.Context // Nil for the system
+	process string       // What the system is doing
+	mu      sync.Mutex
+	entries []pendingEntry
+}
+
+type pendingEntry struct {
+	entry models.AuditLog
+	// Made inside a transaction the handler commits itself; kept only if the request succeeds,
+	// as a failed request has rolled it back
+	inTransaction bool
+}
+
+// Track collects the audit entries of each request, Record's and the database callbacks', and
+// writes them to the chain when the handler has finished, with the actor, IP address, user
+// agent and request ID. Register it before the routes, after middleware.RequestID.
+//
+// The database callbacks find the request from the context of the statement, so handlers
+// write with config.DB.WithContext(c), and services they call take the context; writes
+// without one are not recorded.
+func Track() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req := &request{c: c}
+		c.Set(requestKey, req)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), contextKey{}, req))
+
+		c.Next()
+		req.flush(c.Writer.Status() >= http.StatusBadRequest)
+	}
+}
+
+// System runs work the system does on its own, such as a background job, and records the
+// changes made through ctx with the system as the actor and process as the user agent. They
+// are written to the chain when fn returns; those made inside transactions are dropped if it
+// fails, as they were rolled back.
+func System(process string, fn func(ctx context.Context) error) error {
+	req := &request{process: process}
+	err := fn(context.WithValue(context.Background(), contextKey{}, req))
+	req.flush(err != nil)
+	return err
+}
+
+// add queues an entry, stamped with the time of the change
+func (r *request) add(entry models.AuditLog, inTransaction bool) {
+	entry.CreatedAt = time.Now()
+	r.mu.Lock()
+	r.entries = append(r.entries, pendingEntry{entry: entry, inTransaction: inTransaction})
+	r.mu.Unlock()
+}
+
+// flush writes the entries. The actor is resolved now, so changes made while signing in are
+// attributed to the user who signed in.
+func (r *request) flush(failed bool) {
+	r.mu.Lock()
+	pending := r.entries
+	r.entries = nil
+	r.mu.Unlock()
+
+	entries := make([]models.AuditLog, 0, len(pending))
+	for _, p := range pending {
+		if p.inTransaction && failed {
+			continue
+		}
+		r.describe(&p.entry)
+		entries = append(entries, p.entry)
+	}
+	if err := appendEntries(entries); err != nil {
+		log.Printf("Warning: Failed to write %d audit entries of %s: %v", len(entries), r, err)
+	}
+}
+
+// describe fills in who made a change and from where
+func (r *request) describe(entry *models.AuditLog) {
+	if r.c != nil {
+		describe(r.c, entry)
+		return
+	}
+	entry.ActorEmail = SystemActor
+	entry.UserAgent = r.process
+}
+
+// String names the request or process in logs
+func (r *request) String() string {
+	if r.c != nil {
+		return "request " + r.c.GetString("request_id")
+	}
+	return r.process
+}
+
+// requestFromContext returns the tracked request or system work a context is for. A gin
+// context is looked up directly, as it does not fall back to its request's context.
+func requestFromContext(ctx context.Context) *request {
+	if c, ok := ctx.(*gin.Context); ok {
+		if v, ok := c.Get(requestKey); ok {
+			return v.(*request)
+		}
+		if c.Request == nil {
+			return nil
+		}
+		ctx = c.Request.Context()
+	}
+	if ctx == nil {
+		return nil
+	}
+	req, _ := ctx.Value(contextKey{}).(*request)
+	return req
+}
+
+// currentRequest returns the tracked request or system work a database statement is for,
+// from its context
+func currentRequest(tx *gorm.DB) *request {
+	return requestFromContext(tx.Statement.Context)
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/models"
+)
+
+// ListAuditHandler searches the audit trail (admin)
+func ListAuditHandler(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	query := config.DB.WithContext(c).Model(&models.AuditLog{})
+	if actorID := c.Query("actor_id"); actorID != "" {
+		query = query.Where("actor_id = ?", actorID)
+	}
+	if email := strings.TrimSpace(c.Query("actor_email")); email != "" {
+		query = query.Where("actor_email = ?", email)
+	}
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action IN ?", strings.Split(action, ","))
+	}
+	if targetType := c.Query("target_type"); targetType != "" {
+		query = query.Where("target_type IN ?", strings.Split(targetType, ","))
+	}
+	if targetID := c.Query("target_id"); targetID != "" {
+		query = query.Where("target_id = ?", targetID)
+	}
+	if requestID := c.Query("request_id"); requestID != "" {
+		query = query.Where("request_id = ?", requestID)
+	}
+	if ip := c.Query("ip"); ip != "" {
+		query = query.Where("ip_address = ?", ip)
+	}
+	for param, op := range map[string]string{"from": ">=", "to": "<="} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be an RFC 3339 time"})
+			return
+		}
+		query = query.Where("created_at "+op+" ?", t)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit trail"})
+		return
+	}
+
+	var entries []models.AuditLog
+	if err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit trail"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"audit": entries,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// GetAuditEntryHandler returns one entry of the audit trail (admin)
+func GetAuditEntryHandler(c *gin.Context) {
+	var entry models.AuditLog
+	if err := config.DB.WithContext(c).First(&entry, "id = ?", c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Audit entry not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit entry"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"entry": entry})
+}
+
+// VerifyAuditHandler checks the hash chain of the audit trail and reports the first entry that
+// has been edited, inserted or removed (admin)
+func VerifyAuditHandler(c *gin.Context) {
+	v, err := audit.Verify()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify audit trail"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"verification": v})
+}
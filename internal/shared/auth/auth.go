@@ -5,20 +5,16 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 
+	"gcx-cms/internal/shared/audit"
 	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/lockout"
 	"gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/session"
+	"gcx-cms/internal/shared/token"
 )
 
-var jwtSecret = []byte("gcx_super_secret_jwt_key_change_this_in_production")
-
-type Claims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
-	jwt.RegisteredClaims
-}
+type Claims = token.Claims
 
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
@@ -28,13 +24,14 @@ type LoginRequest struct {
 type RegisterRequest struct {
 	Name     string `json:"name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
-	Role     string `json:"role,omitempty"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+	Role     string `json:"role,omitempty"` // user, or trader for market data sign-up
 }
 
 type AuthResponse struct {
-	Token string      `json:"token"`
-	User  models.User `json:"user"`
+	session.Tokens
+	User    models.User `json:"user"`
+	Message string      `json:"message,omitempty"`
 }
 
 // LoginHandler authenticates a user
@@ -47,38 +44,79 @@ func LoginHandler(c *gin.Context) {
 
 	// Find user by email
 	var user models.User
-	if err := config.DB.Where("email = ?", req.Email).First(&user).Error; err != nil {
+	var known *models.User
+	if err := config.DB.WithContext(c).Where("email = ?", req.Email).First(&user).Error; err == nil {
+		known = &user
+	}
+
+	// Repeated failures are slowed down and then locked out, for known and unknown emails alike
+	guard := lockout.NewService()
+	if !checkLoginAllowed(c, guard, known, req.Email) {
+		return
+	}
+	if known == nil {
+		loginFailed(c, guard, models.AuthEventLoginFailure, nil, req.Email, "unknown_email")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
 	// Check if user is active
 	if !user.IsActive {
+		loginFailed(c, guard, models.AuthEventLoginFailure, &user, req.Email, "account_disabled")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is disabled"})
 		return
 	}
 
 	// Verify password
 	if !user.CheckPassword(req.Password) {
+		loginFailed(c, guard, models.AuthEventLoginFailure, &user, req.Email, "invalid_password")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
+	guard.Release(req.Email, c.ClientIP())
+
+	// An admin has required a new password, sent by email
+	if user.MustResetPassword {
+		audit.RecordAuth(c, models.AuthEventLoginFailure, &user, req.Email, "password_reset_required")
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Password reset required, use the link sent to your email",
+			"code":  "password_reset_required",
+		})
+		return
+	}
+
+	// Tokens are only issued once the second factor is given
+	if user.TwoFactorEnabled {
+		startTwoFactorChallenge(c, &user)
+		return
+	}
+
+	completeLogin(c, &user, "password")
+}
 
+// completeLogin records the sign-in and starts a session for an authenticated user. Method is
+// how they authenticated, for the auth log.
+func completeLogin(c *gin.Context, user *models.User, method string) {
 	// Update last login
 	now := time.Now()
 	user.LastLogin = &now
-	config.DB.Save(&user)
+	config.DB.WithContext(c).Model(user).Update("last_login", now)
 
-	// Generate JWT token
-	token, err := generateToken(&user)
+	// Start a session
+	tokens, err := session.NewService().Create(user, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
+	lockout.NewService().Succeed(user.Email)
+	c.Set("user_id", user.ID)
+	c.Set("session_id", tokens.SessionID)
+	audit.RecordAuth(c, models.AuthEventLoginSuccess, user, "", method)
+
 	c.JSON(http.StatusOK, AuthResponse{
-		Token: token,
-		User:  user,
+		Tokens: *tokens,
+		User:   *user,
 	})
 }
 
@@ -90,71 +128,73 @@ func RegisterHandler(c *gin.Context) {
 		return
 	}
 
+	settings := registrationSettings()
+	if !settings.AllowsEmail(req.Email) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Registration is not open to this email domain"})
+		return
+	}
+
 	// Check if user already exists
 	var existingUser models.User
-	if err := config.DB.Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
+	if err := config.DB.WithContext(c).Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
 		return
 	}
 
-	// Set default role
-	role := models.RoleUser
-	if req.Role != "" {
-		// Only allow setting role if current user is admin
-		if user, exists := c.Get("user"); exists {
-			if u, ok := user.(*models.User); ok && u.Role == models.RoleAdmin {
-				switch req.Role {
-				case string(models.RoleAdmin), string(models.RoleBlogger), string(models.RoleUser):
-					role = models.UserRole(req.Role)
-				}
-			}
-		}
-	}
-
-	// Create user
+	// Create user; privileged roles are only given through admin invites
 	user := models.User{
 		Name:     req.Name,
 		Email:    req.Email,
-		Role:     role,
+		Role:     models.RoleUser,
 		IsActive: true,
 	}
+	switch models.UserRole(req.Role) {
+	case "", models.RoleUser:
+	case models.RoleTrader:
+		// Market data sign-up
+		if settings.MarketDataApproval {
+			user.RequestedRole = models.RoleTrader
+			user.ApprovalStatus = models.ApprovalPending
+		} else {
+			user.Role = models.RoleTrader
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be user or trader; other roles are by invitation"})
+		return
+	}
 
 	// Set password
 	user.Password = req.Password
 
 	// Save user
-	if err := config.DB.Create(&user).Error; err != nil {
+	if err := config.DB.WithContext(c).Create(&user).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
 
-	// Generate token
-	token, err := generateToken(&user)
+	sendVerificationEmail(&user)
+
+	// Start a session, restricted until the email is verified
+	tokens, err := session.NewService().Create(&user, c.Request.UserAgent(), c.ClientIP())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
+	message := "Check your email to verify your account"
+	if user.ApprovalStatus == models.ApprovalPending {
+		message += "; market data access will be enabled once an administrator approves it"
+	}
 	c.JSON(http.StatusCreated, AuthResponse{
-		Token: token,
-		User:  user,
+		Tokens:  *tokens,
+		User:    user,
+		Message: message,
 	})
 }
 
-// generateToken creates a JWT token for a user
-func generateToken(user *models.User) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
-	claims := &Claims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   string(user.Role),
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "gcx-cms",
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+// JWKSHandler publishes the public keys GCX tokens are signed with, so other services can
+// verify them
+func JWKSHandler(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, token.GetService().Keyring().JWKS())
 }
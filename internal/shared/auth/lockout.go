@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/lockout"
+	"gcx-cms/internal/shared/mail"
+	"gcx-cms/internal/shared/models"
+)
+
+type UnlockRequest struct {
+	Email string `json:"email"`
+	IP    string `json:"ip"`
+}
+
+// checkLoginAllowed refuses a sign-in attempt with 429 while the account or IP address is locked
+// out or has to wait after recent failures. The password is not checked; an attempt let through
+// counts as failed until the guard's Release is called.
+func checkLoginAllowed(c *gin.Context, guard *lockout.Service, user *models.User, email string) bool {
+	wait, reason := guard.Check(email, c.ClientIP())
+	if wait <= 0 {
+		return true
+	}
+	audit.RecordAuth(c, models.AuthEventLoginBlocked, user, email, reason)
+
+	seconds := int(math.Ceil(wait.Seconds()))
+	message := "Too many failed sign-in attempts, try again later"
+	if reason == lockout.ReasonTooFast {
+		message = "Too many failed sign-in attempts, wait before trying again"
+	}
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       message,
+		"code":        reason,
+		"retry_after": seconds,
+	})
+	return false
+}
+
+// loginFailed counts a failed sign-in towards the lockout thresholds and logs it, with any lock
+// it starts. The owner of an account that is locked is told by email.
+func loginFailed(c *gin.Context, guard *lockout.Service, event string, user *models.User, email, detail string) {
+	audit.RecordAuth(c, event, user, email, detail)
+
+	locked, err := guard.Fail(email, c.ClientIP())
+	if err != nil {
+		log.Printf("Warning: Failed to record failed sign-in for %s: %v", email, err)
+	}
+	for _, reason := range locked {
+		audit.RecordAuth(c, models.AuthEventLockout, user, email, reason)
+		if reason == lockout.ReasonAccountLocked && user != nil {
+			mail.SendAsync(mail.Message{
+				To:      user.Email,
+				Subject: "Your GCX account was temporarily locked",
+				Body: fmt.Sprintf("Hello %s,\n\nThere were too many failed attempts to sign in to your GCX account "+
+					"from %s, so signing in has been paused for a while.\n\nIf this was not you, consider "+
+					"resetting your password once the lock has passed.\n", user.Name, c.ClientIP()),
+			})
+		}
+	}
+}
+
+// ListAuthEventsHandler searches the authentication log (admin)
+func ListAuthEventsHandler(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	query := config.DB.WithContext(c).Model(&models.AuthEvent{})
+	if userID := c.Query("user_id"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	if email := strings.TrimSpace(c.Query("email")); email != "" {
+		query = query.Where("email = ?", email)
+	}
+	if event := c.Query("event"); event != "" {
+		query = query.Where("event IN ?", strings.Split(event, ","))
+	}
+	if ip := c.Query("ip"); ip != "" {
+		query = query.Where("ip_address = ?", ip)
+	}
+	for param, op := range map[string]string{"from": ">=", "to": "<="} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be an RFC 3339 time"})
+			return
+		}
+		query = query.Where("created_at "+op+" ?", t)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch auth log"})
+		return
+	}
+
+	var events []models.AuthEvent
+	if err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&events).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch auth log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events": events,
+		"total":  total,
+		"page":   page,
+		"limit":  limit,
+	})
+}
+
+// ListLockoutsHandler lists the accounts and IP addresses that are locked out (admin)
+func ListLockoutsHandler(c *gin.Context) {
+	locked, err := lockout.NewService().Locked()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch lockouts"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"lockouts": locked})
+}
+
+// UnlockHandler clears the failed sign-ins and any lock of an account, by email, or of an IP
+// address (admin)
+func UnlockHandler(c *gin.Context) {
+	var req UnlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (req.Email == "") == (req.IP == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Send either email or ip"})
+		return
+	}
+
+	key := lockout.IPKey(req.IP)
+	if req.Email != "" {
+		key = lockout.AccountKey(req.Email)
+	}
+	unlock(c, key, nil, req.Email)
+}
+
+// UnlockUserHandler clears the failed sign-ins and any lock of a user's account (admin)
+func UnlockUserHandler(c *gin.Context) {
+	user, ok := findManagedUser(c)
+	if !ok {
+		return
+	}
+	unlock(c, lockout.AccountKey(user.Email), user, user.Email)
+}
+
+func unlock(c *gin.Context, key string, user *models.User, email string) {
+	cleared, err := lockout.NewService().Unlock(key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock"})
+		return
+	}
+	if !cleared {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No failed sign-ins recorded for this account or address"})
+		return
+	}
+
+	audit.Record(c, "login.unlocked", "login_throttle", key, nil)
+	if user != nil || email != "" {
+		audit.RecordAuth(c, models.AuthEventUnlock, user, email, "")
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Unlocked", "key": key})
+}
@@ -0,0 +1,281 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/oidc"
+	"gcx-cms/internal/shared/rbac"
+	"gcx-cms/internal/shared/token"
+)
+
+// A single sign-on has ten minutes to come back from the provider
+const oidcStateLifetime = 10 * time.Minute
+
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
+
+// OIDCConfigHandler tells the frontend whether single sign-on is available
+func OIDCConfigHandler(c *gin.Context) {
+	cfg := oidc.GetProvider().Config()
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":       cfg.Enabled(),
+		"provider_name": cfg.ProviderName,
+	})
+}
+
+// OIDCLoginHandler starts single sign-on. The frontend keeps the returned state, sends the
+// browser to the authorization URL and, when the provider redirects back, checks the state
+// before posting the code and state to OIDCCallbackHandler.
+func OIDCLoginHandler(c *gin.Context) {
+	provider := oidc.GetProvider()
+	if !provider.Config().Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": oidc.ErrNotConfigured.Error()})
+		return
+	}
+
+	state, errState := token.NewOpaque(24)
+	nonce, errNonce := token.NewOpaque(16)
+	verifier, errVerifier := oidc.NewVerifier()
+	if errState != nil || errNonce != nil || errVerifier != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start single sign-on"})
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(c.Request.Context(), state, nonce, verifier, c.Query("login_hint"))
+	if err != nil {
+		log.Printf("Warning: Failed to start single sign-on: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": oidc.ErrProviderFailure.Error()})
+		return
+	}
+
+	// Sign-ins that never came back are cleared as new ones start
+	config.DB.WithContext(c).Where("expires_at < ?", time.Now()).Delete(&models.OIDCLoginState{})
+	if err := config.DB.WithContext(c).Create(&models.OIDCLoginState{
+		StateHash:    token.Hash(state),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(oidcStateLifetime),
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start single sign-on"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"authorization_url": authURL,
+		"state":             state,
+		"expires_in":        int(oidcStateLifetime.Seconds()),
+	})
+}
+
+// OIDCCallbackHandler completes single sign-on with the code and state the provider sent back,
+// signing the user in as a password sign-in would
+func OIDCCallbackHandler(c *gin.Context) {
+	var req OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	provider := oidc.GetProvider()
+	if !provider.Config().Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": oidc.ErrNotConfigured.Error()})
+		return
+	}
+
+	// Each state works once
+	var state models.OIDCLoginState
+	if err := config.DB.WithContext(c).Where("state_hash = ?", token.Hash(req.State)).First(&state).Error; err != nil ||
+		time.Now().After(state.ExpiresAt) ||
+		config.DB.WithContext(c).Where("id = ?", state.ID).Delete(&models.OIDCLoginState{}).RowsAffected == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired sign-in, please start again"})
+		return
+	}
+
+	identity, err := provider.Exchange(c.Request.Context(), req.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		log.Printf("Warning: Single sign-on failed: %v", err)
+		audit.RecordAuth(c, models.AuthEventLoginFailure, nil, "", "oidc: "+err.Error())
+		switch {
+		case errors.Is(err, oidc.ErrProviderFailure):
+			c.JSON(http.StatusBadGateway, gin.H{"error": oidc.ErrProviderFailure.Error()})
+		case errors.Is(err, oidc.ErrInvalidIDToken):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": oidc.ErrInvalidIDToken.Error()})
+		default:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": oidc.ErrExchangeFailed.Error()})
+		}
+		return
+	}
+
+	user, ok := ssoUser(c, provider.Config(), identity)
+	if !ok {
+		return
+	}
+	if !user.IsActive {
+		audit.RecordAuth(c, models.AuthEventLoginFailure, user, "", "oidc: account_disabled")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is disabled"})
+		return
+	}
+
+	// Two-factor authentication set up in GCX still applies
+	if user.TwoFactorEnabled {
+		startTwoFactorChallenge(c, user)
+		return
+	}
+	completeLogin(c, user, "oidc")
+}
+
+// ssoUser finds the user of a provider identity: by its linked subject, else by email, linking
+// it, else by creating an account. With role mappings the user's role follows their groups.
+// It responds itself when the identity cannot sign in.
+func ssoUser(c *gin.Context, cfg oidc.Config, identity *oidc.Identity) (*models.User, bool) {
+	refuse := func(status int, message, detail string) (*models.User, bool) {
+		audit.RecordAuth(c, models.AuthEventLoginFailure, nil, identity.Email, "oidc: "+detail)
+		c.JSON(status, gin.H{"error": message})
+		return nil, false
+	}
+
+	role, hasRole := cfg.RoleFor(identity.Groups)
+	if hasRole && !rbac.GetService().RoleExists(role) {
+		log.Printf("Warning: OIDC role mapping names unknown role %q", role)
+		hasRole = false
+	}
+	if len(cfg.RoleMappings) > 0 && !hasRole {
+		return refuse(http.StatusForbidden, "Your groups do not give access to GCX", "no_role")
+	}
+
+	var user models.User
+	var link models.ExternalIdentity
+	err := config.DB.WithContext(c).Where("issuer = ? AND subject = ?", identity.Issuer, identity.Subject).First(&link).Error
+	switch {
+	case err == nil:
+		if err := config.DB.WithContext(c).First(&user, link.UserID).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+			return nil, false
+		}
+
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if identity.Email == "" {
+			return refuse(http.StatusForbidden, "The identity provider did not share your email address", "no_email")
+		}
+		if cfg.RequireVerifiedEmail && !identity.EmailVerified {
+			return refuse(http.StatusForbidden, "The identity provider has not verified your email address", "email_unverified")
+		}
+
+		err := config.DB.WithContext(c).Where("email = ?", identity.Email).First(&user).Error
+		switch {
+		case err == nil:
+			var linked int64
+			config.DB.WithContext(c).Model(&models.ExternalIdentity{}).Where("user_id = ? AND issuer = ?", user.ID, identity.Issuer).Count(&linked)
+			if linked > 0 {
+				return refuse(http.StatusConflict, "Your GCX account is linked to a different identity", "linked_elsewhere")
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if !cfg.AutoCreate {
+				return refuse(http.StatusForbidden, "There is no GCX account for "+identity.Email+", ask an administrator for access", "no_account")
+			}
+			if !hasRole {
+				return refuse(http.StatusForbidden, "Your groups do not give access to GCX", "no_role")
+			}
+			return createSSOUser(c, identity, role)
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+			return nil, false
+		}
+
+		link = models.ExternalIdentity{UserID: user.ID, Issuer: identity.Issuer, Subject: identity.Subject}
+		if err := config.DB.WithContext(c).Create(&link).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+			return nil, false
+		}
+		audit.Record(c, "user.sso_linked", "user", user.ID, map[string]interface{}{
+			"issuer":  identity.Issuer,
+			"subject": identity.Subject,
+		})
+
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+		return nil, false
+	}
+
+	// With role mappings the provider decides the role of the accounts it created. Accounts that
+	// existed before keep the role administrators gave them, and the last admin is never demoted.
+	previous := user.Role
+	sync := len(cfg.RoleMappings) > 0 && link.Provisioned && previous != role
+	if sync && previous == models.RoleAdmin && isLastActiveAdmin(&user) {
+		log.Printf("Warning: OIDC role mapping would demote the last active admin, user %d; role kept", user.ID)
+		sync = false
+	}
+	if sync {
+		if err := config.DB.WithContext(c).Model(&user).Update("role", role).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign in"})
+			return nil, false
+		}
+		audit.Record(c, "user.role_synced", "user", user.ID, map[string]interface{}{
+			"role":   audit.Change{From: previous, To: role},
+			"source": identity.Issuer,
+		})
+	}
+
+	now := time.Now()
+	config.DB.WithContext(c).Model(&link).Updates(map[string]interface{}{"email": identity.Email, "last_login_at": now})
+	return &user, true
+}
+
+// createSSOUser creates the account of someone signing in through the provider for the first
+// time, with the identity linked. They have no usable password until they reset it.
+func createSSOUser(c *gin.Context, identity *oidc.Identity, role models.UserRole) (*models.User, bool) {
+	password, err := token.NewOpaque(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
+		return nil, false
+	}
+	name := identity.Name
+	if name == "" {
+		name = identity.Email
+	}
+
+	now := time.Now()
+	user := models.User{
+		Name:            name,
+		Email:           identity.Email,
+		Password:        password,
+		Role:            role,
+		IsActive:        true,
+		EmailVerifiedAt: &now,
+	}
+	err = config.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.ExternalIdentity{
+			UserID:      user.ID,
+			Issuer:      identity.Issuer,
+			Subject:     identity.Subject,
+			Email:       identity.Email,
+			Provisioned: true,
+			LastLoginAt: &now,
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
+		return nil, false
+	}
+
+	audit.Record(c, "user.sso_created", "user", user.ID, map[string]interface{}{
+		"email":   user.Email,
+		"role":    user.Role,
+		"issuer":  identity.Issuer,
+		"subject": identity.Subject,
+	})
+	return &user, true
+}
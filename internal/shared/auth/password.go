@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/lockout"
+	"gcx-cms/internal/shared/mail"
+	"gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/session"
+	"gcx-cms/internal/shared/token"
+)
+
+// Password reset links expire after an hour, and a new one is sent at most once a minute
+const (
+	passwordResetLifetime = time.Hour
+	passwordResetInterval = time.Minute
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+// ForgotPasswordHandler emails a password reset link. It answers the same whether or not the
+// email is registered, so it cannot be used to find accounts.
+func ForgotPasswordHandler(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{"message": "If an account exists for this email, a password reset link has been sent"}
+
+	var user models.User
+	if err := config.DB.WithContext(c).Where("email = ?", strings.TrimSpace(req.Email)).First(&user).Error; err != nil || !user.IsActive {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// Don't send another link while the last one is fresh
+	var recent int64
+	config.DB.WithContext(c).Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND created_at > ?", user.ID, time.Now().Add(-passwordResetInterval)).
+		Count(&recent)
+	if recent > 0 {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	if err := issuePasswordReset(&user, c.ClientIP()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reset link"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// issuePasswordReset emails a user a new reset link, replacing any earlier one
+func issuePasswordReset(user *models.User, ipAddress string) error {
+	raw, err := token.NewOpaque(32)
+	if err != nil {
+		return err
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the newest link works
+		if err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", user.ID).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: token.Hash(raw),
+			ExpiresAt: time.Now().Add(passwordResetLifetime),
+			IPAddress: ipAddress,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	mail.SendAsync(mail.Message{
+		To:      user.Email,
+		Subject: "Reset your GCX password",
+		Body: fmt.Sprintf("Hello %s,\n\nA password reset was requested for your GCX account. "+
+			"Use the link below within the next hour to choose a new password:\n\n%s/reset-password?token=%s\n\n"+
+			"If you did not request this, you can ignore this email; your password has not changed.\n",
+			user.Name, mail.AppURL(), raw),
+	})
+	return nil
+}
+
+// ResetPasswordHandler sets a new password with a reset link token and signs the user out of
+// every session
+func ResetPasswordHandler(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var reset models.PasswordResetToken
+	if err := config.DB.WithContext(c).Where("token_hash = ?", token.Hash(req.Token)).First(&reset).Error; err != nil ||
+		reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.WithContext(c).First(&user, reset.UserID).Error; err != nil || !user.IsActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
+
+	user.Password = req.Password
+	if err := user.HashPassword(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	err := config.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		// Conditional so the token works once even under concurrent requests
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", reset.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&user).Updates(map[string]interface{}{
+			"password":            user.Password,
+			"must_reset_password": false,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	if _, err := session.NewService().RevokeAll(user.ID, "", models.SessionRevokedPasswordReset); err != nil {
+		log.Printf("Warning: Failed to revoke sessions of user %d after password reset: %v", user.ID, err)
+	}
+	// A new password also clears failed sign-ins, so the owner is not kept locked out
+	lockout.NewService().Succeed(user.Email)
+	audit.RecordAuth(c, models.AuthEventPasswordReset, &user, "", "")
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset, please sign in again"})
+}
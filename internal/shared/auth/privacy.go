@@ -0,0 +1,270 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/privacy"
+)
+
+// PersonalDataRequest names the person to search for or export; it is sent in the body, as
+// query strings end up in access logs
+type PersonalDataRequest struct {
+	Email  string `json:"email"`
+	Phone  string `json:"phone"`
+	Format string `json:"format"` // Export only: zip, the default, or json
+}
+
+type EraseRequest struct {
+	Email   string `json:"email"`
+	Phone   string `json:"phone"`
+	Confirm bool   `json:"confirm"` // Must be true; search first to review the plan
+}
+
+type LegalHoldRequest struct {
+	Email      string `json:"email"`
+	Phone      string `json:"phone"`
+	RecordType string `json:"record_type"`
+	RecordID   *uint  `json:"record_id"`
+	Reason     string `json:"reason" binding:"required,max=1000"`
+	Reference  string `json:"reference" binding:"max=100"`
+}
+
+// SearchPersonalDataHandler finds every record held about a person by email and/or phone, with
+// the legal holds on them and what erasure would do with each (admin)
+func SearchPersonalDataHandler(c *gin.Context) {
+	var req PersonalDataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	subject, ok := dataSubject(c, req.Email, req.Phone)
+	if !ok {
+		return
+	}
+
+	service := privacy.NewService()
+	records, err := service.Find(subject)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search personal data"})
+		return
+	}
+	holds, err := service.ActiveHolds(subject, records)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search personal data"})
+		return
+	}
+	plan, err := service.Plan(records, holds)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search personal data"})
+		return
+	}
+	audit.Record(c, "privacy.searched", "data_subject", subject.Key(), map[string]interface{}{
+		"counts": records.Counts(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"subject":     subject,
+		"counts":      records.Counts(),
+		"records":     records,
+		"holds":       holds,
+		"plan":        plan,
+		"plan_counts": privacy.CountActions(plan),
+	})
+}
+
+// ExportPersonalDataHandler downloads every record held about a person, as a ZIP archive of
+// JSON files or with format json as one JSON document (admin)
+func ExportPersonalDataHandler(c *gin.Context) {
+	var req PersonalDataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	subject, ok := dataSubject(c, req.Email, req.Phone)
+	if !ok {
+		return
+	}
+	format := req.Format
+	if format == "" {
+		format = "zip"
+	}
+	if format != "zip" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be zip or json"})
+		return
+	}
+
+	records, err := privacy.NewService().Find(subject)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export personal data"})
+		return
+	}
+	bundle := privacy.NewBundle(subject, records)
+	filename := fmt.Sprintf("personal-data-%s.%s", bundle.GeneratedAt.Format("20060102-150405"), format)
+
+	var data []byte
+	contentType := "application/zip"
+	if format == "json" {
+		data, err = json.MarshalIndent(bundle, "", "  ")
+		contentType = "application/json"
+	} else {
+		data, err = bundle.Zip()
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export personal data"})
+		return
+	}
+	audit.Record(c, "privacy.exported", "data_subject", subject.Key(), map[string]interface{}{
+		"format": format,
+		"counts": bundle.Counts,
+	})
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// ErasePersonalDataHandler erases or anonymises every record held about a person, except those
+// under a legal hold or a retention rule, and reports what was done with each (admin)
+func ErasePersonalDataHandler(c *gin.Context) {
+	var req EraseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	subject, ok := dataSubject(c, req.Email, req.Phone)
+	if !ok {
+		return
+	}
+	if !req.Confirm {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Set confirm to true to erase; search first to review what will be erased"})
+		return
+	}
+
+	erasure, err := privacy.NewService().Erase(subject)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to erase personal data"})
+		return
+	}
+	audit.Record(c, "privacy.erased", "data_subject", subject.Key(), map[string]interface{}{
+		"counts": erasure.Counts,
+		"steps":  erasure.Steps,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"erasure": erasure})
+}
+
+// ListLegalHoldsHandler lists legal holds in force, or all with ?status=all, optionally for an
+// ?email= or ?phone= (admin)
+func ListLegalHoldsHandler(c *gin.Context) {
+	query := config.DB.WithContext(c).Order("created_at DESC")
+	if c.Query("status") != "all" {
+		query = query.Where("released_at IS NULL")
+	}
+	if email := strings.ToLower(strings.TrimSpace(c.Query("email"))); email != "" {
+		query = query.Where("LOWER(email) = ?", email)
+	}
+	if phone := c.Query("phone"); phone != "" {
+		query = query.Where("phone = ?", privacy.NormalizePhone(phone))
+	}
+
+	var holds []models.LegalHold
+	if err := query.Find(&holds).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch legal holds"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"holds": holds})
+}
+
+// CreateLegalHoldHandler keeps a person's records, or only those of one table or one record,
+// from erasure (admin)
+func CreateLegalHoldHandler(c *gin.Context) {
+	var req LegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	subject, ok := dataSubject(c, req.Email, req.Phone)
+	if !ok {
+		return
+	}
+	if req.RecordType != "" && !privacy.IsValidRecordType(req.RecordType) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid record_type", "record_types": privacy.RecordTypes()})
+		return
+	}
+	if req.RecordID != nil && req.RecordType == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "record_id needs a record_type"})
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required"})
+		return
+	}
+
+	createdBy := c.GetUint("user_id")
+	hold := models.LegalHold{
+		Email:       subject.Email,
+		Phone:       subject.Phone,
+		RecordType:  req.RecordType,
+		RecordID:    req.RecordID,
+		Reason:      strings.TrimSpace(req.Reason),
+		Reference:   strings.TrimSpace(req.Reference),
+		CreatedByID: &createdBy,
+	}
+	if err := config.DB.WithContext(c).Create(&hold).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create legal hold"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"hold": hold})
+}
+
+// ReleaseLegalHoldHandler lifts a legal hold; it is kept as a record (admin)
+func ReleaseLegalHoldHandler(c *gin.Context) {
+	var hold models.LegalHold
+	if err := config.DB.WithContext(c).First(&hold, "id = ?", c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Legal hold not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch legal hold"})
+		}
+		return
+	}
+	if !hold.IsActive() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Legal hold already released"})
+		return
+	}
+
+	now := time.Now()
+	releasedBy := c.GetUint("user_id")
+	if err := config.DB.WithContext(c).Model(&hold).Updates(map[string]interface{}{
+		"released_at":    now,
+		"released_by_id": releasedBy,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to release legal hold"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"hold": hold})
+}
+
+// dataSubject reads the person a request is about, responding 400 if neither an email nor a
+// usable phone number is given
+func dataSubject(c *gin.Context, email, phone string) (privacy.Subject, bool) {
+	subject, err := privacy.NewSubject(email, phone)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return subject, false
+	}
+	return subject, true
+}
@@ -0,0 +1,343 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/mail"
+	"gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/rbac"
+	"gcx-cms/internal/shared/session"
+	"gcx-cms/internal/shared/token"
+)
+
+// inviteLifetime is how long an invite link can be accepted
+const inviteLifetime = 7 * 24 * time.Hour
+
+type RegistrationSettingsRequest struct {
+	AllowedDomains     []string `json:"allowed_domains"`
+	MarketDataApproval *bool    `json:"market_data_approval" binding:"required"`
+}
+
+type InviteRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Name  string `json:"name" binding:"max=100"`
+	Role  string `json:"role" binding:"required"`
+}
+
+type AcceptInviteRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Name     string `json:"name" binding:"max=100"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+// registrationSettings loads the self-registration settings, or the defaults if none are saved
+func registrationSettings() models.RegistrationSettings {
+	settings := models.DefaultRegistrationSettings()
+	config.DB.Order("id").Limit(1).Find(&settings)
+	return settings
+}
+
+// GetRegistrationSettingsHandler returns the self-registration settings (admin)
+func GetRegistrationSettingsHandler(c *gin.Context) {
+	settings := registrationSettings()
+	c.JSON(http.StatusOK, gin.H{
+		"settings":        settings,
+		"allowed_domains": settings.Domains(),
+	})
+}
+
+// UpdateRegistrationSettingsHandler sets which email domains may self-register and whether
+// market data sign-ups need approval (admin)
+func UpdateRegistrationSettingsHandler(c *gin.Context) {
+	var req RegistrationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	domains := make([]string, 0, len(req.AllowedDomains))
+	for _, d := range req.AllowedDomains {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == "" {
+			continue
+		}
+		if strings.ContainsAny(d, ", @") || !strings.Contains(d, ".") || strings.Contains(d[1:], "*") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid domain %q", d)})
+			return
+		}
+		domains = append(domains, d)
+	}
+
+	settings := registrationSettings()
+	previous := settings
+	settings.AllowedDomains = strings.Join(domains, ",")
+	settings.MarketDataApproval = *req.MarketDataApproval
+	settings.UpdatedBy = c.GetUint("user_id")
+	if err := config.DB.WithContext(c).Save(&settings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save registration settings"})
+		return
+	}
+	audit.Record(c, "registration.settings_updated", "registration_settings", settings.ID, map[string]audit.Change{
+		"allowed_domains":      {From: previous.AllowedDomains, To: settings.AllowedDomains},
+		"market_data_approval": {From: previous.MarketDataApproval, To: settings.MarketDataApproval},
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"settings":        settings,
+		"allowed_domains": settings.Domains(),
+	})
+}
+
+// ListPendingApprovalsHandler lists sign-ups waiting for a requested role (admin)
+func ListPendingApprovalsHandler(c *gin.Context) {
+	var users []models.User
+	if err := config.DB.WithContext(c).Where("approval_status = ?", models.ApprovalPending).
+		Order("created_at ASC").
+		Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pending sign-ups"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
+// ApproveRegistrationHandler grants a pending sign-up its requested role (admin)
+func ApproveRegistrationHandler(c *gin.Context) {
+	decideRegistration(c, true)
+}
+
+// RejectRegistrationHandler declines a pending sign-up; the account stays a regular user (admin)
+func RejectRegistrationHandler(c *gin.Context) {
+	decideRegistration(c, false)
+}
+
+func decideRegistration(c *gin.Context, approve bool) {
+	var user models.User
+	if err := config.DB.WithContext(c).First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if user.ApprovalStatus != models.ApprovalPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "User has no pending sign-up"})
+		return
+	}
+
+	previousRole := user.Role
+	updates := map[string]interface{}{"approval_status": models.ApprovalRejected}
+	action := "user.registration_rejected"
+	subject := "Your GCX market data request"
+	body := "Your request for market data access was not approved. Contact us if you have any questions."
+	if approve {
+		updates = map[string]interface{}{"approval_status": models.ApprovalApproved, "role": user.RequestedRole}
+		action = "user.registration_approved"
+		body = "Your request for market data access has been approved. Sign in again to start using it."
+	}
+	if err := config.DB.WithContext(c).Model(&user).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+	config.DB.WithContext(c).First(&user, user.ID)
+	audit.Record(c, action, "user", user.ID, map[string]audit.Change{
+		"role":            {From: previousRole, To: user.Role},
+		"approval_status": {From: models.ApprovalPending, To: user.ApprovalStatus},
+	})
+
+	mail.SendAsync(mail.Message{
+		To:      user.Email,
+		Subject: subject,
+		Body:    fmt.Sprintf("Hello %s,\n\n%s\n", user.Name, body),
+	})
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// CreateInviteHandler invites someone to create an account with the given role (admin).
+// A pending invite for the same email is replaced.
+func CreateInviteHandler(c *gin.Context) {
+	var req InviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	role := models.UserRole(req.Role)
+	if !rbac.GetService().RoleExists(role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+	if !canGrant(c, role, rbac.GetService().Permissions(role)) {
+		return
+	}
+
+	email := strings.TrimSpace(req.Email)
+	var count int64
+	config.DB.WithContext(c).Model(&models.User{}).Where("email = ?", email).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
+		return
+	}
+
+	raw, err := token.NewOpaque(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invite"})
+		return
+	}
+
+	invite := models.UserInvite{
+		Email:     email,
+		Name:      strings.TrimSpace(req.Name),
+		Role:      role,
+		TokenHash: token.Hash(raw),
+		InvitedBy: c.GetUint("user_id"),
+		ExpiresAt: time.Now().Add(inviteLifetime),
+	}
+	err = config.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserInvite{}).
+			Where("email = ? AND accepted_at IS NULL AND revoked_at IS NULL", email).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Create(&invite).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invite"})
+		return
+	}
+	audit.Record(c, "invite.created", "invite", invite.ID, map[string]interface{}{
+		"email": invite.Email,
+		"role":  invite.Role,
+	})
+
+	mail.SendAsync(mail.Message{
+		To:      invite.Email,
+		Subject: "You have been invited to GCX",
+		Body: fmt.Sprintf("Hello%s,\n\nYou have been invited to a GCX account with the %s role. "+
+			"Accept the invitation and choose a password within 7 days:\n\n%s/accept-invite?token=%s\n",
+			prefixSpace(invite.Name), invite.Role, mail.AppURL(), raw),
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"invite": invite})
+}
+
+// ListInvitesHandler lists invites, pending ones by default or all with ?status=all (admin)
+func ListInvitesHandler(c *gin.Context) {
+	query := config.DB.WithContext(c).Order("created_at DESC")
+	if c.Query("status") != "all" {
+		query = query.Where("accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", time.Now())
+	}
+
+	var invites []models.UserInvite
+	if err := query.Find(&invites).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invites"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invites": invites})
+}
+
+// RevokeInviteHandler cancels a pending invite (admin)
+func RevokeInviteHandler(c *gin.Context) {
+	result := config.DB.WithContext(c).Model(&models.UserInvite{}).
+		Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", c.Param("id")).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke invite"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pending invite not found"})
+		return
+	}
+	audit.Record(c, "invite.revoked", "invite", c.Param("id"), nil)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invite revoked"})
+}
+
+// AcceptInviteHandler creates the invited account with the invite's role and signs it in.
+// The invite link proves the email address, so the account starts verified.
+func AcceptInviteHandler(c *gin.Context) {
+	var req AcceptInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var invite models.UserInvite
+	if err := config.DB.WithContext(c).Where("token_hash = ?", token.Hash(req.Token)).First(&invite).Error; err != nil || !invite.IsPending() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired invite"})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = invite.Name
+	}
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+
+	now := time.Now()
+	user := models.User{
+		Name:            name,
+		Email:           invite.Email,
+		Password:        req.Password,
+		Role:            invite.Role,
+		IsActive:        true,
+		EmailVerifiedAt: &now,
+	}
+	err := config.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.UserInvite{}).
+			Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", invite.ID).
+			Update("accepted_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		var count int64
+		tx.Model(&models.User{}).Where("email = ?", invite.Email).Count(&count)
+		if count > 0 {
+			return gorm.ErrDuplicatedKey
+		}
+		return tx.Create(&user).Error
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired invite"})
+		return
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		c.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		return
+	}
+
+	tokens, err := session.NewService().Create(&user, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, AuthResponse{
+		Tokens: *tokens,
+		User:   user,
+	})
+}
+
+func prefixSpace(s string) string {
+	if s == "" {
+		return ""
+	}
+	return " " + s
+}
@@ -0,0 +1,271 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/rbac"
+)
+
+// roleNamePattern keeps role names short lowercase identifiers
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,19}$`)
+
+type CreateRoleRequest struct {
+	Name             string   `json:"name" binding:"required"`
+	Description      string   `json:"description" binding:"max=255"`
+	Permissions      []string `json:"permissions" binding:"required"`
+	RequireTwoFactor bool     `json:"require_two_factor"`
+}
+
+// UpdateRoleRequest changes only the fields sent
+type UpdateRoleRequest struct {
+	Description      *string  `json:"description" binding:"omitempty,max=255"`
+	Permissions      []string `json:"permissions"`
+	RequireTwoFactor *bool    `json:"require_two_factor"`
+}
+
+// RoleListItem is a role with the number of users assigned to it
+type RoleListItem struct {
+	models.Role
+	Users int64 `json:"users"`
+}
+
+// MyPermissionsHandler returns the current user's role and the permissions it grants
+func MyPermissionsHandler(c *gin.Context) {
+	user, ok := c.MustGet("user").(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"role":        user.Role,
+		"permissions": rbac.GetService().Permissions(user.Role),
+	})
+}
+
+// ListPermissionsHandler returns the catalogue of permissions roles can grant (admin)
+func ListPermissionsHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"permissions": models.AllPermissions})
+}
+
+// ListRolesHandler lists roles with the number of users in each (admin)
+func ListRolesHandler(c *gin.Context) {
+	var roles []models.Role
+	if err := config.DB.WithContext(c).Order("is_system DESC, name").Find(&roles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch roles"})
+		return
+	}
+
+	var counts []struct {
+		Role  models.UserRole
+		Count int64
+	}
+	config.DB.WithContext(c).Model(&models.User{}).Select("role, COUNT(*) AS count").Group("role").Scan(&counts)
+	userCounts := make(map[models.UserRole]int64, len(counts))
+	for _, row := range counts {
+		userCounts[row.Role] = row.Count
+	}
+
+	items := make([]RoleListItem, len(roles))
+	for i, r := range roles {
+		items[i] = RoleListItem{Role: r, Users: userCounts[r.Name]}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": items})
+}
+
+// CreateRoleHandler creates a custom role (admin)
+func CreateRoleHandler(c *gin.Context) {
+	var req CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if !roleNamePattern.MatchString(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role name must be 2-20 lowercase letters, digits, - or _, starting with a letter"})
+		return
+	}
+	perms, ok := validPermissions(c, req.Permissions)
+	if !ok || !canGrant(c, models.UserRole(name), perms) {
+		return
+	}
+
+	var count int64
+	config.DB.WithContext(c).Model(&models.Role{}).Where("name = ?", name).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Role already exists"})
+		return
+	}
+
+	role := models.Role{
+		Name:             models.UserRole(name),
+		Description:      strings.TrimSpace(req.Description),
+		RequireTwoFactor: req.RequireTwoFactor,
+	}
+	role.SetPermissions(perms)
+	if err := config.DB.WithContext(c).Create(&role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create role"})
+		return
+	}
+	rbac.GetService().Invalidate()
+	audit.Record(c, "role.created", "role", role.Name, map[string]interface{}{
+		"permissions":        role.PermissionList(),
+		"require_two_factor": role.RequireTwoFactor,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{"role": role})
+}
+
+// UpdateRoleHandler changes a role's description, permissions and two-factor requirement
+// (admin). Roles cannot be renamed, and the admin role's permissions are fixed.
+func UpdateRoleHandler(c *gin.Context) {
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, ok := findRole(c)
+	if !ok {
+		return
+	}
+	if role.Name == models.RoleAdmin && req.Permissions != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The admin role always has every permission"})
+		return
+	}
+	perms := role.PermissionList()
+	if req.Permissions != nil {
+		if perms, ok = validPermissions(c, req.Permissions); !ok {
+			return
+		}
+	}
+	if !canGrant(c, role.Name, append(perms, role.PermissionList()...)) {
+		return
+	}
+
+	previous := *role
+	changes := map[string]audit.Change{}
+	updates := map[string]interface{}{}
+	if req.Permissions != nil {
+		role.SetPermissions(perms)
+		updates["permissions"] = role.Permissions
+		changes["permissions"] = audit.Change{From: previous.PermissionList(), To: role.PermissionList()}
+	}
+	if req.Description != nil {
+		role.Description = strings.TrimSpace(*req.Description)
+		updates["description"] = role.Description
+		changes["description"] = audit.Change{From: previous.Description, To: role.Description}
+	}
+	if req.RequireTwoFactor != nil {
+		role.RequireTwoFactor = *req.RequireTwoFactor
+		updates["require_two_factor"] = role.RequireTwoFactor
+		changes["require_two_factor"] = audit.Change{From: previous.RequireTwoFactor, To: role.RequireTwoFactor}
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusOK, gin.H{"role": role})
+		return
+	}
+	if err := config.DB.WithContext(c).Model(role).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+	rbac.GetService().Invalidate()
+	audit.Record(c, "role.updated", "role", role.Name, changes)
+
+	c.JSON(http.StatusOK, gin.H{"role": role})
+}
+
+// DeleteRoleHandler deletes a custom role that no user, invite or pending sign-up uses (admin)
+func DeleteRoleHandler(c *gin.Context) {
+	role, ok := findRole(c)
+	if !ok {
+		return
+	}
+	if role.IsSystem {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Built-in roles cannot be deleted"})
+		return
+	}
+
+	var users, invites int64
+	config.DB.WithContext(c).Model(&models.User{}).
+		Where("role = ? OR (requested_role = ? AND approval_status = ?)", role.Name, role.Name, models.ApprovalPending).
+		Count(&users)
+	config.DB.WithContext(c).Model(&models.UserInvite{}).
+		Where("role = ? AND accepted_at IS NULL AND revoked_at IS NULL", role.Name).
+		Count(&invites)
+	if users > 0 || invites > 0 {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "Role is in use; reassign its users and revoke its invites first",
+			"users":   users,
+			"invites": invites,
+		})
+		return
+	}
+
+	if err := config.DB.WithContext(c).Delete(role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete role"})
+		return
+	}
+	rbac.GetService().Invalidate()
+	audit.Record(c, "role.deleted", "role", role.Name, map[string]interface{}{
+		"permissions": role.PermissionList(),
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted"})
+}
+
+// canGrant checks that the current user holds every permission of a role before they assign
+// it, change it or change who has it, so nobody can hand out access they lack. Only admins
+// can grant the admin role. It responds 403 when they cannot.
+func canGrant(c *gin.Context, role models.UserRole, perms []string) bool {
+	value, _ := c.Get("user_role")
+	actor, _ := value.(models.UserRole)
+	if role == models.RoleAdmin && actor != models.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can manage admin access"})
+		return false
+	}
+	if !rbac.GetService().Has(actor, perms...) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot manage access beyond your own permissions"})
+		return false
+	}
+	return true
+}
+
+// findRole loads the role in the :id parameter, responding 404 if there is none
+func findRole(c *gin.Context) (*models.Role, bool) {
+	var role models.Role
+	if err := config.DB.WithContext(c).First(&role, "id = ?", c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+		return nil, false
+	}
+	return &role, true
+}
+
+// validPermissions checks permissions against the catalogue and removes duplicates,
+// responding 400 on an unknown permission
+func validPermissions(c *gin.Context, perms []string) ([]string, bool) {
+	seen := make(map[string]bool, len(perms))
+	valid := make([]string, 0, len(perms))
+	for _, p := range perms {
+		p = strings.TrimSpace(p)
+		if !models.IsValidPermission(p) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unknown permission %q", p)})
+			return nil, false
+		}
+		if !seen[p] {
+			seen[p] = true
+			valid = append(valid, p)
+		}
+	}
+	return valid, true
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/session"
+)
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// SessionResponse is a session as listed to its user
+type SessionResponse struct {
+	models.Session
+	Current bool `json:"current"`
+}
+
+// RefreshHandler exchanges a refresh token for a new access and refresh token
+func RefreshHandler(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, user, err := session.NewService().Refresh(req.RefreshToken, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		if errors.Is(err, session.ErrRefreshTokenReused) {
+			audit.RecordAuth(c, models.AuthEventTokenReuse, nil, "", "session revoked")
+		}
+		switch {
+		case errors.Is(err, session.ErrInvalidRefreshToken),
+			errors.Is(err, session.ErrRefreshTokenReused),
+			errors.Is(err, session.ErrSessionRevoked),
+			errors.Is(err, session.ErrUserDisabled):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		}
+		return
+	}
+	c.Set("user_id", user.ID)
+	c.Set("session_id", tokens.SessionID)
+	audit.RecordAuth(c, models.AuthEventTokenRefresh, user, "", "")
+
+	c.JSON(http.StatusOK, AuthResponse{
+		Tokens: *tokens,
+		User:   *user,
+	})
+}
+
+// LogoutHandler revokes the current session
+func LogoutHandler(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	sessionID := c.GetString("session_id")
+
+	if err := session.NewService().Revoke(userID, sessionID, models.SessionRevokedLogout); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+	if user, ok := c.Get("user"); ok {
+		audit.RecordAuth(c, models.AuthEventLogout, user.(*models.User), "", "")
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// LogoutAllHandler revokes every session of the current user, on all devices
+func LogoutAllHandler(c *gin.Context) {
+	revoked, err := session.NewService().RevokeAll(c.GetUint("user_id"), "", models.SessionRevokedLogoutAll)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+	if user, ok := c.Get("user"); ok {
+		audit.RecordAuth(c, models.AuthEventLogout, user.(*models.User), "", "all_devices")
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Logged out of all devices",
+		"sessions": revoked,
+	})
+}
+
+// ListSessionsHandler lists the current user's active sessions
+func ListSessionsHandler(c *gin.Context) {
+	sessions, err := session.NewService().List(c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	current := c.GetString("session_id")
+	response := make([]SessionResponse, len(sessions))
+	for i, s := range sessions {
+		response[i] = SessionResponse{Session: s, Current: s.ID == current}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": response})
+}
+
+// RevokeSessionHandler revokes one of the current user's sessions, e.g. a lost device
+func RevokeSessionHandler(c *gin.Context) {
+	err := session.NewService().Revoke(c.GetUint("user_id"), c.Param("id"), models.SessionRevokedLogout)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked"})
+}
@@ -0,0 +1,436 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/lockout"
+	"gcx-cms/internal/shared/mail"
+	"gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/rbac"
+	"gcx-cms/internal/shared/token"
+	"gcx-cms/internal/shared/totp"
+)
+
+// A sign-in challenge is valid for five minutes and five wrong codes; ten recovery codes are
+// issued at a time
+const (
+	twoFactorAudience    = "two-factor-login"
+	twoFactorLifetime    = 5 * time.Minute
+	twoFactorMaxAttempts = 5
+	recoveryCodeCount    = 10
+)
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// startTwoFactorChallenge answers a correct password with a challenge token that is exchanged
+// for a session, together with an authenticator or recovery code, at /api/auth/login/2fa.
+// Only the newest challenge of a user works.
+func startTwoFactorChallenge(c *gin.Context, user *models.User) {
+	challengeID, err := token.NewOpaque(16)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor sign-in"})
+		return
+	}
+	result := config.DB.WithContext(c).Model(&models.TwoFactor{}).
+		Where("user_id = ? AND confirmed_at IS NOT NULL", user.ID).
+		Updates(map[string]interface{}{"challenge_id": challengeID, "failed_attempts": 0})
+	if result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor sign-in"})
+		return
+	}
+
+	now := time.Now()
+	challenge, err := token.GetService().Sign(&jwt.RegisteredClaims{
+		ID:        challengeID,
+		Subject:   strconv.FormatUint(uint64(user.ID), 10),
+		Audience:  jwt.ClaimStrings{twoFactorAudience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(twoFactorLifetime)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		Issuer:    token.Issuer,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor sign-in"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"two_factor_required": true,
+		"challenge_token":     challenge,
+		"expires_in":          int(twoFactorLifetime.Seconds()),
+	})
+}
+
+// VerifyTwoFactorLoginHandler completes a sign-in with the challenge token from the password
+// step and either an authenticator code or a recovery code
+func VerifyTwoFactorLoginHandler(c *gin.Context) {
+	var req TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (req.Code == "") == (req.RecoveryCode == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Send either code or recovery_code"})
+		return
+	}
+
+	claims := &jwt.RegisteredClaims{}
+	if err := token.GetService().Parse(req.ChallengeToken, claims, jwt.WithAudience(twoFactorAudience)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired sign-in, please sign in again"})
+		return
+	}
+
+	var user models.User
+	var enrollment models.TwoFactor
+	if err := config.DB.WithContext(c).First(&user, "id = ?", claims.Subject).Error; err != nil || !user.IsActive ||
+		config.DB.WithContext(c).First(&enrollment, "user_id = ? AND confirmed_at IS NOT NULL", user.ID).Error != nil ||
+		enrollment.ChallengeID == "" || enrollment.ChallengeID != claims.ID {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired sign-in, please sign in again"})
+		return
+	}
+	if enrollment.FailedAttempts >= twoFactorMaxAttempts {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many incorrect codes, please sign in again"})
+		return
+	}
+	guard := lockout.NewService()
+	if !checkLoginAllowed(c, guard, &user, user.Email) {
+		return
+	}
+
+	var ok bool
+	var err error
+	if req.Code != "" {
+		ok, err = useTOTPCode(&enrollment, req.Code)
+	} else {
+		ok, err = useRecoveryCode(user.ID, req.RecoveryCode)
+	}
+	if err != nil {
+		guard.Release(user.Email, c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		return
+	}
+	if !ok {
+		config.DB.WithContext(c).Model(&enrollment).Update("failed_attempts", gorm.Expr("failed_attempts + 1"))
+		loginFailed(c, guard, models.AuthEventTwoFactorFailure, &user, user.Email, "invalid_code")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid code"})
+		return
+	}
+	guard.Release(user.Email, c.ClientIP())
+
+	// The challenge is spent; conditional so it cannot be completed twice
+	result := config.DB.WithContext(c).Model(&models.TwoFactor{}).
+		Where("user_id = ? AND challenge_id = ?", user.ID, claims.ID).
+		Updates(map[string]interface{}{"challenge_id": "", "failed_attempts": 0})
+	if result.Error != nil || result.RowsAffected == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired sign-in, please sign in again"})
+		return
+	}
+
+	method := "two_factor"
+	if req.RecoveryCode != "" {
+		method = "recovery_code"
+	}
+	completeLogin(c, &user, method)
+}
+
+// TwoFactorStatusHandler reports whether the current user has two-factor authentication, whether
+// their role requires it and how many recovery codes are left
+func TwoFactorStatusHandler(c *gin.Context) {
+	user, ok := c.MustGet("user").(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	var remaining int64
+	config.DB.WithContext(c).Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", user.ID).Count(&remaining)
+
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":                  user.TwoFactorEnabled,
+		"required":                 rbac.GetService().RequiresTwoFactor(user.Role),
+		"recovery_codes_remaining": remaining,
+	})
+}
+
+// SetupTwoFactorHandler starts enrollment with a new secret. The provisioning URI is shown as a
+// QR code for the authenticator app; nothing changes at sign-in until the enrollment is
+// confirmed with EnableTwoFactorHandler.
+func SetupTwoFactorHandler(c *gin.Context) {
+	user, ok := c.MustGet("user").(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	if user.TwoFactorEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor setup"})
+		return
+	}
+	// A new setup replaces any unconfirmed one
+	err = config.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.TwoFactor{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.TwoFactor{UserID: user.ID, Secret: secret}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor setup"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":           secret,
+		"provisioning_uri": totp.ProvisioningURI(secret, user.Email),
+	})
+}
+
+// EnableTwoFactorHandler confirms enrollment with a code from the authenticator app and returns
+// the recovery codes. They are shown only this once.
+func EnableTwoFactorHandler(c *gin.Context) {
+	user, ok := c.MustGet("user").(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if user.TwoFactorEnabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	var enrollment models.TwoFactor
+	if err := config.DB.WithContext(c).First(&enrollment, "user_id = ?", user.ID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Start two-factor setup first"})
+		return
+	}
+	ok, err := useTOTPCode(&enrollment, req.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	var codes []string
+	err = config.DB.WithContext(c).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&enrollment).Update("confirmed_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(user).Update("two_factor_enabled", true).Error; err != nil {
+			return err
+		}
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+	audit.RecordAuth(c, models.AuthEventTwoFactorEnabled, user, "", "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled",
+		"recovery_codes": codes,
+	})
+}
+
+// DisableTwoFactorHandler turns two-factor authentication off with the password and a current
+// code. Users whose role requires it cannot turn it off.
+func DisableTwoFactorHandler(c *gin.Context) {
+	user, ok := c.MustGet("user").(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	var req DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !user.TwoFactorEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+	if rbac.GetService().RequiresTwoFactor(user.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your role requires two-factor authentication"})
+		return
+	}
+	if !user.CheckPassword(req.Password) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid password or code"})
+		return
+	}
+	if ok, err := checkCurrentCode(user.ID, req.Code); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		return
+	} else if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid password or code"})
+		return
+	}
+
+	if err := removeTwoFactor(config.DB.WithContext(c), user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+	audit.RecordAuth(c, models.AuthEventTwoFactorDisabled, user, "", "")
+	mail.SendAsync(mail.Message{
+		To:      user.Email,
+		Subject: "Two-factor authentication turned off",
+		Body: fmt.Sprintf("Hello %s,\n\nTwo-factor authentication was turned off for your GCX account. "+
+			"If this was not you, reset your password and contact us.\n", user.Name),
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodesHandler replaces the current user's recovery codes, given a current code
+func RegenerateRecoveryCodesHandler(c *gin.Context) {
+	user, ok := c.MustGet("user").(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !user.TwoFactorEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+	if ok, err := checkCurrentCode(user.ID, req.Code); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify code"})
+		return
+	} else if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid code"})
+		return
+	}
+
+	codes, err := replaceRecoveryCodes(config.DB.WithContext(c), user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create recovery codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// checkCurrentCode checks an authenticator code of a user with confirmed two-factor authentication
+func checkCurrentCode(userID uint, code string) (bool, error) {
+	var enrollment models.TwoFactor
+	if err := config.DB.First(&enrollment, "user_id = ? AND confirmed_at IS NOT NULL", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return useTOTPCode(&enrollment, code)
+}
+
+// useTOTPCode checks an authenticator code and marks its time step used. The update is
+// conditional so a code cannot be used twice, even by concurrent requests.
+func useTOTPCode(enrollment *models.TwoFactor, code string) (bool, error) {
+	step, ok := totp.Validate(enrollment.Secret, code, time.Now())
+	if !ok || step <= enrollment.LastUsedStep {
+		return false, nil
+	}
+	result := config.DB.Model(&models.TwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", enrollment.UserID, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	enrollment.LastUsedStep = step
+	return result.RowsAffected == 1, nil
+}
+
+// useRecoveryCode spends one of a user's recovery codes
+func useRecoveryCode(userID uint, code string) (bool, error) {
+	result := config.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, token.Hash(normalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// replaceRecoveryCodes issues a new set of recovery codes, invalidating the previous ones
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	codes := make([]string, recoveryCodeCount)
+	rows := make([]models.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		raw := strings.ToLower(recoveryEncoding.EncodeToString(b))
+		codes[i] = raw[:4] + "-" + raw[4:8] + "-" + raw[8:12] + "-" + raw[12:]
+		rows[i] = models.RecoveryCode{UserID: userID, CodeHash: token.Hash(raw)}
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode accepts recovery codes typed with any case, spaces or dashes
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// removeTwoFactor deletes a user's enrollment and recovery codes
+func removeTwoFactor(db *gorm.DB, user *models.User) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.TwoFactor{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(user).Update("two_factor_enabled", false).Error; err != nil {
+			return err
+		}
+		return nil
+	})
+}
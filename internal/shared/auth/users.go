@@ -0,0 +1,453 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	marketdata_models "gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/mail"
+	"gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/rbac"
+	"gcx-cms/internal/shared/session"
+)
+
+// userSortColumns are the columns the user list can be sorted by
+var userSortColumns = map[string]string{
+	"created_at": "created_at",
+	"last_login": "last_login",
+	"name":       "name",
+	"email":      "email",
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// UserListItem is a user in the admin list with their number of active sessions
+type UserListItem struct {
+	models.User
+	ActiveSessions int64 `json:"active_sessions"`
+}
+
+// ListUsersHandler lists users with search, filters and pagination (admin)
+// GET /api/admin/users?search=&role=&status=active|inactive|unverified|pending&sort=created_at&order=desc&page=1&limit=20
+func ListUsersHandler(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	query := config.DB.WithContext(c).Model(&models.User{})
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		like := "%" + search + "%"
+		query = query.Where("name LIKE ? OR email LIKE ? OR company LIKE ?", like, like, like)
+	}
+	if role := c.Query("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+	switch c.Query("status") {
+	case "active":
+		query = query.Where("is_active = ?", true)
+	case "inactive":
+		query = query.Where("is_active = ?", false)
+	case "unverified":
+		query = query.Where("email_verified_at IS NULL")
+	case "pending":
+		query = query.Where("approval_status = ?", models.ApprovalPending)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	sort, ok := userSortColumns[c.DefaultQuery("sort", "created_at")]
+	if !ok {
+		sort = "created_at"
+	}
+	order := "DESC"
+	if strings.EqualFold(c.Query("order"), "asc") {
+		order = "ASC"
+	}
+
+	var users []models.User
+	if err := query.Order(sort + " " + order).Order("id " + order).
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	// Active session counts for the page in one query
+	ids := make([]uint, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+	var counts []struct {
+		UserID uint
+		Count  int64
+	}
+	if len(ids) > 0 {
+		config.DB.WithContext(c).Model(&models.Session{}).
+			Select("user_id, COUNT(*) AS count").
+			Where("user_id IN ? AND revoked_at IS NULL AND expires_at > ?", ids, time.Now()).
+			Group("user_id").
+			Scan(&counts)
+	}
+	sessionCounts := make(map[uint]int64, len(counts))
+	for _, row := range counts {
+		sessionCounts[row.UserID] = row.Count
+	}
+
+	items := make([]UserListItem, len(users))
+	for i, u := range users {
+		items[i] = UserListItem{User: u, ActiveSessions: sessionCounts[u.ID]}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    items,
+		"pagination": gin.H{
+			"page":       page,
+			"limit":      limit,
+			"total":      total,
+			"totalPages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// GetUserHandler returns a user with their active sessions, subscriptions and data access (admin)
+func GetUserHandler(c *gin.Context) {
+	user, ok := findUser(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := session.NewService().List(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	var subscriptions []marketdata_models.UserSubscription
+	if err := config.DB.WithContext(c).Preload("Plan").Omit("User").
+		Where("user_id = ?", user.ID).
+		Order("start_date DESC").
+		Find(&subscriptions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch subscriptions"})
+		return
+	}
+
+	var dataAccess []marketdata_models.UserDataAccess
+	if err := config.DB.WithContext(c).Where("user_id = ?", user.ID).Order("data_type").Find(&dataAccess).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch data access"})
+		return
+	}
+
+	var identities []models.ExternalIdentity
+	if err := config.DB.WithContext(c).Where("user_id = ?", user.ID).Order("created_at").Find(&identities).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch linked identities"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user":          user,
+		"sessions":      sessions,
+		"subscriptions": subscriptions,
+		"data_access":   dataAccess,
+		"identities":    identities,
+	})
+}
+
+// UnlinkUserIdentityHandler removes a single sign-on identity from a user, so the next sign-in
+// with it is matched by email again (admin)
+func UnlinkUserIdentityHandler(c *gin.Context) {
+	user, ok := findManagedUser(c)
+	if !ok {
+		return
+	}
+
+	var identity models.ExternalIdentity
+	if err := config.DB.WithContext(c).Where("id = ? AND user_id = ?", c.Param("identityId"), user.ID).First(&identity).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Linked identity not found"})
+		return
+	}
+	if err := config.DB.WithContext(c).Delete(&identity).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlink identity"})
+		return
+	}
+	audit.Record(c, "user.sso_unlinked", "user", user.ID, map[string]interface{}{
+		"issuer":  identity.Issuer,
+		"subject": identity.Subject,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Identity unlinked"})
+}
+
+// UpdateUserRoleHandler changes a user's role (admin)
+func UpdateUserRoleHandler(c *gin.Context) {
+	var req UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	role := models.UserRole(req.Role)
+	if !rbac.GetService().RoleExists(role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role"})
+		return
+	}
+
+	user, ok := findManagedUser(c)
+	if !ok || !guardAdminChange(c, user, role != models.RoleAdmin) ||
+		!canGrant(c, role, rbac.GetService().Permissions(role)) {
+		return
+	}
+	if user.Role == role {
+		c.JSON(http.StatusOK, gin.H{"user": user})
+		return
+	}
+
+	previous := user.Role
+	if err := config.DB.WithContext(c).Model(user).Update("role", role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update role"})
+		return
+	}
+	audit.Record(c, "user.role_changed", "user", user.ID, map[string]audit.Change{
+		"role": {From: previous, To: role},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// ActivateUserHandler re-enables a deactivated account (admin)
+func ActivateUserHandler(c *gin.Context) {
+	setUserActive(c, true)
+}
+
+// DeactivateUserHandler disables an account and signs it out everywhere (admin)
+func DeactivateUserHandler(c *gin.Context) {
+	setUserActive(c, false)
+}
+
+func setUserActive(c *gin.Context, active bool) {
+	user, ok := findManagedUser(c)
+	if !ok || (!active && !guardAdminChange(c, user, user.Role == models.RoleAdmin)) {
+		return
+	}
+	if user.IsActive == active {
+		c.JSON(http.StatusOK, gin.H{"user": user})
+		return
+	}
+
+	if err := config.DB.WithContext(c).Model(user).Update("is_active", active).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+
+	action := "user.activated"
+	var revoked int64
+	if !active {
+		action = "user.deactivated"
+		revoked, _ = session.NewService().RevokeAll(user.ID, "", models.SessionRevokedAdmin)
+	}
+	audit.Record(c, action, "user", user.ID, map[string]interface{}{
+		"is_active":        audit.Change{From: !active, To: active},
+		"revoked_sessions": revoked,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// ForcePasswordResetHandler signs a user out everywhere, refuses their sign-in until they set a
+// new password and emails them a reset link (admin)
+func ForcePasswordResetHandler(c *gin.Context) {
+	user, ok := findManagedUser(c)
+	if !ok {
+		return
+	}
+
+	if err := config.DB.WithContext(c).Model(user).Update("must_reset_password", true).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+	revoked, err := session.NewService().RevokeAll(user.ID, "", models.SessionRevokedAdmin)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+	if err := issuePasswordReset(user, c.ClientIP()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reset link"})
+		return
+	}
+	audit.Record(c, "user.password_reset_forced", "user", user.ID, map[string]interface{}{
+		"revoked_sessions": revoked,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Password reset required; a reset link has been emailed to the user",
+		"revoked_sessions": revoked,
+	})
+}
+
+// ResetUserTwoFactorHandler removes a user's two-factor authentication when they have lost their
+// device, signs them out everywhere and emails them (admin). If their role requires two-factor
+// authentication they set it up again at their next sign-in.
+func ResetUserTwoFactorHandler(c *gin.Context) {
+	user, ok := findManagedUser(c)
+	if !ok {
+		return
+	}
+	if !user.TwoFactorEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled for this user"})
+		return
+	}
+
+	if err := removeTwoFactor(config.DB.WithContext(c), user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset two-factor authentication"})
+		return
+	}
+	revoked, _ := session.NewService().RevokeAll(user.ID, "", models.SessionRevokedAdmin)
+	audit.Record(c, "user.two_factor_reset", "user", user.ID, map[string]interface{}{
+		"two_factor_enabled": audit.Change{From: true, To: false},
+		"revoked_sessions":   revoked,
+	})
+	audit.RecordAuth(c, models.AuthEventTwoFactorDisabled, user, "", "admin_reset")
+
+	mail.SendAsync(mail.Message{
+		To:      user.Email,
+		Subject: "Your GCX two-factor authentication was reset",
+		Body: fmt.Sprintf("Hello %s,\n\nAn administrator removed the authenticator from your GCX account and signed "+
+			"you out everywhere. Sign in with your password and set up two-factor authentication again.\n", user.Name),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Two-factor authentication reset",
+		"revoked_sessions": revoked,
+	})
+}
+
+// ListUserSessionsHandler lists a user's active sessions (admin)
+func ListUserSessionsHandler(c *gin.Context) {
+	user, ok := findUser(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := session.NewService().List(user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"last_login": user.LastLogin,
+		"sessions":   sessions,
+	})
+}
+
+// RevokeUserSessionsHandler signs a user out of every session (admin)
+func RevokeUserSessionsHandler(c *gin.Context) {
+	user, ok := findManagedUser(c)
+	if !ok {
+		return
+	}
+
+	revoked, err := session.NewService().RevokeAll(user.ID, "", models.SessionRevokedAdmin)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+	audit.Record(c, "user.sessions_revoked", "user", user.ID, map[string]interface{}{
+		"revoked_sessions": revoked,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"revoked_sessions": revoked})
+}
+
+// ListUserAuditHandler returns the audit trail of changes made to a user (admin)
+func ListUserAuditHandler(c *gin.Context) {
+	user, ok := findUser(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	var entries []models.AuditLog
+	if err := config.DB.WithContext(c).Where("target_type IN ? AND target_id = ?", []string{"user", "users"}, strconv.FormatUint(uint64(user.ID), 10)).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit trail"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"audit": entries})
+}
+
+// findUser loads the user in the :id parameter, responding 404 if there is none
+func findUser(c *gin.Context) (*models.User, bool) {
+	var user models.User
+	if err := config.DB.WithContext(c).First(&user, "id = ?", c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		}
+		return nil, false
+	}
+	return &user, true
+}
+
+// findManagedUser loads the user in the :id parameter for a change, responding 403 if the current
+// user lacks any of the permissions of their role
+func findManagedUser(c *gin.Context) (*models.User, bool) {
+	user, ok := findUser(c)
+	if !ok || !canGrant(c, user.Role, rbac.GetService().Permissions(user.Role)) {
+		return nil, false
+	}
+	return user, true
+}
+
+// guardAdminChange stops admins from locking themselves out and from removing the last active
+// admin. removesAdmin says whether the change takes admin access away from the user.
+func guardAdminChange(c *gin.Context, user *models.User, removesAdmin bool) bool {
+	if !removesAdmin {
+		return true
+	}
+	if user.ID == c.GetUint("user_id") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot remove your own admin access"})
+		return false
+	}
+	if user.Role == models.RoleAdmin && isLastActiveAdmin(user) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one active admin is required"})
+		return false
+	}
+	return true
+}
+
+// isLastActiveAdmin reports whether no other active user has the admin role
+func isLastActiveAdmin(user *models.User) bool {
+	var admins int64
+	config.DB.Model(&models.User{}).
+		Where("role = ? AND is_active = ? AND id <> ?", models.RoleAdmin, true, user.ID).
+		Count(&admins)
+	return admins == 0
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/mail"
+	"gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/token"
+)
+
+// Verification links are signed tokens valid for a day; a new one can be requested every two minutes
+const (
+	verificationAudience = "email-verification"
+	verificationLifetime = 24 * time.Hour
+	verificationInterval = 2 * time.Minute
+)
+
+// verificationClaims are the claims of an email verification link. The email is included so
+// the link stops working if the address changes.
+type verificationClaims struct {
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// sendVerificationEmail emails a user a link to verify their address
+func sendVerificationEmail(user *models.User) {
+	now := time.Now()
+	link, err := token.GetService().Sign(&verificationClaims{
+		Email: user.Email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(uint64(user.ID), 10),
+			Audience:  jwt.ClaimStrings{verificationAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(verificationLifetime)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    token.Issuer,
+		},
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to sign verification link for user %d: %v", user.ID, err)
+		return
+	}
+
+	user.VerificationSentAt = &now
+	config.DB.Model(user).Update("verification_sent_at", now)
+
+	mail.SendAsync(mail.Message{
+		To:      user.Email,
+		Subject: "Verify your GCX email address",
+		Body: fmt.Sprintf("Hello %s,\n\nPlease confirm your email address to finish setting up your GCX account:\n\n"+
+			"%s/verify-email?token=%s\n\nThis link expires in 24 hours.\n",
+			user.Name, mail.AppURL(), link),
+	})
+}
+
+// VerifyEmailHandler marks a user's email as verified with the token from their link
+func VerifyEmailHandler(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims := &verificationClaims{}
+	if err := token.GetService().Parse(req.Token, claims, jwt.WithAudience(verificationAudience)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification link"})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.WithContext(c).First(&user, "id = ?", claims.Subject).Error; err != nil || user.Email != claims.Email {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification link"})
+		return
+	}
+
+	if !user.IsEmailVerified() {
+		now := time.Now()
+		if err := config.DB.WithContext(c).Model(&user).Update("email_verified_at", now).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified"})
+}
+
+// ResendVerificationHandler sends the current user a new verification link, at most once
+// every two minutes
+func ResendVerificationHandler(c *gin.Context) {
+	user, ok := c.MustGet("user").(*models.User)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
+		return
+	}
+
+	if user.IsEmailVerified() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email is already verified"})
+		return
+	}
+
+	if user.VerificationSentAt != nil {
+		if wait := time.Until(user.VerificationSentAt.Add(verificationInterval)); wait > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "A verification email was sent recently, please wait before requesting another"})
+			return
+		}
+	}
+
+	sendVerificationEmail(user)
+	c.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
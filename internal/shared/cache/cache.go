@@ -0,0 +1,160 @@
+// Package cache stores rendered API responses. It is in memory by default; a shared store such
+// as Redis can be plugged in with SetStore so several API instances see the same entries and
+// invalidations.
+package cache
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Namespaces group cache entries that are invalidated together
+const (
+	NamespaceMarketData  = "marketdata"
+	NamespaceCommodities = "commodities"
+)
+
+// Store is a key-value store with expiry. Implementations must be safe for concurrent use.
+type Store interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte, ttl time.Duration)
+	Delete(key string)
+}
+
+var (
+	store   Store = NewMemoryStore(10000)
+	storeMu sync.RWMutex
+)
+
+// SetStore replaces the default in-memory store, e.g. with a shared cache
+func SetStore(s Store) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	store = s
+}
+
+// GetStore returns the active store
+func GetStore() Store {
+	storeMu.RLock()
+	defer storeMu.RUnlock()
+	return store
+}
+
+// TTL is how long responses stay cached without a write (RESPONSE_CACHE_TTL seconds, default 300)
+func TTL() time.Duration {
+	if raw := os.Getenv("RESPONSE_CACHE_TTL"); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return 5 * time.Minute
+}
+
+// Generation returns the current generation of a namespace. Keys are prefixed with it, so
+// invalidating a namespace only needs a new generation rather than finding every key, which
+// works the same on any store.
+func Generation(namespace string) string {
+	if gen, ok := GetStore().Get("gen:" + namespace); ok {
+		return string(gen)
+	}
+	return "0"
+}
+
+// Invalidate drops every entry in the given namespaces
+func Invalidate(namespaces ...string) {
+	s := GetStore()
+	gen := []byte(strconv.FormatInt(time.Now().UnixNano(), 36))
+	for _, ns := range namespaces {
+		// Generations outlive the entries they prefix
+		s.Set("gen:"+ns, gen, 0)
+	}
+}
+
+// Key builds the cache key of an entry in a namespace
+func Key(namespace, key string) string {
+	return namespace + ":" + Generation(namespace) + ":" + key
+}
+
+// MemoryStore is an in-process Store holding up to a fixed number of entries
+type MemoryStore struct {
+	mu         sync.Mutex
+	entries    map[string]memoryEntry
+	maxEntries int
+}
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time // Zero never expires
+}
+
+// NewMemoryStore creates an in-memory store
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		entries:    make(map[string]memoryEntry),
+		maxEntries: maxEntries,
+	}
+}
+
+func (ms *MemoryStore) Get(key string) ([]byte, bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	entry, ok := ms.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		delete(ms.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (ms *MemoryStore) Set(key string, value []byte, ttl time.Duration) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.entries[key]; !exists && len(ms.entries) >= ms.maxEntries {
+		ms.evict()
+	}
+	entry := memoryEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	ms.entries[key] = entry
+}
+
+func (ms *MemoryStore) Delete(key string) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.entries, key)
+}
+
+// evict removes expired entries, or if none have expired an arbitrary tenth of the expiring
+// ones. Entries of old generations are never read again and age out this way. The caller holds
+// ms.mu.
+func (ms *MemoryStore) evict() {
+	now := time.Now()
+	removed := 0
+	for key, entry := range ms.entries {
+		if !entry.expiresAt.IsZero() && now.After(entry.expiresAt) {
+			delete(ms.entries, key)
+			removed++
+		}
+	}
+	if removed > 0 {
+		return
+	}
+	target := max(ms.maxEntries/10, 1)
+	for key, entry := range ms.entries {
+		if removed >= target {
+			break
+		}
+		if !entry.expiresAt.IsZero() {
+			delete(ms.entries, key)
+			removed++
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// pendingKey is the GORM setting holding the namespaces a transaction has written
+const pendingKey = "cache:pending"
+
+// pending collects the namespaces to invalidate once a transaction commits
+type pending struct {
+	mu         sync.Mutex
+	namespaces []string
+}
+
+func (p *pending) add(namespaces []string) {
+	p.mu.Lock()
+	p.namespaces = append(p.namespaces, namespaces...)
+	p.mu.Unlock()
+}
+
+// InvalidateOnWrite registers GORM callbacks that invalidate namespaces whenever a row of a
+// watched table is created, updated or deleted, whichever handler or service did the write.
+// They run once the write has committed; writes inside an explicit transaction have to go
+// through Transaction to be invalidated after it commits rather than before.
+func InvalidateOnWrite(db *gorm.DB, tables map[string][]string) {
+	invalidate := func(tx *gorm.DB) {
+		if tx.Error != nil || tx.Statement == nil || tx.RowsAffected == 0 {
+			return
+		}
+		namespaces, ok := tables[tx.Statement.Table]
+		if !ok {
+			return
+		}
+		if p, ok := tx.Get(pendingKey); ok {
+			p.(*pending).add(namespaces)
+			return
+		}
+		Invalidate(namespaces...)
+	}
+
+	db.Callback().Create().After("gorm:commit_or_rollback_transaction").Register("cache:invalidate_create", invalidate)
+	db.Callback().Update().After("gorm:commit_or_rollback_transaction").Register("cache:invalidate_update", invalidate)
+	db.Callback().Delete().After("gorm:commit_or_rollback_transaction").Register("cache:invalidate_delete", invalidate)
+}
+
+// Transaction runs fn in a database transaction, as gorm.DB.Transaction does, and invalidates
+// the namespaces of the tables it wrote once it has committed, so that a read in between cannot
+// cache data that is not committed under the new generation. Nested in another, it leaves that
+// to the outermost.
+func Transaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	if _, ok := db.Get(pendingKey); ok {
+		return db.Transaction(fn)
+	}
+
+	p := &pending{}
+	if err := db.Set(pendingKey, p).Transaction(fn); err != nil {
+		return err
+	}
+	Invalidate(p.namespaces...)
+	return nil
+}
@@ -83,13 +83,38 @@ func InitDB() {
 
 	log.Printf("✅ Connected to %s database", dbType)
 
+	// Accounts created before email verification existed count as verified
+	backfillVerified := DB.Migrator().HasTable(&shared_models.User{}) &&
+		!DB.Migrator().HasColumn(&shared_models.User{}, "EmailVerifiedAt")
+
+	// Versions of a contract type's specification once had no unique number
+	renumberSpecs := DB.Migrator().HasTable(&cms_models.ContractSpecVersion{}) &&
+		!DB.Migrator().HasIndex(&cms_models.ContractSpecVersion{}, "idx_contract_spec_version")
+	if renumberSpecs {
+		if err := renumberSpecVersions(); err != nil {
+			log.Fatal("Failed to renumber contract spec versions:", err)
+		}
+	}
+
 	// Run AutoMigrate for new tables; existing tables are altered only if columns changed.
 	log.Println("Running AutoMigrate...")
 	if err := AutoMigrate(); err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
-	// Create default admin user
+	if backfillVerified {
+		if err := DB.Model(&shared_models.User{}).Where("email_verified_at IS NULL").
+			Update("email_verified_at", gorm.Expr("created_at")).Error; err != nil {
+			log.Printf("Warning: Failed to mark existing users as verified: %v", err)
+		}
+	}
+
+	if err := backfillSpecBaselines(); err != nil {
+		log.Printf("Warning: Failed to record baseline contract specifications: %v", err)
+	}
+
+	// Create the built-in roles and default admin user
+	SeedRoles()
 	CreateDefaultAdmin()
 }
 
@@ -98,6 +123,21 @@ func AutoMigrate() error {
 	return DB.AutoMigrate(
 		// Shared models
 		&shared_models.User{},
+		&shared_models.Session{},
+		&shared_models.RefreshToken{},
+		&shared_models.PasswordResetToken{},
+		&shared_models.RegistrationSettings{},
+		&shared_models.UserInvite{},
+		&shared_models.AuditLog{},
+		&shared_models.AuditChainHead{},
+		&shared_models.Role{},
+		&shared_models.TwoFactor{},
+		&shared_models.RecoveryCode{},
+		&shared_models.AuthEvent{},
+		&shared_models.LoginThrottle{},
+		&shared_models.ExternalIdentity{},
+		&shared_models.OIDCLoginState{},
+		&shared_models.LegalHold{},
 
 		// CMS models
 		&cms_models.BlogPost{},
@@ -117,6 +157,9 @@ func AutoMigrate() error {
 		&cms_models.Publication{},
 		&cms_models.Career{},
 		&cms_models.Commodity{},
+		&cms_models.ContractSpecVersion{},
+		&cms_models.ContractCalendarRule{},
+		&cms_models.ContractSeries{},
 
 		// Market Data models
 		&marketdata_models.MarketData{},
@@ -124,18 +167,106 @@ func AutoMigrate() error {
 		&marketdata_models.TradingSession{},
 		&marketdata_models.PriceAlert{},
 		&marketdata_models.MarketAnalytics{},
+		&marketdata_models.Watchlist{},
+		&marketdata_models.WatchlistItem{},
+		&marketdata_models.CommodityIndex{},
+		&marketdata_models.IndexDefinitionVersion{},
+		&marketdata_models.IndexConstituent{},
+		&marketdata_models.IndexValue{},
+		&marketdata_models.ForecastRun{},
+		&marketdata_models.ForecastPoint{},
+		&marketdata_models.ExportJob{},
 
 		// Subscription models
 		&marketdata_models.SubscriptionPlan{},
 		&marketdata_models.UserSubscription{},
 		&marketdata_models.SubscriptionFeature{},
 		&marketdata_models.UserDataAccess{},
+		&marketdata_models.UsageRecord{},
+		&marketdata_models.Organization{},
+		&marketdata_models.OrganizationMember{},
+		&marketdata_models.OrganizationInvite{},
+
+		// Webhook models
+		&marketdata_models.WebhookSubscription{},
+		&marketdata_models.WebhookDelivery{},
 
 		// GCX TV
 		&tv_models.TVConfig{},
 	)
 }
 
+// renumberSpecVersions numbers the versions of each contract type that has two with the same
+// number in the order they were created, so that the unique index on them can be added
+func renumberSpecVersions() error {
+	var contractTypeIDs []uint
+	if err := DB.Model(&cms_models.ContractSpecVersion{}).
+		Group("contract_type_id, version").
+		Having("COUNT(*) > 1").
+		Pluck("contract_type_id", &contractTypeIDs).Error; err != nil {
+		return err
+	}
+
+	seen := make(map[uint]bool)
+	for _, id := range contractTypeIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		var versions []cms_models.ContractSpecVersion
+		if err := DB.Where("contract_type_id = ?", id).Order("version ASC, id ASC").Find(&versions).Error; err != nil {
+			return err
+		}
+		for i, v := range versions {
+			if v.Version == i+1 {
+				continue
+			}
+			if err := DB.Model(&v).UpdateColumn("version", i+1).Error; err != nil {
+				return err
+			}
+		}
+		log.Printf("Renumbered %d specification versions of contract type %d", len(versions), id)
+	}
+	return nil
+}
+
+// backfillSpecBaselines records the specification of each contract type without versions as
+// its version 1, so that reading the versions never has to write
+func backfillSpecBaselines() error {
+	if !DB.Migrator().HasTable(&cms_models.CommodityContractType{}) {
+		return nil
+	}
+
+	var contractTypes []cms_models.CommodityContractType
+	if err := DB.Where("NOT EXISTS (SELECT 1 FROM contract_spec_versions WHERE contract_spec_versions.contract_type_id = commodity_contract_types.id)").
+		Find(&contractTypes).Error; err != nil {
+		return err
+	}
+	for i := range contractTypes {
+		baseline := cms_models.BaselineSpec(&contractTypes[i])
+		if err := DB.Create(&baseline).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SeedRoles creates any built-in role that does not exist yet. Existing roles are left alone so
+// permission changes made by admins are kept.
+func SeedRoles() {
+	for _, role := range shared_models.DefaultRoles() {
+		var count int64
+		DB.Model(&shared_models.Role{}).Where("name = ?", role.Name).Count(&count)
+		if count > 0 {
+			continue
+		}
+		if err := DB.Create(&role).Error; err != nil {
+			log.Printf("Failed to create %s role: %v", role.Name, err)
+		}
+	}
+}
+
 // CreateDefaultAdmin creates a default admin user if none exists
 func CreateDefaultAdmin() {
 	var count int64
@@ -156,12 +287,14 @@ func CreateDefaultAdmin() {
 			adminName = "GCX Admin"
 		}
 
+		now := time.Now()
 		admin := shared_models.User{
-			Name:     adminName,
-			Email:    adminEmail,
-			Password: adminPassword,
-			Role:     shared_models.RoleAdmin,
-			IsActive: true,
+			Name:            adminName,
+			Email:           adminEmail,
+			Password:        adminPassword,
+			Role:            shared_models.RoleAdmin,
+			IsActive:        true,
+			EmailVerifiedAt: &now,
 		}
 
 		if err := DB.Create(&admin).Error; err != nil {
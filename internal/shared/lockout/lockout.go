@@ -0,0 +1,240 @@
+// Package lockout slows down and then locks out repeated failed sign-ins, per account and per IP
+// address. Counters are kept in the database so every instance sees the same attempts.
+package lockout
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Reasons a sign-in attempt is refused
+const (
+	ReasonAccountLocked = "account_locked"
+	ReasonIPLocked      = "ip_locked"
+	ReasonTooFast       = "too_fast"
+)
+
+// Config holds the thresholds, read from the environment by LoadConfig
+type Config struct {
+	// DelayAfter and IPDelayAfter are how many failures an account or an IP address is allowed
+	// before each further attempt has to wait, doubling from one second up to MaxDelay
+	DelayAfter   int
+	IPDelayAfter int
+	MaxDelay     time.Duration
+	// AccountThreshold and IPThreshold are the failures that lock an account or an IP address
+	// out for LockoutDuration
+	AccountThreshold int
+	IPThreshold      int
+	LockoutDuration  time.Duration
+	// Window is how long failures are remembered after the last one
+	Window time.Duration
+}
+
+// LoadConfig reads the thresholds from LOGIN_DELAY_AFTER (default 3), LOGIN_IP_DELAY_AFTER (10),
+// LOGIN_MAX_DELAY (30s), LOGIN_LOCKOUT_ACCOUNT_THRESHOLD (10), LOGIN_LOCKOUT_IP_THRESHOLD (50),
+// LOGIN_LOCKOUT_DURATION (15m) and LOGIN_FAILURE_WINDOW (15m)
+func LoadConfig() Config {
+	return Config{
+		DelayAfter:       envInt("LOGIN_DELAY_AFTER", 3),
+		IPDelayAfter:     envInt("LOGIN_IP_DELAY_AFTER", 10),
+		MaxDelay:         envDuration("LOGIN_MAX_DELAY", 30*time.Second),
+		AccountThreshold: envInt("LOGIN_LOCKOUT_ACCOUNT_THRESHOLD", 10),
+		IPThreshold:      envInt("LOGIN_LOCKOUT_IP_THRESHOLD", 50),
+		LockoutDuration:  envDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		Window:           envDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+	}
+}
+
+// Service checks and records sign-in attempts
+type Service struct {
+	cfg Config
+}
+
+// NewService creates a lockout service with the configured thresholds
+func NewService() *Service {
+	return &Service{cfg: LoadConfig()}
+}
+
+// AccountKey is the throttle key of an account, by email so unknown emails are throttled alike
+func AccountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// IPKey is the throttle key of an IP address
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check reports how long a sign-in for an email from an IP address must wait, and why. A zero
+// wait means the attempt may go ahead. It is then counted as a failure of both straight away,
+// so that attempts made at the same time see each other: Fail confirms it, and Release takes it
+// back once the credentials turn out to be right.
+func (s *Service) Check(email, ip string) (time.Duration, string) {
+	var wait time.Duration
+	reason := ""
+	config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		throttles, err := lockThrottles(tx, now, AccountKey(email), IPKey(ip))
+		if err != nil {
+			return err
+		}
+		for i := range throttles {
+			s.forget(&throttles[i], now)
+		}
+
+		// A lock outweighs a delay
+		for _, t := range throttles {
+			if t.LockedUntil != nil && t.LockedUntil.Sub(now) > wait {
+				wait = t.LockedUntil.Sub(now)
+				reason = ReasonAccountLocked
+				if strings.HasPrefix(t.Key, "ip:") {
+					reason = ReasonIPLocked
+				}
+			}
+		}
+		if reason != "" {
+			return nil
+		}
+		for i := range throttles {
+			if d := s.delay(&throttles[i], now); d > wait {
+				wait = d
+				reason = ReasonTooFast
+			}
+		}
+		if reason != "" {
+			return nil
+		}
+
+		for i := range throttles {
+			throttles[i].Failures++
+			throttles[i].LastFailureAt = now
+			if err := tx.Save(&throttles[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return wait, reason
+}
+
+// Fail confirms the failure of a sign-in Check let through for an email from an IP address,
+// locking the account or the address at its threshold. It returns the reasons for any lock the
+// failure started.
+func (s *Service) Fail(email, ip string) ([]string, error) {
+	var locked []string
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		locked = nil
+		now := time.Now()
+		throttles, err := lockThrottles(tx, now, AccountKey(email), IPKey(ip))
+		if err != nil {
+			return err
+		}
+		for i := range throttles {
+			t := &throttles[i]
+			threshold, reason := s.cfg.AccountThreshold, ReasonAccountLocked
+			if strings.HasPrefix(t.Key, "ip:") {
+				threshold, reason = s.cfg.IPThreshold, ReasonIPLocked
+			}
+			if threshold <= 0 || t.Failures < threshold || t.LockedUntil != nil {
+				continue
+			}
+			until := now.Add(s.cfg.LockoutDuration)
+			if err := tx.Model(t).Update("locked_until", until).Error; err != nil {
+				return err
+			}
+			locked = append(locked, reason)
+		}
+		return nil
+	})
+	return locked, err
+}
+
+// Release takes back the failure Check counted for a sign-in whose credentials were right
+func (s *Service) Release(email, ip string) {
+	config.DB.Model(&models.LoginThrottle{}).
+		Where("throttle_key IN ? AND failures > 0", []string{AccountKey(email), IPKey(ip)}).
+		UpdateColumn("failures", gorm.Expr("failures - 1"))
+}
+
+// Succeed clears the failures of an account after a successful sign-in. Those of the IP address
+// are kept so one valid account cannot be used to reset them.
+func (s *Service) Succeed(email string) {
+	config.DB.Where("throttle_key = ?", AccountKey(email)).Delete(&models.LoginThrottle{})
+}
+
+// Unlock clears the failures and any lock of a throttle key. It reports whether there was one.
+func (s *Service) Unlock(key string) (bool, error) {
+	result := config.DB.Where("throttle_key = ?", key).Delete(&models.LoginThrottle{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Locked lists the accounts and IP addresses that are locked out now
+func (s *Service) Locked() ([]models.LoginThrottle, error) {
+	var throttles []models.LoginThrottle
+	err := config.DB.Where("locked_until > ?", time.Now()).Order("locked_until DESC").Find(&throttles).Error
+	return throttles, err
+}
+
+// lockThrottles locks the throttles of keys for the rest of the transaction, in key order,
+// creating those that do not exist yet
+func lockThrottles(tx *gorm.DB, now time.Time, keys ...string) ([]models.LoginThrottle, error) {
+	fresh := make([]models.LoginThrottle, len(keys))
+	for i, key := range keys {
+		fresh[i] = models.LoginThrottle{Key: key, LastFailureAt: now}
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&fresh).Error; err != nil {
+		return nil, err
+	}
+
+	var throttles []models.LoginThrottle
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("throttle_key IN ?", keys).Order("throttle_key").Find(&throttles).Error
+	return throttles, err
+}
+
+// forget clears the failures of a throttle after a quiet window, or once its lock has run out
+func (s *Service) forget(t *models.LoginThrottle, now time.Time) {
+	if (t.LockedUntil != nil && !now.Before(*t.LockedUntil)) ||
+		(t.LockedUntil == nil && now.Sub(t.LastFailureAt) > s.cfg.Window) {
+		*t = models.LoginThrottle{Key: t.Key, LastFailureAt: t.LastFailureAt}
+	}
+}
+
+// delay is how much longer the next attempt against a throttle has to wait
+func (s *Service) delay(t *models.LoginThrottle, now time.Time) time.Duration {
+	after := s.cfg.DelayAfter
+	if strings.HasPrefix(t.Key, "ip:") {
+		after = s.cfg.IPDelayAfter
+	}
+	over := t.Failures - after
+	if over < 0 || now.Sub(t.LastFailureAt) > s.cfg.Window {
+		return 0
+	}
+	d := time.Second << min(over, 16)
+	if d > s.cfg.MaxDelay {
+		d = s.cfg.MaxDelay
+	}
+	return max(t.LastFailureAt.Add(d).Sub(now), 0)
+}
+
+func envInt(name string, fallback int) int {
+	if n, err := strconv.Atoi(os.Getenv(name)); err == nil && n >= 0 {
+		return n
+	}
+	return fallback
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(name)); err == nil && d >= 0 {
+		return d
+	}
+	return fallback
+}
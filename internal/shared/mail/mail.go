@@ -0,0 +1,132 @@
+// Package mail sends transactional email such as password resets. Messages go through SMTP
+// when SMTP_HOST is set; otherwise they are written to the log so flows can be tried locally.
+package mail
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers messages
+type Sender interface {
+	Send(msg Message) error
+}
+
+var (
+	sender     Sender
+	senderOnce sync.Once
+	senderMu   sync.RWMutex
+)
+
+// GetSender returns the configured sender
+func GetSender() Sender {
+	senderOnce.Do(func() {
+		senderMu.Lock()
+		defer senderMu.Unlock()
+		if sender == nil {
+			sender = senderFromEnv()
+		}
+	})
+	senderMu.RLock()
+	defer senderMu.RUnlock()
+	return sender
+}
+
+// SetSender replaces the configured sender, e.g. with an email provider's API client
+func SetSender(s Sender) {
+	senderOnce.Do(func() {})
+	senderMu.Lock()
+	defer senderMu.Unlock()
+	sender = s
+}
+
+// SendAsync sends a message in the background, logging failures. Handlers use it so response
+// times do not reveal whether an email was sent.
+func SendAsync(msg Message) {
+	go func() {
+		if err := GetSender().Send(msg); err != nil {
+			log.Printf("Warning: Failed to send email %q to %s: %v", msg.Subject, msg.To, err)
+		}
+	}()
+}
+
+// AppURL is the base URL of the website that links in emails point to (APP_URL)
+func AppURL() string {
+	if url := os.Getenv("APP_URL"); url != "" {
+		return strings.TrimRight(url, "/")
+	}
+	return "http://localhost:3000"
+}
+
+func senderFromEnv() Sender {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return logSender{}
+	}
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "no-reply@gcx.com"
+	}
+	return &SMTPSender{
+		Addr:     host + ":" + port,
+		Host:     host,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	}
+}
+
+// SMTPSender sends through an SMTP server, using STARTTLS when the server offers it
+type SMTPSender struct {
+	Addr     string
+	Host     string
+	Username string
+	Password string
+	From     string
+}
+
+func (s *SMTPSender) Send(msg Message) error {
+	if strings.ContainsAny(msg.To+msg.Subject, "\r\n") {
+		return fmt.Errorf("invalid header value")
+	}
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return smtp.SendMail(s.Addr, auth, s.From, []string{msg.To}, []byte(b.String()))
+}
+
+// logSender writes messages to the log instead of sending them
+type logSender struct{}
+
+func (logSender) Send(msg Message) error {
+	log.Printf("Email to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -1,44 +1,25 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
-	"time"
 
 	"gcx-cms/internal/shared/config"
 	shared_models "gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/rbac"
+	"gcx-cms/internal/shared/session"
+	"gcx-cms/internal/shared/token"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
-type Claims struct {
-	UserID uint                   `json:"user_id"`
-	Email  string                 `json:"email"`
-	Role   shared_models.UserRole `json:"role"`
-	jwt.RegisteredClaims
-}
-
-// GenerateToken generates a JWT token for a user
-func GenerateToken(user *shared_models.User) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
-
-	claims := &Claims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "gcx-cms",
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	// Use the same secret as the auth handler
-	jwtSecret := "gcx_super_secret_jwt_key_change_this_in_production"
+// Claims are the claims of a GCX user token
+type Claims = token.Claims
 
-	return token.SignedString([]byte(jwtSecret))
+// GenerateToken generates an access token for a user's session
+func GenerateToken(user *shared_models.User, sessionID string) (string, error) {
+	return token.GetService().Issue(user, sessionID)
 }
 
 // AuthMiddleware validates JWT tokens
@@ -51,54 +32,101 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Bearer token format
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Bearer token required"})
-			c.Abort()
+		if !authenticate(c, authHeader) {
 			return
 		}
 
-		// Parse and validate token
-		claims := &Claims{}
-		// Use the same secret as the auth handler
-		jwtSecret := "gcx_super_secret_jwt_key_change_this_in_production"
-
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(jwtSecret), nil
-		})
+		c.Next()
+	})
+}
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
-			return
+// OptionalAuthMiddleware sets the user in context when a valid token is sent and lets anonymous
+// requests through. A token that is sent but invalid is still rejected.
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return gin.HandlerFunc(func(c *gin.Context) {
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			if !authenticate(c, authHeader) {
+				return
+			}
 		}
 
-		// Check if user still exists and is active
-		var user shared_models.User
-		if err := config.DB.First(&user, claims.UserID).Error; err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
-			c.Abort()
-			return
-		}
+		c.Next()
+	})
+}
 
-		if !user.IsActive {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User account is disabled"})
-			c.Abort()
-			return
-		}
+// unverifiedRoutes are the routes users can reach before verifying their email address
+var unverifiedRoutes = map[string]bool{
+	"/api/user/profile":             true,
+	"/api/user/change-password":     true,
+	"/api/auth/resend-verification": true,
+	"/api/auth/logout":              true,
+	"/api/auth/logout-all":          true,
+	"/api/auth/sessions":            true,
+	"/api/auth/sessions/:id":        true,
+	"/api/auth/permissions":         true,
+}
+
+// twoFactorSetupRoutes are the routes users can reach before setting up two-factor
+// authentication that their role requires
+var twoFactorSetupRoutes = map[string]bool{
+	"/api/user/profile":      true,
+	"/api/auth/logout":       true,
+	"/api/auth/logout-all":   true,
+	"/api/auth/sessions":     true,
+	"/api/auth/sessions/:id": true,
+	"/api/auth/permissions":  true,
+	"/api/auth/2fa":          true,
+	"/api/auth/2fa/setup":    true,
+	"/api/auth/2fa/enable":   true,
+}
 
-		// Set user in context
-		c.Set("user", &user)
-		c.Set("user_id", user.ID)
-		c.Set("user_role", user.Role)
+// authenticate validates a bearer token and sets its user in context, aborting the request on failure
+func authenticate(c *gin.Context, authHeader string) bool {
+	// Bearer token format
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if tokenString == authHeader {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Bearer token required"})
+		c.Abort()
+		return false
+	}
 
-		c.Next()
-	})
+	user, claims, err := ValidateToken(tokenString)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.Abort()
+		return false
+	}
+
+	// Until the email is verified only account basics are reachable
+	if !user.IsEmailVerified() && !unverifiedRoutes[c.FullPath()] {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Email address not verified",
+			"code":  "email_unverified",
+		})
+		c.Abort()
+		return false
+	}
+
+	// Roles that require two-factor authentication can only set it up until they have
+	if !user.TwoFactorEnabled && !twoFactorSetupRoutes[c.FullPath()] && rbac.GetService().RequiresTwoFactor(user.Role) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Two-factor authentication is required for your role, set it up to continue",
+			"code":  "two_factor_setup_required",
+		})
+		c.Abort()
+		return false
+	}
+
+	// Set user in context
+	c.Set("user", user)
+	c.Set("user_id", user.ID)
+	c.Set("user_role", user.Role)
+	c.Set("session_id", claims.SessionID)
+	return true
 }
 
-// AdminMiddleware ensures user has admin role
-func AdminMiddleware() gin.HandlerFunc {
+// RequirePermission ensures the user's role grants every listed permission
+func RequirePermission(perms ...string) gin.HandlerFunc {
 	return gin.HandlerFunc(func(c *gin.Context) {
 		user, exists := c.Get("user")
 		if !exists {
@@ -114,39 +142,53 @@ func AdminMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if u.Role != shared_models.RoleAdmin {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
-			c.Abort()
-			return
+		for _, perm := range perms {
+			if !rbac.Can(u, perm) {
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":      "Permission required",
+					"permission": perm,
+				})
+				c.Abort()
+				return
+			}
 		}
 
 		c.Next()
 	})
 }
 
-// BloggerMiddleware ensures user can manage blog posts
-func BloggerMiddleware() gin.HandlerFunc {
-	return gin.HandlerFunc(func(c *gin.Context) {
-		user, exists := c.Get("user")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found in context"})
-			c.Abort()
-			return
-		}
+// Token validation errors, worded as returned to API clients
+var (
+	ErrInvalidToken = errors.New("Invalid token")
+	ErrUserNotFound = errors.New("User not found")
+	ErrUserDisabled = errors.New("User account is disabled")
+	ErrSessionEnded = errors.New("Session has been revoked or has expired")
+)
 
-		u, ok := user.(*shared_models.User)
-		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type"})
-			c.Abort()
-			return
-		}
+// ValidateToken parses a JWT and returns its user and claims if its session has not been
+// revoked and the user still exists and is active. It is shared by the REST middleware and
+// the gRPC interceptors.
+func ValidateToken(tokenString string) (*shared_models.User, *Claims, error) {
+	// Parse and validate token against the keyring
+	claims := &Claims{}
+	if err := token.GetService().Parse(tokenString, claims); err != nil {
+		return nil, nil, ErrInvalidToken
+	}
 
-		if !u.CanManageBlog() {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Blog management access required"})
-			c.Abort()
-			return
-		}
+	// Tokens without a session cannot be revoked and are no longer accepted
+	if claims.SessionID == "" || !session.NewService().IsActive(claims.UserID, claims.SessionID) {
+		return nil, nil, ErrSessionEnded
+	}
 
-		c.Next()
-	})
+	// Check if user still exists and is active
+	var user shared_models.User
+	if err := config.DB.First(&user, claims.UserID).Error; err != nil {
+		return nil, nil, ErrUserNotFound
+	}
+
+	if !user.IsActive {
+		return nil, nil, ErrUserDisabled
+	}
+
+	return &user, claims, nil
 }
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"gcx-cms/internal/shared/cache"
+
+	"github.com/gin-gonic/gin"
+)
+
+// cachedResponse is a rendered response as kept in the cache
+type cachedResponse struct {
+	ContentType  string    `json:"content_type"`
+	Body         []byte    `json:"body"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// cacheWriter holds back the response body so cache headers can be added once it is known
+type cacheWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *cacheWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *cacheWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// CacheResponse caches successful GET responses of public read endpoints in a cache namespace
+// and answers conditional requests with 304 Not Modified. Entries are keyed by the full URL
+// and dropped when the namespace is invalidated by a write.
+func CacheResponse(namespace string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		store := cache.GetStore()
+		key := cache.Key(namespace, c.Request.URL.RequestURI())
+		if raw, ok := store.Get(key); ok {
+			var entry cachedResponse
+			if err := json.Unmarshal(raw, &entry); err == nil {
+				c.Header("X-Cache", "HIT")
+				serveCached(c, &entry)
+				return
+			}
+		}
+
+		writer := &cacheWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if c.Writer.Status() != http.StatusOK || writer.body.Len() == 0 {
+			c.Writer.Write(writer.body.Bytes())
+			return
+		}
+		sum := sha256.Sum256(writer.body.Bytes())
+		entry := cachedResponse{
+			ContentType:  c.Writer.Header().Get("Content-Type"),
+			Body:         writer.body.Bytes(),
+			ETag:         `"` + hex.EncodeToString(sum[:16]) + `"`,
+			LastModified: time.Now().UTC().Truncate(time.Second),
+		}
+		if raw, err := json.Marshal(entry); err == nil {
+			store.Set(key, raw, cache.TTL())
+		}
+		c.Header("X-Cache", "MISS")
+		serveCached(c, &entry)
+	}
+}
+
+// serveCached writes a cached response, or 304 if the client already has it
+func serveCached(c *gin.Context, entry *cachedResponse) {
+	c.Header("ETag", entry.ETag)
+	c.Header("Last-Modified", entry.LastModified.Format(http.TimeFormat))
+	c.Header("Cache-Control", "no-cache")
+
+	if notModified(c.Request, entry) {
+		c.AbortWithStatus(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, entry.ContentType, entry.Body)
+	c.Abort()
+}
+
+// notModified checks If-None-Match, falling back to If-Modified-Since when no ETag is sent
+func notModified(r *http.Request, entry *cachedResponse) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, tag := range bytes.Split([]byte(match), []byte(",")) {
+			tag = bytes.TrimSpace(tag)
+			tag = bytes.TrimPrefix(tag, []byte("W/"))
+			if string(tag) == entry.ETag || string(tag) == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	if since := r.Header.Get("If-Modified-Since"); since != "" {
+		if t, err := http.ParseTime(since); err == nil {
+			return !entry.LastModified.After(t)
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"regexp"
+
+	"gcx-cms/internal/shared/token"
+
+	"github.com/gin-gonic/gin"
+)
+
+// validRequestID limits the request IDs taken from clients to something safe to log and store
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// RequestID tags each request with an ID, taken from the X-Request-ID header when a client or
+// proxy sent a usable one, and echoes it in the response. Handlers read it with
+// c.GetString("request_id").
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader("X-Request-ID")
+		if !validRequestID.MatchString(id) {
+			id, _ = token.NewOpaque(12)
+		}
+		c.Set("request_id", id)
+		c.Header("X-Request-ID", id)
+		c.Next()
+	}
+}
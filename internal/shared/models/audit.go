@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// AuditLog records a change: who did what to which record. Entries are written by handlers for
+// actions such as user.role_changed and by the database callbacks for every create, update and
+// delete made while serving a request. Each entry's hash covers the previous entry's, so
+// editing or removing an entry breaks the chain from there on. The hash covers a digest of the
+// actor's email, IP address, user agent and changes rather than the values, so that these can be
+// redacted when a person's data is erased.
+type AuditLog struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	ActorID    *uint      `json:"actor_id" gorm:"index"`
+	ActorEmail string     `json:"actor_email" gorm:"type:varchar(191)"`
+	Action     string     `json:"action" gorm:"type:varchar(100);not null;index"` // e.g. user.role_changed, or create, update, delete
+	TargetType string     `json:"target_type" gorm:"type:varchar(50);index:idx_audit_target"`
+	TargetID   string     `json:"target_id" gorm:"type:varchar(64);index:idx_audit_target"`
+	Changes    string     `json:"changes" gorm:"type:text"` // JSON of the changed values
+	IPAddress  string     `json:"ip_address" gorm:"type:varchar(64)"`
+	UserAgent  string     `json:"user_agent" gorm:"type:varchar(500)"`
+	RequestID  string     `json:"request_id" gorm:"type:varchar(64);index"`
+	PrevHash   string     `json:"prev_hash" gorm:"type:varchar(64)"`
+	Hash       string     `json:"hash" gorm:"type:varchar(64)"`   // Empty for entries written before chaining
+	Digest     string     `json:"digest" gorm:"type:varchar(64)"` // Of the personal data in the entry, as written
+	RedactedAt *time.Time `json:"redacted_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"index"`
+}
+
+// TableName returns the table name for AuditLog model
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// AuditChainHead is the single row holding the last entry of the audit chain. Appends lock it,
+// so that entries written by any server process link in one order.
+type AuditChainHead struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	EntryID   uint      `json:"entry_id"`
+	Hash      string    `json:"hash" gorm:"type:varchar(64)"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for AuditChainHead model
+func (AuditChainHead) TableName() string {
+	return "audit_chain_heads"
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Authentication events written to the auth log
+const (
+	AuthEventLoginSuccess      = "login.success"
+	AuthEventLoginFailure      = "login.failure"
+	AuthEventLoginBlocked      = "login.blocked" // Refused without checking the password: locked out or too fast
+	AuthEventLockout           = "login.lockout"
+	AuthEventTwoFactorFailure  = "login.two_factor_failure"
+	AuthEventTokenRefresh      = "token.refresh"
+	AuthEventTokenReuse        = "token.reuse"
+	AuthEventLogout            = "logout"
+	AuthEventPasswordChange    = "password.change"
+	AuthEventPasswordReset     = "password.reset"
+	AuthEventTwoFactorEnabled  = "two_factor.enabled"
+	AuthEventTwoFactorDisabled = "two_factor.disabled"
+	AuthEventUnlock            = "login.unlock"
+)
+
+// AuthEvent is an entry of the authentication log: sign-ins, failures, lockouts, token refreshes
+// and password changes, with where they came from
+type AuthEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    *uint     `json:"user_id" gorm:"index"` // Unset when the email matches no account
+	Email     string    `json:"email" gorm:"type:varchar(191);index"`
+	Event     string    `json:"event" gorm:"type:varchar(50);not null;index"`
+	Detail    string    `json:"detail,omitempty" gorm:"type:varchar(255)"`
+	IPAddress string    `json:"ip_address" gorm:"type:varchar(64);index"`
+	UserAgent string    `json:"user_agent" gorm:"type:varchar(500)"`
+	SessionID string    `json:"session_id,omitempty" gorm:"type:varchar(64)"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName returns the table name for AuthEvent model
+func (AuthEvent) TableName() string {
+	return "auth_events"
+}
+
+// LoginThrottle counts recent failed sign-ins for an account or an IP address
+type LoginThrottle struct {
+	Key           string     `json:"key" gorm:"column:throttle_key;primaryKey;type:varchar(255)"` // account:<email> or ip:<address>
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName returns the table name for LoginThrottle model
+func (LoginThrottle) TableName() string {
+	return "login_throttles"
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// ExternalIdentity links a user to their account at an OpenID Connect provider, so single
+// sign-on finds them by subject even if their email changes
+type ExternalIdentity struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"not null;index"`
+	Issuer      string     `json:"issuer" gorm:"type:varchar(255);not null;uniqueIndex:idx_external_identity"`
+	Subject     string     `json:"subject" gorm:"type:varchar(255);not null;uniqueIndex:idx_external_identity"`
+	Email       string     `json:"email" gorm:"type:varchar(191)"`   // As the provider last reported it
+	Provisioned bool       `json:"provisioned" gorm:"default:false"` // The account was created at the first sign-in; its role follows the provider
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// TableName returns the table name for ExternalIdentity model
+func (ExternalIdentity) TableName() string {
+	return "user_external_identities"
+}
+
+// OIDCLoginState is a single sign-on started at the provider and not yet completed. It holds
+// the PKCE verifier and nonce the callback needs; the state is only stored hashed.
+type OIDCLoginState struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	StateHash    string    `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	CodeVerifier string    `json:"-" gorm:"type:varchar(128);not null"`
+	Nonce        string    `json:"-" gorm:"type:varchar(64);not null"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"index"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName returns the table name for OIDCLoginState model
+func (OIDCLoginState) TableName() string {
+	return "oidc_login_states"
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// LegalHold keeps a person's records from being erased while they are needed for a legal claim,
+// an investigation or a statutory duty. It applies to every record found for the email or phone,
+// or only to one kind of record, or one record.
+type LegalHold struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	Email        string     `json:"email" gorm:"type:varchar(191);index"`
+	Phone        string     `json:"phone" gorm:"type:varchar(50);index"` // Digits of the national number
+	RecordType   string     `json:"record_type" gorm:"type:varchar(50)"` // Table name; empty for every record
+	RecordID     *uint      `json:"record_id"`                           // Unset for every record of the type
+	Reason       string     `json:"reason" gorm:"type:text;not null"`
+	Reference    string     `json:"reference" gorm:"type:varchar(100)"` // Case or file number
+	CreatedByID  *uint      `json:"created_by_id"`
+	ReleasedAt   *time.Time `json:"released_at"`
+	ReleasedByID *uint      `json:"released_by_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName returns the table name for LegalHold model
+func (LegalHold) TableName() string {
+	return "legal_holds"
+}
+
+// IsActive reports whether the hold still applies
+func (h *LegalHold) IsActive() bool {
+	return h.ReleasedAt == nil
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// PasswordResetToken is a single-use password reset link token, stored as a SHA-256 hash
+type PasswordResetToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	IPAddress string     `json:"ip_address" gorm:"type:varchar(64)"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName returns the table name for PasswordResetToken model
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// RegistrationSettings controls self-registration. There is a single row, edited by admins.
+type RegistrationSettings struct {
+	ID uint `json:"-" gorm:"primaryKey"`
+	// AllowedDomains lists the email domains that may self-register, comma-separated;
+	// "*.example.com" also allows subdomains. Empty allows every domain.
+	AllowedDomains string `json:"allowed_domains" gorm:"type:text"`
+	// MarketDataApproval makes trader sign-ups wait for an admin before getting the role
+	MarketDataApproval bool      `json:"market_data_approval"`
+	UpdatedBy          uint      `json:"updated_by"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// DefaultRegistrationSettings apply until an admin saves settings
+func DefaultRegistrationSettings() RegistrationSettings {
+	return RegistrationSettings{MarketDataApproval: true}
+}
+
+// Domains returns the allowed domains, normalised
+func (rs *RegistrationSettings) Domains() []string {
+	var domains []string
+	for _, d := range strings.Split(rs.AllowedDomains, ",") {
+		if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+			domains = append(domains, d)
+		}
+	}
+	return domains
+}
+
+// AllowsEmail checks an email address against the allowed domains
+func (rs *RegistrationSettings) AllowsEmail(email string) bool {
+	domains := rs.Domains()
+	if len(domains) == 0 {
+		return true
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range domains {
+		if domain == strings.TrimPrefix(allowed, "*.") {
+			return true
+		}
+		if strings.HasPrefix(allowed, "*.") && strings.HasSuffix(domain, allowed[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// TableName returns the table name for RegistrationSettings model
+func (RegistrationSettings) TableName() string {
+	return "registration_settings"
+}
+
+// UserInvite lets an admin create an account with any role. The invitee sets their own
+// password through the emailed link, which also verifies their email.
+type UserInvite struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Email      string     `json:"email" gorm:"type:varchar(191);not null;index"`
+	Name       string     `json:"name"`
+	Role       UserRole   `json:"role" gorm:"type:varchar(20);not null"`
+	TokenHash  string     `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	InvitedBy  uint       `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// IsPending reports whether the invite can still be accepted
+func (i *UserInvite) IsPending() bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && time.Now().Before(i.ExpiresAt)
+}
+
+// TableName returns the table name for UserInvite model
+func (UserInvite) TableName() string {
+	return "user_invites"
+}
@@ -0,0 +1,207 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Permissions granted by roles. Routes require them with middleware.RequirePermission.
+const (
+	PermDashboardView = "dashboard.view"
+
+	PermBlogWrite     = "blog.write"      // Write own blog posts
+	PermBlogManageAll = "blog.manage_all" // See and edit everyone's posts
+	PermMediaManage   = "media.manage"    // Media library, documents and uploads
+	PermPagesManage   = "pages.manage"
+	PermMenusManage   = "menus.manage"
+
+	PermSettingsRead  = "settings.read"
+	PermSettingsWrite = "settings.write"
+
+	PermBoardManage        = "board.manage"
+	PermTeamManage         = "team.manage"
+	PermDirectoryManage    = "directory.manage" // Traders and brokers
+	PermPartnersManage     = "partners.manage"
+	PermPublicationsManage = "publications.manage"
+	PermCareersManage      = "careers.manage"
+
+	PermCommoditiesManage = "commodities.manage" // Commodities, calendars, contract types and spec proposals
+	PermContractsApprove  = "contracts.approve"  // Approve or reject contract spec versions
+
+	PermEventsManage = "events.manage"
+
+	PermRTIRead    = "rti.read"
+	PermRTIRespond = "rti.respond"
+	PermRTIManage  = "rti.manage" // Delete requests and manage RTI documents
+
+	PermGalleriesManage = "galleries.manage"
+	PermVideosManage    = "videos.manage"
+	PermNewsManage      = "news.manage"
+	PermNewsPublish     = "news.publish"
+
+	PermTVManage = "tv.manage"
+
+	PermMarketDataAccess      = "marketdata.access"
+	PermMarketDataRealtime    = "marketdata.realtime"
+	PermMarketDataHistorical  = "marketdata.historical"
+	PermMarketDataAnalytics   = "marketdata.analytics"
+	PermMarketDataPricesWrite = "marketdata.prices.write"
+	PermMarketDataPlans       = "marketdata.plans.manage"
+	PermMarketDataCommodities = "marketdata.commodities.manage"
+	PermMarketDataSessions    = "marketdata.sessions.manage"
+	PermMarketDataIndices     = "marketdata.indices.manage"
+	PermMarketDataReplays     = "marketdata.replays.manage"
+	PermMarketDataUsage       = "marketdata.usage.read"
+
+	PermUsersManage = "users.manage" // Users, sign-up approvals, invites and registration settings
+	PermRolesManage = "roles.manage"
+	PermAuditRead   = "audit.read" // The audit trail of changes across the CMS and admin
+
+	PermPrivacyManage = "privacy.manage" // Data-protection access and erasure requests, legal holds
+)
+
+// PermissionInfo describes a permission for the admin UI
+type PermissionInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// AllPermissions is the catalogue of permissions a role can be given
+var AllPermissions = []PermissionInfo{
+	{PermDashboardView, "View the CMS dashboard"},
+	{PermBlogWrite, "Write and edit own blog posts"},
+	{PermBlogManageAll, "See and edit every author's blog posts"},
+	{PermMediaManage, "Upload and delete media files and documents"},
+	{PermPagesManage, "Manage pages"},
+	{PermMenusManage, "Manage menus and menu items"},
+	{PermSettingsRead, "Read site settings"},
+	{PermSettingsWrite, "Create, update and delete site settings"},
+	{PermBoardManage, "Manage board members"},
+	{PermTeamManage, "Manage team members"},
+	{PermDirectoryManage, "Manage the trader and broker directory"},
+	{PermPartnersManage, "Manage partners"},
+	{PermPublicationsManage, "Manage publications"},
+	{PermCareersManage, "Manage careers"},
+	{PermCommoditiesManage, "Manage commodities, contract calendars and contract types, and propose spec changes"},
+	{PermContractsApprove, "Approve or reject contract specification versions"},
+	{PermEventsManage, "Manage events and view registrations"},
+	{PermRTIRead, "View RTI requests and statistics"},
+	{PermRTIRespond, "Respond to RTI requests and update their status"},
+	{PermRTIManage, "Delete RTI requests and manage RTI documents"},
+	{PermGalleriesManage, "Manage photo galleries"},
+	{PermVideosManage, "Manage video libraries"},
+	{PermNewsManage, "Write and edit news items and categories"},
+	{PermNewsPublish, "Publish, archive and flag breaking news"},
+	{PermTVManage, "Configure GCX TV and upload its assets"},
+	{PermMarketDataAccess, "Use market data subscriptions, watchlists, alerts and webhooks"},
+	{PermMarketDataRealtime, "Stream real-time market data"},
+	{PermMarketDataHistorical, "Export historical market data"},
+	{PermMarketDataAnalytics, "Use market analytics and forecasts"},
+	{PermMarketDataPricesWrite, "Create, update and delete market prices"},
+	{PermMarketDataPlans, "Manage subscription plans"},
+	{PermMarketDataCommodities, "Manage market data commodities"},
+	{PermMarketDataSessions, "Open, close and halt trading sessions"},
+	{PermMarketDataIndices, "Define and compute GCX indices"},
+	{PermMarketDataReplays, "Replay historical market data"},
+	{PermMarketDataUsage, "Review market data usage across users"},
+	{PermUsersManage, "Manage users, sign-up approvals, invites and registration settings"},
+	{PermRolesManage, "Create and edit roles"},
+	{PermAuditRead, "Review and verify the audit trail of changes"},
+	{PermPrivacyManage, "Find, export and erase a person's data, and place legal holds"},
+}
+
+// AllPermissionNames lists the name of every permission
+func AllPermissionNames() []string {
+	names := make([]string, len(AllPermissions))
+	for i, p := range AllPermissions {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// IsValidPermission reports whether a permission is in the catalogue
+func IsValidPermission(name string) bool {
+	for _, p := range AllPermissions {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Role is a named set of permissions assigned to users through User.Role. The built-in roles
+// are seeded at startup; admins can change their permissions and create custom roles. The
+// admin role always holds every permission.
+type Role struct {
+	ID          uint     `json:"id" gorm:"primaryKey"`
+	Name        UserRole `json:"name" gorm:"type:varchar(20);uniqueIndex;not null"`
+	Description string   `json:"description"`
+	// Permissions is the comma-separated list of granted permissions
+	Permissions string   `json:"-" gorm:"type:text"`
+	Granted     []string `json:"permissions" gorm:"-"`
+	// RequireTwoFactor makes users with the role set up two-factor authentication before they
+	// can do anything else
+	RequireTwoFactor bool      `json:"require_two_factor"`
+	IsSystem         bool      `json:"is_system"` // Built-in roles cannot be renamed or deleted
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// PermissionList returns the role's permissions, sorted. The admin role has every permission.
+func (r *Role) PermissionList() []string {
+	if r.Name == RoleAdmin {
+		return AllPermissionNames()
+	}
+	perms := []string{}
+	for _, p := range strings.Split(r.Permissions, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			perms = append(perms, p)
+		}
+	}
+	sort.Strings(perms)
+	return perms
+}
+
+// SetPermissions replaces the role's permissions
+func (r *Role) SetPermissions(perms []string) {
+	sorted := append([]string(nil), perms...)
+	sort.Strings(sorted)
+	r.Permissions = strings.Join(sorted, ",")
+	r.Granted = r.PermissionList()
+}
+
+// AfterFind fills in the list of granted permissions
+func (r *Role) AfterFind(tx *gorm.DB) error {
+	r.Granted = r.PermissionList()
+	return nil
+}
+
+// TableName returns the table name for Role model
+func (Role) TableName() string {
+	return "roles"
+}
+
+// DefaultRoles are the built-in roles with the permissions they are seeded with
+func DefaultRoles() []Role {
+	roles := []Role{
+		{Name: RoleAdmin, Description: "Full access to everything"},
+		{Name: RoleBlogger, Description: "Writes blog posts and manages media"},
+		{Name: RoleUser, Description: "Market data subscriber"},
+		{Name: RoleTrader, Description: "Market data with real-time, historical and analytics access"},
+		{Name: RolePremium, Description: "Premium market data with real-time, historical and analytics access"},
+	}
+	perms := map[UserRole][]string{
+		RoleBlogger: {PermDashboardView, PermBlogWrite, PermMediaManage},
+		RoleUser:    {PermMarketDataAccess},
+		RoleTrader:  {PermMarketDataAccess, PermMarketDataRealtime, PermMarketDataHistorical, PermMarketDataAnalytics},
+		RolePremium: {PermMarketDataAccess, PermMarketDataRealtime, PermMarketDataHistorical, PermMarketDataAnalytics},
+	}
+	for i := range roles {
+		roles[i].IsSystem = true
+		roles[i].SetPermissions(perms[roles[i].Name])
+	}
+	return roles
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Session reasons for revocation
+const (
+	SessionRevokedLogout         = "logout"
+	SessionRevokedLogoutAll      = "logout_all"
+	SessionRevokedReuse          = "refresh_token_reuse"
+	SessionRevokedPasswordChange = "password_change"
+	SessionRevokedPasswordReset  = "password_reset"
+	SessionRevokedAdmin          = "admin"
+)
+
+// Session is a signed-in device. Its refresh tokens form one family: each refresh replaces the
+// current token, and presenting a replaced token again revokes the whole session.
+type Session struct {
+	ID            string     `json:"id" gorm:"primaryKey;type:varchar(64)"`
+	UserID        uint       `json:"user_id" gorm:"not null;index"`
+	UserAgent     string     `json:"user_agent" gorm:"type:varchar(500)"`
+	IPAddress     string     `json:"ip_address" gorm:"type:varchar(64)"`
+	LastUsedAt    time.Time  `json:"last_used_at"`
+	ExpiresAt     time.Time  `json:"expires_at" gorm:"index"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	RevokedReason string     `json:"revoked_reason,omitempty" gorm:"type:varchar(50)"`
+	CreatedAt     time.Time  `json:"created_at"`
+
+	RefreshTokens []RefreshToken `json:"-" gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE"`
+}
+
+// IsActive reports whether the session can still be used
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// RefreshToken is a refresh token of a session, stored as a SHA-256 hash
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	SessionID string     `json:"session_id" gorm:"type:varchar(64);not null;index"`
+	TokenHash string     `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"` // Set when exchanged for a new token
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName returns the table name for Session model
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// TableName returns the table name for RefreshToken model
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
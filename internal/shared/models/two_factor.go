@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// TwoFactor is a user's authenticator app enrollment. It stays pending, and is not asked for at
+// sign-in, until the user confirms it with a code.
+type TwoFactor struct {
+	UserID      uint       `json:"user_id" gorm:"primaryKey"`
+	Secret      string     `json:"-" gorm:"type:varchar(64);not null"`
+	ConfirmedAt *time.Time `json:"confirmed_at"`
+	// LastUsedStep is the time step of the last accepted code; codes up to it are refused so
+	// each works once
+	LastUsedStep int64 `json:"-"`
+	// ChallengeID is the sign-in challenge waiting for a code, and FailedAttempts the wrong codes
+	// given for it
+	ChallengeID    string    `json:"-" gorm:"type:varchar(64)"`
+	FailedAttempts int       `json:"-"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for TwoFactor model
+func (TwoFactor) TableName() string {
+	return "user_two_factors"
+}
+
+// RecoveryCode is a single-use code that replaces an authenticator code when the device is
+// lost. Only a hash is stored.
+type RecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	CodeHash  string     `json:"-" gorm:"type:varchar(64);not null;index"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName returns the table name for RecoveryCode model
+func (RecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
@@ -35,8 +35,23 @@ type User struct {
 	Country     *string `json:"country"`      // Country for regional access
 	TimeZone    *string `json:"time_zone"`    // User's timezone
 	Preferences string  `json:"preferences" gorm:"type:text"` // JSON string for user preferences
+
+	// Registration
+	EmailVerifiedAt    *time.Time `json:"email_verified_at"`
+	VerificationSentAt *time.Time `json:"-"`
+	RequestedRole      UserRole   `json:"requested_role,omitempty" gorm:"type:varchar(20)"`   // Role awaiting admin approval
+	ApprovalStatus     string     `json:"approval_status,omitempty" gorm:"type:varchar(20);index"` // pending, approved, rejected
+	MustResetPassword  bool       `json:"must_reset_password"`                                      // Set by an admin; sign-in is refused until reset
+	TwoFactorEnabled   bool       `json:"two_factor_enabled"`                                       // Sign-in needs an authenticator or recovery code
 }
 
+// Approval states of a requested role
+const (
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved"
+	ApprovalRejected = "rejected"
+)
+
 // HashPassword hashes the user's password
 func (u *User) HashPassword() error {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
@@ -53,39 +68,9 @@ func (u *User) CheckPassword(password string) bool {
 	return err == nil
 }
 
-// CanManageBlog checks if user can manage blog posts
-func (u *User) CanManageBlog() bool {
-	return u.Role == RoleAdmin || u.Role == RoleBlogger
-}
-
-// CanManageUsers checks if user can manage other users
-func (u *User) CanManageUsers() bool {
-	return u.Role == RoleAdmin
-}
-
-// CanManageSettings checks if user can manage system settings
-func (u *User) CanManageSettings() bool {
-	return u.Role == RoleAdmin
-}
-
-// CanAccessMarketData checks if user can access market data
-func (u *User) CanAccessMarketData() bool {
-	return u.Role == RoleAdmin || u.Role == RoleTrader || u.Role == RolePremium || u.Role == RoleUser
-}
-
-// CanAccessRealTimeData checks if user can access real-time market data
-func (u *User) CanAccessRealTimeData() bool {
-	return u.Role == RoleAdmin || u.Role == RoleTrader || u.Role == RolePremium
-}
-
-// CanAccessHistoricalData checks if user can access historical market data
-func (u *User) CanAccessHistoricalData() bool {
-	return u.Role == RoleAdmin || u.Role == RoleTrader || u.Role == RolePremium
-}
-
-// CanAccessAnalytics checks if user can access market analytics
-func (u *User) CanAccessAnalytics() bool {
-	return u.Role == RoleAdmin || u.Role == RoleTrader || u.Role == RolePremium
+// IsEmailVerified checks if the user has confirmed their email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
 // BeforeCreate hook to hash password before creating user
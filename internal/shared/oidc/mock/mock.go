@@ -0,0 +1,266 @@
+// Package mock is a minimal OpenID Connect provider for trying single sign-on locally. It signs
+// in whichever of its users the browser picks, without a password, so it must never be exposed.
+package mock
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// codeLifetime bounds how long an authorization code can be exchanged
+const codeLifetime = time.Minute
+
+// User is an account of the mock provider
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+}
+
+// grant is an issued authorization code waiting to be exchanged
+type grant struct {
+	user        User
+	clientID    string
+	redirectURI string
+	nonce       string
+	challenge   string
+	expiresAt   time.Time
+}
+
+// Provider is the mock provider. Its issuer must be the URL it is served at.
+type Provider struct {
+	Issuer   string
+	ClientID string
+
+	key   *rsa.PrivateKey
+	kid   string
+	mu    sync.Mutex
+	users []User
+	codes map[string]grant
+}
+
+// New creates a mock provider with a fresh RSA signing key
+func New(issuer, clientID string) (*Provider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return &Provider{
+		Issuer:   strings.TrimRight(issuer, "/"),
+		ClientID: clientID,
+		key:      key,
+		kid:      "mock-1",
+		codes:    map[string]grant{},
+	}, nil
+}
+
+// AddUser adds an account, replacing any with the same email. The subject defaults to one
+// derived from the email.
+func (p *Provider) AddUser(u User) {
+	if u.Subject == "" {
+		sum := sha256.Sum256([]byte(u.Email))
+		u.Subject = fmt.Sprintf("mock-%x", sum[:8])
+	}
+	if u.Name == "" {
+		u.Name = u.Email
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i := range p.users {
+		if strings.EqualFold(p.users[i].Email, u.Email) {
+			p.users[i] = u
+			return
+		}
+	}
+	p.users = append(p.users, u)
+}
+
+// Handler serves the discovery document, key set, authorization and token endpoints
+func (p *Provider) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/jwks", p.jwks)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	return mux
+}
+
+func (p *Provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.Issuer,
+		"authorization_endpoint":                p.Issuer + "/authorize",
+		"token_endpoint":                        p.Issuer + "/token",
+		"jwks_uri":                              p.Issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *Provider) jwks(w http.ResponseWriter, r *http.Request) {
+	pub := p.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": p.kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+var chooser = template.Must(template.New("choose").Parse(`<!doctype html>
+<title>Mock sign-in</title>
+<h1>Sign in as</h1>
+<ul>{{range .}}<li><a href="{{.Link}}">{{.User.Name}} &lt;{{.User.Email}}&gt;</a> {{.User.Groups}}</li>{{end}}</ul>`))
+
+// authorize signs in the user named by login_hint, or shows the users to pick from
+func (p *Provider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("response_type") != "code" || q.Get("client_id") != p.ClientID || q.Get("redirect_uri") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+		http.Error(w, "PKCE with S256 is required", http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	users := append([]User(nil), p.users...)
+	p.mu.Unlock()
+
+	hint := strings.ToLower(q.Get("login_hint"))
+	var user *User
+	for i := range users {
+		if strings.ToLower(users[i].Email) == hint {
+			user = &users[i]
+			break
+		}
+	}
+	if user == nil {
+		type choice struct {
+			User User
+			Link string
+		}
+		choices := make([]choice, len(users))
+		for i, u := range users {
+			link := *r.URL
+			params := link.Query()
+			params.Set("login_hint", u.Email)
+			link.RawQuery = params.Encode()
+			choices[i] = choice{User: u, Link: link.String()}
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		chooser.Execute(w, choices)
+		return
+	}
+
+	code := randomString()
+	p.mu.Lock()
+	p.codes[code] = grant{
+		user:        *user,
+		clientID:    q.Get("client_id"),
+		redirectURI: q.Get("redirect_uri"),
+		nonce:       q.Get("nonce"),
+		challenge:   q.Get("code_challenge"),
+		expiresAt:   time.Now().Add(codeLifetime),
+	}
+	p.mu.Unlock()
+
+	target, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	params := target.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	target.RawQuery = params.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// token exchanges a code for an ID token, checking the PKCE verifier
+func (p *Provider) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unsupported_grant_type"})
+		return
+	}
+	clientID := r.PostForm.Get("client_id")
+	if id, _, ok := r.BasicAuth(); ok {
+		clientID, _ = url.QueryUnescape(id)
+	}
+
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	g, ok := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || time.Now().After(g.expiresAt) || g.clientID != clientID ||
+		g.redirectURI != r.PostForm.Get("redirect_uri") ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            p.Issuer,
+		"sub":            g.user.Subject,
+		"aud":            p.ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"nonce":          g.nonce,
+		"email":          g.user.Email,
+		"email_verified": g.user.EmailVerified,
+		"name":           g.user.Name,
+		"groups":         g.user.Groups,
+	}
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = p.kid
+	signed, err := idToken.SignedString(p.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     signed,
+	})
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
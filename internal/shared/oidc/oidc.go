@@ -0,0 +1,441 @@
+// Package oidc signs staff in through an OpenID Connect provider with the authorization code
+// flow and PKCE, and verifies the ID tokens it returns
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gcx-cms/internal/shared/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Provider metadata is refetched hourly; unknown signing keys trigger a refetch of the key set
+// at most once a minute
+const (
+	discoveryTTL    = time.Hour
+	keyRefetchDelay = time.Minute
+	clockSkew       = time.Minute
+)
+
+// Errors worded as returned to API clients
+var (
+	ErrNotConfigured   = errors.New("Single sign-on is not configured")
+	ErrInvalidIDToken  = errors.New("Invalid ID token from the identity provider")
+	ErrExchangeFailed  = errors.New("The identity provider did not accept the sign-in")
+	ErrProviderFailure = errors.New("Could not reach the identity provider")
+)
+
+// RoleMapping gives users in a provider group a GCX role
+type RoleMapping struct {
+	Group string
+	Role  models.UserRole
+}
+
+// Config is the provider registration, read from the environment by LoadConfig
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string // Empty for a public client, which relies on PKCE alone
+	RedirectURL  string // Where the provider sends the browser back, a page of the frontend
+	Scopes       []string
+	ProviderName string // Shown on the sign-in button
+	// GroupsClaim is the ID token claim listing the user's groups; dots reach into nested
+	// claims such as realm_access.roles
+	GroupsClaim string
+	// RoleMappings are checked in order and the first group the user is in sets their role.
+	// With mappings the provider decides the role at every sign-in.
+	RoleMappings []RoleMapping
+	// DefaultRole is given when no mapping matches; without one such users are refused
+	DefaultRole models.UserRole
+	// AutoCreate creates accounts for staff signing in for the first time
+	AutoCreate bool
+	// RequireVerifiedEmail only matches existing accounts by email when the provider says
+	// the address is verified
+	RequireVerifiedEmail bool
+}
+
+// LoadConfig reads OIDC_ISSUER, OIDC_CLIENT_ID, OIDC_CLIENT_SECRET, OIDC_REDIRECT_URL,
+// OIDC_SCOPES, OIDC_PROVIDER_NAME, OIDC_GROUPS_CLAIM, OIDC_ROLE_MAPPING (group=role pairs
+// separated by commas), OIDC_DEFAULT_ROLE, OIDC_AUTO_CREATE and OIDC_REQUIRE_VERIFIED_EMAIL
+func LoadConfig() Config {
+	cfg := Config{
+		Issuer:               strings.TrimRight(os.Getenv("OIDC_ISSUER"), "/"),
+		ClientID:             os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret:         os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:          os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:               strings.Fields(os.Getenv("OIDC_SCOPES")),
+		ProviderName:         os.Getenv("OIDC_PROVIDER_NAME"),
+		GroupsClaim:          os.Getenv("OIDC_GROUPS_CLAIM"),
+		DefaultRole:          models.UserRole(os.Getenv("OIDC_DEFAULT_ROLE")),
+		AutoCreate:           os.Getenv("OIDC_AUTO_CREATE") != "false",
+		RequireVerifiedEmail: os.Getenv("OIDC_REQUIRE_VERIFIED_EMAIL") != "false",
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	if cfg.ProviderName == "" {
+		cfg.ProviderName = "Single sign-on"
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	for _, pair := range strings.Split(os.Getenv("OIDC_ROLE_MAPPING"), ",") {
+		group, role, ok := strings.Cut(pair, "=")
+		if group, role = strings.TrimSpace(group), strings.TrimSpace(role); ok && group != "" && role != "" {
+			cfg.RoleMappings = append(cfg.RoleMappings, RoleMapping{Group: group, Role: models.UserRole(role)})
+		}
+	}
+	return cfg
+}
+
+// Enabled reports whether a provider is configured
+func (c Config) Enabled() bool {
+	return c.Issuer != "" && c.ClientID != "" && c.RedirectURL != ""
+}
+
+// RoleFor returns the role for a user in the given groups: that of the first mapping matching
+// one of them, else the default role. ok is false when the user gets no role.
+func (c Config) RoleFor(groups []string) (models.UserRole, bool) {
+	member := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		member[g] = true
+	}
+	for _, m := range c.RoleMappings {
+		if member[m.Group] {
+			return m.Role, true
+		}
+	}
+	return c.DefaultRole, c.DefaultRole != ""
+}
+
+// Identity is the verified user an ID token describes
+type Identity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+}
+
+// Discovery is the part of the provider metadata the flow uses
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider talks to the configured OpenID Connect provider, caching its metadata and keys
+type Provider struct {
+	cfg    Config
+	client *http.Client
+
+	mu            sync.Mutex
+	discovery     *Discovery
+	discoveredAt  time.Time
+	keys          map[string]any
+	keysFetchedAt time.Time
+}
+
+var (
+	provider     *Provider
+	providerOnce sync.Once
+)
+
+// GetProvider returns the provider configured in the environment
+func GetProvider() *Provider {
+	providerOnce.Do(func() {
+		provider = NewProvider(LoadConfig(), &http.Client{Timeout: 10 * time.Second})
+	})
+	return provider
+}
+
+// NewProvider creates a provider client for a configuration
+func NewProvider(cfg Config, client *http.Client) *Provider {
+	return &Provider{cfg: cfg, client: client}
+}
+
+// Config returns the provider's configuration
+func (p *Provider) Config() Config {
+	return p.cfg
+}
+
+// AuthCodeURL returns the provider URL that starts a sign-in, with the state, nonce and PKCE
+// challenge of the verifier. A login hint, usually an email, preselects the account.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier, loginHint string) (string, error) {
+	if !p.cfg.Enabled() {
+		return "", ErrNotConfigured
+	}
+	d, err := p.metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.cfg.ClientID)
+	params.Set("redirect_uri", p.cfg.RedirectURL)
+	params.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", Challenge(verifier))
+	params.Set("code_challenge_method", "S256")
+	if loginHint != "" {
+		params.Set("login_hint", loginHint)
+	}
+
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return d.AuthorizationEndpoint + sep + params.Encode(), nil
+}
+
+// Exchange trades an authorization code and its PKCE verifier for the user's identity, checking
+// the ID token against the provider's keys and the nonce of the sign-in
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error) {
+	if !p.cfg.Enabled() {
+		return nil, ErrNotConfigured
+	}
+	d, err := p.metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", p.cfg.ClientID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProviderFailure, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProviderFailure, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: token endpoint returned %d: %s", ErrExchangeFailed, resp.StatusCode, truncate(body, 200))
+	}
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &tokens); err != nil || tokens.IDToken == "" {
+		return nil, fmt.Errorf("%w: no id_token in the token response", ErrExchangeFailed)
+	}
+	return p.Verify(ctx, tokens.IDToken, nonce)
+}
+
+// Verify checks an ID token's signature, issuer, audience, expiry and nonce and returns the
+// identity it describes
+func (p *Provider) Verify(ctx context.Context, rawIDToken, nonce string) (*Identity, error) {
+	d, err := p.metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, d, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384"}),
+		jwt.WithIssuer(d.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+	if _, ok := claims["exp"]; !ok {
+		return nil, fmt.Errorf("%w: no expiry", ErrInvalidIDToken)
+	}
+	if got, _ := claims["nonce"].(string); got == "" || got != nonce {
+		return nil, fmt.Errorf("%w: nonce does not match", ErrInvalidIDToken)
+	}
+	// With several audiences the token must have been issued to this client
+	if aud, _ := claims.GetAudience(); len(aud) > 1 {
+		if azp, _ := claims["azp"].(string); azp != p.cfg.ClientID {
+			return nil, fmt.Errorf("%w: issued to another client", ErrInvalidIDToken)
+		}
+	}
+
+	identity := &Identity{Issuer: d.Issuer}
+	identity.Subject, _ = claims["sub"].(string)
+	if identity.Subject == "" {
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidIDToken)
+	}
+	identity.Email, _ = claims["email"].(string)
+	identity.Email = strings.ToLower(strings.TrimSpace(identity.Email))
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		identity.EmailVerified = v
+	case string:
+		identity.EmailVerified = v == "true"
+	}
+	identity.Name, _ = claims["name"].(string)
+	identity.Groups = stringList(lookup(claims, p.cfg.GroupsClaim))
+	return identity, nil
+}
+
+// NewVerifier returns a random PKCE code verifier (RFC 7636)
+func NewVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Challenge returns the S256 code challenge of a verifier
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// metadata returns the provider's discovery document, fetching it when stale
+func (p *Provider) metadata(ctx context.Context) (*Discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil && time.Since(p.discoveredAt) < discoveryTTL {
+		return p.discovery, nil
+	}
+
+	var d Discovery
+	if err := p.getJSON(ctx, p.cfg.Issuer+"/.well-known/openid-configuration", &d); err != nil {
+		if p.discovery != nil {
+			return p.discovery, nil
+		}
+		return nil, err
+	}
+	if strings.TrimRight(d.Issuer, "/") != p.cfg.Issuer {
+		return nil, fmt.Errorf("%w: discovery issuer %q does not match %q", ErrProviderFailure, d.Issuer, p.cfg.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: incomplete discovery document", ErrProviderFailure)
+	}
+	p.discovery = &d
+	p.discoveredAt = time.Now()
+	return p.discovery, nil
+}
+
+// key returns the provider's public key with a kid, refetching the key set when it is unknown
+func (p *Provider) key(ctx context.Context, d *Discovery, kid string) (any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.pick(kid); ok {
+		return key, nil
+	}
+	if p.keys != nil && time.Since(p.keysFetchedAt) < keyRefetchDelay {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var set jwkSet
+	if err := p.getJSON(ctx, d.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+	p.keys = set.publicKeys()
+	p.keysFetchedAt = time.Now()
+	if key, ok := p.pick(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// pick finds a cached key by kid; a token without a kid matches a provider's only key
+func (p *Provider) pick(kid string) (any, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *Provider) getJSON(ctx context.Context, target string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrProviderFailure, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s returned %d", ErrProviderFailure, target, resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", ErrProviderFailure, err)
+	}
+	return nil
+}
+
+// lookup finds a claim by a dotted path
+func lookup(claims map[string]interface{}, path string) interface{} {
+	var value interface{} = claims
+	for _, part := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[part]
+	}
+	return value
+}
+
+// stringList reads a claim that is a list of strings or a single string
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+func truncate(b []byte, n int) string {
+	if len(b) > n {
+		return string(b[:n]) + "..."
+	}
+	return string(b)
+}
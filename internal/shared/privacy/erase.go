@@ -0,0 +1,416 @@
+package privacy
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	cms_models "gcx-cms/internal/cms/models"
+	marketdata_models "gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/models"
+
+	"gorm.io/gorm"
+)
+
+// What erasure does with a record
+const (
+	ActionAnonymise = "anonymise" // Personal fields are cleared; the record is kept
+	ActionDelete    = "delete"
+	ActionRetain    = "retain" // Kept as it is, for the step's reason
+)
+
+// erasedName replaces the names of anonymised records
+const erasedName = "Erased"
+
+// Step is what erasure does, or would do, with one record
+type Step struct {
+	Record string `json:"record"` // Table
+	ID     string `json:"id"`
+	Action string `json:"action"`
+	Reason string `json:"reason,omitempty"` // Why a record is retained
+	HoldID *uint  `json:"hold_id,omitempty"`
+}
+
+// Erasure is the outcome of an erasure: every step, and how many records took each action
+type Erasure struct {
+	Steps  []Step         `json:"steps"`
+	Counts map[string]int `json:"counts"`
+}
+
+// ActiveHolds returns the legal holds in force on the subject's records: those filed under any
+// email or phone on the records, as a person may be found by one and held under another, and
+// those on one of the records themselves
+func (s *Service) ActiveHolds(subject Subject, r *Records) ([]models.LegalHold, error) {
+	emails, phones := r.identifiers(subject)
+	matches := config.DB.Where("1 = 0")
+	if len(emails) > 0 {
+		matches = matches.Or("LOWER(email) IN ?", emails)
+	}
+	if len(phones) > 0 {
+		matches = matches.Or("phone IN ?", phones)
+	}
+	for _, section := range r.Sections() {
+		var ids []uint
+		for _, id := range section.ids() {
+			if n, err := strconv.ParseUint(id, 10, 64); err == nil {
+				ids = append(ids, uint(n))
+			}
+		}
+		if len(ids) > 0 {
+			matches = matches.Or("record_type = ? AND record_id IN ?", section.Table, ids)
+		}
+	}
+
+	var holds []models.LegalHold
+	err := config.DB.Where("released_at IS NULL").Where(matches).Order("id").Find(&holds).Error
+	return holds, err
+}
+
+// identifiers returns the subject's email and phone and every other one on their accounts,
+// event registrations and RTI requests, lower-cased and normalised
+func (r *Records) identifiers(subject Subject) (emails, phones []string) {
+	seen := map[string]bool{}
+	add := func(email, phone string) {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" && !seen["e:"+email] {
+			seen["e:"+email] = true
+			emails = append(emails, email)
+		}
+		if phone = NormalizePhone(phone); phone != "" && !seen["p:"+phone] {
+			seen["p:"+phone] = true
+			phones = append(phones, phone)
+		}
+	}
+	add(subject.Email, subject.Phone)
+	for _, u := range r.Users {
+		phone := ""
+		if u.Phone != nil {
+			phone = *u.Phone
+		}
+		add(u.Email, phone)
+	}
+	for _, reg := range r.EventRegistrations {
+		add(reg.Email, reg.Phone)
+	}
+	for _, req := range r.RTIRequests {
+		add(req.Email, req.Phone)
+	}
+	return emails, phones
+}
+
+// Plan works out what erasure would do with each record. Records under a legal hold are
+// retained, as are:
+//   - accounts with the admin role, an active subscription, or that are the only admin of an
+//     organisation with other members, and what belongs to them
+//   - subscriptions, which are financial records; they stay with the anonymised account
+//   - the authentication log, kept for security
+//   - RTI requests still being processed
+//   - audit entries about a record that is retained, or made by an account that is
+//
+// Accounts, event registrations, closed RTI requests and audit entries are anonymised; the rest
+// is deleted.
+func (s *Service) Plan(r *Records, holds []models.LegalHold) ([]Step, error) {
+	retainedUsers := map[uint]bool{}
+	var steps []Step
+	add := func(record string, id interface{}, action, reason string) {
+		step := Step{Record: record, ID: fmt.Sprint(id), Action: action, Reason: reason}
+		if hold := holdFor(holds, record, step.ID); hold != nil {
+			step.Action = ActionRetain
+			step.Reason = "Legal hold: " + hold.Reason
+			step.HoldID = &hold.ID
+		}
+		steps = append(steps, step)
+	}
+	owned := func(record string, id interface{}, userID uint) {
+		if retainedUsers[userID] {
+			add(record, id, ActionRetain, "Account retained")
+		} else {
+			add(record, id, ActionDelete, "")
+		}
+	}
+
+	for _, u := range r.Users {
+		reason, err := accountRetention(u, r.Subscriptions)
+		if err != nil {
+			return nil, err
+		}
+		action := ActionAnonymise
+		if reason != "" {
+			action = ActionRetain
+		}
+		add("users", u.ID, action, reason)
+		retainedUsers[u.ID] = steps[len(steps)-1].Action == ActionRetain
+	}
+
+	for _, sub := range r.Subscriptions {
+		add("user_subscriptions", sub.ID, ActionRetain, "Financial record")
+	}
+	for _, a := range r.DataAccess {
+		owned("user_data_access", a.ID, a.UserID)
+	}
+	for _, m := range r.OrganizationMembers {
+		owned("organization_members", m.ID, m.UserID)
+	}
+	for _, w := range r.Watchlists {
+		owned("watchlists", w.ID, w.UserID)
+	}
+	for _, a := range r.PriceAlerts {
+		owned("price_alerts", a.ID, a.UserID)
+	}
+	for _, w := range r.Webhooks {
+		owned("webhook_subscriptions", w.ID, w.UserID)
+	}
+	for _, j := range r.ExportJobs {
+		owned("export_jobs", j.ID, j.UserID)
+	}
+	for _, i := range r.Identities {
+		owned("external_identities", i.ID, i.UserID)
+	}
+	for _, sess := range r.Sessions {
+		owned("sessions", sess.ID, sess.UserID)
+	}
+	for _, e := range r.AuthEvents {
+		add("auth_events", e.ID, ActionRetain, "Security log")
+	}
+	for _, i := range r.Invites {
+		add("user_invites", i.ID, ActionDelete, "")
+	}
+	for _, i := range r.OrganizationInvites {
+		add("organization_invites", i.ID, ActionDelete, "")
+	}
+	for _, reg := range r.EventRegistrations {
+		add("event_registrations", reg.ID, ActionAnonymise, "")
+	}
+	for _, req := range r.RTIRequests {
+		if req.Status == cms_models.RTIStatusCompleted || req.Status == cms_models.RTIStatusRejected {
+			add("rti_requests", req.ID, ActionAnonymise, "")
+		} else {
+			add("rti_requests", req.ID, ActionRetain, "RTI request still being processed")
+		}
+	}
+
+	retained := map[string]bool{}
+	for _, step := range steps {
+		if step.Action == ActionRetain {
+			retained[step.Record+"/"+step.ID] = true
+		}
+	}
+	for _, e := range r.AuditLogs {
+		switch {
+		case retained[auditTable(e.TargetType)+"/"+e.TargetID]:
+			add("audit_logs", e.ID, ActionRetain, "Record retained")
+		case e.ActorID != nil && retainedUsers[*e.ActorID]:
+			add("audit_logs", e.ID, ActionRetain, "Account retained")
+		default:
+			add("audit_logs", e.ID, ActionAnonymise, "")
+		}
+	}
+	return steps, nil
+}
+
+// accountRetention returns why an account cannot be erased, if it cannot
+func accountRetention(u models.User, subscriptions []marketdata_models.UserSubscription) (string, error) {
+	if u.Role == models.RoleAdmin {
+		return "Admin account; change its role first", nil
+	}
+	for _, sub := range subscriptions {
+		if sub.UserID == u.ID && sub.Status == "active" && sub.EndDate.After(time.Now()) {
+			return "Active subscription; cancel it first", nil
+		}
+	}
+
+	var member marketdata_models.OrganizationMember
+	if err := config.DB.Where("user_id = ? AND role = ?", u.ID, marketdata_models.OrganizationRoleAdmin).
+		Limit(1).Find(&member).Error; err != nil || member.ID == 0 {
+		return "", err
+	}
+	var admins, others int64
+	config.DB.Model(&marketdata_models.OrganizationMember{}).
+		Where("organization_id = ? AND role = ?", member.OrganizationID, marketdata_models.OrganizationRoleAdmin).
+		Count(&admins)
+	config.DB.Model(&marketdata_models.OrganizationMember{}).
+		Where("organization_id = ? AND user_id <> ?", member.OrganizationID, u.ID).
+		Count(&others)
+	if admins == 1 && others > 0 {
+		return "Only admin of an organisation with other members; make another member admin first", nil
+	}
+	return "", nil
+}
+
+// holdFor returns the legal hold covering a record, if any
+func holdFor(holds []models.LegalHold, record, id string) *models.LegalHold {
+	for i := range holds {
+		h := &holds[i]
+		if h.RecordType != "" && h.RecordType != record {
+			continue
+		}
+		if h.RecordID != nil && fmt.Sprint(*h.RecordID) != id {
+			continue
+		}
+		return h
+	}
+	return nil
+}
+
+// Erase carries out the plan for the subject's records in one transaction. The writes are kept
+// out of the audit trail, which would otherwise hold the erased values; the caller records
+// the erasure itself.
+func (s *Service) Erase(subject Subject) (*Erasure, error) {
+	records, err := s.Find(subject)
+	if err != nil {
+		return nil, err
+	}
+	holds, err := s.ActiveHolds(subject, records)
+	if err != nil {
+		return nil, err
+	}
+	steps, err := s.Plan(records, holds)
+	if err != nil {
+		return nil, err
+	}
+
+	err = audit.Untracked(config.DB).Transaction(func(tx *gorm.DB) error {
+		for _, step := range steps {
+			if step.Action == ActionRetain {
+				continue
+			}
+			if err := apply(tx, step, subject, records); err != nil {
+				return fmt.Errorf("%s %s %s: %w", step.Action, step.Record, step.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Exported files go once their jobs are gone
+	var files []string
+	for _, step := range steps {
+		if step.Record != "export_jobs" || step.Action != ActionDelete {
+			continue
+		}
+		for _, job := range records.ExportJobs {
+			if fmt.Sprint(job.ID) == step.ID && job.FilePath != "" {
+				files = append(files, job.FilePath)
+			}
+		}
+	}
+	for _, path := range files {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("Warning: Failed to remove export file %s: %v", path, err)
+		}
+	}
+
+	return &Erasure{Steps: steps, Counts: CountActions(steps)}, nil
+}
+
+// CountActions counts the steps taking each action
+func CountActions(steps []Step) map[string]int {
+	counts := map[string]int{ActionAnonymise: 0, ActionDelete: 0, ActionRetain: 0}
+	for _, step := range steps {
+		counts[step.Action]++
+	}
+	return counts
+}
+
+// apply anonymises or deletes one record
+func apply(tx *gorm.DB, step Step, subject Subject, records *Records) error {
+	id := step.ID
+	switch step.Record {
+	case "audit_logs":
+		// Who made the change and from where is the subject's data if they made it, or if no one
+		// signed in did, as when they registered for an event
+		userIDs := records.userIDs()
+		for _, e := range records.AuditLogs {
+			if fmt.Sprint(e.ID) != id {
+				continue
+			}
+			actor := e.ActorID == nil || subject.hasEmail(e.ActorEmail)
+			for _, userID := range userIDs {
+				if e.ActorID != nil && *e.ActorID == userID {
+					actor = true
+				}
+			}
+			return audit.Redact(tx, e, actor)
+		}
+		return nil
+	case "users":
+		// The address must stay unique, and an empty password hash matches no password
+		if err := tx.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"name":               erasedName,
+			"email":              "erased-" + id + "@erased.invalid",
+			"password":           "",
+			"avatar":             nil,
+			"bio":                nil,
+			"company":            nil,
+			"phone":              nil,
+			"country":            nil,
+			"time_zone":          nil,
+			"preferences":        "",
+			"is_active":          false,
+			"two_factor_enabled": false,
+		}).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&models.TwoFactor{}, &models.RecoveryCode{}, &models.PasswordResetToken{}} {
+			if err := tx.Where("user_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	case "sessions":
+		if err := tx.Where("session_id = ?", id).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&models.Session{}).Error
+	case "watchlists":
+		if err := tx.Where("watchlist_id = ?", id).Delete(&marketdata_models.WatchlistItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&marketdata_models.Watchlist{}).Error
+	case "webhook_subscriptions":
+		if err := tx.Where("subscription_id = ?", id).Delete(&marketdata_models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&marketdata_models.WebhookSubscription{}).Error
+	case "event_registrations":
+		return tx.Unscoped().Model(&cms_models.EventRegistration{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"full_name":            erasedName,
+			"email":                "",
+			"phone":                "",
+			"organization":         nil,
+			"position":             nil,
+			"dietary_requirements": nil,
+			"special_needs":        nil,
+		}).Error
+	case "rti_requests":
+		return tx.Unscoped().Model(&cms_models.RTIRequest{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"full_name":    erasedName,
+			"email":        "",
+			"phone":        "",
+			"id_number":    "",
+			"address":      "",
+			"organization": nil,
+		}).Error
+	}
+
+	deletable := map[string]interface{}{
+		"user_data_access":     &marketdata_models.UserDataAccess{},
+		"organization_members": &marketdata_models.OrganizationMember{},
+		"price_alerts":         &marketdata_models.PriceAlert{},
+		"export_jobs":          &marketdata_models.ExportJob{},
+		"external_identities":  &models.ExternalIdentity{},
+		"user_invites":         &models.UserInvite{},
+		"organization_invites": &marketdata_models.OrganizationInvite{},
+	}
+	model, ok := deletable[step.Record]
+	if !ok || step.Action != ActionDelete {
+		return fmt.Errorf("no way to %s %s", step.Action, strings.ReplaceAll(step.Record, "_", " "))
+	}
+	return tx.Where("id = ?", id).Delete(model).Error
+}
@@ -0,0 +1,64 @@
+package privacy
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// Bundle is the answer to an access request: every record held about the subject
+type Bundle struct {
+	Subject     Subject        `json:"subject"`
+	GeneratedAt time.Time      `json:"generated_at"`
+	Counts      map[string]int `json:"counts"`
+	Records     *Records       `json:"records,omitempty"`
+}
+
+// NewBundle puts the subject's records in a bundle
+func NewBundle(subject Subject, records *Records) *Bundle {
+	return &Bundle{
+		Subject:     subject,
+		GeneratedAt: time.Now().UTC(),
+		Counts:      records.Counts(),
+		Records:     records,
+	}
+}
+
+// Zip writes the bundle as a ZIP archive: manifest.json with the subject and counts, and a
+// JSON file of the records of each table that has any
+func (b *Bundle) Zip() ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	manifest := *b
+	manifest.Records = nil
+	type file struct {
+		name string
+		data interface{}
+	}
+	files := []file{{"manifest.json", manifest}}
+	for _, section := range b.Records.Sections() {
+		if section.Count > 0 {
+			files = append(files, file{section.Table + ".json", section.Records})
+		}
+	}
+
+	for _, file := range files {
+		data, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: b.GeneratedAt})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
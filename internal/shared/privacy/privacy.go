@@ -0,0 +1,331 @@
+// Package privacy answers data-protection requests under the Data Protection Act: it finds the
+// records held about a person by email address or phone number across the CMS and market data,
+// exports them, and erases or anonymises them unless a legal hold or a retention rule keeps them.
+package privacy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	cms_models "gcx-cms/internal/cms/models"
+	marketdata_models "gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoSubject    = errors.New("an email address or a phone number is required")
+	ErrInvalidPhone = errors.New("phone number is too short")
+)
+
+// countryCode is dropped from phone numbers so that local and international forms match
+const countryCode = "233"
+
+// Subject identifies the person a request is about
+type Subject struct {
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"` // Digits of the national number
+}
+
+// NewSubject normalises an email address and a phone number, at least one of which is needed
+func NewSubject(email, phone string) (Subject, error) {
+	s := Subject{Email: strings.ToLower(strings.TrimSpace(email)), Phone: NormalizePhone(phone)}
+	if s.Email == "" && strings.TrimSpace(phone) == "" {
+		return s, ErrNoSubject
+	}
+	if strings.TrimSpace(phone) != "" && len(s.Phone) < 7 {
+		return s, ErrInvalidPhone
+	}
+	return s, nil
+}
+
+// NormalizePhone reduces a phone number to the digits of the national number, so that
+// 024 412 3456, 0244123456 and +233 24 412 3456 match
+func NormalizePhone(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+	digits = strings.TrimLeft(digits, "0")
+	if len(digits) == len(countryCode)+9 && strings.HasPrefix(digits, countryCode) {
+		digits = digits[len(countryCode):]
+	}
+	return strings.TrimLeft(digits, "0")
+}
+
+// Key identifies the subject in the audit trail without recording the email or phone number
+func (s Subject) Key() string {
+	sum := sha256.Sum256([]byte(s.Email + "|" + s.Phone))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s Subject) hasEmail(email string) bool {
+	return s.Email != "" && strings.EqualFold(strings.TrimSpace(email), s.Email)
+}
+
+func (s Subject) hasPhone(phone string) bool {
+	return s.Phone != "" && NormalizePhone(phone) == s.Phone
+}
+
+func (s Subject) matches(email, phone string) bool {
+	return s.hasEmail(email) || s.hasPhone(phone)
+}
+
+// where selects rows whose email column is the subject's, or whose phone column may be; phone
+// numbers are stored in any format, so candidates are narrowed by their last digits and
+// matched exactly by the caller
+func (s Subject) where(db *gorm.DB, emailColumn, phoneColumn string) *gorm.DB {
+	var conditions []string
+	var args []interface{}
+	if s.Email != "" && emailColumn != "" {
+		conditions = append(conditions, "LOWER("+emailColumn+") = ?")
+		args = append(args, s.Email)
+	}
+	if s.Phone != "" && phoneColumn != "" {
+		conditions = append(conditions, phoneColumn+" LIKE ?")
+		args = append(args, "%"+s.Phone[len(s.Phone)-4:])
+	}
+	if len(conditions) == 0 {
+		return db.Where("1 = 0")
+	}
+	return db.Where(strings.Join(conditions, " OR "), args...)
+}
+
+// Records are the records held about a subject, by table
+type Records struct {
+	Users               []models.User                           `json:"users"`
+	Subscriptions       []marketdata_models.UserSubscription    `json:"user_subscriptions"`
+	DataAccess          []marketdata_models.UserDataAccess      `json:"user_data_access"`
+	OrganizationMembers []marketdata_models.OrganizationMember  `json:"organization_members"`
+	Watchlists          []marketdata_models.Watchlist           `json:"watchlists"`
+	PriceAlerts         []marketdata_models.PriceAlert          `json:"price_alerts"`
+	Webhooks            []marketdata_models.WebhookSubscription `json:"webhook_subscriptions"`
+	ExportJobs          []marketdata_models.ExportJob           `json:"export_jobs"`
+	Identities          []models.ExternalIdentity               `json:"external_identities"`
+	Sessions            []models.Session                        `json:"sessions"`
+	AuthEvents          []models.AuthEvent                      `json:"auth_events"`
+	Invites             []models.UserInvite                     `json:"user_invites"`
+	OrganizationInvites []marketdata_models.OrganizationInvite  `json:"organization_invites"`
+	EventRegistrations  []cms_models.EventRegistration          `json:"event_registrations"`
+	RTIRequests         []cms_models.RTIRequest                 `json:"rti_requests"`
+	AuditLogs           []models.AuditLog                       `json:"audit_logs"`
+}
+
+// Section is the records of one table
+type Section struct {
+	Table   string
+	Records interface{}
+	Count   int
+}
+
+// Sections lists the records by table, in a fixed order
+func (r *Records) Sections() []Section {
+	return []Section{
+		{"users", r.Users, len(r.Users)},
+		{"user_subscriptions", r.Subscriptions, len(r.Subscriptions)},
+		{"user_data_access", r.DataAccess, len(r.DataAccess)},
+		{"organization_members", r.OrganizationMembers, len(r.OrganizationMembers)},
+		{"watchlists", r.Watchlists, len(r.Watchlists)},
+		{"price_alerts", r.PriceAlerts, len(r.PriceAlerts)},
+		{"webhook_subscriptions", r.Webhooks, len(r.Webhooks)},
+		{"export_jobs", r.ExportJobs, len(r.ExportJobs)},
+		{"external_identities", r.Identities, len(r.Identities)},
+		{"sessions", r.Sessions, len(r.Sessions)},
+		{"auth_events", r.AuthEvents, len(r.AuthEvents)},
+		{"user_invites", r.Invites, len(r.Invites)},
+		{"organization_invites", r.OrganizationInvites, len(r.OrganizationInvites)},
+		{"event_registrations", r.EventRegistrations, len(r.EventRegistrations)},
+		{"rti_requests", r.RTIRequests, len(r.RTIRequests)},
+		{"audit_logs", r.AuditLogs, len(r.AuditLogs)},
+	}
+}
+
+// ids returns the primary keys of the section's records, as the audit trail records them
+func (s Section) ids() []string {
+	records := reflect.ValueOf(s.Records)
+	ids := make([]string, records.Len())
+	for i := range ids {
+		ids[i] = fmt.Sprint(records.Index(i).FieldByName("ID").Interface())
+	}
+	return ids
+}
+
+// auditAliases are the target types the trail records a section's records under, besides the
+// section's name: the names handlers record actions under, and table names that differ
+var auditAliases = map[string]string{
+	"user":                     "users",
+	"invite":                   "user_invites",
+	"user_external_identities": "external_identities",
+}
+
+// auditTable returns the table an audit entry's target is a record of
+func auditTable(targetType string) string {
+	if table, ok := auditAliases[targetType]; ok {
+		return table
+	}
+	return targetType
+}
+
+// Counts returns the number of records found in each table
+func (r *Records) Counts() map[string]int {
+	counts := map[string]int{}
+	for _, section := range r.Sections() {
+		counts[section.Table] = section.Count
+	}
+	return counts
+}
+
+// RecordTypes are the tables searched, which a legal hold can be limited to
+func RecordTypes() []string {
+	var types []string
+	for _, section := range (&Records{}).Sections() {
+		types = append(types, section.Table)
+	}
+	return types
+}
+
+// IsValidRecordType checks if a table is one searched
+func IsValidRecordType(table string) bool {
+	for _, t := range RecordTypes() {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+// Service finds, exports and erases the records of data subjects
+type Service struct{}
+
+// NewService creates a privacy service
+func NewService() *Service {
+	return &Service{}
+}
+
+// Find loads every record held about the subject: their accounts and what belongs to them,
+// the event registrations, RTI requests and invites made with their email or phone, including
+// soft-deleted ones, and the audit entries of what they did, of changes to those records, and
+// that mention their email
+func (s *Service) Find(subject Subject) (*Records, error) {
+	db := config.DB
+	r := &Records{}
+
+	var users []models.User
+	if err := subject.where(db, "email", "phone").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		phone := ""
+		if u.Phone != nil {
+			phone = *u.Phone
+		}
+		if subject.matches(u.Email, phone) {
+			r.Users = append(r.Users, u)
+		}
+	}
+
+	if ids := r.userIDs(); len(ids) > 0 {
+		// A new session, so that each load starts from these conditions alone
+		byUser := db.Where("user_id IN ?", ids).Session(&gorm.Session{})
+		loads := []struct {
+			query *gorm.DB
+			dest  interface{}
+		}{
+			{byUser.Preload("Plan"), &r.Subscriptions},
+			{byUser, &r.DataAccess},
+			{byUser, &r.OrganizationMembers},
+			{byUser.Preload("Items"), &r.Watchlists},
+			{byUser, &r.PriceAlerts},
+			{byUser, &r.Webhooks},
+			{byUser, &r.ExportJobs},
+			{byUser, &r.Identities},
+			{byUser, &r.Sessions},
+		}
+		for _, load := range loads {
+			if err := load.query.Order("id").Find(load.dest).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	authEvents := db.Where("1 = 0")
+	if ids := r.userIDs(); len(ids) > 0 {
+		authEvents = authEvents.Or("user_id IN ?", ids)
+	}
+	if subject.Email != "" {
+		authEvents = authEvents.Or("LOWER(email) = ?", subject.Email)
+	}
+	if err := authEvents.Order("id").Find(&r.AuthEvents).Error; err != nil {
+		return nil, err
+	}
+
+	if subject.Email != "" {
+		if err := db.Where("LOWER(email) = ?", subject.Email).Order("id").Find(&r.Invites).Error; err != nil {
+			return nil, err
+		}
+		if err := db.Where("LOWER(email) = ?", subject.Email).Order("id").Find(&r.OrganizationInvites).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var registrations []cms_models.EventRegistration
+	if err := subject.where(db.Unscoped().Preload("Event"), "email", "phone").Order("id").Find(&registrations).Error; err != nil {
+		return nil, err
+	}
+	for _, reg := range registrations {
+		if subject.matches(reg.Email, reg.Phone) {
+			r.EventRegistrations = append(r.EventRegistrations, reg)
+		}
+	}
+
+	var requests []cms_models.RTIRequest
+	if err := subject.where(db.Unscoped(), "email", "phone").Order("id").Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	for _, req := range requests {
+		if subject.matches(req.Email, req.Phone) {
+			r.RTIRequests = append(r.RTIRequests, req)
+		}
+	}
+
+	entries := db.Where("1 = 0")
+	if ids := r.userIDs(); len(ids) > 0 {
+		entries = entries.Or("actor_id IN ?", ids)
+	}
+	if subject.Email != "" {
+		entries = entries.Or("LOWER(actor_email) = ? OR LOWER(changes) LIKE ?", subject.Email, "%"+subject.Email+"%")
+	}
+	for _, section := range r.Sections() {
+		if section.Count == 0 || section.Table == "audit_logs" {
+			continue
+		}
+		types := []string{section.Table}
+		for alias, table := range auditAliases {
+			if table == section.Table {
+				types = append(types, alias)
+			}
+		}
+		entries = entries.Or("target_type IN ? AND target_id IN ?", types, section.ids())
+	}
+	if err := entries.Order("id").Find(&r.AuditLogs).Error; err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *Records) userIDs() []uint {
+	ids := make([]uint, len(r.Users))
+	for i, u := range r.Users {
+		ids[i] = u.ID
+	}
+	return ids
+}
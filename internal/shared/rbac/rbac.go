@@ -0,0 +1,140 @@
+// Package rbac resolves what a user may do from the permissions of their role
+package rbac
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/models"
+)
+
+// cacheTTL bounds how long role changes made by another instance take to apply
+const cacheTTL = 30 * time.Second
+
+// Service caches the permissions of every role
+type Service struct {
+	mu       sync.RWMutex
+	roles    map[models.UserRole]roleEntry
+	loadedAt time.Time
+}
+
+// roleEntry is what the cache keeps of a role
+type roleEntry struct {
+	granted          map[string]bool
+	requireTwoFactor bool
+}
+
+var (
+	service     *Service
+	serviceOnce sync.Once
+)
+
+// GetService returns the shared role permission cache
+func GetService() *Service {
+	serviceOnce.Do(func() {
+		service = &Service{}
+	})
+	return service
+}
+
+// Can reports whether a user's role grants every listed permission
+func Can(user *models.User, perms ...string) bool {
+	return user != nil && GetService().Has(user.Role, perms...)
+}
+
+// Has reports whether a role grants every listed permission. The admin role grants everything.
+func (s *Service) Has(role models.UserRole, perms ...string) bool {
+	if role == models.RoleAdmin {
+		return true
+	}
+	entry, ok := s.load()[role]
+	if !ok {
+		return false
+	}
+	for _, p := range perms {
+		if !entry.granted[p] {
+			return false
+		}
+	}
+	return true
+}
+
+// Permissions lists the permissions a role grants
+func (s *Service) Permissions(role models.UserRole) []string {
+	if role == models.RoleAdmin {
+		return models.AllPermissionNames()
+	}
+	granted := s.load()[role].granted
+	perms := []string{}
+	for _, p := range models.AllPermissions {
+		if granted[p.Name] {
+			perms = append(perms, p.Name)
+		}
+	}
+	return perms
+}
+
+// RoleExists reports whether a role is defined
+func (s *Service) RoleExists(role models.UserRole) bool {
+	_, ok := s.load()[role]
+	return ok || role == models.RoleAdmin
+}
+
+// RequiresTwoFactor reports whether users with a role must use two-factor authentication
+func (s *Service) RequiresTwoFactor(role models.UserRole) bool {
+	return s.load()[role].requireTwoFactor
+}
+
+// Invalidate drops the cache so the next check sees role changes
+func (s *Service) Invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// load returns the cached permissions, reloading them from the database when stale. If the
+// database cannot be read the previous permissions, or the built-in defaults, stay in use.
+func (s *Service) load() map[models.UserRole]roleEntry {
+	s.mu.RLock()
+	roles, fresh := s.roles, time.Since(s.loadedAt) < cacheTTL
+	s.mu.RUnlock()
+	if fresh {
+		return roles
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.roles != nil && time.Since(s.loadedAt) < cacheTTL {
+		return s.roles
+	}
+
+	var stored []models.Role
+	if err := config.DB.Find(&stored).Error; err != nil {
+		log.Printf("Warning: Failed to load roles: %v", err)
+		if s.roles == nil {
+			s.roles = index(models.DefaultRoles())
+		}
+		s.loadedAt = time.Now()
+		return s.roles
+	}
+	if len(stored) == 0 {
+		stored = models.DefaultRoles()
+	}
+	s.roles = index(stored)
+	s.loadedAt = time.Now()
+	return s.roles
+}
+
+func index(roles []models.Role) map[models.UserRole]roleEntry {
+	indexed := make(map[models.UserRole]roleEntry, len(roles))
+	for _, r := range roles {
+		granted := map[string]bool{}
+		for _, p := range r.PermissionList() {
+			granted[p] = true
+		}
+		indexed[r.Name] = roleEntry{granted: granted, requireTwoFactor: r.RequireTwoFactor}
+	}
+	return indexed
+}
@@ -0,0 +1,263 @@
+// Package session manages signed-in sessions: short-lived access tokens backed by rotating,
+// server-side refresh tokens that can be revoked per device or for all of a user's devices.
+package session
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"gcx-cms/internal/shared/config"
+	"gcx-cms/internal/shared/models"
+	"gcx-cms/internal/shared/token"
+
+	"gorm.io/gorm"
+)
+
+// Refresh errors, worded as returned to API clients
+var (
+	ErrInvalidRefreshToken = errors.New("Invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("Refresh token reuse detected, session revoked")
+	ErrSessionRevoked      = errors.New("Session has been revoked or has expired")
+	ErrUserDisabled        = errors.New("User account is disabled")
+)
+
+// Tokens are the credentials returned on sign-in and refresh
+type Tokens struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // Access token lifetime in seconds
+	SessionID    string `json:"session_id"`
+}
+
+// Service creates, refreshes and revokes sessions
+type Service struct{}
+
+// NewService creates a session service
+func NewService() *Service {
+	return &Service{}
+}
+
+// RefreshLifetime is how long a session lasts without being refreshed
+// (JWT_REFRESH_EXPIRES_IN, default 30 days)
+func RefreshLifetime() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("JWT_REFRESH_EXPIRES_IN")); err == nil && d > 0 {
+		return d
+	}
+	return 30 * 24 * time.Hour
+}
+
+// Create starts a session for a user who has just authenticated
+func (s *Service) Create(user *models.User, userAgent, ipAddress string) (*Tokens, error) {
+	id, err := token.NewOpaque(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	sess := models.Session{
+		ID:         id,
+		UserID:     user.ID,
+		UserAgent:  truncate(userAgent, 500),
+		IPAddress:  ipAddress,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(RefreshLifetime()),
+	}
+
+	var tokens *Tokens
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&sess).Error; err != nil {
+			return err
+		}
+		var err error
+		tokens, err = issue(tx, user, &sess)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// Refresh exchanges a refresh token for new tokens. Each refresh token works once; presenting
+// one that was already exchanged means it leaked, so the whole session is revoked.
+func (s *Service) Refresh(refreshToken, userAgent, ipAddress string) (*Tokens, *models.User, error) {
+	var stored models.RefreshToken
+	if err := config.DB.Where("token_hash = ?", token.Hash(refreshToken)).First(&stored).Error; err != nil {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	var sess models.Session
+	if err := config.DB.First(&sess, "id = ?", stored.SessionID).Error; err != nil {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	if !sess.IsActive() {
+		return nil, nil, ErrSessionRevoked
+	}
+
+	if stored.UsedAt != nil {
+		s.revokeReused(&sess)
+		return nil, nil, ErrRefreshTokenReused
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, sess.UserID).Error; err != nil {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	if !user.IsActive {
+		return nil, nil, ErrUserDisabled
+	}
+
+	var tokens *Tokens
+	reused := false
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		// Conditional so two concurrent exchanges of the same token cannot both succeed
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", stored.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			reused = true
+			return ErrRefreshTokenReused
+		}
+
+		sess.LastUsedAt = now
+		sess.ExpiresAt = now.Add(RefreshLifetime())
+		sess.UserAgent = truncate(userAgent, 500)
+		sess.IPAddress = ipAddress
+		if err := tx.Model(&sess).Updates(map[string]interface{}{
+			"last_used_at": sess.LastUsedAt,
+			"expires_at":   sess.ExpiresAt,
+			"user_agent":   sess.UserAgent,
+			"ip_address":   sess.IPAddress,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		tokens, err = issue(tx, &user, &sess)
+		return err
+	})
+	if reused {
+		s.revokeReused(&sess)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, &user, nil
+}
+
+// Revoke ends one of a user's sessions
+func (s *Service) Revoke(userID uint, sessionID, reason string) error {
+	result := config.DB.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RevokeAll ends every session of a user except keepSessionID, which may be empty
+func (s *Service) RevokeAll(userID uint, keepSessionID, reason string) (int64, error) {
+	query := config.DB.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if keepSessionID != "" {
+		query = query.Where("id <> ?", keepSessionID)
+	}
+	result := query.Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason})
+	return result.RowsAffected, result.Error
+}
+
+// List returns a user's active sessions, most recently used first
+func (s *Service) List(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	err := config.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// IsActive reports whether an access token's session is still valid, so revocation applies
+// to access tokens already issued rather than only at the next refresh
+func (s *Service) IsActive(userID uint, sessionID string) bool {
+	var count int64
+	config.DB.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", sessionID, userID, time.Now()).
+		Count(&count)
+	return count > 0
+}
+
+// StartCleanup periodically deletes sessions that expired or were revoked more than a day ago
+func (s *Service) StartCleanup(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.cleanup()
+		}
+	}()
+}
+
+func (s *Service) cleanup() {
+	cutoff := time.Now().Add(-24 * time.Hour)
+	expired := config.DB.Model(&models.Session{}).
+		Select("id").
+		Where("expires_at < ? OR revoked_at < ?", cutoff, cutoff)
+
+	if err := config.DB.Where("session_id IN (?)", expired).Delete(&models.RefreshToken{}).Error; err != nil {
+		log.Printf("Warning: Failed to delete expired refresh tokens: %v", err)
+		return
+	}
+	if err := config.DB.Where("expires_at < ? OR revoked_at < ?", cutoff, cutoff).Delete(&models.Session{}).Error; err != nil {
+		log.Printf("Warning: Failed to delete expired sessions: %v", err)
+	}
+}
+
+// revokeReused revokes a session whose refresh token was presented twice
+func (s *Service) revokeReused(sess *models.Session) {
+	log.Printf("Security: refresh token reuse for user %d, revoking session %s", sess.UserID, sess.ID)
+	if err := s.Revoke(sess.UserID, sess.ID, models.SessionRevokedReuse); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Warning: Failed to revoke session %s: %v", sess.ID, err)
+	}
+}
+
+// issue stores a new refresh token for a session and signs an access token bound to it
+func issue(tx *gorm.DB, user *models.User, sess *models.Session) (*Tokens, error) {
+	refresh, err := token.NewOpaque(32)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Create(&models.RefreshToken{
+		SessionID: sess.ID,
+		TokenHash: token.Hash(refresh),
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	access, err := token.GetService().Issue(user, sess.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &Tokens{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(token.Lifetime().Seconds()),
+		SessionID:    sess.ID,
+	}, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
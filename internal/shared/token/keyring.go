@@ -0,0 +1,339 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Supported signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+// minSecretLength is the shortest HS256 secret accepted, matching the SHA-256 block it keys
+const minSecretLength = 32
+
+// Key is a signing or verification key identified by its kid
+type Key struct {
+	ID        string
+	Algorithm string
+	// private signs tokens; nil for keys kept only to verify tokens issued before a rotation
+	private any
+	// public verifies tokens; for HS256 it is the shared secret
+	public any
+}
+
+// CanSign reports whether the key holds private material
+func (k *Key) CanSign() bool {
+	return k.private != nil
+}
+
+// NewHMACKey creates an HS256 key. Its kid is derived from the secret so it stays the same
+// when the secret moves from JWT_SECRET to JWT_PREVIOUS_SECRETS.
+func NewHMACKey(secret string) (*Key, error) {
+	if len(secret) < minSecretLength {
+		return nil, fmt.Errorf("HS256 secret must be at least %d characters", minSecretLength)
+	}
+	sum := sha256.Sum256([]byte(secret))
+	return &Key{
+		ID:        "hs-" + hex.EncodeToString(sum[:6]),
+		Algorithm: AlgHS256,
+		private:   []byte(secret),
+		public:    []byte(secret),
+	}, nil
+}
+
+// NewKeyFromPEM parses an RSA or P-256 EC key, private or public, from PEM
+func NewKeyFromPEM(kid string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{ID: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.private, key.public = k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.public = k
+	case *ecdsa.PrivateKey:
+		key.private, key.public = k, &k.PublicKey
+	case *ecdsa.PublicKey:
+		key.public = k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	switch pub := key.public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		key.Algorithm = AlgRS256
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, errors.New("EC keys must use the P-256 curve")
+		}
+		key.Algorithm = AlgES256
+	}
+	return key, nil
+}
+
+// GenerateES256Key creates a random ES256 key, used when no keys are configured in development
+func GenerateES256Key(kid string) (*Key, error) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{ID: kid, Algorithm: AlgES256, private: private, public: &private.PublicKey}, nil
+}
+
+// Keyring holds the key new tokens are signed with and every key tokens are still accepted from
+type Keyring struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+// NewKeyring creates an empty keyring
+func NewKeyring() *Keyring {
+	return &Keyring{keys: make(map[string]*Key)}
+}
+
+// Add adds a key to the keyring. Kids must be unique.
+func (kr *Keyring) Add(key *Key) error {
+	if key.ID == "" {
+		return errors.New("key id is required")
+	}
+	if _, exists := kr.keys[key.ID]; exists {
+		return fmt.Errorf("duplicate key id %q", key.ID)
+	}
+	kr.keys[key.ID] = key
+	return nil
+}
+
+// SetSigningKey selects the key new tokens are signed with
+func (kr *Keyring) SetSigningKey(kid string) error {
+	key, ok := kr.keys[kid]
+	if !ok {
+		return fmt.Errorf("signing key %q not found", kid)
+	}
+	if !key.CanSign() {
+		return fmt.Errorf("key %q has no private key", kid)
+	}
+	kr.signing = key
+	return nil
+}
+
+// SigningKey returns the key new tokens are signed with
+func (kr *Keyring) SigningKey() *Key {
+	return kr.signing
+}
+
+// Lookup finds a key by kid
+func (kr *Keyring) Lookup(kid string) (*Key, bool) {
+	key, ok := kr.keys[kid]
+	return key, ok
+}
+
+// hmacKeys returns the HS256 keys, which are tried for tokens issued before kids were set
+func (kr *Keyring) hmacKeys() []*Key {
+	var keys []*Key
+	for _, kid := range kr.kids() {
+		if key := kr.keys[kid]; key.Algorithm == AlgHS256 {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (kr *Keyring) kids() []string {
+	kids := make([]string, 0, len(kr.keys))
+	for kid := range kr.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+	return kids
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the keyring. HS256 secrets are never published, so services
+// verifying GCX tokens through the JWKS need an RS256 or ES256 signing key.
+func (kr *Keyring) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, kid := range kr.kids() {
+		key := kr.keys[kid]
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Algorithm,
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case *ecdsa.PublicKey:
+			size := (pub.Curve.Params().BitSize + 7) / 8
+			set.Keys = append(set.Keys, JWK{
+				Kty: "EC",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Algorithm,
+				Crv: pub.Curve.Params().Name,
+				X:   base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size))),
+				Y:   base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size))),
+			})
+		}
+	}
+	return set
+}
+
+// LoadKeyringFromEnv builds the keyring from configuration:
+//
+//	JWT_KEYS_DIR          directory of PEM keys named <kid>.pem; public-only keys just verify
+//	JWT_SIGNING_KID       kid of the key to sign with, required when the directory has several
+//	JWT_SECRET            HS256 secret, used to sign when no key directory is set
+//	JWT_PREVIOUS_SECRETS  comma-separated retired HS256 secrets still accepted
+//
+// Rotating means adding the new key, switching JWT_SIGNING_KID, and removing the old key
+// once the tokens it signed have expired.
+func LoadKeyringFromEnv(allowEphemeral bool) (*Keyring, error) {
+	kr := NewKeyring()
+	var signingKid string
+
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		key, err := NewHMACKey(secret)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_SECRET: %w", err)
+		}
+		if err := kr.Add(key); err != nil {
+			return nil, err
+		}
+		signingKid = key.ID
+	}
+
+	for _, secret := range strings.Split(os.Getenv("JWT_PREVIOUS_SECRETS"), ",") {
+		if secret = strings.TrimSpace(secret); secret == "" {
+			continue
+		}
+		key, err := NewHMACKey(secret)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_PREVIOUS_SECRETS: %w", err)
+		}
+		// Only verifies
+		key.private = nil
+		if err := kr.Add(key); err != nil {
+			return nil, fmt.Errorf("JWT_PREVIOUS_SECRETS: %w", err)
+		}
+	}
+
+	if dir := os.Getenv("JWT_KEYS_DIR"); dir != "" {
+		paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+		if err != nil {
+			return nil, err
+		}
+		var signers []string
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			kid := strings.TrimSuffix(filepath.Base(path), ".pem")
+			key, err := NewKeyFromPEM(kid, data)
+			if err != nil {
+				return nil, fmt.Errorf("JWT key %s: %w", path, err)
+			}
+			if err := kr.Add(key); err != nil {
+				return nil, err
+			}
+			if key.CanSign() {
+				signers = append(signers, kid)
+			}
+		}
+
+		switch {
+		case os.Getenv("JWT_SIGNING_KID") != "":
+			signingKid = os.Getenv("JWT_SIGNING_KID")
+		case len(signers) == 1:
+			signingKid = signers[0]
+		case len(signers) > 1:
+			return nil, fmt.Errorf("JWT_SIGNING_KID is required with %d private keys in %s", len(signers), dir)
+		}
+	} else if kid := os.Getenv("JWT_SIGNING_KID"); kid != "" {
+		signingKid = kid
+	}
+
+	if signingKid == "" {
+		if !allowEphemeral {
+			return nil, errors.New("no JWT signing key configured, set JWT_KEYS_DIR or JWT_SECRET")
+		}
+		key, err := GenerateES256Key("ephemeral")
+		if err != nil {
+			return nil, err
+		}
+		if err := kr.Add(key); err != nil {
+			return nil, err
+		}
+		signingKid = key.ID
+	}
+
+	if err := kr.SetSigningKey(signingKid); err != nil {
+		return nil, err
+	}
+	return kr, nil
+}
@@ -0,0 +1,25 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// NewOpaque creates a random URL-safe token of n bytes for links and refresh tokens, which
+// unlike JWTs carry no claims and are looked up server-side by their Hash. It also serves for
+// secrets and for IDs and file names that must not be guessed.
+func NewOpaque(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Hash is the SHA-256 hex digest opaque tokens are stored as
+func Hash(t string) string {
+	sum := sha256.Sum256([]byte(t))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,155 @@
+// Package token issues and validates the JWTs GCX APIs are called with. Keys come from
+// configuration and are held in a keyring so tokens signed by a retired key stay valid
+// during a rotation; the public keys are published as a JWKS for other services.
+package token
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"gcx-cms/internal/shared/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Issuer is the iss claim of GCX tokens
+const Issuer = "gcx-cms"
+
+// Lifetime is how long access tokens are valid (JWT_EXPIRES_IN, default 15m). They are kept
+// short because clients renew them with a refresh token.
+func Lifetime() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("JWT_EXPIRES_IN")); err == nil && d > 0 {
+		return d
+	}
+	return 15 * time.Minute
+}
+
+// Claims are the claims of a GCX user token
+type Claims struct {
+	UserID uint            `json:"user_id"`
+	Email  string          `json:"email"`
+	Role   models.UserRole `json:"role"`
+	// SessionID binds the token to a server-side session so revoking it takes effect at once
+	SessionID string `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// ErrUnknownKey is returned for tokens signed by a key not in the keyring
+var ErrUnknownKey = errors.New("token signed by unknown key")
+
+// Service signs and verifies tokens with a keyring
+type Service struct {
+	keyring *Keyring
+}
+
+var (
+	service     *Service
+	serviceOnce sync.Once
+)
+
+// GetService returns the token service, loading its keys from the environment on first use.
+// Outside release mode a missing configuration falls back to a random key that lasts until
+// restart.
+func GetService() *Service {
+	serviceOnce.Do(func() {
+		allowEphemeral := os.Getenv("GIN_MODE") != "release"
+		keyring, err := LoadKeyringFromEnv(allowEphemeral)
+		if err != nil {
+			log.Fatal("Failed to load JWT keys:", err)
+		}
+		if keyring.SigningKey().ID == "ephemeral" {
+			log.Println("Warning: no JWT keys configured, signing with a temporary key; tokens will not survive a restart")
+		}
+		service = NewService(keyring)
+	})
+	return service
+}
+
+// NewService creates a token service over a keyring
+func NewService(keyring *Keyring) *Service {
+	return &Service{keyring: keyring}
+}
+
+// Keyring returns the service's keyring
+func (s *Service) Keyring() *Keyring {
+	return s.keyring
+}
+
+// Issue creates a signed access token for a user's session
+func (s *Service) Issue(user *models.User, sessionID string) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(Lifetime())),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    Issuer,
+		},
+	}
+	return s.Sign(claims)
+}
+
+// Sign signs claims with the current signing key, setting its kid in the header
+func (s *Service) Sign(claims jwt.Claims) (string, error) {
+	key := s.keyring.SigningKey()
+	method := jwt.GetSigningMethod(key.Algorithm)
+	if method == nil {
+		return "", fmt.Errorf("unsupported signing algorithm %s", key.Algorithm)
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.private)
+}
+
+// Parse verifies a token against the keyring and decodes it into claims. Tokens without a kid
+// predate key ids and are checked against the HS256 secrets. Extra options such as an audience
+// apply on top of the issuer check.
+func (s *Service) Parse(tokenString string, claims jwt.Claims, opts ...jwt.ParserOption) error {
+	options := append([]jwt.ParserOption{jwt.WithIssuer(Issuer)}, opts...)
+
+	unverified, _, err := jwt.NewParser().ParseUnverified(tokenString, claims)
+	if err != nil {
+		return err
+	}
+	kid, _ := unverified.Header["kid"].(string)
+
+	if kid == "" {
+		err = ErrUnknownKey
+		for _, key := range s.keyring.hmacKeys() {
+			if err = parseWithKey(tokenString, claims, key, options); err == nil {
+				return nil
+			}
+		}
+		return err
+	}
+
+	key, ok := s.keyring.Lookup(kid)
+	if !ok {
+		return ErrUnknownKey
+	}
+	return parseWithKey(tokenString, claims, key, options)
+}
+
+// parseWithKey verifies a token with one key, which must match the token's algorithm so a
+// public key can never be used as an HMAC secret
+func parseWithKey(tokenString string, claims jwt.Claims, key *Key, options []jwt.ParserOption) error {
+	options = append(options, jwt.WithValidMethods([]string{key.Algorithm}))
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(*jwt.Token) (interface{}, error) {
+		return key.public, nil
+	}, options...)
+	if err != nil {
+		return err
+	}
+	if !token.Valid {
+		return jwt.ErrTokenSignatureInvalid
+	}
+	return nil
+}
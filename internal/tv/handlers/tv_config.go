@@ -45,7 +45,7 @@ func getOrCreateConfig(db *gorm.DB) (tv_models.TVConfig, error) {
 // GetTVConfig returns the current TV config (public).
 // GET /api/tv/config
 func GetTVConfig(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 	cfg, err := getOrCreateConfig(db)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get TV config"})
@@ -57,7 +57,7 @@ func GetTVConfig(c *gin.Context) {
 // SaveTVConfig accepts a partial TVConfig JSON body and merges it into the stored config.
 // POST /api/tv/config
 func SaveTVConfig(c *gin.Context) {
-	db := database.GetDB()
+	db := database.GetDB().WithContext(c)
 
 	current, err := getOrCreateConfig(db)
 	if err != nil {
@@ -0,0 +1,72 @@
+package routes
+
+import (
+	auth_handlers "gcx-cms/internal/shared/auth"
+	"gcx-cms/internal/shared/middleware"
+	shared_models "gcx-cms/internal/shared/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAdminRoutes configures account administration routes
+func SetupAdminRoutes(r *gin.Engine) {
+	admin := r.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware())
+	{
+		manageUsers := middleware.RequirePermission(shared_models.PermUsersManage)
+		manageRoles := middleware.RequirePermission(shared_models.PermRolesManage)
+		readAudit := middleware.RequirePermission(shared_models.PermAuditRead)
+		managePrivacy := middleware.RequirePermission(shared_models.PermPrivacyManage)
+
+		// User management
+		admin.GET("/users", manageUsers, auth_handlers.ListUsersHandler)
+		admin.GET("/users/:id", manageUsers, auth_handlers.GetUserHandler)
+		admin.PUT("/users/:id/role", manageUsers, auth_handlers.UpdateUserRoleHandler)
+		admin.POST("/users/:id/activate", manageUsers, auth_handlers.ActivateUserHandler)
+		admin.POST("/users/:id/deactivate", manageUsers, auth_handlers.DeactivateUserHandler)
+		admin.POST("/users/:id/force-password-reset", manageUsers, auth_handlers.ForcePasswordResetHandler)
+		admin.POST("/users/:id/reset-2fa", manageUsers, auth_handlers.ResetUserTwoFactorHandler)
+		admin.GET("/users/:id/sessions", manageUsers, auth_handlers.ListUserSessionsHandler)
+		admin.DELETE("/users/:id/sessions", manageUsers, auth_handlers.RevokeUserSessionsHandler)
+		admin.GET("/users/:id/audit", manageUsers, auth_handlers.ListUserAuditHandler)
+		admin.POST("/users/:id/unlock", manageUsers, auth_handlers.UnlockUserHandler)
+		admin.DELETE("/users/:id/identities/:identityId", manageUsers, auth_handlers.UnlinkUserIdentityHandler)
+
+		// Authentication log and sign-in lockouts
+		admin.GET("/auth-events", manageUsers, auth_handlers.ListAuthEventsHandler)
+		admin.GET("/lockouts", manageUsers, auth_handlers.ListLockoutsHandler)
+		admin.POST("/lockouts/unlock", manageUsers, auth_handlers.UnlockHandler)
+
+		// Audit trail of changes and its hash chain
+		admin.GET("/audit", readAudit, auth_handlers.ListAuditHandler)
+		admin.GET("/audit/verify", readAudit, auth_handlers.VerifyAuditHandler)
+		admin.GET("/audit/:id", readAudit, auth_handlers.GetAuditEntryHandler)
+
+		// Data-protection access and erasure requests
+		admin.POST("/privacy/search", managePrivacy, auth_handlers.SearchPersonalDataHandler)
+		admin.POST("/privacy/export", managePrivacy, auth_handlers.ExportPersonalDataHandler)
+		admin.POST("/privacy/erase", managePrivacy, auth_handlers.ErasePersonalDataHandler)
+		admin.GET("/privacy/holds", managePrivacy, auth_handlers.ListLegalHoldsHandler)
+		admin.POST("/privacy/holds", managePrivacy, auth_handlers.CreateLegalHoldHandler)
+		admin.DELETE("/privacy/holds/:id", managePrivacy, auth_handlers.ReleaseLegalHoldHandler)
+
+		// Self-registration settings and market data sign-up approvals
+		admin.GET("/registration/settings", manageUsers, auth_handlers.GetRegistrationSettingsHandler)
+		admin.PUT("/registration/settings", manageUsers, auth_handlers.UpdateRegistrationSettingsHandler)
+		admin.GET("/registration/pending", manageUsers, auth_handlers.ListPendingApprovalsHandler)
+		admin.POST("/registration/:id/approve", manageUsers, auth_handlers.ApproveRegistrationHandler)
+		admin.POST("/registration/:id/reject", manageUsers, auth_handlers.RejectRegistrationHandler)
+
+		// Invites for privileged accounts
+		admin.GET("/invites", manageUsers, auth_handlers.ListInvitesHandler)
+		admin.POST("/invites", manageUsers, auth_handlers.CreateInviteHandler)
+		admin.DELETE("/invites/:id", manageUsers, auth_handlers.RevokeInviteHandler)
+
+		// Roles and the permissions they grant
+		admin.GET("/permissions", manageRoles, auth_handlers.ListPermissionsHandler)
+		admin.GET("/roles", manageRoles, auth_handlers.ListRolesHandler)
+		admin.POST("/roles", manageRoles, auth_handlers.CreateRoleHandler)
+		admin.PUT("/roles/:id", manageRoles, auth_handlers.UpdateRoleHandler)
+		admin.DELETE("/roles/:id", manageRoles, auth_handlers.DeleteRoleHandler)
+	}
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	auth_handlers "gcx-cms/internal/shared/auth"
+	"gcx-cms/internal/shared/middleware"
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,6 +14,39 @@ func SetupAuthRoutes(r *gin.Engine) {
 	// Public auth routes (no authentication required)
 	{
 		auth.POST("/login", auth_handlers.LoginHandler)
+		auth.POST("/login/2fa", auth_handlers.VerifyTwoFactorLoginHandler)
 		auth.POST("/register", auth_handlers.RegisterHandler)
+		auth.POST("/refresh", auth_handlers.RefreshHandler)
+		auth.POST("/forgot-password", auth_handlers.ForgotPasswordHandler)
+		auth.POST("/reset-password", auth_handlers.ResetPasswordHandler)
+		auth.POST("/verify-email", auth_handlers.VerifyEmailHandler)
+		auth.POST("/accept-invite", auth_handlers.AcceptInviteHandler)
+
+		// Single sign-on through the OpenID Connect provider
+		auth.GET("/oidc", auth_handlers.OIDCConfigHandler)
+		auth.GET("/oidc/login", auth_handlers.OIDCLoginHandler)
+		auth.POST("/oidc/callback", auth_handlers.OIDCCallbackHandler)
+	}
+
+	// Session management (authentication required)
+	sessions := auth.Group("")
+	sessions.Use(middleware.AuthMiddleware())
+	{
+		sessions.POST("/logout", auth_handlers.LogoutHandler)
+		sessions.POST("/logout-all", auth_handlers.LogoutAllHandler)
+		sessions.GET("/sessions", auth_handlers.ListSessionsHandler)
+		sessions.DELETE("/sessions/:id", auth_handlers.RevokeSessionHandler)
+		sessions.POST("/resend-verification", auth_handlers.ResendVerificationHandler)
+		sessions.GET("/permissions", auth_handlers.MyPermissionsHandler)
+
+		// Two-factor authentication
+		sessions.GET("/2fa", auth_handlers.TwoFactorStatusHandler)
+		sessions.POST("/2fa/setup", auth_handlers.SetupTwoFactorHandler)
+		sessions.POST("/2fa/enable", auth_handlers.EnableTwoFactorHandler)
+		sessions.POST("/2fa/disable", auth_handlers.DisableTwoFactorHandler)
+		sessions.POST("/2fa/recovery-codes", auth_handlers.RegenerateRecoveryCodesHandler)
 	}
+
+	// Public keys for verifying GCX tokens
+	r.GET("/.well-known/jwks.json", auth_handlers.JWKSHandler)
 }
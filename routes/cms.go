@@ -2,7 +2,9 @@ package routes
 
 import (
 	"gcx-cms/internal/cms/handlers"
+	"gcx-cms/internal/shared/cache"
 	"gcx-cms/internal/shared/middleware"
+	shared_models "gcx-cms/internal/shared/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -61,19 +63,21 @@ func SetupCMSRoutes(r *gin.Engine) {
 		cms.GET("/careers/:id", handlers.GetCareer) // GET /api/careers/{id}
 
 		// Public commodities (for website)
-		cms.GET("/commodities", handlers.GetCommodities)   // GET /api/commodities (list all commodities)
-		
+		cms.GET("/commodities", middleware.CacheResponse(cache.NamespaceCommodities), handlers.GetCommodities) // GET /api/commodities (list all commodities)
+
 		// Contract file presigned URL (for website) - must be before :id
 		cms.GET("/commodities/contract-url", handlers.GetContractFilePresignedURL) // GET /api/commodities/contract-url
-		
-		cms.GET("/commodities/:id", handlers.GetCommodity) // GET /api/commodities/{id}
+
+		cms.GET("/commodities/:id", middleware.CacheResponse(cache.NamespaceCommodities), handlers.GetCommodity) // GET /api/commodities/{id}
+		cms.GET("/commodities/:id/contracts", handlers.GetCommodityContracts)                                    // GET /api/commodities/{id}/contracts?status=active|expired|all
 
 		// Public commodities with contract types (for website)
-		cms.GET("/commodities-with-contract-types", handlers.GetCommoditiesWithContractTypes) // GET /api/commodities-with-contract-types
+		cms.GET("/commodities-with-contract-types", middleware.CacheResponse(cache.NamespaceCommodities), handlers.GetCommoditiesWithContractTypes) // GET /api/commodities-with-contract-types
 
 		// Public contract types (for website)
-		cms.GET("/contract-types/commodity/:commodityId", handlers.GetAllCommodityContractTypes) // GET /api/contract-types/commodity/{commodityId}
-		cms.GET("/contract-types/:id", handlers.GetCommodityContractType)                        // GET /api/contract-types/{id}
+		cms.GET("/contract-types/commodity/:commodityId", handlers.GetAllCommodityContractTypes) // GET /api/contract-types/commodity/{commodityId}?as_of=YYYY-MM-DD
+		cms.GET("/contract-types/:id", handlers.GetCommodityContractType)                        // GET /api/contract-types/{id}?as_of=YYYY-MM-DD
+		cms.GET("/contract-types/:id/versions", handlers.GetContractSpecVersions)                // GET /api/contract-types/{id}/versions (approved spec history)
 
 		// Public events (for website)
 		cms.GET("/events", handlers.GetEvents)                      // GET /api/events (list all events with filters)
@@ -111,22 +115,46 @@ func SetupCMSRoutes(r *gin.Engine) {
 		cms.GET("/news-categories", handlers.GetNewsCategories) // GET /api/news-categories (list news categories)
 	}
 
-	// Protected CMS routes (authentication required)
+	// Protected CMS routes (authentication and the permission for each area required)
 	protected := cms.Group("")
 	protected.Use(middleware.AuthMiddleware())
 	{
+		// Permissions required by each area of the CMS
+		viewDashboard := middleware.RequirePermission(shared_models.PermDashboardView)
+		manageMedia := middleware.RequirePermission(shared_models.PermMediaManage)
+		managePages := middleware.RequirePermission(shared_models.PermPagesManage)
+		manageMenus := middleware.RequirePermission(shared_models.PermMenusManage)
+		readSettings := middleware.RequirePermission(shared_models.PermSettingsRead)
+		writeSettings := middleware.RequirePermission(shared_models.PermSettingsWrite)
+		manageBoard := middleware.RequirePermission(shared_models.PermBoardManage)
+		manageTeam := middleware.RequirePermission(shared_models.PermTeamManage)
+		manageDirectory := middleware.RequirePermission(shared_models.PermDirectoryManage)
+		managePartners := middleware.RequirePermission(shared_models.PermPartnersManage)
+		managePublications := middleware.RequirePermission(shared_models.PermPublicationsManage)
+		manageCareers := middleware.RequirePermission(shared_models.PermCareersManage)
+		manageCommodities := middleware.RequirePermission(shared_models.PermCommoditiesManage)
+		approveContracts := middleware.RequirePermission(shared_models.PermContractsApprove)
+		manageEvents := middleware.RequirePermission(shared_models.PermEventsManage)
+		readRTI := middleware.RequirePermission(shared_models.PermRTIRead)
+		respondRTI := middleware.RequirePermission(shared_models.PermRTIRespond)
+		manageRTI := middleware.RequirePermission(shared_models.PermRTIManage)
+		manageGalleries := middleware.RequirePermission(shared_models.PermGalleriesManage)
+		manageVideos := middleware.RequirePermission(shared_models.PermVideosManage)
+		manageNews := middleware.RequirePermission(shared_models.PermNewsManage)
+		publishNews := middleware.RequirePermission(shared_models.PermNewsPublish)
+
 		// Dashboard routes
-		protected.GET("/cms/dashboard/stats", handlers.GetDashboardStats)
-		protected.GET("/cms/dashboard/activity", handlers.GetDashboardActivity)
+		protected.GET("/cms/dashboard/stats", viewDashboard, handlers.GetDashboardStats)
+		protected.GET("/cms/dashboard/activity", viewDashboard, handlers.GetDashboardActivity)
 
 		// User profile management
 		protected.GET("/user/profile", handlers.GetProfile)
 		protected.PUT("/user/profile", handlers.UpdateProfile)
 		protected.POST("/user/change-password", handlers.ChangePassword)
 
-		// CMS Blog management (requires blogger or admin role)
+		// CMS Blog management (requires blog.write; blog.manage_all to see other authors' posts)
 		cmsProtected := protected.Group("/cms")
-		cmsProtected.Use(middleware.BloggerMiddleware())
+		cmsProtected.Use(middleware.RequirePermission(shared_models.PermBlogWrite))
 		{
 			cmsProtected.GET("/posts", handlers.GetAllPosts)       // Get all posts for CMS
 			cmsProtected.POST("/posts", handlers.CreatePost)       // Create new post
@@ -135,149 +163,160 @@ func SetupCMSRoutes(r *gin.Engine) {
 			cmsProtected.DELETE("/posts/:id", handlers.DeletePost) // Delete post
 		}
 
-		// Media management routes (requires authentication)
-		protected.GET("/media", handlers.GetMedia)           // GET /api/media (list all media)
-		protected.POST("/media", handlers.UploadFile)        // POST /api/media (upload file)
-		protected.POST("/upload", handlers.UploadFile)       // POST /api/upload (alternative upload endpoint)
-		protected.GET("/media/:id", handlers.GetMediaFile)   // GET /api/media/{id}
-		protected.DELETE("/media/:id", handlers.DeleteMedia) // DELETE /api/media/{id}
-
-		// Document management routes (requires authentication)
-		protected.GET("/documents", handlers.GetDocuments)    // GET /api/documents (list all documents)
-		protected.POST("/documents", handlers.UploadDocument) // POST /api/documents (upload document)
-
-		// Page management routes (requires authentication)
-		protected.GET("/pages", handlers.GetPages)             // GET /api/pages (list all pages)
-		protected.POST("/pages", handlers.CreatePage)          // POST /api/pages (create page)
-		protected.GET("/pages/id/:id", handlers.GetPage)       // GET /api/pages/id/{id} (get page by ID)
-		protected.PUT("/pages/id/:id", handlers.UpdatePage)    // PUT /api/pages/id/{id} (update page)
-		protected.DELETE("/pages/id/:id", handlers.DeletePage) // DELETE /api/pages/id/{id} (delete page)
-
-		// Settings management routes (requires authentication)
-		protected.GET("/settings", handlers.GetSettings)                     // GET /api/settings (list all settings)
-		protected.GET("/settings/group/:group", handlers.GetSettingsByGroup) // GET /api/settings/group/{group}
-		protected.GET("/settings/:key", handlers.GetSetting)                 // GET /api/settings/{key}
-		protected.POST("/settings", handlers.CreateSetting)                  // POST /api/settings (create setting)
-		protected.PUT("/settings/batch", handlers.UpdateSettingsBatch)       // PUT /api/settings/batch (update multiple)
-
-		// Board Members management routes (requires authentication)
-		protected.GET("/board-members", handlers.GetBoardMembers)             // GET /api/board-members (list all board members)
-		protected.POST("/board-members", handlers.CreateBoardMember)          // POST /api/board-members (create board member)
-		protected.GET("/board-members/:id", handlers.GetBoardMember)          // GET /api/board-members/{id}
-		protected.PUT("/board-members/:id", handlers.UpdateBoardMember)       // PUT /api/board-members/{id}
-		protected.DELETE("/board-members/:id", handlers.DeleteBoardMember)    // DELETE /api/board-members/{id}
-		protected.PUT("/board-members/reorder", handlers.ReorderBoardMembers) // PUT /api/board-members/reorder
-
-		// Team Members management routes (requires authentication) - CRUD operations only
-		protected.POST("/team-members", handlers.CreateTeamMember)          // POST /api/team-members (create team member)
-		protected.PUT("/team-members/:id", handlers.UpdateTeamMember)       // PUT /api/team-members/{id}
-		protected.DELETE("/team-members/:id", handlers.DeleteTeamMember)    // DELETE /api/team-members/{id}
-		protected.PUT("/team-members/reorder", handlers.ReorderTeamMembers) // PUT /api/team-members/reorder
-
-		// Traders management routes (requires authentication) - CRUD operations
-		protected.POST("/traders", handlers.CreateTrader)       // POST /api/traders (create trader)
-		protected.PUT("/traders/:id", handlers.UpdateTrader)    // PUT /api/traders/{id}
-		protected.DELETE("/traders/:id", handlers.DeleteTrader) // DELETE /api/traders/{id}
-
-		// Brokers management routes (requires authentication) - CRUD operations
-		protected.POST("/brokers", handlers.CreateBroker)       // POST /api/brokers (create broker)
-		protected.PUT("/brokers/:id", handlers.UpdateBroker)    // PUT /api/brokers/{id}
-		protected.DELETE("/brokers/:id", handlers.DeleteBroker) // DELETE /api/brokers/{id}
-
-		// Partners management routes (requires authentication) - CRUD operations
-		protected.GET("/cms/partners", handlers.GetAllPartners)       // GET /api/cms/partners (list all partners for CMS)
-		protected.POST("/cms/partners", handlers.CreatePartner)       // POST /api/cms/partners (create partner)
-		protected.GET("/cms/partners/:id", handlers.GetPartner)       // GET /api/cms/partners/{id}
-		protected.PUT("/cms/partners/:id", handlers.UpdatePartner)    // PUT /api/cms/partners/{id}
-		protected.DELETE("/cms/partners/:id", handlers.DeletePartner) // DELETE /api/cms/partners/{id}
-
-		// Publications management routes (requires authentication) - CRUD operations
-		protected.POST("/publications", handlers.CreatePublication)       // POST /api/publications (create publication)
-		protected.PUT("/publications/:id", handlers.UpdatePublication)    // PUT /api/publications/{id}
-		protected.DELETE("/publications/:id", handlers.DeletePublication) // DELETE /api/publications/{id}
-
-		// Careers management routes (requires authentication) - CRUD operations
-		protected.POST("/careers", handlers.CreateCareer)       // POST /api/careers (create career)
-		protected.PUT("/careers/:id", handlers.UpdateCareer)    // PUT /api/careers/{id}
-		protected.DELETE("/careers/:id", handlers.DeleteCareer) // DELETE /api/careers/{id}
-
-		// Commodities management routes (requires authentication) - CRUD operations
-		protected.POST("/commodities", handlers.CreateCommodity)       // POST /api/commodities (create commodity)
-		protected.PUT("/commodities/:id", handlers.UpdateCommodity)    // PUT /api/commodities/{id}
-		protected.DELETE("/commodities/:id", handlers.DeleteCommodity) // DELETE /api/commodities/{id}
-
-		// Contract Types management routes (requires authentication) - CRUD operations
-		protected.POST("/contract-types", handlers.CreateCommodityContractType)        // POST /api/contract-types (create contract type)
-		protected.PUT("/contract-types/:id", handlers.UpdateCommodityContractType)     // PUT /api/contract-types/{id}
-		protected.DELETE("/contract-types/:id", handlers.DeleteCommodityContractType)  // DELETE /api/contract-types/{id}
-		protected.PUT("/contract-types/reorder", handlers.UpdateContractTypeSortOrder) // PUT /api/contract-types/reorder
-		protected.PUT("/settings/:key", handlers.UpdateSetting)                        // PUT /api/settings/{key} (update setting)
-		protected.DELETE("/settings/:key", handlers.DeleteSetting)                     // DELETE /api/settings/{key} (delete setting)
-
-		// Events management routes (requires authentication) - CRUD operations
-		protected.GET("/cms/events", handlers.GetAllEvents)                            // GET /api/cms/events (list all events for CMS)
-		protected.POST("/cms/events", handlers.CreateEvent)                            // POST /api/cms/events (create event)
-		protected.PUT("/cms/events/:id", handlers.UpdateEvent)                         // PUT /api/cms/events/{id}
-		protected.DELETE("/cms/events/:id", handlers.DeleteEvent)                      // DELETE /api/cms/events/{id}
-		protected.GET("/cms/events/stats", handlers.GetEventStats)                     // GET /api/cms/events/stats
-		protected.GET("/cms/events/:id/registrations", handlers.GetEventRegistrations) // GET /api/cms/events/{id}/registrations
-
-		// RTI management routes (requires authentication) - CRUD operations
-		protected.GET("/cms/rti/requests", handlers.GetAllRTIRequests)                // GET /api/cms/rti/requests (list all RTI requests)
-		protected.GET("/cms/rti/requests/:id", handlers.GetRTIRequest)                // GET /api/cms/rti/requests/{id}
-		protected.PUT("/cms/rti/requests/:id", handlers.UpdateRTIRequest)             // PUT /api/cms/rti/requests/{id}
-		protected.POST("/cms/rti/requests/:id/respond", handlers.RespondToRTIRequest) // POST /api/cms/rti/requests/{id}/respond
-		protected.PUT("/cms/rti/requests/:id/status", handlers.UpdateRTIStatus)       // PUT /api/cms/rti/requests/{id}/status
-		protected.DELETE("/cms/rti/requests/:id", handlers.DeleteRTIRequest)          // DELETE /api/cms/rti/requests/{id}
-		protected.GET("/cms/rti/stats", handlers.GetRTIStats)                         // GET /api/cms/rti/stats
-
-		// RTI documents management (requires authentication) - CRUD operations
-		protected.GET("/cms/rti/documents", handlers.GetAllRTIDocuments)       // GET /api/cms/rti/documents (list all documents)
-		protected.POST("/cms/rti/documents", handlers.CreateRTIDocument)       // POST /api/cms/rti/documents (create document)
-		protected.PUT("/cms/rti/documents/:id", handlers.UpdateRTIDocument)    // PUT /api/cms/rti/documents/{id}
-		protected.DELETE("/cms/rti/documents/:id", handlers.DeleteRTIDocument) // DELETE /api/cms/rti/documents/{id}
-
-		// Photo gallery management (requires authentication) - CRUD operations
-		protected.GET("/cms/galleries", handlers.GetAllGalleries)                  // GET /api/cms/galleries (list all galleries)
-		protected.POST("/cms/galleries", handlers.CreateGallery)                   // POST /api/cms/galleries (create gallery)
-		protected.PUT("/cms/galleries/:id", handlers.UpdateGallery)                // PUT /api/cms/galleries/{id}
-		protected.DELETE("/cms/galleries/:id", handlers.DeleteGallery)             // DELETE /api/cms/galleries/{id}
-		protected.POST("/cms/galleries/:id/photos", handlers.AddPhotoToGallery)    // POST /api/cms/galleries/{id}/photos (add photo)
-		protected.PUT("/cms/galleries/photos/:id", handlers.UpdateGalleryPhoto)    // PUT /api/cms/galleries/photos/{id}
-		protected.DELETE("/cms/galleries/photos/:id", handlers.DeleteGalleryPhoto) // DELETE /api/cms/galleries/photos/{id}
-
-		// Video library management (requires authentication) - CRUD operations
-		protected.GET("/cms/video-libraries", handlers.GetAllVideoLibraries)             // GET /api/cms/video-libraries (list all libraries)
-		protected.POST("/cms/video-libraries", handlers.CreateVideoLibrary)              // POST /api/cms/video-libraries (create library)
-		protected.PUT("/cms/video-libraries/:id", handlers.UpdateVideoLibrary)           // PUT /api/cms/video-libraries/{id}
-		protected.DELETE("/cms/video-libraries/:id", handlers.DeleteVideoLibrary)        // DELETE /api/cms/video-libraries/{id}
-		protected.POST("/cms/video-libraries/:id/videos", handlers.AddVideoToLibrary)    // POST /api/cms/video-libraries/{id}/videos (add video)
-		protected.PUT("/cms/video-libraries/videos/:id", handlers.UpdateLibraryVideo)    // PUT /api/cms/video-libraries/videos/{id}
-		protected.DELETE("/cms/video-libraries/videos/:id", handlers.DeleteLibraryVideo) // DELETE /api/cms/video-libraries/videos/{id}
-
-		// Menu management routes (requires authentication)
-		protected.GET("/menus", handlers.GetMenus)                       // GET /api/menus (list all menus)
-		protected.POST("/menus", handlers.CreateMenu)                    // POST /api/menus (create menu)
-		protected.GET("/menus/id/:id", handlers.GetMenu)                 // GET /api/menus/id/{id} (get menu by ID)
-		protected.PUT("/menus/id/:id", handlers.UpdateMenu)              // PUT /api/menus/id/{id} (update menu)
-		protected.DELETE("/menus/id/:id", handlers.DeleteMenu)           // DELETE /api/menus/id/{id} (delete menu)
-		protected.GET("/menus/:menu_id/items", handlers.GetMenuItems)    // GET /api/menus/{menu_id}/items
-		protected.POST("/menus/:menu_id/items", handlers.CreateMenuItem) // POST /api/menus/{menu_id}/items
-		protected.PUT("/menu-items/:id", handlers.UpdateMenuItem)        // PUT /api/menu-items/{id}
-		protected.DELETE("/menu-items/:id", handlers.DeleteMenuItem)     // DELETE /api/menu-items/{id}
-
-		// News management routes (requires authentication) - CRUD operations
-		protected.GET("/cms/news", handlers.GetAllNewsItems)              // GET /api/cms/news (list all news items for CMS)
-		protected.POST("/cms/news", handlers.CreateNewsItem)              // POST /api/cms/news (create news item)
-		protected.PUT("/cms/news/:id", handlers.UpdateNewsItem)           // PUT /api/cms/news/{id} (update news item)
-		protected.DELETE("/cms/news/:id", handlers.DeleteNewsItem)        // DELETE /api/cms/news/{id} (delete news item)
-		protected.POST("/cms/news/:id/publish", handlers.PublishNewsItem) // POST /api/cms/news/{id}/publish (publish news item)
-		protected.POST("/cms/news/:id/archive", handlers.ArchiveNewsItem) // POST /api/cms/news/{id}/archive (archive news item)
-		protected.PUT("/cms/news/:id/breaking", handlers.SetBreakingNews) // PUT /api/cms/news/{id}/breaking (set breaking news)
-
-		// News categories management routes (requires authentication) - CRUD operations
-		protected.POST("/cms/news-categories", handlers.CreateNewsCategory)       // POST /api/cms/news-categories (create news category)
-		protected.PUT("/cms/news-categories/:id", handlers.UpdateNewsCategory)    // PUT /api/cms/news-categories/{id} (update news category)
-		protected.DELETE("/cms/news-categories/:id", handlers.DeleteNewsCategory) // DELETE /api/cms/news-categories/{id} (delete news category)
+		// Media management routes
+		protected.GET("/media", manageMedia, handlers.GetMedia)           // GET /api/media (list all media)
+		protected.POST("/media", manageMedia, handlers.UploadFile)        // POST /api/media (upload file)
+		protected.POST("/upload", manageMedia, handlers.UploadFile)       // POST /api/upload (alternative upload endpoint)
+		protected.GET("/media/:id", manageMedia, handlers.GetMediaFile)   // GET /api/media/{id}
+		protected.DELETE("/media/:id", manageMedia, handlers.DeleteMedia) // DELETE /api/media/{id}
+
+		// Document management routes
+		protected.GET("/documents", manageMedia, handlers.GetDocuments)    // GET /api/documents (list all documents)
+		protected.POST("/documents", manageMedia, handlers.UploadDocument) // POST /api/documents (upload document)
+
+		// Page management routes
+		protected.GET("/pages", managePages, handlers.GetPages)             // GET /api/pages (list all pages)
+		protected.POST("/pages", managePages, handlers.CreatePage)          // POST /api/pages (create page)
+		protected.GET("/pages/id/:id", managePages, handlers.GetPage)       // GET /api/pages/id/{id} (get page by ID)
+		protected.PUT("/pages/id/:id", managePages, handlers.UpdatePage)    // PUT /api/pages/id/{id} (update page)
+		protected.DELETE("/pages/id/:id", managePages, handlers.DeletePage) // DELETE /api/pages/id/{id} (delete page)
+
+		// Settings management routes
+		protected.GET("/settings", readSettings, handlers.GetSettings)                     // GET /api/settings (list all settings)
+		protected.GET("/settings/group/:group", readSettings, handlers.GetSettingsByGroup) // GET /api/settings/group/{group}
+		protected.GET("/settings/:key", readSettings, handlers.GetSetting)                 // GET /api/settings/{key}
+		protected.POST("/settings", writeSettings, handlers.CreateSetting)                 // POST /api/settings (create setting)
+		protected.PUT("/settings/batch", writeSettings, handlers.UpdateSettingsBatch)      // PUT /api/settings/batch (update multiple)
+
+		// Board Members management routes
+		protected.GET("/board-members", manageBoard, handlers.GetBoardMembers)             // GET /api/board-members (list all board members)
+		protected.POST("/board-members", manageBoard, handlers.CreateBoardMember)          // POST /api/board-members (create board member)
+		protected.GET("/board-members/:id", manageBoard, handlers.GetBoardMember)          // GET /api/board-members/{id}
+		protected.PUT("/board-members/:id", manageBoard, handlers.UpdateBoardMember)       // PUT /api/board-members/{id}
+		protected.DELETE("/board-members/:id", manageBoard, handlers.DeleteBoardMember)    // DELETE /api/board-members/{id}
+		protected.PUT("/board-members/reorder", manageBoard, handlers.ReorderBoardMembers) // PUT /api/board-members/reorder
+
+		// Team Members management routes - CRUD operations only
+		protected.POST("/team-members", manageTeam, handlers.CreateTeamMember)          // POST /api/team-members (create team member)
+		protected.PUT("/team-members/:id", manageTeam, handlers.UpdateTeamMember)       // PUT /api/team-members/{id}
+		protected.DELETE("/team-members/:id", manageTeam, handlers.DeleteTeamMember)    // DELETE /api/team-members/{id}
+		protected.PUT("/team-members/reorder", manageTeam, handlers.ReorderTeamMembers) // PUT /api/team-members/reorder
+
+		// Traders management routes - CRUD operations
+		protected.POST("/traders", manageDirectory, handlers.CreateTrader)       // POST /api/traders (create trader)
+		protected.PUT("/traders/:id", manageDirectory, handlers.UpdateTrader)    // PUT /api/traders/{id}
+		protected.DELETE("/traders/:id", manageDirectory, handlers.DeleteTrader) // DELETE /api/traders/{id}
+
+		// Brokers management routes - CRUD operations
+		protected.POST("/brokers", manageDirectory, handlers.CreateBroker)       // POST /api/brokers (create broker)
+		protected.PUT("/brokers/:id", manageDirectory, handlers.UpdateBroker)    // PUT /api/brokers/{id}
+		protected.DELETE("/brokers/:id", manageDirectory, handlers.DeleteBroker) // DELETE /api/brokers/{id}
+
+		// Partners management routes - CRUD operations
+		protected.GET("/cms/partners", managePartners, handlers.GetAllPartners)       // GET /api/cms/partners (list all partners for CMS)
+		protected.POST("/cms/partners", managePartners, handlers.CreatePartner)       // POST /api/cms/partners (create partner)
+		protected.GET("/cms/partners/:id", managePartners, handlers.GetPartner)       // GET /api/cms/partners/{id}
+		protected.PUT("/cms/partners/:id", managePartners, handlers.UpdatePartner)    // PUT /api/cms/partners/{id}
+		protected.DELETE("/cms/partners/:id", managePartners, handlers.DeletePartner) // DELETE /api/cms/partners/{id}
+
+		// Publications management routes - CRUD operations
+		protected.POST("/publications", managePublications, handlers.CreatePublication)       // POST /api/publications (create publication)
+		protected.PUT("/publications/:id", managePublications, handlers.UpdatePublication)    // PUT /api/publications/{id}
+		protected.DELETE("/publications/:id", managePublications, handlers.DeletePublication) // DELETE /api/publications/{id}
+
+		// Careers management routes - CRUD operations
+		protected.POST("/careers", manageCareers, handlers.CreateCareer)       // POST /api/careers (create career)
+		protected.PUT("/careers/:id", manageCareers, handlers.UpdateCareer)    // PUT /api/careers/{id}
+		protected.DELETE("/careers/:id", manageCareers, handlers.DeleteCareer) // DELETE /api/careers/{id}
+
+		// Commodities management routes - CRUD operations
+		protected.POST("/commodities", manageCommodities, handlers.CreateCommodity)       // POST /api/commodities (create commodity)
+		protected.PUT("/commodities/:id", manageCommodities, handlers.UpdateCommodity)    // PUT /api/commodities/{id}
+		protected.DELETE("/commodities/:id", manageCommodities, handlers.DeleteCommodity) // DELETE /api/commodities/{id}
+
+		// Contract calendar management
+		protected.GET("/commodities/:id/calendar-rules", manageCommodities, handlers.GetContractCalendarRules)        // GET /api/commodities/{id}/calendar-rules
+		protected.PUT("/commodities/:id/calendar-rule", manageCommodities, handlers.SaveContractCalendarRule)         // PUT /api/commodities/{id}/calendar-rule
+		protected.POST("/commodities/:id/contracts/generate", manageCommodities, handlers.GenerateCommodityContracts) // POST /api/commodities/{id}/contracts/generate
+
+		// Contract Types management routes - CRUD operations
+		protected.POST("/contract-types", manageCommodities, handlers.CreateCommodityContractType)        // POST /api/contract-types (create contract type)
+		protected.PUT("/contract-types/:id", manageCommodities, handlers.UpdateCommodityContractType)     // PUT /api/contract-types/{id}
+		protected.DELETE("/contract-types/:id", manageCommodities, handlers.DeleteCommodityContractType)  // DELETE /api/contract-types/{id}
+		protected.PUT("/contract-types/reorder", manageCommodities, handlers.UpdateContractTypeSortOrder) // PUT /api/contract-types/reorder
+		protected.PUT("/settings/:key", writeSettings, handlers.UpdateSetting)                            // PUT /api/settings/{key} (update setting)
+		protected.DELETE("/settings/:key", writeSettings, handlers.DeleteSetting)                         // DELETE /api/settings/{key} (delete setting)
+
+		// Contract specification versions - proposals require approval by someone with contracts.approve
+		protected.GET("/cms/contract-types/:id/versions", manageCommodities, handlers.GetAllContractSpecVersions)            // GET /api/cms/contract-types/{id}/versions
+		protected.POST("/contract-types/:id/versions", manageCommodities, handlers.ProposeContractSpecVersion)               // POST /api/contract-types/{id}/versions
+		protected.POST("/contract-types/versions/:versionId/approve", approveContracts, handlers.ApproveContractSpecVersion) // POST /api/contract-types/versions/{versionId}/approve
+		protected.POST("/contract-types/versions/:versionId/reject", approveContracts, handlers.RejectContractSpecVersion)   // POST /api/contract-types/versions/{versionId}/reject
+
+		// Events management routes - CRUD operations
+		protected.GET("/cms/events", manageEvents, handlers.GetAllEvents)                            // GET /api/cms/events (list all events for CMS)
+		protected.POST("/cms/events", manageEvents, handlers.CreateEvent)                            // POST /api/cms/events (create event)
+		protected.PUT("/cms/events/:id", manageEvents, handlers.UpdateEvent)                         // PUT /api/cms/events/{id}
+		protected.DELETE("/cms/events/:id", manageEvents, handlers.DeleteEvent)                      // DELETE /api/cms/events/{id}
+		protected.GET("/cms/events/stats", manageEvents, handlers.GetEventStats)                     // GET /api/cms/events/stats
+		protected.GET("/cms/events/:id/registrations", manageEvents, handlers.GetEventRegistrations) // GET /api/cms/events/{id}/registrations
+
+		// RTI management routes - CRUD operations
+		protected.GET("/cms/rti/requests", readRTI, handlers.GetAllRTIRequests)                   // GET /api/cms/rti/requests (list all RTI requests)
+		protected.GET("/cms/rti/requests/:id", readRTI, handlers.GetRTIRequest)                   // GET /api/cms/rti/requests/{id}
+		protected.PUT("/cms/rti/requests/:id", respondRTI, handlers.UpdateRTIRequest)             // PUT /api/cms/rti/requests/{id}
+		protected.POST("/cms/rti/requests/:id/respond", respondRTI, handlers.RespondToRTIRequest) // POST /api/cms/rti/requests/{id}/respond
+		protected.PUT("/cms/rti/requests/:id/status", respondRTI, handlers.UpdateRTIStatus)       // PUT /api/cms/rti/requests/{id}/status
+		protected.DELETE("/cms/rti/requests/:id", manageRTI, handlers.DeleteRTIRequest)           // DELETE /api/cms/rti/requests/{id}
+		protected.GET("/cms/rti/stats", readRTI, handlers.GetRTIStats)                            // GET /api/cms/rti/stats
+
+		// RTI documents management - CRUD operations
+		protected.GET("/cms/rti/documents", manageRTI, handlers.GetAllRTIDocuments)       // GET /api/cms/rti/documents (list all documents)
+		protected.POST("/cms/rti/documents", manageRTI, handlers.CreateRTIDocument)       // POST /api/cms/rti/documents (create document)
+		protected.PUT("/cms/rti/documents/:id", manageRTI, handlers.UpdateRTIDocument)    // PUT /api/cms/rti/documents/{id}
+		protected.DELETE("/cms/rti/documents/:id", manageRTI, handlers.DeleteRTIDocument) // DELETE /api/cms/rti/documents/{id}
+
+		// Photo gallery management - CRUD operations
+		protected.GET("/cms/galleries", manageGalleries, handlers.GetAllGalleries)                  // GET /api/cms/galleries (list all galleries)
+		protected.POST("/cms/galleries", manageGalleries, handlers.CreateGallery)                   // POST /api/cms/galleries (create gallery)
+		protected.PUT("/cms/galleries/:id", manageGalleries, handlers.UpdateGallery)                // PUT /api/cms/galleries/{id}
+		protected.DELETE("/cms/galleries/:id", manageGalleries, handlers.DeleteGallery)             // DELETE /api/cms/galleries/{id}
+		protected.POST("/cms/galleries/:id/photos", manageGalleries, handlers.AddPhotoToGallery)    // POST /api/cms/galleries/{id}/photos (add photo)
+		protected.PUT("/cms/galleries/photos/:id", manageGalleries, handlers.UpdateGalleryPhoto)    // PUT /api/cms/galleries/photos/{id}
+		protected.DELETE("/cms/galleries/photos/:id", manageGalleries, handlers.DeleteGalleryPhoto) // DELETE /api/cms/galleries/photos/{id}
+
+		// Video library management - CRUD operations
+		protected.GET("/cms/video-libraries", manageVideos, handlers.GetAllVideoLibraries)             // GET /api/cms/video-libraries (list all libraries)
+		protected.POST("/cms/video-libraries", manageVideos, handlers.CreateVideoLibrary)              // POST /api/cms/video-libraries (create library)
+		protected.PUT("/cms/video-libraries/:id", manageVideos, handlers.UpdateVideoLibrary)           // PUT /api/cms/video-libraries/{id}
+		protected.DELETE("/cms/video-libraries/:id", manageVideos, handlers.DeleteVideoLibrary)        // DELETE /api/cms/video-libraries/{id}
+		protected.POST("/cms/video-libraries/:id/videos", manageVideos, handlers.AddVideoToLibrary)    // POST /api/cms/video-libraries/{id}/videos (add video)
+		protected.PUT("/cms/video-libraries/videos/:id", manageVideos, handlers.UpdateLibraryVideo)    // PUT /api/cms/video-libraries/videos/{id}
+		protected.DELETE("/cms/video-libraries/videos/:id", manageVideos, handlers.DeleteLibraryVideo) // DELETE /api/cms/video-libraries/videos/{id}
+
+		// Menu management routes
+		protected.GET("/menus", manageMenus, handlers.GetMenus)                       // GET /api/menus (list all menus)
+		protected.POST("/menus", manageMenus, handlers.CreateMenu)                    // POST /api/menus (create menu)
+		protected.GET("/menus/id/:id", manageMenus, handlers.GetMenu)                 // GET /api/menus/id/{id} (get menu by ID)
+		protected.PUT("/menus/id/:id", manageMenus, handlers.UpdateMenu)              // PUT /api/menus/id/{id} (update menu)
+		protected.DELETE("/menus/id/:id", manageMenus, handlers.DeleteMenu)           // DELETE /api/menus/id/{id} (delete menu)
+		protected.GET("/menus/:menu_id/items", manageMenus, handlers.GetMenuItems)    // GET /api/menus/{menu_id}/items
+		protected.POST("/menus/:menu_id/items", manageMenus, handlers.CreateMenuItem) // POST /api/menus/{menu_id}/items
+		protected.PUT("/menu-items/:id", manageMenus, handlers.UpdateMenuItem)        // PUT /api/menu-items/{id}
+		protected.DELETE("/menu-items/:id", manageMenus, handlers.DeleteMenuItem)     // DELETE /api/menu-items/{id}
+
+		// News management routes - CRUD operations
+		protected.GET("/cms/news", manageNews, handlers.GetAllNewsItems)               // GET /api/cms/news (list all news items for CMS)
+		protected.POST("/cms/news", manageNews, handlers.CreateNewsItem)               // POST /api/cms/news (create news item)
+		protected.PUT("/cms/news/:id", manageNews, handlers.UpdateNewsItem)            // PUT /api/cms/news/{id} (update news item)
+		protected.DELETE("/cms/news/:id", manageNews, handlers.DeleteNewsItem)         // DELETE /api/cms/news/{id} (delete news item)
+		protected.POST("/cms/news/:id/publish", publishNews, handlers.PublishNewsItem) // POST /api/cms/news/{id}/publish (publish news item)
+		protected.POST("/cms/news/:id/archive", publishNews, handlers.ArchiveNewsItem) // POST /api/cms/news/{id}/archive (archive news item)
+		protected.PUT("/cms/news/:id/breaking", publishNews, handlers.SetBreakingNews) // PUT /api/cms/news/{id}/breaking (set breaking news)
+
+		// News categories management routes - CRUD operations
+		protected.POST("/cms/news-categories", manageNews, handlers.CreateNewsCategory)       // POST /api/cms/news-categories (create news category)
+		protected.PUT("/cms/news-categories/:id", manageNews, handlers.UpdateNewsCategory)    // PUT /api/cms/news-categories/{id} (update news category)
+		protected.DELETE("/cms/news-categories/:id", manageNews, handlers.DeleteNewsCategory) // DELETE /api/cms/news-categories/{id} (delete news category)
 	}
 }
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"gcx-cms/internal/graphql"
+	"gcx-cms/internal/shared/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupGraphQLRoutes configures the read-only GraphQL API
+func SetupGraphQLRoutes(r *gin.Engine) {
+	// Anonymous requests see public data; a bearer token unlocks entitled fields such as realtimePrices
+	handler := graphql.Handler(graphql.NewSchema())
+
+	gql := r.Group("/api/graphql")
+	gql.Use(middleware.OptionalAuthMiddleware())
+	{
+		gql.POST("", handler) // POST /api/graphql {"query": ..., "variables": ...}
+		gql.GET("", handler)  // GET /api/graphql?query=...&variables=...
+	}
+}
@@ -1,17 +1,26 @@
 package routes
 
 import (
+	"gcx-cms/internal/shared/audit"
+	"gcx-cms/internal/shared/middleware"
+
 	"github.com/gin-gonic/gin"
 )
 
 // SetupAllRoutes configures all application routes
 func SetupAllRoutes(r *gin.Engine) {
+	// Tag every request and collect the audit trail of the changes it makes
+	r.Use(middleware.RequestID(), audit.Track())
+
 	// Setup different route modules
 	SetupAuthRoutes(r)
+	SetupAdminRoutes(r)
 	SetupCMSRoutes(r)
 	SetupMarketDataRoutes(r)
+	SetupMarketDataAdminRoutes(r)
 	SetupUploadRoutes(r)
 	SetupTVRoutes(r)
+	SetupGraphQLRoutes(r)
 
 	// Health check
 	r.GET("/health", func(c *gin.Context) {
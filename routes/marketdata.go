@@ -2,7 +2,10 @@ package routes
 
 import (
 	"gcx-cms/internal/marketdata/handlers"
+	"gcx-cms/internal/marketdata/models"
+	"gcx-cms/internal/shared/cache"
 	"gcx-cms/internal/shared/middleware"
+	shared_models "gcx-cms/internal/shared/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,63 +18,153 @@ func SetupMarketDataRoutes(r *gin.Engine) {
 	// Public routes (no authentication required)
 	{
 		// Get current market prices
-		marketData.GET("/prices", handlers.GetCurrentPrices)
+		marketData.GET("/prices", middleware.CacheResponse(cache.NamespaceMarketData), handlers.GetCurrentPrices)
 
 		// Get commodity prices
-		marketData.GET("/prices/:commodity", handlers.GetCommodityPrices)
+		marketData.GET("/prices/:commodity", middleware.CacheResponse(cache.NamespaceMarketData), handlers.GetCommodityPrices)
 
 		// Get historical prices
 		marketData.GET("/history", handlers.GetHistoricalPrices)
 
 		// Get price summary
-		marketData.GET("/summary/:commodity", handlers.GetPriceSummary)
+		marketData.GET("/summary/:commodity", middleware.CacheResponse(cache.NamespaceMarketData), handlers.GetPriceSummary)
 
 		// Get subscription plans (public pricing)
 		marketData.GET("/plans", handlers.GetSubscriptionPlans)
+
+		// Get trading session status
+		marketData.GET("/session", handlers.GetTradingSession)
+
+		// GCX indices
+		marketData.GET("/indices", handlers.GetIndices)
+		marketData.GET("/indices/:code", handlers.GetIndex)
+		marketData.GET("/indices/:code/history", handlers.GetIndexHistory)
+		marketData.GET("/indices/:code/versions", handlers.GetIndexVersions)
 	}
 
-	// Protected routes (authentication required)
+	// Protected routes (authentication and market data access required)
 	protected := marketData.Group("")
-	protected.Use(middleware.AuthMiddleware())
+	protected.Use(middleware.AuthMiddleware(), middleware.RequirePermission(shared_models.PermMarketDataAccess))
 	{
+		// Data types beyond basic access need their own permission or a plan that includes them,
+		// the user's own or their organisation's
+		realTime := handlers.RequireDataAccess(models.DataTypeRealTime)
+		historical := handlers.RequireDataAccess(models.DataTypeHistorical)
+		analytics := handlers.RequireDataAccess(models.DataTypeAnalytics)
+
+		// Usage metering and daily quotas per data type
+		meterRealTime := handlers.MeterUsage(models.DataTypeRealTime)
+		meterHistorical := handlers.MeterUsage(models.DataTypeHistorical)
+		meterAnalytics := handlers.MeterUsage(models.DataTypeAnalytics)
+		meterAlerts := handlers.MeterUsage(models.DataTypeAlerts)
+		protected.GET("/usage", handlers.GetUsage)
+
 		// User subscription management
 		protected.GET("/subscription", handlers.GetUserSubscription)
 		protected.POST("/subscription", handlers.CreateSubscription)
 		protected.PUT("/subscription/:id", handlers.UpdateSubscription)
 		protected.DELETE("/subscription/:id", handlers.CancelSubscription)
 
+		// Organisations share a subscription across its seats
+		protected.GET("/organization", handlers.GetOrganization)
+		protected.POST("/organization", handlers.CreateOrganization)
+		protected.PUT("/organization", handlers.UpdateOrganization)
+		protected.POST("/organization/leave", handlers.LeaveOrganization)
+		protected.GET("/organization/invites", handlers.GetOrganizationInvites)
+		protected.POST("/organization/invites", handlers.CreateOrganizationInvite)
+		protected.DELETE("/organization/invites/:id", handlers.RevokeOrganizationInvite)
+		protected.POST("/organization/invites/accept", handlers.AcceptOrganizationInvite)
+		protected.PUT("/organization/members/:userId", handlers.UpdateOrganizationMember)
+		protected.DELETE("/organization/members/:userId", handlers.RemoveOrganizationMember)
+
 		// Advanced market data (requires subscription)
-		protected.GET("/analytics", handlers.GetMarketAnalytics)
-		protected.GET("/alerts", handlers.GetPriceAlerts)
-		protected.POST("/alerts", handlers.CreatePriceAlert)
+		protected.GET("/analytics", analytics, meterAnalytics, handlers.GetMarketAnalytics)
+		protected.GET("/seasonality/:commodity", analytics, meterAnalytics, handlers.GetSeasonality)
+		protected.GET("/forecasts", analytics, meterAnalytics, handlers.GetForecasts)
+		protected.POST("/forecasts", analytics, meterAnalytics, handlers.CreateForecast)
+		protected.GET("/forecasts/:id", analytics, meterAnalytics, handlers.GetForecast)
+
+		// Bulk historical exports (requires historical data access)
+		protected.GET("/export", historical, meterHistorical, handlers.ExportHistoricalPrices)
+		protected.GET("/exports", handlers.GetExportJobs)
+		protected.GET("/exports/:id", handlers.GetExportJob)
+		protected.GET("/exports/:id/download", historical, meterHistorical, handlers.DownloadExportJob)
+		protected.GET("/alerts", meterAlerts, handlers.GetPriceAlerts)
+		protected.POST("/alerts", meterAlerts, handlers.CreatePriceAlert)
 		protected.PUT("/alerts/:id", handlers.UpdatePriceAlert)
 		protected.DELETE("/alerts/:id", handlers.DeletePriceAlert)
 
+		// Watchlists and personalised dashboard
+		protected.GET("/watchlist", handlers.GetWatchlistSummary)
+		protected.GET("/watchlists", handlers.GetWatchlists)
+		protected.POST("/watchlists", handlers.CreateWatchlist)
+		protected.PUT("/watchlists/:id", handlers.UpdateWatchlist)
+		protected.DELETE("/watchlists/:id", handlers.DeleteWatchlist)
+
 		// Real-time data (requires premium subscription)
-		protected.GET("/realtime", handlers.GetRealTimeData)
-		protected.GET("/stream", handlers.GetDataStream)
+		protected.GET("/realtime", realTime, meterRealTime, handlers.GetRealTimeData)
+		protected.GET("/stream", realTime, meterRealTime, handlers.GetDataStream)
+
+		// Outbound webhook subscriptions, which push prices as they are published
+		protected.GET("/webhooks", realTime, handlers.GetWebhookSubscriptions)
+		protected.POST("/webhooks", realTime, handlers.CreateWebhookSubscription)
+		protected.PUT("/webhooks/:id", realTime, handlers.UpdateWebhookSubscription)
+		protected.DELETE("/webhooks/:id", realTime, handlers.DeleteWebhookSubscription)
+		protected.GET("/webhooks/:id/deliveries", realTime, handlers.GetWebhookDeliveries)
+		protected.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", realTime, handlers.RedeliverWebhook)
 	}
 }
 
-// SetupMarketDataAdminRoutes configures admin-only market data routes
+// SetupMarketDataAdminRoutes configures market data administration routes, each guarded by its permission
 func SetupMarketDataAdminRoutes(r *gin.Engine) {
 	admin := r.Group("/api/admin/marketdata")
 	admin.Use(middleware.AuthMiddleware())
-	admin.Use(middleware.AdminMiddleware())
 	{
-		// Admin can manage all market data
-		admin.POST("/prices", handlers.AdminCreatePrice)
-		admin.PUT("/prices/:id", handlers.AdminUpdatePrice)
-		admin.DELETE("/prices/:id", handlers.AdminDeletePrice)
-
-		// Admin can manage subscription plans
-		admin.POST("/plans", handlers.AdminCreatePlan)
-		admin.PUT("/plans/:id", handlers.AdminUpdatePlan)
-		admin.DELETE("/plans/:id", handlers.AdminDeletePlan)
-
-		// Admin can manage commodities
-		admin.POST("/commodities", handlers.AdminCreateCommodity)
-		admin.PUT("/commodities/:id", handlers.AdminUpdateCommodity)
-		admin.DELETE("/commodities/:id", handlers.AdminDeleteCommodity)
+		writePrices := middleware.RequirePermission(shared_models.PermMarketDataPricesWrite)
+		managePlans := middleware.RequirePermission(shared_models.PermMarketDataPlans)
+		manageCommodities := middleware.RequirePermission(shared_models.PermMarketDataCommodities)
+		manageSessions := middleware.RequirePermission(shared_models.PermMarketDataSessions)
+		manageIndices := middleware.RequirePermission(shared_models.PermMarketDataIndices)
+		manageReplays := middleware.RequirePermission(shared_models.PermMarketDataReplays)
+		readUsage := middleware.RequirePermission(shared_models.PermMarketDataUsage)
+
+		// Manage all market data
+		admin.POST("/prices", writePrices, handlers.AdminCreatePrice)
+		admin.PUT("/prices/:id", writePrices, handlers.AdminUpdatePrice)
+		admin.DELETE("/prices/:id", writePrices, handlers.AdminDeletePrice)
+
+		// Manage subscription plans
+		admin.POST("/plans", managePlans, handlers.AdminCreatePlan)
+		admin.PUT("/plans/:id", managePlans, handlers.AdminUpdatePlan)
+		admin.DELETE("/plans/:id", managePlans, handlers.AdminDeletePlan)
+
+		// Review organisations and their seats
+		admin.GET("/organizations", managePlans, handlers.AdminGetOrganizations)
+		admin.GET("/organizations/:id", managePlans, handlers.AdminGetOrganization)
+
+		// Manage commodities
+		admin.POST("/commodities", manageCommodities, handlers.AdminCreateCommodity)
+		admin.PUT("/commodities/:id", manageCommodities, handlers.AdminUpdateCommodity)
+		admin.DELETE("/commodities/:id", manageCommodities, handlers.AdminDeleteCommodity)
+
+		// Open, close or halt trading sessions
+		admin.POST("/sessions", manageSessions, handlers.AdminUpdateTradingSession)
+
+		// Define GCX indices and recompute their levels
+		admin.POST("/indices", manageIndices, handlers.AdminCreateIndex)
+		admin.PUT("/indices/:code", manageIndices, handlers.AdminUpdateIndex)
+		admin.POST("/indices/:code/versions", manageIndices, handlers.AdminCreateIndexVersion)
+		admin.POST("/indices/:code/compute", manageIndices, handlers.AdminComputeIndex)
+
+		// Replay historical market data through the price stream
+		admin.GET("/replays", manageReplays, handlers.AdminGetReplays)
+		admin.POST("/replays", manageReplays, handlers.AdminStartReplay)
+		admin.GET("/replays/:id", manageReplays, handlers.AdminGetReplay)
+		admin.POST("/replays/:id/pause", manageReplays, handlers.AdminPauseReplay)
+		admin.POST("/replays/:id/resume", manageReplays, handlers.AdminResumeReplay)
+		admin.POST("/replays/:id/stop", manageReplays, handlers.AdminStopReplay)
+
+		// Review market data usage across users
+		admin.GET("/usage", readUsage, handlers.AdminGetUsageReport)
 	}
 }
@@ -3,6 +3,7 @@ package routes
 import (
 	tv_handlers "gcx-cms/internal/tv/handlers"
 	"gcx-cms/internal/shared/middleware"
+	shared_models "gcx-cms/internal/shared/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,9 +16,9 @@ func SetupTVRoutes(r *gin.Engine) {
 	// Public: reading the config and playing video requires no auth
 	tv.GET("/config", tv_handlers.GetTVConfig)
 
-	// Protected: writing config and uploading assets requires auth
+	// Protected: writing config and uploading assets requires the tv.manage permission
 	protected := tv.Group("")
-	protected.Use(middleware.AuthMiddleware())
+	protected.Use(middleware.AuthMiddleware(), middleware.RequirePermission(shared_models.PermTVManage))
 	{
 		protected.POST("/config", tv_handlers.SaveTVConfig)
 		protected.POST("/upload/image", tv_handlers.UploadTVImage)
@@ -3,6 +3,7 @@ package routes
 import (
 	"gcx-cms/internal/handlers"
 	"gcx-cms/internal/shared/middleware"
+	shared_models "gcx-cms/internal/shared/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,9 +13,9 @@ func SetupUploadRoutes(r *gin.Engine) {
 	// Initialize upload handler
 	uploadHandler := handlers.NewUploadHandler()
 
-	// Upload routes group with authentication
+	// Upload routes group with authentication and media permission
 	upload := r.Group("/api/upload")
-	upload.Use(middleware.AuthMiddleware())
+	upload.Use(middleware.AuthMiddleware(), middleware.RequirePermission(shared_models.PermMediaManage))
 	{
 		// General file upload
 		upload.POST("/file", uploadHandler.UploadFile)